@@ -39,7 +39,7 @@ go mod tidy
 REM Build the application
 echo Building application...
 set CGO_ENABLED=1
-go build -o bin\telegram-store-bot.exe cmd\bot\main.go
+go build -tags sqlite_fts5 -o bin\telegram-store-bot.exe cmd\bot\main.go
 
 if %ERRORLEVEL% EQU 0 (
     echo.
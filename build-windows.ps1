@@ -42,7 +42,7 @@ go mod tidy
 # Build the application
 Write-Host "Building application..." -ForegroundColor Yellow
 $env:CGO_ENABLED = "1"
-go build -o bin\telegram-store-bot.exe cmd\bot\main.go
+go build -tags sqlite_fts5 -o bin\telegram-store-bot.exe cmd\bot\main.go
 
 if ($LASTEXITCODE -eq 0) {
     Write-Host ""
@@ -256,7 +256,76 @@ func (a *AdminCLI) showStatistics() {
 	fmt.Printf("⏳ Pesanan Pending     : -\n")
 	fmt.Printf("📅 Pesanan Hari Ini    : -\n")
 	fmt.Println(strings.Repeat("=", 50))
-	fmt.Println("💡 Statistik lengkap akan tersedia di versi mendatang.")
+
+	a.showProfitSummary()
+}
+
+// showProfitSummary prints revenue, cost and profit per period and breakdowns for the last 30 days
+func (a *AdminCLI) showProfitSummary() {
+	fmt.Println("\n💰 PROFIT & MARGIN")
+	fmt.Println(strings.Repeat("=", 80))
+
+	periods := []struct {
+		days  int
+		label string
+	}{
+		{1, "Hari Ini"},
+		{7, "7 Hari"},
+		{30, "30 Hari"},
+		{0, "Semua"},
+	}
+
+	fmt.Printf("%-12s %-8s %-18s %-18s %-18s %-8s\n", "Periode", "Item", "Omzet", "Modal", "Profit", "Margin")
+	fmt.Println(strings.Repeat("-", 80))
+	for _, period := range periods {
+		summary, err := a.db.GetProfitSummary(period.days)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+		a.printProfitRow(period.label, summary)
+	}
+
+	breakdowns := []struct {
+		groupBy models.ProfitGroupBy
+		title   string
+	}{
+		{models.ProfitByProduct, "PER PRODUK"},
+		{models.ProfitByCategory, "PER KATEGORI"},
+		{models.ProfitBySupplier, "PER SUPPLIER"},
+		{models.ProfitByDay, "PER HARI"},
+	}
+
+	for _, breakdown := range breakdowns {
+		report, err := a.db.GetProfitReport(breakdown.groupBy, 30)
+		if err != nil {
+			fmt.Printf("❌ Error: %v\n", err)
+			return
+		}
+
+		fmt.Printf("\n📈 %s (30 hari terakhir)\n", breakdown.title)
+		fmt.Println(strings.Repeat("-", 80))
+		if len(report) == 0 {
+			fmt.Println("Belum ada penjualan.")
+			continue
+		}
+		for _, row := range report {
+			a.printProfitRow(row.Label, &row)
+		}
+	}
+}
+
+func (a *AdminCLI) printProfitRow(label string, row *models.ProfitReportRow) {
+	if len(label) > 12 {
+		label = label[:9] + "..."
+	}
+	fmt.Printf("%-12s %-8d %-18s %-18s %-18s %6.1f%%\n",
+		label,
+		row.ItemsSold,
+		models.FormatPrice(row.Revenue, a.config.CurrencySymbol),
+		models.FormatPrice(row.Cost, a.config.CurrencySymbol),
+		models.FormatPrice(row.Profit(), a.config.CurrencySymbol),
+		row.Margin())
 }
 
 func (a *AdminCLI) manageCategories() {
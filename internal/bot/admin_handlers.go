@@ -39,7 +39,7 @@ func (b *Bot) handleStockManagement(callback *tgbotapi.CallbackQuery) {
 				stockStatus = "⚠️"
 			}
 
-			text.WriteString(fmt.Sprintf("%s *%s*\n", stockStatus, product.Name))
+			text.WriteString(fmt.Sprintf("%s *%s*\n", stockStatus, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
 			text.WriteString(fmt.Sprintf("   Stok: %d | Harga: %s\n\n", 
 				product.Stock, models.FormatPrice(product.Price, b.config.CurrencySymbol)))
 		}
@@ -52,6 +52,9 @@ func (b *Bot) handleStockManagement(callback *tgbotapi.CallbackQuery) {
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("✏️ Edit Stok", "admin:editstock"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏷️ Batch Stok", "admin:batches"),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
 		),
@@ -61,7 +64,7 @@ func (b *Bot) handleStockManagement(callback *tgbotapi.CallbackQuery) {
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleLowStock shows products with low stock
@@ -90,7 +93,7 @@ func (b *Bot) handleLowStock(callback *tgbotapi.CallbackQuery) {
 				stockIcon = "⚠️"
 			}
 
-			text.WriteString(fmt.Sprintf("%s *%s*\n", stockIcon, product.Name))
+			text.WriteString(fmt.Sprintf("%s *%s*\n", stockIcon, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
 			text.WriteString(fmt.Sprintf("   Stok tersisa: *%d*\n", product.Stock))
 			text.WriteString(fmt.Sprintf("   Kategori: %s\n\n", product.Category))
 		}
@@ -108,7 +111,7 @@ func (b *Bot) handleLowStock(callback *tgbotapi.CallbackQuery) {
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleCategoryManagement handles category management
@@ -132,8 +135,8 @@ func (b *Bot) handleCategoryManagement(callback *tgbotapi.CallbackQuery) {
 		text.WriteString("Belum ada kategori yang tersedia.")
 	} else {
 		for _, category := range categories {
-			text.WriteString(fmt.Sprintf("%s *%s*\n", category.Icon, category.DisplayName))
-			text.WriteString(fmt.Sprintf("   Produk: %d | ID: %s\n\n", category.Count, category.Name))
+			text.WriteString(fmt.Sprintf("%s *%s*\n", category.Icon, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, category.DisplayName)))
+			text.WriteString(fmt.Sprintf("   Produk: %d | ID: %s\n\n", category.Count, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, category.Name)))
 		}
 	}
 
@@ -154,69 +157,7 @@ func (b *Bot) handleCategoryManagement(callback *tgbotapi.CallbackQuery) {
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
-}
-
-// handleProductManagement handles product management
-func (b *Bot) handleProductManagement(callback *tgbotapi.CallbackQuery) {
-	if !b.config.IsAdmin(callback.From.ID) {
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Akses ditolak"))
-		return
-	}
-
-	products, err := b.db.GetProducts("", 10, 0) // Get first 10 products
-	if err != nil {
-		logrus.Errorf("Failed to get products: %v", err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat produk"))
-		return
-	}
-
-	var text strings.Builder
-	text.WriteString("📦 *KELOLA PRODUK*\n\n")
-
-	if len(products) == 0 {
-		text.WriteString("Belum ada produk yang tersedia.")
-	} else {
-		for i, product := range products {
-			if i >= 5 { // Show only first 5 in summary
-				text.WriteString(fmt.Sprintf("... dan %d produk lainnya\n", len(products)-5))
-				break
-			}
-
-			stockStatus := "✅"
-			if product.Stock == 0 {
-				stockStatus = "❌"
-			} else if product.Stock <= 5 {
-				stockStatus = "⚠️"
-			}
-
-			text.WriteString(fmt.Sprintf("%s *%s*\n", stockStatus, product.Name))
-			text.WriteString(fmt.Sprintf("   Stok: %d | %s\n\n", 
-				product.Stock, models.FormatPrice(product.Price, b.config.CurrencySymbol)))
-		}
-	}
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("➕ Tambah Produk", "admin:addproduct"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✏️ Edit Produk", "admin:editproduct"),
-			tgbotapi.NewInlineKeyboardButtonData("🗑️ Hapus Produk", "admin:deleteproduct"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📊 Kelola Stok", "admin:stock"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
-		),
-	)
-
-	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
-	edit.ParseMode = tgbotapi.ModeMarkdown
-	edit.ReplyMarkup = &keyboard
-
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleBroadcastManagement handles broadcast management
@@ -260,7 +201,7 @@ Gunakan fitur broadcast untuk mengirim promosi atau pengumuman ke semua pengguna
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleSendBroadcast initiates broadcast sending process
@@ -286,7 +227,7 @@ Ketik pesan yang ingin Anda kirim ke %s. Pesan akan dikirim dalam format Markdow
 Diskon 50%% untuk semua produk premium!
 Valid sampai 31 Desember 2024.
 
-Gunakan /cancel untuk membatalkan.`, targetText, targetText)
+Gunakan /batal untuk membatalkan.`, targetText, targetText)
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -298,10 +239,10 @@ Gunakan /cancel untuk membatalkan.`, targetText, targetText)
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 
-	// Set user state for broadcast message input
-	b.setUserState(callback.From.ID, fmt.Sprintf("waiting_broadcast_%s", targetType))
+	// Wait for the broadcast message, remembering the target
+	b.setConversation(callback.From.ID, models.StateBroadcast, targetType)
 }
 
 // processBroadcastMessage processes broadcast message from admin
@@ -364,7 +305,7 @@ Apakah Anda yakin ingin mengirim broadcast ini?`, len(targetUsers), broadcastTex
 	msg.ParseMode = tgbotapi.ModeMarkdown
 	msg.ReplyMarkup = keyboard
 
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 // executeBroadcast executes the broadcast to all target users
@@ -384,30 +325,11 @@ func (b *Bot) executeBroadcast(callback *tgbotapi.CallbackQuery, broadcastID int
 		"✅ *BROADCAST SELESAI*\n\nBroadcast telah dikirim ke semua pengguna target.")
 	edit.ParseMode = tgbotapi.ModeMarkdown
 
-	b.api.Send(edit)
+	b.send(edit)
 
 	logrus.Infof("Broadcast %d executed by admin %d", broadcastID, callback.From.ID)
 }
 
-// User state management
-var userStates = make(map[int64]string)
-
-func (b *Bot) setUserState(userID int64, state string) {
-	userStates[userID] = state
-}
-
-func (b *Bot) getUserState(userID int64) string {
-	return userStates[userID]
-}
-
-func (b *Bot) clearUserState(userID int64) {
-	delete(userStates, userID)
-}
-
-func (b *Bot) isUserInState(userID int64, state string) bool {
-	return userStates[userID] == state
-}
-
 // handleAddProductStock handles adding product stock (supports all formats)
 func (b *Bot) handleAddProductStock(callback *tgbotapi.CallbackQuery) {
 	if !b.config.IsAdmin(callback.From.ID) {
@@ -438,7 +360,11 @@ Contoh:
 /addstock 1 account user@gmail.com | pass123
 /addstock 2 link https://netflix.com/redeem?code=ABC
 /addstock 3 code SPOTIFY-CODE-XYZ789
-/addstock 4 custom UserID: 123 | Level: 100`
+/addstock 4 custom UserID: 123 | Level: 100
+
+📥 *Import massal dengan harga modal:*
+/importstock [product_id] [type] [harga_modal] [nama_batch] [supplier]
+lalu 1 item per baris berikutnya.`
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -453,7 +379,7 @@ Contoh:
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // processAddStockCommand processes /addstock command
@@ -510,6 +436,16 @@ Tipe yang tersedia: account, link, code, custom`)
 		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Produk dengan ID %d tidak ditemukan!", productID))
 		return
 	}
+	if product.IsBundle {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ *%s* adalah paket bundling. Tambahkan stok ke produk komponennya.",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+		return
+	}
+	if product.IsGiftCard() {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ *%s* adalah gift card. Kode gift card dibuat otomatis saat dibeli.",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+		return
+	}
 
 	// Add product content to stock
 	err = b.db.AddProductContent(productID, contentType, contentData)
@@ -550,13 +486,132 @@ Tipe yang tersedia: account, link, code, custom`)
 %s
 
 Stok produk berhasil diperbarui dan siap dijual!`,
-		product.Name,
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name),
 		typeIcon,
 		typeLabel,
 		availableStock,
-		contentData)
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, contentData))
 
 	b.sendMessage(message.Chat.ID, successMsg)
+	b.fulfillPreordersAfterRestock(message.Chat.ID, productID)
 
 	logrus.Infof("Admin %d added %s stock for product %d: %s", message.From.ID, contentType, productID, contentData)
-}
\ No newline at end of file
+}
+
+// processImportStockCommand processes /importstock command for bulk stock import as a named batch
+func (b *Bot) processImportStockCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	usage := `❌ Format salah!
+
+Gunakan:
+/importstock [product_id] [type] [harga_modal] [nama_batch] [supplier]
+[data 1]
+[data 2]
+...
+
+Contoh:
+/importstock 1 account 15000 NFX-0412 SupplierA
+user1@gmail.com | pass123
+user2@gmail.com | pass456
+
+Setiap baris setelah baris pertama adalah 1 item stok.
+Nama batch dipakai untuk melacak dan me-revoke stok dari supplier.
+Tipe yang tersedia: account, link, code, custom`
+
+	// First line holds the parameters, remaining lines are stock items
+	lines := strings.Split(message.Text, "\n")
+	header := strings.Fields(lines[0])
+	if len(header) < 5 || len(lines) < 2 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	productID, err := strconv.Atoi(header[1])
+	if err != nil {
+		b.sendMessage(message.Chat.ID, "❌ Product ID harus berupa angka!")
+		return
+	}
+
+	contentType := strings.ToLower(header[2])
+	switch models.ProductContentType(contentType) {
+	case models.ContentTypeAccount, models.ContentTypeLink, models.ContentTypeCode, models.ContentTypeCustom:
+	default:
+		b.sendMessage(message.Chat.ID, "❌ Tipe tidak valid! Gunakan: account, link, code, atau custom")
+		return
+	}
+
+	costPrice, err := strconv.Atoi(header[3])
+	if err != nil || costPrice < 0 {
+		b.sendMessage(message.Chat.ID, "❌ Harga modal harus berupa angka positif!")
+		return
+	}
+
+	batchName := header[4]
+	supplier := strings.Join(header[5:], " ")
+
+	var contents []string
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			contents = append(contents, line)
+		}
+	}
+	if len(contents) == 0 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	product, err := b.db.GetProduct(productID)
+	if err != nil || product == nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Produk dengan ID %d tidak ditemukan!", productID))
+		return
+	}
+	if product.IsBundle {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ *%s* adalah paket bundling. Tambahkan stok ke produk komponennya.",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+		return
+	}
+	if product.IsGiftCard() {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ *%s* adalah gift card. Kode gift card dibuat otomatis saat dibeli.",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+		return
+	}
+
+	batch, err := b.db.ImportProductContents(productID, contentType, contents, costPrice, batchName, supplier, message.From.ID)
+	if err != nil {
+		logrus.Errorf("Failed to import stock for product %d: %v", productID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal mengimpor stok produk!")
+		return
+	}
+
+	availableStock, _ := b.db.GetAvailableAccountCount(productID)
+
+	successMsg := fmt.Sprintf(`✅ *IMPORT STOK BERHASIL!*
+
+📦 Produk: *%s*
+🏷️ Batch: *#%d %s*
+📥 Item diimpor: %d
+💵 Modal per item: %s
+🚚 Supplier: %s
+📊 Stok tersedia: %d
+
+💰 Estimasi profit per item: %s`,
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name),
+		batch.ID,
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, batch.Name),
+		batch.ItemCount,
+		models.FormatPrice(costPrice, b.config.CurrencySymbol),
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, batch.SupplierName()),
+		availableStock,
+		models.FormatPrice(product.Price-costPrice, b.config.CurrencySymbol))
+
+	b.sendMessage(message.Chat.ID, successMsg)
+	b.fulfillPreordersAfterRestock(message.Chat.ID, productID)
+
+	logrus.Infof("Admin %d imported batch #%d %q with %d %s stock items for product %d (cost %d, supplier %q)",
+		message.From.ID, batch.ID, batch.Name, batch.ItemCount, contentType, productID, costPrice, supplier)
+}
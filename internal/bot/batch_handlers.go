@@ -0,0 +1,433 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// maxAffectedListed limits affected items shown at once to keep the keyboard manageable
+const maxAffectedListed = 15
+
+// handleStockBatches lists recent stock batches
+func (b *Bot) handleStockBatches(callback *tgbotapi.CallbackQuery) {
+	batches, err := b.db.GetStockBatches(20)
+	if err != nil {
+		logrus.Errorf("Failed to get stock batches: %v", err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat batch"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString("🏷️ *BATCH STOK SUPPLIER*\n\n")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if len(batches) == 0 {
+		text.WriteString("Belum ada batch. Import stok dengan /importstock untuk membuat batch.")
+	} else {
+		for _, batch := range batches {
+			status := "✅"
+			if batch.IsRevoked() {
+				status = "🚫"
+			}
+			text.WriteString(fmt.Sprintf("%s *#%d %s* - %s\n", status, batch.ID,
+				tgbotapi.EscapeText(tgbotapi.ModeMarkdown, batch.Name), batch.ProductName))
+			text.WriteString(fmt.Sprintf("   Supplier: %s | Tersedia: %d | Terjual: %d\n\n",
+				tgbotapi.EscapeText(tgbotapi.ModeMarkdown, batch.SupplierName()), batch.AvailableCount, batch.SoldCount))
+
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					fmt.Sprintf("%s #%d %s", status, batch.ID, batch.Name),
+					fmt.Sprintf("admin:batch:%d", batch.ID)),
+			))
+		}
+	}
+
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Kelola Stok", "admin:stock"),
+	))
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &markup
+
+	b.send(edit)
+}
+
+// buildBatchDetailText describes a stock batch for the admin
+func (b *Bot) buildBatchDetailText(batch *models.StockBatch) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🏷️ *BATCH #%d %s*\n\n", batch.ID, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, batch.Name)))
+	text.WriteString(fmt.Sprintf("📦 Produk: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, batch.ProductName)))
+	text.WriteString(fmt.Sprintf("🚚 Supplier: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, batch.SupplierName())))
+	text.WriteString(fmt.Sprintf("💵 Modal per item: %s\n", models.FormatPrice(batch.CostPrice, b.config.CurrencySymbol)))
+	text.WriteString(fmt.Sprintf("📥 Jumlah item: %d\n", batch.ItemCount))
+	text.WriteString(fmt.Sprintf("📊 Tersedia: %d | Terjual: %d\n", batch.AvailableCount, batch.SoldCount))
+	text.WriteString(fmt.Sprintf("📅 Diimpor: %s\n", batch.CreatedAt.Format("02/01/2006 15:04")))
+
+	if batch.IsRevoked() {
+		text.WriteString(fmt.Sprintf("\n🚫 *Direvoke:* %s\n", batch.RevokedAt.Format("02/01/2006 15:04")))
+		if batch.RevokeReason != nil {
+			text.WriteString(fmt.Sprintf("📝 Alasan: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *batch.RevokeReason)))
+		}
+	}
+
+	return text.String()
+}
+
+// handleBatchDetail shows a stock batch with its revoke actions
+func (b *Bot) handleBatchDetail(callback *tgbotapi.CallbackQuery, batchID int) {
+	batch, err := b.db.GetStockBatch(batchID)
+	if err != nil || batch == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Batch tidak ditemukan"))
+		return
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if batch.IsRevoked() {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚠️ Item Terdampak", fmt.Sprintf("admin:affected:%d", batch.ID)),
+		))
+	} else {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚫 Revoke Batch", fmt.Sprintf("admin:revokebatch:%d", batch.ID)),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Daftar Batch", "admin:batches"),
+	))
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, b.buildBatchDetailText(batch))
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &markup
+
+	b.send(edit)
+}
+
+// handleRevokeBatchConfirm asks the admin to confirm revoking a batch
+func (b *Bot) handleRevokeBatchConfirm(callback *tgbotapi.CallbackQuery, batchID int) {
+	batch, err := b.db.GetStockBatch(batchID)
+	if err != nil || batch == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Batch tidak ditemukan"))
+		return
+	}
+	if batch.IsRevoked() {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "ℹ️ Batch sudah direvoke"))
+		return
+	}
+
+	text := b.buildBatchDetailText(batch) + fmt.Sprintf(`
+⚠️ *Yakin ingin me-revoke batch ini?*
+
+• %d item yang belum terjual akan ditarik dari penjualan
+• Item di order yang belum dibayar akan diganti stok lain
+• Pembeli dari %d item terjual akan diberi notifikasi
+
+💡 Untuk mencatat alasan gunakan: /revokebatch %d [alasan]`, batch.AvailableCount, batch.SoldCount, batch.ID)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Ya, Revoke", fmt.Sprintf("admin:confirmrevoke:%d", batch.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Batal", fmt.Sprintf("admin:batch:%d", batch.ID)),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+
+	b.send(edit)
+}
+
+// revokeBatch revokes a batch, notifies affected buyers and returns a summary for the admin
+func (b *Bot) revokeBatch(batchID int, reason string, adminID int64) (string, error) {
+	batch, err := b.db.GetStockBatch(batchID)
+	if err != nil {
+		return "", err
+	}
+	if batch == nil {
+		return "", fmt.Errorf("batch %d not found", batchID)
+	}
+
+	result, err := b.db.RevokeBatch(batchID, reason)
+	if err != nil {
+		return "", err
+	}
+
+	notified := b.notifyRevokedBuyers(result.Affected)
+
+	logrus.Infof("Admin %d revoked batch #%d %q: %d pulled, %d pending swapped, %d pending unswapped, %d delivered affected",
+		adminID, batchID, batch.Name, result.PulledCount, result.SwappedPending, result.UnswappedPending, len(result.Affected))
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🚫 *BATCH #%d %s DIREVOKE*\n\n", batch.ID, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, batch.Name)))
+	text.WriteString(fmt.Sprintf("📦 Ditarik dari penjualan: %d item\n", result.PulledCount))
+	text.WriteString(fmt.Sprintf("🔄 Order belum bayar diganti stok lain: %d item\n", result.SwappedPending))
+	if result.UnswappedPending > 0 {
+		text.WriteString(fmt.Sprintf("⚠️ Order belum bayar tanpa stok pengganti: %d item\n", result.UnswappedPending))
+	}
+	text.WriteString(fmt.Sprintf("👥 Item terjual terdampak: %d (%d pembeli dinotifikasi)\n\n", len(result.Affected), notified))
+
+	if len(result.Affected) > 0 {
+		text.WriteString("Pilih *Item Terdampak* untuk mengganti atau me-refund tiap item.")
+	}
+
+	return text.String(), nil
+}
+
+// notifyRevokedBuyers tells each buyer that items from their order were revoked by the supplier.
+// Returns the number of buyers notified.
+func (b *Bot) notifyRevokedBuyers(affected []models.SoldAccount) int {
+	// Group affected items per buyer and order
+	type buyerOrder struct {
+		userID  int64
+		orderID string
+	}
+	grouped := make(map[buyerOrder][]models.SoldAccount)
+	var keys []buyerOrder
+	for _, account := range affected {
+		key := buyerOrder{account.UserID, account.OrderID}
+		if _, exists := grouped[key]; !exists {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], account)
+	}
+
+	buyers := make(map[int64]bool)
+	for _, key := range keys {
+		var text strings.Builder
+		text.WriteString("⚠️ *PEMBERITAHUAN PRODUK BERMASALAH*\n\n")
+		text.WriteString(fmt.Sprintf("Supplier kami melaporkan masalah pada item berikut dari Order #%s:\n\n", shortOrderID(key.orderID)))
+		for _, account := range grouped[key] {
+			text.WriteString(fmt.Sprintf("• %s (%s)\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, account.ProductName), account.GetContentLabel()))
+		}
+		text.WriteString("\nItem tersebut mungkin tidak dapat digunakan lagi. ")
+		text.WriteString("Admin akan segera mengirim pengganti atau memproses pengembalian dana.\n\n")
+		text.WriteString("🙏 Mohon maaf atas ketidaknyamanannya. Hubungi /contact jika ada pertanyaan.")
+
+		msg := tgbotapi.NewMessage(key.userID, text.String())
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		if _, err := b.send(msg); err != nil {
+			logrus.Errorf("Failed to notify user %d about revoked items in order %s: %v", key.userID, key.orderID, err)
+			continue
+		}
+		buyers[key.userID] = true
+	}
+
+	return len(buyers)
+}
+
+// shortOrderID returns the short order reference shown to users
+func shortOrderID(orderID string) string {
+	if len(orderID) > 8 {
+		return orderID[:8]
+	}
+	return orderID
+}
+
+// handleConfirmRevokeBatch revokes a batch from the admin panel
+func (b *Bot) handleConfirmRevokeBatch(callback *tgbotapi.CallbackQuery, batchID int) {
+	text, err := b.revokeBatch(batchID, "", callback.From.ID)
+	if err != nil {
+		logrus.Errorf("Failed to revoke batch %d: %v", batchID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal me-revoke batch"))
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚠️ Item Terdampak", fmt.Sprintf("admin:affected:%d", batchID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔙 Daftar Batch", "admin:batches"),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+
+	b.send(edit)
+}
+
+// processRevokeBatchCommand processes /revokebatch command
+func (b *Bot) processRevokeBatchCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	// Parse command: /revokebatch [batch_id] [alasan]
+	parts := strings.SplitN(message.CommandArguments(), " ", 2)
+	batchID, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		b.sendMessage(message.Chat.ID, `❌ Format salah!
+
+Gunakan: /revokebatch [batch_id] [alasan]
+
+Contoh:
+/revokebatch 12 Akun dibanned oleh supplier`)
+		return
+	}
+
+	reason := ""
+	if len(parts) > 1 {
+		reason = strings.TrimSpace(parts[1])
+	}
+
+	text, err := b.revokeBatch(batchID, reason, message.From.ID)
+	if err != nil {
+		logrus.Errorf("Failed to revoke batch %d: %v", batchID, err)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Gagal me-revoke batch #%d. Pastikan batch ada dan belum direvoke.", batchID))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚠️ Item Terdampak", fmt.Sprintf("admin:affected:%d", batchID)),
+		),
+	)
+	b.send(msg)
+}
+
+// handleAffectedItems lists delivered items of a revoked batch that still need replacement or refund
+func (b *Bot) handleAffectedItems(callback *tgbotapi.CallbackQuery, batchID int) {
+	affected, err := b.db.GetAffectedSoldAccounts(batchID)
+	if err != nil {
+		logrus.Errorf("Failed to get affected items for batch %d: %v", batchID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat item terdampak"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("⚠️ *ITEM TERDAMPAK BATCH #%d*\n\n", batchID))
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if len(affected) == 0 {
+		text.WriteString("✅ Semua item terdampak sudah ditangani.")
+	} else {
+		for i, account := range affected {
+			if i >= maxAffectedListed {
+				text.WriteString(fmt.Sprintf("... dan %d item lainnya\n", len(affected)-maxAffectedListed))
+				break
+			}
+
+			buyer := fmt.Sprintf("%d", account.UserID)
+			if account.BuyerUsername != nil && *account.BuyerUsername != "" {
+				buyer = "@" + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *account.BuyerUsername)
+			} else if account.BuyerFirstName != nil {
+				buyer = tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *account.BuyerFirstName)
+			}
+
+			text.WriteString(fmt.Sprintf("🔸 *Item #%d* - %s\n", account.ID, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, account.ProductName)))
+			text.WriteString(fmt.Sprintf("   Order #%s | Pembeli: %s\n", shortOrderID(account.OrderID), buyer))
+			text.WriteString(fmt.Sprintf("   Harga: %s\n\n", models.FormatPrice(account.SoldPrice, b.config.CurrencySymbol)))
+
+			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔄 Ganti #%d", account.ID), fmt.Sprintf("admin:replace:%d", account.ID)),
+				tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("💸 Refund #%d", account.ID), fmt.Sprintf("admin:refund:%d", account.ID)),
+			))
+		}
+	}
+
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Detail Batch", fmt.Sprintf("admin:batch:%d", batchID)),
+	))
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &markup
+
+	b.send(edit)
+}
+
+// handleReplaceSoldAccount sends a fresh item to the buyer in place of a revoked one
+func (b *Bot) handleReplaceSoldAccount(callback *tgbotapi.CallbackQuery, soldAccountID int) {
+	old, err := b.db.GetSoldAccount(soldAccountID)
+	if err != nil || old == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Item tidak ditemukan"))
+		return
+	}
+
+	replacement, err := b.db.ReplaceSoldAccount(soldAccountID)
+	if err != nil {
+		logrus.Errorf("Failed to replace sold account %d: %v", soldAccountID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal mengganti item (stok pengganti habis?)"))
+		return
+	}
+
+	buyerText := fmt.Sprintf(`🔄 *ITEM PENGGANTI*
+
+Berikut pengganti untuk item bermasalah dari Order #%s:
+
+📦 *%s*
+%s:
+`+"`%s`"+`
+
+_Tap untuk menyalin_
+
+🙏 Terima kasih atas kesabaran Anda!`,
+		shortOrderID(replacement.OrderID),
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, replacement.ProductName),
+		replacement.GetContentLabel(),
+		replacement.FormatContent())
+
+	msg := tgbotapi.NewMessage(replacement.UserID, buyerText)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := b.send(msg); err != nil {
+		logrus.Errorf("Failed to send replacement item to user %d: %v", replacement.UserID, err)
+	}
+
+	logrus.Infof("Admin %d replaced sold account %d with %d for order %s",
+		callback.From.ID, soldAccountID, replacement.ID, replacement.OrderID)
+
+	b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Item pengganti terkirim"))
+	if old.BatchID != nil {
+		b.handleAffectedItems(callback, *old.BatchID)
+	}
+}
+
+// handleRefundSoldAccount refunds a revoked item to the buyer's store balance and informs the buyer
+func (b *Bot) handleRefundSoldAccount(callback *tgbotapi.CallbackQuery, soldAccountID int) {
+	account, refunded, err := b.db.RefundSoldAccount(soldAccountID)
+	if err != nil {
+		logrus.Errorf("Failed to refund sold account %d: %v", soldAccountID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memproses refund"))
+		return
+	}
+
+	buyerText := fmt.Sprintf(`💸 *PENGEMBALIAN DANA*
+
+Item *%s* dari Order #%s tidak dapat diganti.
+Dana sebesar *%s* telah dikembalikan ke saldo toko Anda dan bisa dipakai saat checkout.
+
+Hubungi /contact jika ada pertanyaan.`,
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, account.ProductName),
+		shortOrderID(account.OrderID),
+		models.FormatPrice(refunded, b.config.CurrencySymbol))
+
+	msg := tgbotapi.NewMessage(account.UserID, buyerText)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := b.send(msg); err != nil {
+		logrus.Errorf("Failed to send refund notice to user %d: %v", account.UserID, err)
+	}
+
+	logrus.Infof("Admin %d refunded sold account %d (%d to balance) for order %s",
+		callback.From.ID, soldAccountID, refunded, account.OrderID)
+
+	b.api.Request(tgbotapi.NewCallback(callback.ID, fmt.Sprintf("✅ %s dikembalikan ke saldo pembeli",
+		models.FormatPrice(refunded, b.config.CurrencySymbol))))
+	if account.BatchID != nil {
+		b.handleAffectedItems(callback, *account.BatchID)
+	}
+}
@@ -3,18 +3,20 @@ package bot
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"telegram-premium-store/internal/config"
 	"telegram-premium-store/internal/database"
+	"telegram-premium-store/internal/i18n"
 	"telegram-premium-store/internal/models"
 	"telegram-premium-store/internal/payment"
 	"telegram-premium-store/internal/qris"
+	"telegram-premium-store/internal/ratelimit"
 	"telegram-premium-store/internal/scheduler"
+	"telegram-premium-store/internal/templates"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 // Bot represents the Telegram bot
@@ -25,8 +27,13 @@ type Bot struct {
 	paymentService   *payment.QRISService
 	realQRISService  *qris.RealQRISService
 	scheduler        *scheduler.Scheduler
-	messages         *config.Messages
+	i18n             *i18n.Catalog
+	templates        *templates.Store
+	limiter          *ratelimit.Limiter
 	updates          tgbotapi.UpdatesChannel
+
+	// Serializes product wizard draft updates, since photos sent as an album arrive as concurrent updates
+	wizardMu sync.Mutex
 }
 
 // New creates a new bot instance
@@ -38,17 +45,24 @@ func New(cfg *config.Config, db *database.DB, paymentService *payment.QRISServic
 
 	api.Debug = cfg.LogLevel == "DEBUG"
 
+	catalog, err := i18n.Load(cfg.LocalesDir, cfg.DefaultLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load locales: %w", err)
+	}
+
 	bot := &Bot{
 		api:              api,
 		config:           cfg,
 		db:               db,
 		paymentService:   paymentService,
 		realQRISService:  qris.NewRealQRISService(cfg),
-		messages:         config.GetMessages(),
+		i18n:             catalog,
+		templates:        templates.NewStore(db),
+		limiter:          newRateLimiter(cfg),
 	}
 
 	// Initialize scheduler
-	bot.scheduler = scheduler.NewScheduler(db, api, cfg)
+	bot.scheduler = scheduler.NewScheduler(db, api, cfg, catalog)
 
 	return bot, nil
 }
@@ -93,12 +107,28 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 		}
 	}()
 
+	// Drop updates from users who exceed their rate limits before doing any work for them
+	if !b.allowUpdate(update) {
+		return
+	}
+
+	// Banned users only get told that they are banned
+	if b.rejectBanned(update) {
+		return
+	}
+
 	// Handle callback queries
 	if update.CallbackQuery != nil {
 		b.handleCallbackQuery(update.CallbackQuery)
 		return
 	}
 
+	// Handle inline queries (@bot spotify) for sharing products
+	if update.InlineQuery != nil {
+		b.handleInlineQuery(update.InlineQuery)
+		return
+	}
+
 	// Handle messages
 	if update.Message != nil {
 		// Register user if not exists
@@ -110,17 +140,7 @@ func (b *Bot) handleUpdate(update tgbotapi.Update) {
 		if update.Message.IsCommand() {
 			b.handleCommand(update.Message)
 		} else {
-			// Check if it's a QRIS image upload
-			if update.Message.Photo != nil && b.isUserInState(update.Message.From.ID, "waiting_qris_upload") {
-				b.handleQRISImageUpload(update.Message)
-			} else if strings.HasPrefix(b.getUserState(update.Message.From.ID), "waiting_broadcast_") {
-				// Handle broadcast message input
-				targetType := strings.TrimPrefix(b.getUserState(update.Message.From.ID), "waiting_broadcast_")
-				b.clearUserState(update.Message.From.ID)
-				b.processBroadcastMessage(update.Message, targetType)
-			} else {
-				b.handleMessage(update.Message)
-			}
+			b.handleConversationMessage(update.Message)
 		}
 	}
 }
@@ -133,6 +153,7 @@ func (b *Bot) registerUser(user *tgbotapi.User) {
 		FirstName: &user.FirstName,
 		LastName:  &user.LastName,
 		IsAdmin:   b.config.IsAdmin(user.ID),
+		Language:  b.i18n.Match(user.LanguageCode), // Only used for new users
 	}
 
 	if err := b.db.CreateUser(dbUser); err != nil {
@@ -149,14 +170,34 @@ func (b *Bot) handleCommand(message *tgbotapi.Message) {
 		b.handleHelp(message)
 	case "catalog":
 		b.handleCatalog(message, "", 0)
+	case "cari", "search":
+		b.handleSearchCommand(message)
 	case "cart":
 		b.handleCart(message)
 	case "history":
 		b.handleHistory(message)
+	case "produkku", "myproducts":
+		b.handleMyProductsCommand(message)
 	case "payment":
 		b.handlePaymentStatus(message)
 	case "contact":
 		b.handleContact(message)
+	case "bantuan", "support":
+		b.handleSupportCommand(message)
+	case "pengingat":
+		b.handleCartReminderCommand(message)
+	case "kupon":
+		b.handleCouponCommand(message)
+	case "referral":
+		b.handleReferralCommand(message)
+	case "redeem":
+		b.handleRedeemCommand(message)
+	case "poin":
+		b.handlePointsCommand(message)
+	case "batal", "cancel":
+		b.handleCancelCommand(message)
+	case "bahasa", "language":
+		b.handleLanguageCommand(message)
 	case "admin":
 		b.handleAdmin(message)
 	case "addproduct":
@@ -174,87 +215,199 @@ func (b *Bot) handleCommand(message *tgbotapi.Message) {
 	case "addstock":
 		// Admin command to add product stock (supports all formats)
 		b.processAddStockCommand(message)
+	case "importstock":
+		// Admin command to bulk import stock with cost price and supplier
+		b.processImportStockCommand(message)
+	case "revokebatch":
+		// Admin command to revoke a supplier batch
+		b.processRevokeBatchCommand(message)
+	case "preorder":
+		// Admin command to manage pre-orders for out-of-stock products
+		b.processPreorderCommand(message)
+	case "addcoupon":
+		// Admin command to create a coupon code
+		b.processAddCouponCommand(message)
+	case "coupons":
+		// Admin command to list coupons with usage
+		b.handleCouponsCommand(message)
+	case "coupon":
+		// Admin command to view or enable/disable a coupon
+		b.processCouponCommand(message)
+	case "addflashsale":
+		// Admin command to schedule a flash sale
+		b.processAddFlashSaleCommand(message)
+	case "flashsales":
+		// Admin command to list flash sales
+		b.handleFlashSalesCommand(message)
+	case "flashsale":
+		// Admin command to view or enable/disable a flash sale
+		b.processFlashSaleCommand(message)
+	case "addbundle":
+		// Admin command to create a bundle of existing products
+		b.processAddBundleCommand(message)
+	case "bundles":
+		// Admin command to list bundles with their components and stock
+		b.handleBundlesCommand(message)
+	case "setgroup":
+		// Admin command to move a user to a customer group
+		b.processSetGroupCommand(message)
+	case "addtier":
+		// Admin command to add a group or wholesale price
+		b.processAddTierCommand(message)
+	case "tiers":
+		// Admin command to list customer groups and their prices
+		b.handleTiersCommand(message)
+	case "deltier":
+		// Admin command to delete a group price
+		b.processDeleteTierCommand(message)
+	case "addgiftcard":
+		// Admin command to create a gift card product
+		b.processAddGiftCardCommand(message)
+	case "giftcards":
+		// Admin command to list issued gift cards
+		b.handleGiftCardsCommand(message)
+	case "giftcard":
+		// Admin command to view or void a gift card
+		b.processGiftCardCommand(message)
+	case "translate":
+		// Admin command to translate a product's name and description
+		b.processTranslateCommand(message)
+	case "searchlog":
+		// Admin command to list searches that found no products
+		b.handleSearchLogCommand(message)
+	case "reviews":
+		// Admin command to list recent reviews, including hidden ones
+		b.handleReviewsCommand(message)
+	case "review":
+		// Admin command to show, hide or delete a review
+		b.processReviewCommand(message)
+	case "tiket", "tickets":
+		// Admin command to show the support ticket queue
+		b.handleTicketsCommand(message)
+	case "template", "templates":
+		// Admin command to view, edit, preview and reset message templates
+		b.handleTemplateCommand(message)
+	case "ratelimit":
+		// Admin command to show throttled requests and lift automatic mutes
+		b.handleRateLimitCommand(message)
+	case "ban":
+		// Admin command to ban a user with a reason and optional duration
+		b.handleBanCommand(message)
+	case "unban":
+		// Admin command to lift a user's ban
+		b.handleUnbanCommand(message)
+	case "moderasi", "moderation":
+		// Admin command to show banned users or a user's ban status and moderation history
+		b.handleModerationCommand(message)
 	default:
 		b.sendMessage(message.Chat.ID, "❌ Perintah tidak dikenal. Ketik /help untuk bantuan.")
 	}
 }
 
-// handleStart handles /start command
-func (b *Bot) handleStart(message *tgbotapi.Message) {
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+// mainMenuKeyboard returns the main menu buttons shown by /start
+func (b *Bot) mainMenuKeyboard(lang string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.catalog"), "catalog:0"),
+		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📱 Lihat Katalog", "catalog:0"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.cart"), "cart"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.contact"), "contact"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🛒 Keranjang", "cart"),
-			tgbotapi.NewInlineKeyboardButtonData("📞 Kontak", "contact"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.referral"), "referral"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.help"), "help"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("ℹ️ Bantuan", "help"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.my_products"), "myp:0"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.language"), "lang:menu"),
 		),
 	)
+}
+
+// handleStart handles /start command, including deep link parameters like ref_<code> and p_<id>
+func (b *Bot) handleStart(message *tgbotapi.Message) {
+	param := message.CommandArguments()
+	if strings.HasPrefix(param, "ref_") {
+		b.handleReferralStart(message, strings.TrimPrefix(param, "ref_"))
+	}
+	if strings.HasPrefix(param, "p_") {
+		// Product shared through inline mode
+		b.handleProductStart(message, strings.TrimPrefix(param, "p_"))
+		return
+	}
+
+	lang := b.userLanguage(message.From.ID)
+	keyboard := b.mainMenuKeyboard(lang)
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, b.messages.Welcome)
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.t(lang, "welcome"))
 	msg.ParseMode = tgbotapi.ModeMarkdown
 	msg.ReplyMarkup = keyboard
 
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 // handleHelp handles /help command
 func (b *Bot) handleHelp(message *tgbotapi.Message) {
-	msg := tgbotapi.NewMessage(message.Chat.ID, b.messages.Help)
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.t(b.userLanguage(message.From.ID), "help"))
 	msg.ParseMode = tgbotapi.ModeMarkdown
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 // handleCatalog handles /catalog command and catalog display
 func (b *Bot) handleCatalog(message *tgbotapi.Message, category string, page int) {
-	const itemsPerPage = 5
+	lang := b.userLanguage(message.From.ID)
+	text, keyboard, err := b.buildCatalog(message.From.ID, lang, category, page)
+	if err != nil {
+		logrus.Errorf("Failed to build catalog: %v", err)
+		b.sendMessage(message.Chat.ID, b.t(lang, "catalog.products_failed"))
+		return
+	}
 
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+
+	b.send(msg)
+}
+
+// catalogPageSize is the number of products on one catalog or search results page
+const catalogPageSize = 5
+
+// buildCatalog renders one catalog page in the user's language, optionally filtered by category
+func (b *Bot) buildCatalog(userID int64, lang, category string, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
 	// Get categories for filter buttons
 	categories, err := b.db.GetCategories()
 	if err != nil {
-		logrus.Errorf("Failed to get categories: %v", err)
-		b.sendMessage(message.Chat.ID, "❌ Gagal memuat kategori.")
-		return
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get categories: %w", err)
 	}
 
 	// Get products
-	products, err := b.db.GetProducts(category, itemsPerPage, page*itemsPerPage)
+	products, err := b.db.GetProducts(category, catalogPageSize, page*catalogPageSize)
 	if err != nil {
-		logrus.Errorf("Failed to get products: %v", err)
-		b.sendMessage(message.Chat.ID, "❌ Gagal memuat produk.")
-		return
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get products: %w", err)
 	}
 
+	mainMenuRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
+	)
+
 	if len(products) == 0 {
-		text := "🚫 Tidak ada produk yang tersedia"
+		text := b.t(lang, "catalog.empty")
 		if category != "" {
-			text += " untuk kategori ini"
+			text = b.t(lang, "catalog.empty_category")
 		}
-		text += "."
-		
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
-			),
-		)
-		
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		msg.ReplyMarkup = keyboard
-		b.api.Send(msg)
-		return
+		return text, tgbotapi.NewInlineKeyboardMarkup(mainMenuRow), nil
 	}
 
 	// Build message text
 	var text strings.Builder
-	text.WriteString("📱 *KATALOG APLIKASI PREMIUM*\n\n")
+	text.WriteString(b.t(lang, "catalog.title") + "\n\n")
 
 	if category != "" {
 		for _, cat := range categories {
 			if cat.Name == category {
-				text.WriteString(fmt.Sprintf("📂 Kategori: %s\n\n", cat.DisplayName))
+				text.WriteString(b.t(lang, "catalog.category", b.categoryLabel(lang, cat)) + "\n\n")
 				break
 			}
 		}
@@ -263,8 +416,8 @@ func (b *Bot) handleCatalog(message *tgbotapi.Message, category string, page int
 	// Category filter buttons (only show if no category selected)
 	var keyboard [][]tgbotapi.InlineKeyboardButton
 	if category == "" {
-		text.WriteString("🏷️ *Filter berdasarkan kategori:*\n\n")
-		
+		text.WriteString(b.t(lang, "catalog.filter") + "\n\n")
+
 		var catRow []tgbotapi.InlineKeyboardButton
 		for _, cat := range categories {
 			if cat.Count > 0 {
@@ -272,7 +425,7 @@ func (b *Bot) handleCatalog(message *tgbotapi.Message, category string, page int
 					fmt.Sprintf("%s (%d)", cat.Icon, cat.Count),
 					fmt.Sprintf("category:%s:0", cat.Name),
 				))
-				
+
 				if len(catRow) == 2 {
 					keyboard = append(keyboard, catRow)
 					catRow = nil
@@ -282,17 +435,54 @@ func (b *Bot) handleCatalog(message *tgbotapi.Message, category string, page int
 		if len(catRow) > 0 {
 			keyboard = append(keyboard, catRow)
 		}
-		
+
 		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📋 Semua Produk", "catalog:0"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "catalog.all_products"), "catalog:0"),
 		))
 	}
 
 	// Product list
+	keyboard = append(keyboard, b.writeProductList(&text, userID, lang, products)...)
+
+	// Navigation buttons (there may be more products when the page is full)
+	navRow := b.pageNavRow(lang, page, len(products) == catalogPageSize, func(p int) string {
+		if category != "" {
+			return fmt.Sprintf("category:%s:%d", category, p)
+		}
+		return fmt.Sprintf("catalog:%d", p)
+	})
+	if len(navRow) > 0 {
+		keyboard = append(keyboard, navRow)
+	}
+
+	// Back buttons
+	if category != "" {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "catalog.all_categories"), "catalog:0"),
+		))
+	}
+
+	keyboard = append(keyboard, mainMenuRow)
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// writeProductList appends each product with its price and returns their Detail/Beli buttons
+func (b *Bot) writeProductList(text *strings.Builder, userID int64, lang string, products []models.Product) [][]tgbotapi.InlineKeyboardButton {
+	b.applyPricing(userID, products)
+	b.translateProducts(lang, products)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
 	for _, product := range products {
-		text.WriteString(fmt.Sprintf("🔸 *%s*\n", product.Name))
-		text.WriteString(fmt.Sprintf("💰 %s\n", models.FormatPrice(product.Price, b.config.CurrencySymbol)))
-		
+		text.WriteString(fmt.Sprintf("🔸 *%s*\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+		text.WriteString(fmt.Sprintf("💰 %s\n", b.formatSalePrice(lang, product.Price, product.OriginalPrice)))
+		if product.FlashSale != nil {
+			text.WriteString(b.formatFlashSaleNote(lang, product.FlashSale) + "\n")
+		}
+		if product.PriceTier != nil {
+			text.WriteString(b.formatPriceTierNote(lang, product.PriceTier) + "\n")
+		}
+
 		desc := product.Description
 		if len(desc) > 80 {
 			desc = desc[:80] + "..."
@@ -300,162 +490,122 @@ func (b *Bot) handleCatalog(message *tgbotapi.Message, category string, page int
 		text.WriteString(fmt.Sprintf("📝 %s\n\n", desc))
 
 		// Product buttons
-		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("👁️ Detail", fmt.Sprintf("product:%d", product.ID)),
-			tgbotapi.NewInlineKeyboardButtonData("🛒 Beli", fmt.Sprintf("buy:%d", product.ID)),
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "catalog.detail"), fmt.Sprintf("product:%d", product.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "catalog.buy"), fmt.Sprintf("buy:%d", product.ID)),
 		))
 	}
+	return rows
+}
 
-	// Navigation buttons
+// pageNavRow returns the previous/next buttons of a paged list, using callback for each page's callback data
+func (b *Bot) pageNavRow(lang string, page int, hasNext bool, callback func(page int) string) []tgbotapi.InlineKeyboardButton {
 	var navRow []tgbotapi.InlineKeyboardButton
-	
-	// Previous page
 	if page > 0 {
-		prevCallback := fmt.Sprintf("catalog:%d", page-1)
-		if category != "" {
-			prevCallback = fmt.Sprintf("category:%s:%d", category, page-1)
-		}
-		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("⬅️ Sebelumnya", prevCallback))
-	}
-
-	// Next page (check if there are more products)
-	if len(products) == itemsPerPage {
-		nextCallback := fmt.Sprintf("catalog:%d", page+1)
-		if category != "" {
-			nextCallback = fmt.Sprintf("category:%s:%d", category, page+1)
-		}
-		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("Selanjutnya ➡️", nextCallback))
+		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "catalog.prev"), callback(page-1)))
 	}
-
-	if len(navRow) > 0 {
-		keyboard = append(keyboard, navRow)
+	if hasNext {
+		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "catalog.next"), callback(page+1)))
 	}
-
-	// Back buttons
-	if category != "" {
-		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Semua Kategori", "catalog:0"),
-		))
-	}
-	
-	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
-	))
-
-	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
-	msg.ParseMode = tgbotapi.ModeMarkdown
-	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
-
-	b.api.Send(msg)
+	return navRow
 }
 
 // handleCart handles /cart command and cart display
 func (b *Bot) handleCart(message *tgbotapi.Message) {
 	userID := message.From.ID
-	cartItems, err := b.db.GetCart(userID)
+	lang := b.userLanguage(userID)
+	cartItems, err := b.getPricedCart(userID)
 	if err != nil {
 		logrus.Errorf("Failed to get cart for user %d: %v", userID, err)
-		b.sendMessage(message.Chat.ID, "❌ Gagal memuat keranjang.")
+		b.sendMessage(message.Chat.ID, b.t(lang, "cart.load_failed"))
 		return
 	}
 
 	if len(cartItems) == 0 {
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📱 Lihat Katalog", "catalog:0"),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.catalog"), "catalog:0"),
 			),
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 			),
 		)
 
-		msg := tgbotapi.NewMessage(message.Chat.ID, b.messages.CartEmpty)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.t(lang, "cart.empty"))
 		msg.ParseMode = tgbotapi.ModeMarkdown
 		msg.ReplyMarkup = keyboard
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
+	quote, err := b.buildCheckoutQuote(userID, cartItems)
+	if err != nil {
+		logrus.Errorf("Failed to price cart for user %d: %v", userID, err)
+		b.sendMessage(message.Chat.ID, b.t(lang, "cart.load_failed"))
+		return
+	}
+	b.translateCartItems(lang, cartItems)
+
 	// Build cart display
 	var text strings.Builder
-	text.WriteString("🛒 *KERANJANG BELANJA*\n\n")
+	text.WriteString(b.t(lang, "cart.title") + "\n\n")
 
-	totalPrice := 0
-	for _, item := range cartItems {
-		subtotal := item.ProductPrice * item.Quantity
-		totalPrice += subtotal
+	b.writeCartItems(&text, lang, cartItems)
 
-		text.WriteString(fmt.Sprintf("🔸 *%s*\n", item.ProductName))
-		text.WriteString(fmt.Sprintf("   Jumlah: %d x %s = %s\n\n",
-			item.Quantity,
-			models.FormatPrice(item.ProductPrice, b.config.CurrencySymbol),
-			models.FormatPrice(subtotal, b.config.CurrencySymbol)))
-	}
+	b.writeCartTotals(&text, lang, quote)
 
-	text.WriteString(fmt.Sprintf("💰 *Total: %s*\n", models.FormatPrice(totalPrice, b.config.CurrencySymbol)))
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("💳 Checkout", "checkout"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🗑️ Kosongkan Keranjang", "clearcart"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📱 Lanjut Belanja", "catalog:0"),
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
-		),
-	)
+	keyboard := b.cartKeyboard(lang, quote)
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
 	msg.ParseMode = tgbotapi.ModeMarkdown
 	msg.ReplyMarkup = keyboard
 
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 // handleHistory handles /history command
 func (b *Bot) handleHistory(message *tgbotapi.Message) {
 	userID := message.From.ID
+	lang := b.userLanguage(userID)
 	orders, err := b.db.GetUserOrders(userID, 10, 0)
 	if err != nil {
 		logrus.Errorf("Failed to get orders for user %d: %v", userID, err)
-		b.sendMessage(message.Chat.ID, "❌ Gagal memuat riwayat pembelian.")
+		b.sendMessage(message.Chat.ID, b.t(lang, "history.load_failed"))
 		return
 	}
 
 	if len(orders) == 0 {
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📱 Mulai Belanja", "catalog:0"),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "history.start_shopping"), "catalog:0"),
 			),
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 			),
 		)
 
-		msg := tgbotapi.NewMessage(message.Chat.ID, "📋 *RIWAYAT PEMBELIAN*\n\nAnda belum memiliki riwayat pembelian.")
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.t(lang, "history.title")+"\n\n"+b.t(lang, "history.empty"))
 		msg.ParseMode = tgbotapi.ModeMarkdown
 		msg.ReplyMarkup = keyboard
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
 	var text strings.Builder
-	text.WriteString("📋 *RIWAYAT PEMBELIAN*\n\n")
+	text.WriteString(b.t(lang, "history.title") + "\n\n")
 
 	for _, order := range orders {
 		statusEmoji := b.getStatusEmoji(order.PaymentStatus)
-		
-		text.WriteString(fmt.Sprintf("🔸 Order #%s\n", order.ID[:8]))
-		text.WriteString(fmt.Sprintf("💰 Total: %s\n", models.FormatPrice(order.TotalAmount, b.config.CurrencySymbol)))
-		text.WriteString(fmt.Sprintf("📅 Tanggal: %s\n", order.CreatedAt.Format("02/01/2006 15:04")))
-		text.WriteString(fmt.Sprintf("📊 Status: %s %s\n", statusEmoji, cases.Title(language.Und).String(string(order.PaymentStatus))))
-		
+
+		text.WriteString(b.t(lang, "history.order", order.ID[:8]) + "\n")
+		text.WriteString(b.t(lang, "order.total", b.formatPrice(lang, order.TotalAmount)) + "\n")
+		text.WriteString(b.t(lang, "order.date", b.i18n.FormatDateTime(lang, order.CreatedAt)) + "\n")
+		text.WriteString(b.t(lang, "order.status", statusEmoji, b.t(lang, "order.status."+string(order.PaymentStatus))) + "\n")
+
 		if len(order.Items) > 0 {
-			text.WriteString(fmt.Sprintf("📦 Item: %s", order.Items[0].ProductName))
+			text.WriteString(b.t(lang, "history.item", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, order.Items[0].ProductName)))
 			if len(order.Items) > 1 {
-				text.WriteString(fmt.Sprintf(" (+%d lainnya)", len(order.Items)-1))
+				text.WriteString(b.t(lang, "history.more_items", len(order.Items)-1))
 			}
 			text.WriteString("\n")
 		}
@@ -464,10 +614,10 @@ func (b *Bot) handleHistory(message *tgbotapi.Message) {
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📱 Belanja Lagi", "catalog:0"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "history.shop_again"), "catalog:0"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 		),
 	)
 
@@ -475,17 +625,18 @@ func (b *Bot) handleHistory(message *tgbotapi.Message) {
 	msg.ParseMode = tgbotapi.ModeMarkdown
 	msg.ReplyMarkup = keyboard
 
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 // handlePaymentStatus handles /payment command
 func (b *Bot) handlePaymentStatus(message *tgbotapi.Message) {
 	// Get user's pending orders
 	userID := message.From.ID
+	lang := b.userLanguage(userID)
 	orders, err := b.db.GetUserOrders(userID, 5, 0)
 	if err != nil {
 		logrus.Errorf("Failed to get orders for user %d: %v", userID, err)
-		b.sendMessage(message.Chat.ID, "❌ Gagal memuat status pembayaran.")
+		b.sendMessage(message.Chat.ID, b.t(lang, "payment_status.load_failed"))
 		return
 	}
 
@@ -498,53 +649,54 @@ func (b *Bot) handlePaymentStatus(message *tgbotapi.Message) {
 	}
 
 	if len(pendingOrders) == 0 {
-		msg := tgbotapi.NewMessage(message.Chat.ID, "💳 *STATUS PEMBAYARAN*\n\nTidak ada pembayaran yang sedang menunggu.")
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.t(lang, "payment_status.title")+"\n\n"+b.t(lang, "payment_status.none"))
 		msg.ParseMode = tgbotapi.ModeMarkdown
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
 	var text strings.Builder
-	text.WriteString("💳 *STATUS PEMBAYARAN*\n\n")
-	text.WriteString("Pesanan yang menunggu pembayaran:\n\n")
+	text.WriteString(b.t(lang, "payment_status.title") + "\n\n")
+	text.WriteString(b.t(lang, "payment_status.pending") + "\n\n")
 
 	var keyboard [][]tgbotapi.InlineKeyboardButton
 	for _, order := range pendingOrders {
-		text.WriteString(fmt.Sprintf("🔸 Order #%s\n", order.ID[:8]))
-		text.WriteString(fmt.Sprintf("💰 Total: %s\n", models.FormatPrice(order.TotalAmount, b.config.CurrencySymbol)))
-		text.WriteString(fmt.Sprintf("📅 Dibuat: %s\n", order.CreatedAt.Format("02/01/2006 15:04")))
-		
+		text.WriteString(b.t(lang, "history.order", order.ID[:8]) + "\n")
+		text.WriteString(b.t(lang, "order.total", b.formatPrice(lang, order.TotalAmount)) + "\n")
+		text.WriteString(b.t(lang, "payment_status.created", b.i18n.FormatDateTime(lang, order.CreatedAt)) + "\n")
+
 		if order.QRISExpiry != nil {
 			if b.paymentService.IsExpired(order.QRISExpiry) {
-				text.WriteString("⏰ Status: Expired\n")
+				text.WriteString(b.t(lang, "payment_status.expired") + "\n")
 			} else {
-				text.WriteString(fmt.Sprintf("⏰ Berlaku sampai: %s\n", order.QRISExpiry.Format("15:04")))
+				text.WriteString(b.t(lang, "payment_status.valid_until", order.QRISExpiry.Format("15:04")) + "\n")
 			}
 		}
 		text.WriteString("\n")
 
 		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("📄 Detail #%s", order.ID[:8]),
+				b.t(lang, "payment_status.detail_button", order.ID[:8]),
 				fmt.Sprintf("order:%s", order.ID),
 			),
 		))
 	}
 
 	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 	))
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
 	msg.ParseMode = tgbotapi.ModeMarkdown
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
 
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 // handleContact handles /contact command
 func (b *Bot) handleContact(message *tgbotapi.Message) {
-	contactText := fmt.Sprintf(b.messages.Contact,
+	lang := b.userLanguage(message.From.ID)
+	contactText := b.t(lang, "contact",
 		b.config.AdminUsername,
 		b.config.AdminEmail,
 		b.config.SupportPhone,
@@ -552,7 +704,10 @@ func (b *Bot) handleContact(message *tgbotapi.Message) {
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.support_ticket"), "ticket:start"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 		),
 	)
 
@@ -560,7 +715,40 @@ func (b *Bot) handleContact(message *tgbotapi.Message) {
 	msg.ParseMode = tgbotapi.ModeMarkdown
 	msg.ReplyMarkup = keyboard
 
-	b.api.Send(msg)
+	b.send(msg)
+}
+
+// handleCartReminderCommand handles /pengingat command to toggle abandoned-cart reminders
+func (b *Bot) handleCartReminderCommand(message *tgbotapi.Message) {
+	userID := message.From.ID
+	lang := b.userLanguage(userID)
+
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "on":
+		if err := b.db.SetCartReminders(userID, true); err != nil {
+			logrus.Errorf("Failed to enable cart reminders for user %d: %v", userID, err)
+			b.sendMessage(message.Chat.ID, b.t(lang, "cart_reminder.update_failed"))
+			return
+		}
+		b.sendMessage(message.Chat.ID, b.t(lang, "cart_reminder.enabled"))
+	case "off":
+		if err := b.db.SetCartReminders(userID, false); err != nil {
+			logrus.Errorf("Failed to disable cart reminders for user %d: %v", userID, err)
+			b.sendMessage(message.Chat.ID, b.t(lang, "cart_reminder.update_failed"))
+			return
+		}
+		b.sendMessage(message.Chat.ID, b.t(lang, "cart_reminder.disabled"))
+	default:
+		enabled, err := b.db.GetCartReminders(userID)
+		if err != nil {
+			logrus.Errorf("Failed to get cart reminder setting for user %d: %v", userID, err)
+		}
+		status := b.t(lang, "cart_reminder.status_off")
+		if enabled {
+			status = b.t(lang, "cart_reminder.status_on")
+		}
+		b.sendMessage(message.Chat.ID, b.t(lang, "cart_reminder.settings", status))
+	}
 }
 
 // Admin command handlers
@@ -580,6 +768,10 @@ func (b *Bot) handleAdmin(message *tgbotapi.Message) {
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("💰 Pesanan", "admin:orders"),
+			tgbotapi.NewInlineKeyboardButtonData("🎫 Tiket Bantuan", "admin:tickets"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📝 Template Pesan", "admin:templates"),
 		),
 	)
 
@@ -587,42 +779,26 @@ func (b *Bot) handleAdmin(message *tgbotapi.Message) {
 	msg.ParseMode = tgbotapi.ModeMarkdown
 	msg.ReplyMarkup = keyboard
 
-	b.api.Send(msg)
+	b.send(msg)
 }
 
-func (b *Bot) handleAddProduct(message *tgbotapi.Message) {
+func (b *Bot) handleUsers(message *tgbotapi.Message) {
 	if !b.config.IsAdmin(message.From.ID) {
 		b.sendMessage(message.Chat.ID, "❌ Anda tidak memiliki akses admin!")
 		return
 	}
 
-	helpText := `📝 *TAMBAH PRODUK BARU*
-
-Format: /addproduct <nama> | <deskripsi> | <harga> | <kategori>
-
-*Contoh:*
-/addproduct Discord Nitro | Premium Discord features | 50000 | gaming
-
-*Kategori yang tersedia:*
-• music - Musik & Audio
-• entertainment - Hiburan  
-• design - Design & Kreativitas
-• productivity - Produktivitas
-• education - Edukasi
-• gaming - Gaming
-• social - Sosial Media
-• utility - Utilitas`
-
-	b.sendMessage(message.Chat.ID, helpText)
-}
-
-func (b *Bot) handleUsers(message *tgbotapi.Message) {
-	if !b.config.IsAdmin(message.From.ID) {
-		b.sendMessage(message.Chat.ID, "❌ Anda tidak memiliki akses admin!")
+	text, keyboard, err := b.buildBannedUserList()
+	if err != nil {
+		logrus.Errorf("Failed to build banned user list: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat daftar pengguna diblokir.")
 		return
 	}
 
-	b.sendMessage(message.Chat.ID, "👥 *STATISTIK PENGGUNA*\n\nFitur ini akan dikembangkan lebih lanjut.")
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
 }
 
 func (b *Bot) handleOrders(message *tgbotapi.Message) {
@@ -640,19 +816,50 @@ func (b *Bot) handleStats(message *tgbotapi.Message) {
 		return
 	}
 
-	b.sendMessage(message.Chat.ID, "📊 *STATISTIK BOT*\n\nFitur ini akan dikembangkan lebih lanjut.")
+	text, err := b.buildProfitSummaryText()
+	if err != nil {
+		logrus.Errorf("Failed to build profit summary: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat statistik.")
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = profitReportKeyboard(30)
+	b.send(msg)
 }
 
 // handleMessage processes non-command messages
 func (b *Bot) handleMessage(message *tgbotapi.Message) {
-	b.sendMessage(message.Chat.ID, "ℹ️ Gunakan menu atau ketik /help untuk melihat perintah yang tersedia.")
+	// Plain text is treated as a product search
+	if len(database.NormalizeSearchQuery(message.Text)) > 0 {
+		if reply, ok := b.allowAction(message.From.ID, ratelimit.ActionSearch); !ok {
+			if reply != "" {
+				b.sendMessage(message.Chat.ID, reply)
+			}
+			return
+		}
+		b.sendSearchResults(message, message.Text)
+		return
+	}
+	b.sendMessage(message.Chat.ID, b.t(b.userLanguage(message.From.ID), "message.unknown"))
 }
 
 // Helper methods
 func (b *Bot) sendMessage(chatID int64, text string) {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = tgbotapi.ModeMarkdown
-	b.api.Send(msg)
+	b.send(msg)
+}
+
+// send sends a message or edit, falling back to plain text if Telegram rejects its formatting
+func (b *Bot) send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return templates.SendFormatted(b.api, c)
+}
+
+// escapeHTML escapes user-supplied text for messages sent with tgbotapi.ModeHTML
+func escapeHTML(text string) string {
+	return templates.Escape(templates.FormatHTML, text)
 }
 
 func (b *Bot) getStatusEmoji(status models.PaymentStatus) string {
@@ -667,6 +874,8 @@ func (b *Bot) getStatusEmoji(status models.PaymentStatus) string {
 		return "❌"
 	case models.PaymentStatusRefunded:
 		return "🔄"
+	case models.PaymentStatusAwaitingStock:
+		return "📦"
 	default:
 		return "❓"
 	}
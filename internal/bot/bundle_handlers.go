@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// parseBundleComponents parses "1, 2:2, 5" into components; "ID:jumlah" sets the quantity, default 1.
+// Repeated product IDs are merged.
+func parseBundleComponents(spec string) ([]models.BundleItem, error) {
+	var items []models.BundleItem
+	index := make(map[int]int)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		idStr, qtyStr, hasQty := strings.Cut(part, ":")
+		productID, err := strconv.Atoi(strings.TrimSpace(idStr))
+		if err != nil {
+			return nil, fmt.Errorf("ID produk tidak valid: %s", part)
+		}
+		quantity := 1
+		if hasQty {
+			quantity, err = strconv.Atoi(strings.TrimSpace(qtyStr))
+			if err != nil || quantity <= 0 {
+				return nil, fmt.Errorf("jumlah tidak valid: %s", part)
+			}
+		}
+
+		if i, ok := index[productID]; ok {
+			items[i].Quantity += quantity
+			continue
+		}
+		index[productID] = len(items)
+		items = append(items, models.BundleItem{ProductID: productID, Quantity: quantity})
+	}
+	return items, nil
+}
+
+// processAddBundleCommand handles /addbundle <nama> | <deskripsi> | <harga> | <kategori> | <komponen>
+func (b *Bot) processAddBundleCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	usage := `❌ Format salah!
+
+Gunakan:
+/addbundle <nama> | <deskripsi> | <harga> | <kategori> | <komponen>
+
+Komponen berupa ID produk dipisah koma, tambahkan :jumlah bila lebih dari 1.
+
+Contoh:
+/addbundle Paket Hiburan | Spotify + Netflix + YouTube | 90000 | entertainment | 1, 2, 5
+/addbundle Netflix Duo | 2 akun Netflix | 100000 | entertainment | 2:2`
+
+	parts := strings.Split(message.CommandArguments(), "|")
+	if len(parts) != 5 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	price, err := strconv.Atoi(parts[2])
+	if err != nil || price <= 0 {
+		b.sendMessage(message.Chat.ID, "❌ Harga harus berupa angka lebih dari 0!")
+		return
+	}
+
+	components, err := parseBundleComponents(parts[4])
+	if err != nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Komponen tidak valid: %s", err))
+		return
+	}
+	units := 0
+	for _, component := range components {
+		units += component.Quantity
+	}
+	if units < 2 {
+		b.sendMessage(message.Chat.ID, "❌ Paket bundling minimal berisi 2 item!")
+		return
+	}
+
+	bundle := &models.Product{
+		Name:        parts[0],
+		Description: parts[1],
+		Price:       price,
+		Category:    strings.ToLower(parts[3]),
+	}
+	if bundle.Name == "" || bundle.Description == "" || bundle.Category == "" {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	if err := b.db.CreateBundle(bundle, components); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			b.sendMessage(message.Chat.ID, "❌ Ada produk komponen yang tidak ditemukan atau tidak aktif!")
+		case strings.Contains(err.Error(), "is a bundle"):
+			b.sendMessage(message.Chat.ID, "❌ Paket bundling tidak bisa berisi paket bundling lain!")
+		case strings.Contains(err.Error(), "is a gift card"):
+			b.sendMessage(message.Chat.ID, "❌ Paket bundling tidak bisa berisi gift card!")
+		default:
+			logrus.Errorf("Failed to create bundle %s: %v", bundle.Name, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal membuat paket bundling!")
+		}
+		return
+	}
+
+	logrus.Infof("Admin %d created bundle %d (%s)", message.From.ID, bundle.ID, bundle.Name)
+	b.sendMessage(message.Chat.ID, "✅ *Paket bundling berhasil dibuat!*\n\n"+b.formatBundleDetail(bundle))
+}
+
+// formatBundleDetail describes a bundle with its components, savings and availability
+func (b *Bot) formatBundleDetail(bundle *models.Product) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🆔 ID: %d\n", bundle.ID))
+	text.WriteString(fmt.Sprintf("📦 Nama: *%s*\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, bundle.Name)))
+	text.WriteString(fmt.Sprintf("💰 Harga: %s\n", models.FormatPrice(bundle.Price, b.config.CurrencySymbol)))
+
+	components, err := b.db.GetBundleItems(bundle.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get components of bundle %d: %v", bundle.ID, err)
+	}
+	b.writeBundleComponents(&text, bundle, components)
+
+	available, _ := b.db.GetAvailableAccountCount(bundle.ID)
+	text.WriteString(fmt.Sprintf("📊 Stok paket: %d", available))
+	return text.String()
+}
+
+// writeBundleComponents lists the bundle contents and the saving compared to buying them separately
+func (b *Bot) writeBundleComponents(text *strings.Builder, bundle *models.Product, components []models.BundleItem) {
+	if len(components) == 0 {
+		return
+	}
+
+	text.WriteString("🎁 *Isi paket:*\n")
+	for _, component := range components {
+		text.WriteString(fmt.Sprintf("• %dx %s\n", component.Quantity, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, component.ProductName)))
+	}
+
+	value := models.BundleComponentsValue(components)
+	if value > bundle.Price {
+		text.WriteString(fmt.Sprintf("💸 *Hemat:* %s dari harga satuan %s\n",
+			models.FormatPrice(value-bundle.Price, b.config.CurrencySymbol),
+			models.FormatPrice(value, b.config.CurrencySymbol)))
+	}
+}
+
+// handleBundlesCommand handles /bundles
+func (b *Bot) handleBundlesCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	bundles, err := b.db.GetBundles()
+	if err != nil {
+		logrus.Errorf("Failed to get bundles: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat paket bundling!")
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString("🎁 *PAKET BUNDLING*\n\n")
+	if len(bundles) == 0 {
+		text.WriteString("Belum ada paket bundling.\n\n")
+	}
+	for i := range bundles {
+		text.WriteString(b.formatBundleDetail(&bundles[i]))
+		text.WriteString("\n\n")
+	}
+	text.WriteString("💡 /addbundle untuk membuat paket bundling\n💡 Stok paket mengikuti stok komponen yang paling sedikit")
+
+	b.sendMessage(message.Chat.ID, text.String())
+}
@@ -8,11 +8,10 @@ import (
 
 	"telegram-premium-store/internal/models"
 	"telegram-premium-store/internal/payment"
+	"telegram-premium-store/internal/ratelimit"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 // handleCallbackQuery processes callback queries from inline keyboards
@@ -50,12 +49,24 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 			}
 		}
 		b.handleCatalogCallback(callback, category, page)
+	case "search":
+		if len(parts) > 2 {
+			if page, err := strconv.Atoi(parts[1]); err == nil {
+				b.handleSearchCallback(callback, page, parts[2])
+			}
+		}
 	case "product":
 		if len(parts) > 1 {
 			if productID, err := strconv.Atoi(parts[1]); err == nil {
 				b.handleProductDetail(callback, productID)
 			}
 		}
+	case "gallery":
+		if len(parts) > 1 {
+			if productID, err := strconv.Atoi(parts[1]); err == nil {
+				b.handleGalleryCallback(callback, productID)
+			}
+		}
 	case "buy":
 		if len(parts) > 1 {
 			productID, _ := strconv.Atoi(parts[1])
@@ -74,6 +85,18 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 			}
 			b.handleAddToCart(callback, productID, quantity)
 		}
+	case "preorder":
+		if len(parts) > 1 {
+			if productID, err := strconv.Atoi(parts[1]); err == nil {
+				b.handlePreorder(callback, productID)
+			}
+		}
+	case "preorder_pay":
+		if len(parts) > 1 {
+			if productID, err := strconv.Atoi(parts[1]); err == nil {
+				b.handlePreorderPay(callback, productID)
+			}
+		}
 	case "notify":
 		if len(parts) > 1 {
 			if productID, err := strconv.Atoi(parts[1]); err == nil {
@@ -86,12 +109,50 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 		b.handleClearCart(callback)
 	case "checkout":
 		b.handleCheckout(callback)
+	case "cart_reminder":
+		if len(parts) > 1 {
+			b.handleCartReminderCallback(callback, parts[1] == "on")
+		}
+	case "coupon":
+		if len(parts) > 1 {
+			b.handleCouponCallback(callback, parts[1])
+		}
+	case "points":
+		if len(parts) > 1 {
+			b.handlePointsToggle(callback, parts[1] == "on")
+		}
+	case "balance":
+		if len(parts) > 1 {
+			b.handleBalanceToggle(callback, parts[1] == "on")
+		}
+	case "lang":
+		if len(parts) > 1 {
+			b.handleLanguageCallback(callback, parts[1])
+		}
+	case "referral":
+		b.handleReferralCallback(callback)
 	case "order":
 		if len(parts) > 1 {
 			b.handleOrderDetail(callback, parts[1])
 		}
+	case "invoice":
+		if len(parts) > 1 {
+			b.handleInvoiceCallback(callback, parts[1])
+		}
 	case "contact":
 		b.handleContactCallback(callback)
+	case "ticket":
+		b.startSupportTicket(callback.Message.Chat.ID, callback.From.ID)
+	case "ticket_new":
+		if len(parts) > 1 {
+			b.handleTicketNewCallback(callback, parts[1])
+		}
+	case "ticket_close":
+		if len(parts) > 1 {
+			if ticketID, err := strconv.Atoi(parts[1]); err == nil {
+				b.handleTicketCloseCallback(callback, ticketID)
+			}
+		}
 	case "cancel":
 		if len(parts) > 1 {
 			b.handleCancelOrder(callback, parts[1])
@@ -101,8 +162,9 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 			b.handleConfirmCancel(callback, parts[1])
 		}
 	case "admin":
+		// Admin actions carry their own arguments, e.g. "admin:profit:category:30"
 		if len(parts) > 1 {
-			b.handleAdminCallback(callback, parts[1])
+			b.handleAdminCallback(callback, strings.TrimPrefix(data, "admin:"))
 		}
 	case "qris":
 		if len(parts) > 1 {
@@ -119,9 +181,50 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 			orderID := parts[2]
 			b.handleCopyAccount(callback, accountID, orderID)
 		}
+	case "myp":
+		// Purchased items (/produkku): myp:<page>
+		page := 0
+		if len(parts) > 1 {
+			page, _ = strconv.Atoi(parts[1])
+		}
+		b.handleMyProductsCallback(callback, page)
+	case "myp_order":
+		if len(parts) > 1 {
+			b.handlePurchasedOrderCallback(callback, parts[1])
+		}
+	case "myp_reveal":
+		if len(parts) > 1 {
+			if id, err := strconv.Atoi(parts[1]); err == nil {
+				b.revealPurchasedItem(callback, id)
+			}
+		}
+	case "myp_file":
+		if len(parts) > 1 {
+			b.handlePurchasedFileCallback(callback, parts[1])
+		}
 	case "product_header":
 		// Just acknowledge, this is a display button
 		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+	case "pwiz":
+		// Admin product wizard: pwiz:<action>[:arg]
+		if len(parts) > 1 {
+			arg := ""
+			if len(parts) > 2 {
+				arg = parts[2]
+			}
+			b.handleProductWizardCallback(callback, parts[1], arg)
+		}
+	case "rate":
+		// Rating from a review prompt: rate:<order>:<product>:<stars>
+		if len(parts) >= 4 {
+			productID, err1 := strconv.Atoi(parts[2])
+			rating, err2 := strconv.Atoi(parts[3])
+			if err1 == nil && err2 == nil {
+				b.handleRateCallback(callback, parts[1], productID, rating)
+			}
+		}
+	case "review_skip":
+		b.handleReviewSkipCallback(callback)
 	case "investigate":
 		// Handle investigation request
 		if len(parts) > 1 {
@@ -134,251 +237,217 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 
 // handleStartCallback handles start button callback
 func (b *Bot) handleStartCallback(callback *tgbotapi.CallbackQuery) {
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📱 Lihat Katalog", "catalog:0"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🛒 Keranjang", "cart"),
-			tgbotapi.NewInlineKeyboardButtonData("📞 Kontak", "contact"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("ℹ️ Bantuan", "help"),
-		),
-	)
+	lang := b.userLanguage(callback.From.ID)
+	keyboard := b.mainMenuKeyboard(lang)
 
-	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, b.messages.Welcome)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, b.t(lang, "welcome"))
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleHelpCallback handles help button callback
 func (b *Bot) handleHelpCallback(callback *tgbotapi.CallbackQuery) {
+	lang := b.userLanguage(callback.From.ID)
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 		),
 	)
 
-	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, b.messages.Help)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, b.t(lang, "help"))
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleCatalogCallback handles catalog display via callback
 func (b *Bot) handleCatalogCallback(callback *tgbotapi.CallbackQuery, category string, page int) {
-	const itemsPerPage = 5
-
-	// Get categories for filter buttons
-	categories, err := b.db.GetCategories()
+	lang := b.userLanguage(callback.From.ID)
+	text, keyboard, err := b.buildCatalog(callback.From.ID, lang, category, page)
 	if err != nil {
-		logrus.Errorf("Failed to get categories: %v", err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat kategori"))
+		logrus.Errorf("Failed to build catalog: %v", err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "catalog.products_failed")))
 		return
 	}
 
-	// Get products
-	products, err := b.db.GetProducts(category, itemsPerPage, page*itemsPerPage)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+
+	b.send(edit)
+}
+
+// handleProductDetail shows detailed product information
+func (b *Bot) handleProductDetail(callback *tgbotapi.CallbackQuery, productID int) {
+	lang := b.userLanguage(callback.From.ID)
+	product, err := b.db.GetProduct(productID)
 	if err != nil {
-		logrus.Errorf("Failed to get products: %v", err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat produk"))
+		logrus.Errorf("Failed to get product %d: %v", productID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "catalog.products_failed")))
 		return
 	}
 
-	if len(products) == 0 {
-		text := "🚫 Tidak ada produk yang tersedia"
-		if category != "" {
-			text += " untuk kategori ini"
-		}
-		text += "."
-		
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
-			),
-		)
-		
-		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
-		edit.ReplyMarkup = &keyboard
-		b.api.Send(edit)
+	if product == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "product.not_found")))
 		return
 	}
 
-	// Build message text
-	var text strings.Builder
-	text.WriteString("📱 *KATALOG APLIKASI PREMIUM*\n\n")
+	text, keyboard := b.buildProductDetail(callback.From.ID, lang, product)
 
-	if category != "" {
-		for _, cat := range categories {
-			if cat.Name == category {
-				text.WriteString(fmt.Sprintf("📂 Kategori: %s\n\n", cat.DisplayName))
-				break
-			}
-		}
-	}
+	// A text message can't be edited into a photo, so with a photo the detail is sent again below it
+	if b.sendProductPhoto(callback.Message.Chat.ID, lang, product) {
+		b.api.Request(tgbotapi.NewDeleteMessage(callback.Message.Chat.ID, callback.Message.MessageID))
 
-	// Category filter buttons (only show if no category selected)
-	var keyboard [][]tgbotapi.InlineKeyboardButton
-	if category == "" {
-		text.WriteString("🏷️ *Filter berdasarkan kategori:*\n\n")
-		
-		var catRow []tgbotapi.InlineKeyboardButton
-		for _, cat := range categories {
-			if cat.Count > 0 {
-				catRow = append(catRow, tgbotapi.NewInlineKeyboardButtonData(
-					fmt.Sprintf("%s (%d)", cat.Icon, cat.Count),
-					fmt.Sprintf("category:%s:0", cat.Name),
-				))
-				
-				if len(catRow) == 2 {
-					keyboard = append(keyboard, catRow)
-					catRow = nil
-				}
-			}
-		}
-		if len(catRow) > 0 {
-			keyboard = append(keyboard, catRow)
-		}
-		
-		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📋 Semua Produk", "catalog:0"),
-		))
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = keyboard
+		b.send(msg)
+		return
 	}
 
-	// Product list
-	for _, product := range products {
-		text.WriteString(fmt.Sprintf("🔸 *%s*\n", product.Name))
-		text.WriteString(fmt.Sprintf("💰 %s\n", models.FormatPrice(product.Price, b.config.CurrencySymbol)))
-		
-		desc := product.Description
-		if len(desc) > 80 {
-			desc = desc[:80] + "..."
-		}
-		text.WriteString(fmt.Sprintf("📝 %s\n\n", desc))
-
-		// Product buttons
-		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("👁️ Detail", fmt.Sprintf("product:%d", product.ID)),
-			tgbotapi.NewInlineKeyboardButtonData("🛒 Beli", fmt.Sprintf("buy:%d", product.ID)),
-		))
-	}
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
 
-	// Navigation buttons
-	var navRow []tgbotapi.InlineKeyboardButton
-	
-	// Previous page
-	if page > 0 {
-		prevCallback := fmt.Sprintf("catalog:%d", page-1)
-		if category != "" {
-			prevCallback = fmt.Sprintf("category:%s:%d", category, page-1)
-		}
-		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("⬅️ Sebelumnya", prevCallback))
-	}
+	b.send(edit)
+}
 
-	// Next page (check if there are more products)
-	if len(products) == itemsPerPage {
-		nextCallback := fmt.Sprintf("catalog:%d", page+1)
-		if category != "" {
-			nextCallback = fmt.Sprintf("category:%s:%d", category, page+1)
-		}
-		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("Selanjutnya ➡️", nextCallback))
-	}
+// buildProductDetail renders the product screen with the user's price, stock and purchase buttons
+func (b *Bot) buildProductDetail(userID int64, lang string, product *models.Product) (string, tgbotapi.InlineKeyboardMarkup) {
+	// Translate a copy for display; stock and pricing still use the stored product
+	display := []models.Product{*product}
+	b.translateProducts(lang, display)
 
-	if len(navRow) > 0 {
-		keyboard = append(keyboard, navRow)
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📱 *%s*\n\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, display[0].Name)))
+	text.WriteString(b.t(lang, "product.description", display[0].Description) + "\n\n")
+	pricing := b.loadPriceContext(userID)
+	pricing.applyProduct(product, 1)
+	text.WriteString(b.t(lang, "product.price", b.formatSalePrice(lang, product.Price, product.OriginalPrice)) + "\n")
+	if product.FlashSale != nil {
+		text.WriteString(b.t(lang, "product.flash_saving", b.formatFlashSaleNote(lang, product.FlashSale),
+			b.formatPrice(lang, product.OriginalPrice-product.Price)) + "\n")
 	}
-
-	// Back buttons
-	if category != "" {
-		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Semua Kategori", "catalog:0"),
-		))
+	if product.PriceTier != nil {
+		text.WriteString(b.formatPriceTierNote(lang, product.PriceTier) + "\n")
 	}
-	
-	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
-	))
-
-	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
-	edit.ParseMode = tgbotapi.ModeMarkdown
-	edit.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	b.writeQuantityTiers(&text, lang, pricing, product)
 
-	b.api.Send(edit)
-}
-
-// handleProductDetail shows detailed product information
-func (b *Bot) handleProductDetail(callback *tgbotapi.CallbackQuery, productID int) {
-	product, err := b.db.GetProduct(productID)
-	if err != nil {
-		logrus.Errorf("Failed to get product %d: %v", productID, err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat produk"))
-		return
+	if product.IsBundle {
+		components, err := b.db.GetBundleItems(product.ID)
+		if err != nil {
+			logrus.Errorf("Failed to get components of bundle %d: %v", product.ID, err)
+		}
+		b.writeBundleComponents(&text, product, components)
 	}
-
-	if product == nil {
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Produk tidak ditemukan"))
-		return
+	if product.IsGiftCard() {
+		b.writeGiftCardTerms(&text, lang, product)
 	}
 
-	var text strings.Builder
-	text.WriteString(fmt.Sprintf("📱 *%s*\n\n", product.Name))
-	text.WriteString(fmt.Sprintf("📝 *Deskripsi:*\n%s\n\n", product.Description))
-	text.WriteString(fmt.Sprintf("💰 *Harga:* %s\n", models.FormatPrice(product.Price, b.config.CurrencySymbol)))
-	
 	// Find category display name
 	categories, _ := b.db.GetCategories()
 	for _, cat := range categories {
 		if cat.Name == product.Category {
-			text.WriteString(fmt.Sprintf("🏷️ *Kategori:* %s\n", cat.DisplayName))
+			text.WriteString(b.t(lang, "product.category", b.categoryLabel(lang, cat)) + "\n")
 			break
 		}
 	}
-	
-	text.WriteString(fmt.Sprintf("📦 *Stok:* %d tersedia\n\n", product.Stock))
-	text.WriteString("✅ *Status:* Tersedia")
+	b.writeProductReviews(&text, lang, product.ID)
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🛒 Tambah ke Keranjang", fmt.Sprintf("addcart:%d", product.ID)),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("💳 Beli Sekarang", fmt.Sprintf("buy:%d", product.ID)),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Kembali ke Katalog", "catalog:0"),
-		),
-	)
+	availableAccounts, err := b.db.GetAvailableAccountCount(product.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get available accounts for product %d: %v", product.ID, err)
+	}
 
-	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
-	edit.ParseMode = tgbotapi.ModeMarkdown
-	edit.ReplyMarkup = &keyboard
+	var keyboard tgbotapi.InlineKeyboardMarkup
+	if availableAccounts == 0 {
+		text.WriteString(b.t(lang, "product.stock_empty") + "\n\n")
+
+		// Offer a pre-order when enabled and quota remains, otherwise a restock notification
+		if slotsLeft := b.preorderSlotsLeft(product); slotsLeft > 0 {
+			text.WriteString(b.t(lang, "product.preorder_open") + "\n")
+			text.WriteString(b.t(lang, "product.preorder_eta", b.formatPreorderETA(lang, product)) + "\n")
+			text.WriteString(b.t(lang, "product.preorder_slots", slotsLeft))
+
+			keyboard = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "product.preorder_now"), fmt.Sprintf("preorder:%d", product.ID)),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "product.back_to_catalog"), "catalog:0"),
+				),
+			)
+		} else {
+			text.WriteString(b.t(lang, "product.status_sold_out"))
+
+			keyboard = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "product.notify_me"), fmt.Sprintf("notify:%d", product.ID)),
+				),
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "product.back_to_catalog"), "catalog:0"),
+				),
+			)
+		}
+	} else {
+		if product.IsGiftCard() {
+			text.WriteString(b.t(lang, "product.stock_always") + "\n\n")
+		} else {
+			text.WriteString(b.t(lang, "product.stock_available", availableAccounts) + "\n\n")
+		}
+		text.WriteString(b.t(lang, "product.status_available"))
 
-	b.api.Send(edit)
+		keyboard = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "product.add_to_cart"), fmt.Sprintf("addcart:%d", product.ID)),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "product.buy_now"), fmt.Sprintf("buy:%d", product.ID)),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "product.back_to_catalog"), "catalog:0"),
+			),
+		)
+	}
+
+	return text.String(), keyboard
 }
 
 // handleAddToCart adds product to user's cart
 func (b *Bot) handleAddToCart(callback *tgbotapi.CallbackQuery, productID, quantity int) {
 	userID := callback.From.ID
+	lang := b.userLanguage(userID)
 
 	// Check if product exists and is available
 	product, err := b.db.GetProduct(productID)
 	if err != nil {
 		logrus.Errorf("Failed to get product %d: %v", productID, err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat produk"))
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "catalog.products_failed")))
 		return
 	}
 
 	if product == nil {
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Produk tidak ditemukan"))
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "product.not_found")))
+		return
+	}
+
+	// The stock column is not kept for bundles and gift cards, so count what can actually be delivered
+	available, err := b.db.GetAvailableAccountCount(productID)
+	if err != nil {
+		logrus.Errorf("Failed to get available stock of product %d: %v", productID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "catalog.products_failed")))
+		return
+	}
+	if available < quantity {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "cart.insufficient_stock")))
 		return
 	}
 
-	if product.Stock < quantity {
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Stok tidak mencukupi"))
+	if msg := b.checkCartLimits(lang, userID, product, quantity); msg != "" {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, msg))
 		return
 	}
 
@@ -386,12 +455,12 @@ func (b *Bot) handleAddToCart(callback *tgbotapi.CallbackQuery, productID, quant
 	err = b.db.AddToCart(userID, productID, quantity)
 	if err != nil {
 		logrus.Errorf("Failed to add product %d to cart for user %d: %v", productID, userID, err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal menambahkan ke keranjang"))
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "cart.add_failed")))
 		return
 	}
 
-	b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Produk ditambahkan ke keranjang!"))
-	
+	b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "cart.added")))
+
 	// Show cart after adding
 	b.handleCartCallback(callback)
 }
@@ -399,72 +468,60 @@ func (b *Bot) handleAddToCart(callback *tgbotapi.CallbackQuery, productID, quant
 // handleCartCallback shows user's shopping cart
 func (b *Bot) handleCartCallback(callback *tgbotapi.CallbackQuery) {
 	userID := callback.From.ID
-	cartItems, err := b.db.GetCart(userID)
+	lang := b.userLanguage(userID)
+	cartItems, err := b.getPricedCart(userID)
 	if err != nil {
 		logrus.Errorf("Failed to get cart for user %d: %v", userID, err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat keranjang"))
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "cart.load_failed")))
 		return
 	}
 
 	if len(cartItems) == 0 {
 		keyboard := tgbotapi.NewInlineKeyboardMarkup(
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📱 Lihat Katalog", "catalog:0"),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.catalog"), "catalog:0"),
 			),
 			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 			),
 		)
 
-		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, b.messages.CartEmpty)
+		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, b.t(lang, "cart.empty"))
 		edit.ParseMode = tgbotapi.ModeMarkdown
 		edit.ReplyMarkup = &keyboard
-		b.api.Send(edit)
+		b.send(edit)
+		return
+	}
+
+	quote, err := b.buildCheckoutQuote(userID, cartItems)
+	if err != nil {
+		logrus.Errorf("Failed to price cart for user %d: %v", userID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "cart.load_failed")))
 		return
 	}
+	b.translateCartItems(lang, cartItems)
 
 	// Build cart display
 	var text strings.Builder
-	text.WriteString("🛒 *KERANJANG BELANJA*\n\n")
+	text.WriteString(b.t(lang, "cart.title") + "\n\n")
 
-	totalPrice := 0
-	for _, item := range cartItems {
-		subtotal := item.ProductPrice * item.Quantity
-		totalPrice += subtotal
+	b.writeCartItems(&text, lang, cartItems)
 
-		text.WriteString(fmt.Sprintf("🔸 *%s*\n", item.ProductName))
-		text.WriteString(fmt.Sprintf("   Jumlah: %d x %s = %s\n\n",
-			item.Quantity,
-			models.FormatPrice(item.ProductPrice, b.config.CurrencySymbol),
-			models.FormatPrice(subtotal, b.config.CurrencySymbol)))
-	}
+	b.writeCartTotals(&text, lang, quote)
 
-	text.WriteString(fmt.Sprintf("💰 *Total: %s*\n", models.FormatPrice(totalPrice, b.config.CurrencySymbol)))
-
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("💳 Checkout", "checkout"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🗑️ Kosongkan Keranjang", "clearcart"),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📱 Lanjut Belanja", "catalog:0"),
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
-		),
-	)
+	keyboard := b.cartKeyboard(lang, quote)
 
 	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleClearCart clears user's shopping cart
 func (b *Bot) handleClearCart(callback *tgbotapi.CallbackQuery) {
 	userID := callback.From.ID
-	
+
 	err := b.db.ClearCart(userID)
 	if err != nil {
 		logrus.Errorf("Failed to clear cart for user %d: %v", userID, err)
@@ -476,26 +533,53 @@ func (b *Bot) handleClearCart(callback *tgbotapi.CallbackQuery) {
 	b.handleCartCallback(callback)
 }
 
+// handleCartReminderCallback turns abandoned-cart reminders on or off from the reminder message
+func (b *Bot) handleCartReminderCallback(callback *tgbotapi.CallbackQuery, enabled bool) {
+	lang := b.userLanguage(callback.From.ID)
+	if err := b.db.SetCartReminders(callback.From.ID, enabled); err != nil {
+		logrus.Errorf("Failed to update cart reminders for user %d: %v", callback.From.ID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "cart_reminder.update_failed")))
+		return
+	}
+
+	if enabled {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "cart_reminder.enabled_alert")))
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.view_cart"), "cart"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart_reminder.turn_on"), "cart_reminder:on"),
+		),
+	)
+	b.send(tgbotapi.NewEditMessageReplyMarkup(callback.Message.Chat.ID, callback.Message.MessageID, keyboard))
+	b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "cart_reminder.disabled_alert")))
+}
+
 // handleCheckout processes checkout and creates order with QRIS payment
 func (b *Bot) handleCheckout(callback *tgbotapi.CallbackQuery) {
 	userID := callback.From.ID
-	
+
 	// Get cart items
-	cartItems, err := b.db.GetCart(userID)
+	lang := b.userLanguage(userID)
+	cartItems, err := b.getPricedCart(userID)
 	if err != nil {
 		logrus.Errorf("Failed to get cart for user %d: %v", userID, err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat keranjang"))
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "cart.load_failed")))
 		return
 	}
 
 	if len(cartItems) == 0 {
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Keranjang kosong"))
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "checkout.cart_empty")))
 		return
 	}
 
 	// Check if real QRIS is configured
 	if !b.realQRISService.IsConfigured() {
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Sistem pembayaran belum dikonfigurasi"))
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "payment.not_configured")))
 		return
 	}
 
@@ -503,7 +587,7 @@ func (b *Bot) handleCheckout(callback *tgbotapi.CallbackQuery) {
 	for _, item := range cartItems {
 		product, err := b.db.GetProduct(item.ProductID)
 		if err != nil || product == nil {
-			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Produk tidak ditemukan"))
+			b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "product.not_found")))
 			return
 		}
 
@@ -511,95 +595,152 @@ func (b *Bot) handleCheckout(callback *tgbotapi.CallbackQuery) {
 		availableAccounts, err := b.db.GetAvailableAccountCount(item.ProductID)
 		if err != nil {
 			logrus.Errorf("Failed to get available accounts for product %d: %v", item.ProductID, err)
-			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal validasi stok"))
+			b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "checkout.stock_check_failed")))
 			return
 		}
 
 		if availableAccounts < item.Quantity {
-			b.api.Request(tgbotapi.NewCallback(callback.ID, 
-				fmt.Sprintf("❌ Stok %s tidak mencukupi! Tersedia: %d akun, diminta: %d", 
-					product.Name, availableAccounts, item.Quantity)))
+			b.api.Request(tgbotapi.NewCallback(callback.ID,
+				b.t(lang, "checkout.insufficient_stock", product.Name, availableAccounts, item.Quantity)))
 			return
 		}
 
 		if availableAccounts == 0 {
-			b.api.Request(tgbotapi.NewCallback(callback.ID, 
-				fmt.Sprintf("❌ %s sedang tidak tersedia (stok habis)", product.Name)))
+			b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "checkout.sold_out", product.Name)))
 			return
 		}
 	}
 
-	// Calculate total
-	totalAmount := 0
 	var orderItems []models.OrderItem
 	for _, item := range cartItems {
-		subtotal := item.ProductPrice * item.Quantity
-		totalAmount += subtotal
-
-		orderItems = append(orderItems, models.OrderItem{
+		orderItem := models.OrderItem{
 			ProductID: item.ProductID,
 			Quantity:  item.Quantity,
 			Price:     item.ProductPrice,
-		})
+		}
+		if item.FlashSale != nil {
+			orderItem.FlashSaleID = &item.FlashSale.ID
+		}
+		orderItems = append(orderItems, orderItem)
 	}
 
+	if msg := b.checkOrderLimits(lang, userID, orderItems); msg != "" {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, msg))
+		return
+	}
+
+	// Calculate total with the applied coupon
+	quote, err := b.buildCheckoutQuote(userID, cartItems)
+	if err != nil {
+		logrus.Errorf("Failed to price cart for user %d: %v", userID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "checkout.total_failed")))
+		return
+	}
+	if quote.CouponError != "" {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID,
+			b.t(lang, "checkout.coupon_invalid", quote.CouponCode, quote.CouponError)))
+		return
+	}
+
+	order := &models.Order{
+		TotalAmount:    quote.Total,
+		DiscountAmount: quote.Discount,
+		BalanceUsed:    quote.BalanceUsed,
+		PointsUsed:     quote.PointsUsed,
+		PointsDiscount: quote.PointsDiscount,
+		Items:          orderItems,
+	}
+	if quote.Coupon != nil {
+		order.CouponID = &quote.Coupon.ID
+		order.CouponCode = &quote.Coupon.Code
+	}
+
+	if _, ok := b.startQRISPayment(callback, order, false); !ok {
+		return
+	}
+
+	// Clear cart, coupon, points and balance choice after successful order creation
+	b.db.ClearCart(userID)
+	b.db.ClearCartCoupon(userID)
+	b.db.SetCartUsePoints(userID, false)
+	b.db.SetCartUseBalance(userID, false)
+
+	b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "checkout.success")))
+}
+
+// startQRISPayment generates a QRIS payment for the order total, stores the order and sends the payment instructions.
+// The caller fills the order items, total and discount. Pre-orders are stored without account assignment
+// and wait for stock after payment. Orders fully covered by discount and balance skip QRIS and complete right away.
+func (b *Bot) startQRISPayment(callback *tgbotapi.CallbackQuery, order *models.Order, preorder bool) (string, bool) {
 	// Generate order ID using real QRIS service
 	orderID := b.realQRISService.GenerateOrderID()
+	totalAmount := order.TotalAmount
+	lang := b.userLanguage(callback.From.ID)
+
+	order.ID = orderID
+	order.UserID = callback.From.ID
+	order.PaymentStatus = models.PaymentStatusPending
+	order.Limits = models.OrderLimits{
+		MaxPendingOrders: b.config.MaxPendingOrders,
+		MaxQtyPerProduct: b.config.MaxQtyPerProduct,
+		PeriodHours:      b.config.PurchaseLimitPeriodHrs,
+	}
+
+	if totalAmount <= 0 {
+		return b.completeZeroAmountOrder(callback, order, preorder)
+	}
+
+	// Every QRIS image is a new payment; limit how many a user can generate
+	if reply, ok := b.allowAction(callback.From.ID, ratelimit.ActionQR); !ok {
+		if reply == "" {
+			reply = b.t(lang, "payment.qr_throttled")
+		}
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, reply))
+		return "", false
+	}
 
 	// Generate dynamic QRIS payment
 	qrisPayment, qrImage, err := b.realQRISService.GenerateDynamicQRIS(orderID, totalAmount)
 	if err != nil {
 		logrus.Errorf("Failed to generate QRIS for order %s: %v", orderID, err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal membuat pembayaran"))
-		return
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "payment.create_failed")))
+		return "", false
 	}
 
-	// Create order
-	order := &models.Order{
-		ID:            orderID,
-		UserID:        userID,
-		TotalAmount:   totalAmount,
-		PaymentMethod: "qris",
-		PaymentStatus: models.PaymentStatusPending,
-		QRISCode:      &qrisPayment.QRString,
-		QRISExpiry:    &qrisPayment.ExpiryTime,
-		Items:         orderItems,
-	}
-
-	// Create order with account assignment
-	_, err = b.db.CreateOrderWithAccounts(order)
-	if err != nil {
-		logrus.Errorf("Failed to create order %s: %v", orderID, err)
-		if strings.Contains(err.Error(), "insufficient accounts") {
-			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Stok akun tidak mencukupi"))
-		} else {
-			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal membuat pesanan"))
-		}
-		return
+	order.PaymentMethod = "qris"
+	order.QRISCode = &qrisPayment.QRString
+	order.QRISExpiry = &qrisPayment.ExpiryTime
+
+	if !b.createOrder(callback, order, preorder) {
+		return "", false
 	}
 
 	// Create payment verification record
 	verifier := payment.NewPaymentVerifier(b.config.PaymentSecretKey)
 	verificationHash := verifier.GenerateVerificationHash(orderID, totalAmount, qrisPayment.QRString)
-	
+
 	err = b.db.CreatePaymentVerification(orderID, totalAmount, qrisPayment.QRString, verificationHash)
 	if err != nil {
 		logrus.Errorf("Failed to create payment verification for order %s: %v", orderID, err)
 	}
 
-	// Clear cart after successful order creation
-	b.db.ClearCart(userID)
-
 	// Send order success message
-	orderText := fmt.Sprintf(b.messages.OrderSuccess,
+	orderText := b.t(lang, "order.success",
 		orderID,
-		b.config.CurrencySymbol,
-		models.FormatPrice(totalAmount, b.config.CurrencySymbol),
-		time.Now().Format("02/01/2006 15:04"))
+		b.formatPrice(lang, totalAmount),
+		b.i18n.FormatDateTime(lang, time.Now()))
+	if order.DiscountAmount > 0 || order.PointsDiscount > 0 || order.BalanceUsed > 0 {
+		var deductions strings.Builder
+		b.writeOrderDeductions(&deductions, lang, order)
+		orderText += "\n\n" + deductions.String()
+	}
+	if preorder {
+		orderText += "\n\n" + b.t(lang, "payment.preorder_note")
+	}
 
 	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, orderText)
 	edit.ParseMode = tgbotapi.ModeMarkdown
-	b.api.Send(edit)
+	b.send(edit)
 
 	// Send QRIS QR Code
 	qrMsg := tgbotapi.NewPhoto(callback.Message.Chat.ID, tgbotapi.FileBytes{
@@ -614,11 +755,11 @@ func (b *Bot) handleCheckout(callback *tgbotapi.CallbackQuery) {
 
 	// Add supported banks info
 	supportedBanks := b.realQRISService.GetSupportedBanks()
-	qrMsg.Caption += "\n\n📱 *Aplikasi yang mendukung QRIS:*\n"
+	qrMsg.Caption += "\n\n" + b.t(lang, "payment.supported_apps") + "\n"
 	for i, bank := range supportedBanks {
 		qrMsg.Caption += fmt.Sprintf("• %s\n", bank)
 		if i >= 9 { // Show first 10 only to avoid message length limit
-			qrMsg.Caption += fmt.Sprintf("• ... dan %d lainnya\n", len(supportedBanks)-10)
+			qrMsg.Caption += b.t(lang, "payment.more_apps", len(supportedBanks)-10) + "\n"
 			break
 		}
 	}
@@ -626,93 +767,206 @@ func (b *Bot) handleCheckout(callback *tgbotapi.CallbackQuery) {
 	// Add keyboard for order management
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📄 Detail Pesanan", fmt.Sprintf("order:%s", orderID)),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "order.detail_button"), fmt.Sprintf("order:%s", orderID)),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("❌ Batalkan Pesanan", fmt.Sprintf("cancel:%s", orderID)),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "order.cancel_button"), fmt.Sprintf("cancel:%s", orderID)),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📞 Hubungi Admin", "contact"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.contact_admin"), "contact"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 		),
 	)
 	qrMsg.ReplyMarkup = keyboard
 
-	b.api.Send(qrMsg)
+	b.send(qrMsg)
+
+	return orderID, true
+}
+
+// createOrder stores the order with account assignment (or as a pre-order) and reports failures to the buyer
+func (b *Bot) createOrder(callback *tgbotapi.CallbackQuery, order *models.Order, preorder bool) bool {
+	var err error
+	if preorder {
+		err = b.db.CreatePreorder(order)
+	} else {
+		// Create order with account assignment
+		_, err = b.db.CreateOrderWithAccounts(order)
+	}
+	if err == nil {
+		return true
+	}
+
+	logrus.Errorf("Failed to create order %s: %v", order.ID, err)
+	lang := b.userLanguage(callback.From.ID)
+	switch {
+	case strings.Contains(err.Error(), "insufficient accounts"):
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.insufficient_accounts")))
+	case strings.Contains(err.Error(), "pre-order limit"):
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.preorder_full")))
+	case strings.Contains(err.Error(), "coupon"):
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, b.t(lang, "order.coupon_unavailable")))
+	case strings.Contains(err.Error(), "flash sale"):
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, b.t(lang, "order.flash_sale_ended")))
+	case strings.Contains(err.Error(), "insufficient points"):
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, b.t(lang, "order.insufficient_points")))
+	case strings.Contains(err.Error(), "pending order limit"):
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, b.t(lang, "order.pending_limit")))
+	case strings.Contains(err.Error(), "purchase limit"):
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, b.t(lang, "order.purchase_limit")))
+	case strings.Contains(err.Error(), "insufficient balance"):
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, b.t(lang, "order.insufficient_balance")))
+	default:
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.create_failed")))
+	}
+	return false
+}
+
+// completeZeroAmountOrder stores an order whose total is fully covered by discount, points and balance and delivers it without payment
+func (b *Bot) completeZeroAmountOrder(callback *tgbotapi.CallbackQuery, order *models.Order, preorder bool) (string, bool) {
+	order.PaymentMethod = "coupon"
+	if order.PointsUsed > 0 {
+		order.PaymentMethod = "points"
+	}
+	if order.BalanceUsed > 0 {
+		order.PaymentMethod = "balance"
+	}
+	if !b.createOrder(callback, order, preorder) {
+		return "", false
+	}
 
-	b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Pesanan berhasil dibuat!"))
+	lang := b.userLanguage(callback.From.ID)
+	var text strings.Builder
+	text.WriteString(b.t(lang, "order.paid_title") + "\n\n")
+	text.WriteString(b.t(lang, "order.id", order.ID) + "\n")
+	b.writeOrderDeductions(&text, lang, order)
+	text.WriteString(b.t(lang, "order.total", b.formatPrice(lang, 0)) + "\n\n")
+	text.WriteString(b.t(lang, "order.paid_without_qris"))
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	b.send(edit)
+
+	if err := b.handlePaymentSuccess(order.ID, 0); err != nil {
+		logrus.Errorf("Failed to complete zero-amount order %s: %v", order.ID, err)
+	}
+
+	return order.ID, true
+}
+
+// writeOrderDeductions appends the coupon discount, points and balance lines of an order
+func (b *Bot) writeOrderDeductions(text *strings.Builder, lang string, order *models.Order) {
+	if order.DiscountAmount > 0 {
+		if order.CouponCode != nil {
+			text.WriteString(b.t(lang, "order.coupon_discount", *order.CouponCode, b.formatPrice(lang, order.DiscountAmount)) + "\n")
+		} else {
+			text.WriteString(b.t(lang, "order.discount", b.formatPrice(lang, order.DiscountAmount)) + "\n")
+		}
+	}
+	if order.PointsUsed > 0 {
+		text.WriteString(b.t(lang, "cart.points_used", order.PointsUsed, b.formatPrice(lang, order.PointsDiscount)) + "\n")
+	}
+	if order.BalanceUsed > 0 {
+		text.WriteString(b.t(lang, "cart.balance_used", b.formatPrice(lang, order.BalanceUsed)) + "\n")
+	}
+}
+
+// paymentMethodName returns the name of an order payment method in lang
+func (b *Bot) paymentMethodName(lang, method string) string {
+	switch method {
+	case "coupon", "points", "balance":
+		return b.t(lang, "payment_method."+method)
+	default:
+		return b.t(lang, "payment_method.qris")
+	}
 }
 
 // handleOrderDetail shows order details
 func (b *Bot) handleOrderDetail(callback *tgbotapi.CallbackQuery, orderID string) {
+	lang := b.userLanguage(callback.From.ID)
 	order, err := b.db.GetOrder(orderID)
 	if err != nil {
 		logrus.Errorf("Failed to get order %s: %v", orderID, err)
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat pesanan"))
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.load_failed")))
 		return
 	}
 
 	if order == nil || order.UserID != callback.From.ID {
-		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Pesanan tidak ditemukan"))
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.not_found")))
 		return
 	}
 
 	var text strings.Builder
-	text.WriteString("📄 *DETAIL PESANAN*\n\n")
-	text.WriteString(fmt.Sprintf("🆔 Order ID: #%s\n", order.ID))
-	text.WriteString(fmt.Sprintf("📅 Tanggal: %s\n", order.CreatedAt.Format("02/01/2006 15:04")))
-	text.WriteString(fmt.Sprintf("💰 Total: %s\n", models.FormatPrice(order.TotalAmount, b.config.CurrencySymbol)))
-	text.WriteString(fmt.Sprintf("💳 Metode: QRIS\n"))
-	text.WriteString(fmt.Sprintf("📊 Status: %s %s\n\n", b.getStatusEmoji(order.PaymentStatus), cases.Title(language.Und).String(string(order.PaymentStatus))))
+	text.WriteString(b.t(lang, "order.detail_title") + "\n\n")
+	text.WriteString(b.t(lang, "order.id", order.ID) + "\n")
+	text.WriteString(b.t(lang, "order.date", b.i18n.FormatDateTime(lang, order.CreatedAt)) + "\n")
+	b.writeOrderDeductions(&text, lang, order)
+	text.WriteString(b.t(lang, "order.total", b.formatPrice(lang, order.TotalAmount)) + "\n")
+	text.WriteString(b.t(lang, "order.method", b.paymentMethodName(lang, order.PaymentMethod)) + "\n")
+	text.WriteString(b.t(lang, "order.status", b.getStatusEmoji(order.PaymentStatus),
+		b.t(lang, "order.status."+string(order.PaymentStatus))) + "\n")
+	if order.IsPreorder {
+		text.WriteString(b.t(lang, "order.type_preorder") + "\n")
+		if order.PaymentStatus == models.PaymentStatusAwaitingStock {
+			text.WriteString(b.t(lang, "order.awaiting_stock") + "\n")
+		}
+	}
+	text.WriteString("\n")
 
 	if order.QRISExpiry != nil {
 		if b.paymentService.IsExpired(order.QRISExpiry) {
-			text.WriteString("⏰ QR Code: Expired\n\n")
+			text.WriteString(b.t(lang, "order.qr_expired") + "\n\n")
 		} else {
-			text.WriteString(fmt.Sprintf("⏰ QR Code berlaku sampai: %s\n\n", order.QRISExpiry.Format("15:04")))
+			text.WriteString(b.t(lang, "order.qr_valid_until", order.QRISExpiry.Format("15:04")) + "\n\n")
 		}
 	}
 
-	text.WriteString("📦 *Item Pesanan:*\n")
+	text.WriteString(b.t(lang, "order.items_title") + "\n")
 	for _, item := range order.Items {
 		subtotal := item.Price * item.Quantity
-		text.WriteString(fmt.Sprintf("• %s\n", item.ProductName))
-		text.WriteString(fmt.Sprintf("  %d x %s = %s\n",
+		text.WriteString(fmt.Sprintf("• %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, item.ProductName)))
+		text.WriteString(b.t(lang, "order.item_quantity",
 			item.Quantity,
-			models.FormatPrice(item.Price, b.config.CurrencySymbol),
-			models.FormatPrice(subtotal, b.config.CurrencySymbol)))
+			b.formatPrice(lang, item.Price),
+			b.formatPrice(lang, subtotal)) + "\n")
 	}
 
 	var keyboardRows [][]tgbotapi.InlineKeyboardButton
-	
+
 	// Add simulate payment button for admins if order is pending
 	if b.config.IsAdmin(callback.From.ID) && order.PaymentStatus == models.PaymentStatusPending {
 		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("🧪 [Admin] Simulasi Pembayaran", fmt.Sprintf("simulate_payment:%s", orderID)),
 		))
 	}
-	
+
+	if isInvoiceable(order) {
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "order.invoice_button"), fmt.Sprintf("invoice:%s", orderID)),
+		))
+	}
+
 	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("📞 Hubungi Admin", "contact"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.contact_admin"), "contact"),
 	))
 	keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 	))
-	
+
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
 
 	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleContactCallback handles contact button callback
 func (b *Bot) handleContactCallback(callback *tgbotapi.CallbackQuery) {
-	contactText := fmt.Sprintf(b.messages.Contact,
+	lang := b.userLanguage(callback.From.ID)
+	contactText := b.t(lang, "contact",
 		b.config.AdminUsername,
 		b.config.AdminEmail,
 		b.config.SupportPhone,
@@ -720,7 +974,10 @@ func (b *Bot) handleContactCallback(callback *tgbotapi.CallbackQuery) {
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.support_ticket"), "ticket:start"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 		),
 	)
 
@@ -728,7 +985,7 @@ func (b *Bot) handleContactCallback(callback *tgbotapi.CallbackQuery) {
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleAdminCallback handles admin panel callbacks
@@ -749,9 +1006,76 @@ func (b *Bot) handleAdminCallback(callback *tgbotapi.CallbackQuery, action strin
 	case "users":
 		b.handleAdminUsers(callback)
 	case "products":
-		b.handleProductManagement(callback)
+		page := 0
+		if len(parts) > 1 {
+			page, _ = strconv.Atoi(parts[1])
+		}
+		b.handleProductManagement(callback, page)
+	case "addproduct":
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.startProductWizard(callback.Message.Chat.ID, callback.From.ID, &models.ProductDraft{
+			Step:     wizardStepName,
+			IsActive: true,
+		})
+	case "product", "editproduct", "toggleproduct", "deleteproduct", "confirmdeleteproduct",
+		"photos", "addphoto", "photosdone", "primaryphoto", "deletephoto":
+		if len(parts) < 2 {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Aksi tidak dikenal"))
+			return
+		}
+		id, err := strconv.Atoi(parts[1])
+		if err != nil {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ ID tidak valid"))
+			return
+		}
+		if mainAction == "primaryphoto" || mainAction == "deletephoto" {
+			b.handleAdminPhotoAction(callback, mainAction, id)
+		} else {
+			b.handleAdminProductAction(callback, mainAction, id)
+		}
 	case "orders":
 		b.handleAdminOrders(callback)
+	case "tickets":
+		b.handleAdminTickets(callback)
+	case "templates":
+		b.handleAdminTemplates(callback)
+	case "template", "tplpreview", "tpledit", "tplreset":
+		// e.g. "template:payment_success:en"; without a language the default one is shown
+		if len(parts) < 2 {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Aksi tidak dikenal"))
+			return
+		}
+		lang := ""
+		if len(parts) > 2 {
+			lang = parts[2]
+		}
+		b.handleAdminTemplateAction(callback, mainAction, parts[1], lang)
+	case "ticket", "claimticket", "closeticket", "reopenticket", "replyticket":
+		if len(parts) < 2 {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Aksi tidak dikenal"))
+			return
+		}
+		id, err := strconv.Atoi(parts[1])
+		if err != nil {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ ID tidak valid"))
+			return
+		}
+		b.handleAdminTicketAction(callback, mainAction, id)
+	case "moduser", "banuser", "unbanuser":
+		if len(parts) < 2 {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Aksi tidak dikenal"))
+			return
+		}
+		userID, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ ID tidak valid"))
+			return
+		}
+		arg := ""
+		if len(parts) > 2 {
+			arg = parts[2]
+		}
+		b.handleAdminModerationAction(callback, mainAction, userID, arg)
 	case "stock":
 		b.handleStockManagement(callback)
 	case "addstock":
@@ -760,6 +1084,48 @@ func (b *Bot) handleAdminCallback(callback *tgbotapi.CallbackQuery, action strin
 		b.handleLowStock(callback)
 	case "categories":
 		b.handleCategoryManagement(callback)
+	case "profit":
+		groupBy := models.ProfitByProduct
+		days := 30
+		if len(parts) > 1 {
+			groupBy = models.ProfitGroupBy(parts[1])
+		}
+		if len(parts) > 2 {
+			if d, err := strconv.Atoi(parts[2]); err == nil {
+				days = d
+			}
+		}
+		b.handleProfitReport(callback, groupBy, days)
+	case "batches":
+		b.handleStockBatches(callback)
+	case "coupons":
+		b.handleAdminCoupons(callback)
+	case "flashsales":
+		b.handleAdminFlashSales(callback)
+	case "batch", "revokebatch", "confirmrevoke", "affected", "replace", "refund":
+		if len(parts) < 2 {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Aksi tidak dikenal"))
+			return
+		}
+		id, err := strconv.Atoi(parts[1])
+		if err != nil {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ ID tidak valid"))
+			return
+		}
+		switch mainAction {
+		case "batch":
+			b.handleBatchDetail(callback, id)
+		case "revokebatch":
+			b.handleRevokeBatchConfirm(callback, id)
+		case "confirmrevoke":
+			b.handleConfirmRevokeBatch(callback, id)
+		case "affected":
+			b.handleAffectedItems(callback, id)
+		case "replace":
+			b.handleReplaceSoldAccount(callback, id)
+		case "refund":
+			b.handleRefundSoldAccount(callback, id)
+		}
 	case "broadcast":
 		if len(parts) > 1 {
 			if parts[1] == "all" || parts[1] == "active" {
@@ -782,41 +1148,9 @@ func (b *Bot) handleAdminCallback(callback *tgbotapi.CallbackQuery, action strin
 }
 
 // Admin callback handlers (simplified for demo)
-func (b *Bot) handleAdminStats(callback *tgbotapi.CallbackQuery) {
-	text := "📊 *STATISTIK BOT*\n\nFitur ini akan dikembangkan lebih lanjut."
-	
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
-		),
-	)
-
-	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
-	edit.ParseMode = tgbotapi.ModeMarkdown
-	edit.ReplyMarkup = &keyboard
-
-	b.api.Send(edit)
-}
-
-func (b *Bot) handleAdminUsers(callback *tgbotapi.CallbackQuery) {
-	text := "👥 *KELOLA PENGGUNA*\n\nFitur ini akan dikembangkan lebih lanjut."
-	
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
-		),
-	)
-
-	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
-	edit.ParseMode = tgbotapi.ModeMarkdown
-	edit.ReplyMarkup = &keyboard
-
-	b.api.Send(edit)
-}
-
 func (b *Bot) handleAdminProducts(callback *tgbotapi.CallbackQuery) {
 	text := "📦 *KELOLA PRODUK*\n\nFitur ini akan dikembangkan lebih lanjut."
-	
+
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
@@ -827,7 +1161,7 @@ func (b *Bot) handleAdminProducts(callback *tgbotapi.CallbackQuery) {
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 func (b *Bot) handleAdminMain(callback *tgbotapi.CallbackQuery) {
@@ -845,23 +1179,29 @@ func (b *Bot) handleAdminMain(callback *tgbotapi.CallbackQuery) {
 		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("📢 Broadcast", "admin:broadcast"),
+			tgbotapi.NewInlineKeyboardButtonData("🎟️ Kupon", "admin:coupons"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚡ Flash Sale", "admin:flashsales"),
 			tgbotapi.NewInlineKeyboardButtonData("🔧 Setup QRIS", "qris:setup"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🎫 Tiket Bantuan", "admin:tickets"),
+			tgbotapi.NewInlineKeyboardButtonData("📝 Template Pesan", "admin:templates"),
+		),
 	)
 
-	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, 
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
 		"👨‍💼 *PANEL ADMIN*\n\nPilih menu admin:")
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 func (b *Bot) handleAdminOrders(callback *tgbotapi.CallbackQuery) {
 	text := "💰 *KELOLA PESANAN*\n\nFitur ini akan dikembangkan lebih lanjut."
-	
+
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
@@ -872,13 +1212,17 @@ func (b *Bot) handleAdminOrders(callback *tgbotapi.CallbackQuery) {
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
-// handleCopyAccount handles copy account callback (displays copyable text)
+// handleCopyAccount handles the copy buttons of the delivery message by revealing the item again in a spoiler
 func (b *Bot) handleCopyAccount(callback *tgbotapi.CallbackQuery, accountID, orderID string) {
-	// This callback is mainly for UI interaction feedback
-	b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Tap pada kredensial untuk menyalin"))
+	id, err := strconv.Atoi(accountID)
+	if err != nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ ID tidak valid"))
+		return
+	}
+	b.revealPurchasedItem(callback, id)
 }
 
 // handleInvestigateOrder handles investigation request from admin
@@ -897,7 +1241,7 @@ func (b *Bot) handleInvestigateOrder(callback *tgbotapi.CallbackQuery, orderID s
 
 	// Get payment verification
 	verification, err := b.db.GetPaymentVerification(orderID)
-	
+
 	var text strings.Builder
 	text.WriteString("🔍 *INVESTIGASI ORDER*\n\n")
 	text.WriteString(fmt.Sprintf("🆔 Order ID: `%s`\n", orderID))
@@ -932,6 +1276,6 @@ func (b *Bot) handleInvestigateOrder(callback *tgbotapi.CallbackQuery, orderID s
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 	b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
-}
\ No newline at end of file
+}
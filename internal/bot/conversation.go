@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// setConversation puts the user in a conversation state that expires after the state's TTL
+func (b *Bot) setConversation(userID int64, state models.ConversationState, payload string) {
+	if err := b.db.SetConversation(userID, state, payload, state.TTL()); err != nil {
+		logrus.Errorf("Failed to set conversation state %s for user %d: %v", state, userID, err)
+	}
+}
+
+// getConversation returns the user's active conversation state, or nil when they are not in a flow
+func (b *Bot) getConversation(userID int64) *models.Conversation {
+	conv, err := b.db.GetConversation(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get conversation state for user %d: %v", userID, err)
+		return nil
+	}
+	return conv
+}
+
+// takeConversation ends the user's conversation if it is in the given state and returns its payload
+func (b *Bot) takeConversation(userID int64, state models.ConversationState) (string, bool) {
+	payload, ok, err := b.db.TakeConversation(userID, state)
+	if err != nil {
+		logrus.Errorf("Failed to take conversation state %s for user %d: %v", state, userID, err)
+		return "", false
+	}
+	return payload, ok
+}
+
+// handleConversationMessage routes a non-command message to the flow the user is in, if any
+func (b *Bot) handleConversationMessage(message *tgbotapi.Message) {
+	// Replying to a relayed support ticket message always continues that ticket
+	if b.handleTicketReply(message) {
+		return
+	}
+
+	conv := b.getConversation(message.From.ID)
+	if conv == nil {
+		b.handleMessage(message)
+		return
+	}
+
+	switch conv.State {
+	case models.StateQRISUpload:
+		if message.Photo == nil {
+			b.handleMessage(message)
+			return
+		}
+		b.handleQRISImageUpload(message)
+	case models.StateCouponCode:
+		// Handle coupon code input from the cart screen
+		if _, ok := b.takeConversation(message.From.ID, models.StateCouponCode); ok {
+			b.applyCouponCode(message, message.Text)
+		}
+	case models.StateBroadcast:
+		// Handle broadcast message input
+		if targetType, ok := b.takeConversation(message.From.ID, models.StateBroadcast); ok {
+			b.processBroadcastMessage(message, targetType)
+		}
+	case models.StateReviewComment:
+		// Handle the optional comment after rating a product
+		if reviewID, ok := b.takeConversation(message.From.ID, models.StateReviewComment); ok {
+			b.processReviewComment(message, reviewID)
+		}
+	case models.StateProductWizard:
+		// Handle the admin's answer to the current product wizard step; the state is kept until saved
+		b.processProductWizardInput(message)
+	case models.StateProductPhotos:
+		// Handle photos sent for a product until the admin presses Selesai
+		b.processProductPhotoUpload(message, conv.Payload)
+	case models.StateSupportTicket:
+		// Handle the buyer's messages to support; the state is kept so that follow-ups are relayed too
+		b.processSupportMessage(message, conv.Payload)
+	case models.StateTicketReply:
+		// Handle the admin's answer typed from the ticket queue
+		if ticketID, ok := b.takeConversation(message.From.ID, models.StateTicketReply); ok {
+			b.processTicketReply(message, ticketID)
+		}
+	case models.StateTemplateEdit:
+		// Handle the admin's new template text; the state is kept until the text is accepted and saved
+		b.processTemplateEdit(message, conv.Payload)
+	case models.StateBanReason:
+		// Handle the reason for a ban chosen from the moderation screen; the state is kept until the reason is valid
+		b.processBanReason(message, conv.Payload)
+	default:
+		b.handleMessage(message)
+	}
+}
+
+// handleCancelCommand handles /batal and leaves whatever flow the user is in
+func (b *Bot) handleCancelCommand(message *tgbotapi.Message) {
+	lang := b.userLanguage(message.From.ID)
+	state, err := b.db.ClearConversation(message.From.ID)
+	if err != nil {
+		logrus.Errorf("Failed to clear conversation state for user %d: %v", message.From.ID, err)
+		b.sendMessage(message.Chat.ID, b.t(lang, "cancel.failed"))
+		return
+	}
+
+	switch state {
+	case "":
+		b.sendMessage(message.Chat.ID, b.t(lang, "cancel.nothing"))
+	case models.StateQRISUpload:
+		b.sendMessage(message.Chat.ID, "✅ Upload QRIS dibatalkan.")
+	case models.StateCouponCode:
+		b.sendMessage(message.Chat.ID, b.t(lang, "cancel.coupon"))
+	case models.StateBroadcast:
+		b.sendMessage(message.Chat.ID, "✅ Broadcast dibatalkan.")
+	case models.StateReviewComment:
+		b.sendMessage(message.Chat.ID, b.t(lang, "cancel.review_comment"))
+	case models.StateProductWizard:
+		b.sendMessage(message.Chat.ID, "✅ Wizard produk dibatalkan. Tidak ada perubahan yang disimpan.")
+	case models.StateProductPhotos:
+		b.sendMessage(message.Chat.ID, "✅ Upload foto produk selesai. Foto yang sudah terkirim tetap tersimpan.")
+	case models.StateSupportTicket:
+		b.sendMessage(message.Chat.ID, b.t(lang, "cancel.support_ticket"))
+	case models.StateTicketReply:
+		b.sendMessage(message.Chat.ID, "✅ Balasan tiket dibatalkan.")
+	case models.StateTemplateEdit:
+		b.sendMessage(message.Chat.ID, "✅ Perubahan template dibatalkan. Teks template tidak berubah.")
+	case models.StateBanReason:
+		b.sendMessage(message.Chat.ID, "✅ Blokir pengguna dibatalkan.")
+	default:
+		b.sendMessage(message.Chat.ID, b.t(lang, "cancel.done"))
+	}
+}
@@ -0,0 +1,717 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// checkoutQuote is the price breakdown of a cart with the applied coupon, loyalty points and store balance
+type checkoutQuote struct {
+	Subtotal       int
+	Discount       int
+	Total          int            // Amount left to pay after discount, points and balance
+	Coupon         *models.Coupon // nil when no coupon applies
+	CouponCode     string         // code applied to the cart, even when it is no longer valid
+	CouponError    string         // why CouponCode does not apply, empty when valid
+	Points         int            // Loyalty points available to the user
+	UsePoints      bool
+	PointsUsed     int
+	PointsDiscount int
+	Balance        int // Store balance available to the user
+	UseBalance     bool
+	BalanceUsed    int
+}
+
+// buildCheckoutQuote prices the cart and applies the coupon and balance stored in the user's cart options
+func (b *Bot) buildCheckoutQuote(userID int64, cartItems []models.CartItem) (*checkoutQuote, error) {
+	quote := &checkoutQuote{}
+	for _, item := range cartItems {
+		quote.Subtotal += item.ProductPrice * item.Quantity
+	}
+	quote.Total = quote.Subtotal
+
+	options, err := b.db.GetCartOptions(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.CouponCode != "" {
+		quote.CouponCode = options.CouponCode
+		coupon, discount, reason := b.evaluateCoupon(userID, options.CouponCode, cartItems)
+		if reason != "" {
+			quote.CouponError = reason
+		} else {
+			quote.Coupon = coupon
+			quote.Discount = discount
+			quote.Total = quote.Subtotal - discount
+		}
+	}
+
+	quote.Points, err = b.db.GetLoyaltyPoints(userID)
+	if err != nil {
+		return nil, err
+	}
+	quote.UsePoints = options.UsePoints
+	if pointValue := b.config.LoyaltyPointValue; quote.UsePoints && quote.Points > 0 && pointValue > 0 {
+		// Only whole points are spent, so a remainder below one point's value is left to pay
+		quote.PointsUsed = quote.Points
+		if maxPoints := quote.Total / pointValue; quote.PointsUsed > maxPoints {
+			quote.PointsUsed = maxPoints
+		}
+		quote.PointsDiscount = quote.PointsUsed * pointValue
+		quote.Total -= quote.PointsDiscount
+	}
+
+	quote.Balance, err = b.db.GetBalance(userID)
+	if err != nil {
+		return nil, err
+	}
+	quote.UseBalance = options.UseBalance
+	if quote.UseBalance && quote.Balance > 0 {
+		quote.BalanceUsed = quote.Balance
+		if quote.BalanceUsed > quote.Total {
+			quote.BalanceUsed = quote.Total
+		}
+		quote.Total -= quote.BalanceUsed
+	}
+
+	return quote, nil
+}
+
+// evaluateCoupon checks a coupon code against the cart and the user's history.
+// Returns the coupon and discount, or an Indonesian reason when it cannot be used.
+func (b *Bot) evaluateCoupon(userID int64, code string, cartItems []models.CartItem) (*models.Coupon, int, string) {
+	coupon, err := b.db.GetCouponByCode(code)
+	if err != nil {
+		logrus.Errorf("Failed to get coupon %s: %v", code, err)
+		return nil, 0, "gagal memeriksa kupon, coba lagi"
+	}
+	if coupon == nil || !coupon.IsActive {
+		return nil, 0, "kode kupon tidak ditemukan atau sudah tidak aktif"
+	}
+	if coupon.UserID != nil && *coupon.UserID != userID {
+		return nil, 0, "kupon ini khusus untuk pengguna lain"
+	}
+
+	now := time.Now()
+	if !coupon.IsWithinPeriod(now) {
+		if coupon.StartsAt != nil && now.Before(*coupon.StartsAt) {
+			return nil, 0, fmt.Sprintf("kupon baru berlaku mulai %s", coupon.StartsAt.Format("02/01/2006"))
+		}
+		return nil, 0, "kupon sudah kedaluwarsa"
+	}
+
+	if coupon.UsageLimit > 0 && coupon.UsedCount >= coupon.UsageLimit {
+		return nil, 0, "kuota kupon sudah habis"
+	}
+
+	if coupon.PerUserLimit > 0 {
+		used, err := b.db.CountUserCouponRedemptions(coupon.ID, userID)
+		if err != nil {
+			logrus.Errorf("Failed to count coupon %d usage for user %d: %v", coupon.ID, userID, err)
+			return nil, 0, "gagal memeriksa kupon, coba lagi"
+		}
+		if used >= coupon.PerUserLimit {
+			return nil, 0, fmt.Sprintf("Anda sudah memakai kupon ini (maksimal %d kali)", coupon.PerUserLimit)
+		}
+	}
+
+	if coupon.FirstPurchaseOnly {
+		purchased, err := b.db.HasCompletedPurchase(userID)
+		if err != nil {
+			logrus.Errorf("Failed to check purchase history for user %d: %v", userID, err)
+			return nil, 0, "gagal memeriksa kupon, coba lagi"
+		}
+		if purchased {
+			return nil, 0, "kupon hanya berlaku untuk pembelian pertama"
+		}
+	}
+
+	eligible := 0
+	for _, item := range cartItems {
+		if coupon.AppliesTo(item.ProductID, item.ProductCategory) {
+			eligible += item.ProductPrice * item.Quantity
+		}
+	}
+	if eligible == 0 {
+		return nil, 0, "kupon tidak berlaku untuk produk di keranjang Anda"
+	}
+	if eligible < coupon.MinSpend {
+		return nil, 0, fmt.Sprintf("minimal belanja %s untuk kupon ini",
+			models.FormatPrice(coupon.MinSpend, b.config.CurrencySymbol))
+	}
+
+	return coupon, coupon.CalculateDiscount(eligible), ""
+}
+
+// writeCartTotals appends the subtotal, coupon, points, balance and total lines of a cart
+func (b *Bot) writeCartTotals(text *strings.Builder, lang string, quote *checkoutQuote) {
+	if quote.CouponCode == "" && quote.PointsUsed == 0 && quote.BalanceUsed == 0 {
+		text.WriteString(b.t(lang, "cart.total", b.formatPrice(lang, quote.Total)) + "\n")
+		return
+	}
+
+	text.WriteString(b.t(lang, "cart.subtotal", b.formatPrice(lang, quote.Subtotal)) + "\n")
+	if quote.CouponError != "" {
+		text.WriteString(b.t(lang, "cart.coupon_invalid", quote.CouponCode, quote.CouponError) + "\n")
+	} else if quote.CouponCode != "" {
+		text.WriteString(b.t(lang, "cart.coupon", quote.CouponCode, b.formatPrice(lang, quote.Discount)) + "\n")
+	}
+	if quote.PointsUsed > 0 {
+		text.WriteString(b.t(lang, "cart.points_used", quote.PointsUsed, b.formatPrice(lang, quote.PointsDiscount)) + "\n")
+	}
+	if quote.BalanceUsed > 0 {
+		text.WriteString(b.t(lang, "cart.balance_used", b.formatPrice(lang, quote.BalanceUsed)) + "\n")
+	}
+	text.WriteString(b.t(lang, "cart.total", b.formatPrice(lang, quote.Total)) + "\n")
+}
+
+// cartKeyboard returns the cart screen buttons
+func (b *Bot) cartKeyboard(lang string, quote *checkoutQuote) tgbotapi.InlineKeyboardMarkup {
+	couponButton := tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart.use_coupon"), "coupon:enter")
+	if quote.CouponCode != "" {
+		couponButton = tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart.remove_coupon", quote.CouponCode), "coupon:remove")
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart.checkout"), "checkout"),
+		),
+		tgbotapi.NewInlineKeyboardRow(couponButton),
+	}
+
+	if quote.UsePoints {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart.skip_points"), "points:off"),
+		))
+	} else if quote.Points > 0 && b.config.LoyaltyPointValue > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart.use_points", quote.Points), "points:on"),
+		))
+	}
+
+	if quote.UseBalance {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart.skip_balance"), "balance:off"),
+		))
+	} else if quote.Balance > 0 {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart.use_balance", b.formatPrice(lang, quote.Balance)), "balance:on"),
+		))
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart.clear"), "clearcart"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "cart.continue_shopping"), "catalog:0"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
+		),
+	)
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleCouponCallback handles the coupon buttons on the cart screen
+func (b *Bot) handleCouponCallback(callback *tgbotapi.CallbackQuery, action string) {
+	userID := callback.From.ID
+
+	switch action {
+	case "enter":
+		b.setConversation(userID, models.StateCouponCode, "")
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID,
+			"🎟️ *MASUKKAN KODE KUPON*\n\nKetik kode kupon Anda sekarang.\n\n💡 Bisa juga dengan perintah /kupon KODE")
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("❌ Batal", "coupon:cancel"),
+			),
+		)
+		b.send(msg)
+	case "cancel":
+		b.takeConversation(userID, models.StateCouponCode)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "Dibatalkan"))
+		b.api.Request(tgbotapi.NewDeleteMessage(callback.Message.Chat.ID, callback.Message.MessageID))
+	case "remove":
+		if err := b.db.ClearCartCoupon(userID); err != nil {
+			logrus.Errorf("Failed to remove coupon for user %d: %v", userID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal menghapus kupon"))
+			return
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "🗑️ Kupon dihapus"))
+		b.handleCartCallback(callback)
+	default:
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Aksi tidak dikenal"))
+	}
+}
+
+// handleBalanceToggle turns spending the store balance at checkout on or off
+func (b *Bot) handleBalanceToggle(callback *tgbotapi.CallbackQuery, useBalance bool) {
+	userID := callback.From.ID
+	if err := b.db.SetCartUseBalance(userID, useBalance); err != nil {
+		logrus.Errorf("Failed to set balance option for user %d: %v", userID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memperbarui keranjang"))
+		return
+	}
+
+	if useBalance {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "💰 Saldo akan dipakai saat checkout"))
+	} else {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "Saldo tidak dipakai"))
+	}
+	b.handleCartCallback(callback)
+}
+
+// handlePointsToggle turns redeeming loyalty points at checkout on or off
+func (b *Bot) handlePointsToggle(callback *tgbotapi.CallbackQuery, usePoints bool) {
+	userID := callback.From.ID
+	if err := b.db.SetCartUsePoints(userID, usePoints); err != nil {
+		logrus.Errorf("Failed to set points option for user %d: %v", userID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memperbarui keranjang"))
+		return
+	}
+
+	if usePoints {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "⭐ Poin akan dipakai saat checkout"))
+	} else {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "Poin tidak dipakai"))
+	}
+	b.handleCartCallback(callback)
+}
+
+// handleCouponCommand handles /kupon [KODE|hapus]
+func (b *Bot) handleCouponCommand(message *tgbotapi.Message) {
+	userID := message.From.ID
+	arg := strings.TrimSpace(message.CommandArguments())
+
+	if arg == "" {
+		options, err := b.db.GetCartOptions(userID)
+		if err != nil {
+			logrus.Errorf("Failed to get cart options for user %d: %v", userID, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal memuat kupon.")
+			return
+		}
+		code := options.CouponCode
+
+		text := "🎟️ *KUPON*\n\nGunakan /kupon KODE untuk memakai kupon di keranjang Anda."
+		if code != "" {
+			text = fmt.Sprintf("🎟️ *KUPON*\n\nKupon aktif di keranjang: `%s`\n\nGunakan /kupon KODE untuk mengganti atau /kupon hapus untuk melepas kupon.", code)
+		}
+		b.sendMessage(message.Chat.ID, text)
+		return
+	}
+
+	if strings.EqualFold(arg, "hapus") {
+		if err := b.db.ClearCartCoupon(userID); err != nil {
+			logrus.Errorf("Failed to remove coupon for user %d: %v", userID, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal menghapus kupon.")
+			return
+		}
+		b.sendMessage(message.Chat.ID, "🗑️ Kupon dihapus dari keranjang.")
+		return
+	}
+
+	b.applyCouponCode(message, arg)
+}
+
+// applyCouponCode validates a code against the user's cart and stores it when valid
+func (b *Bot) applyCouponCode(message *tgbotapi.Message, code string) {
+	userID := message.From.ID
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" || strings.ContainsAny(code, " \n") {
+		b.sendMessage(message.Chat.ID, "❌ Kode kupon tidak valid.")
+		return
+	}
+
+	// Gift card codes entered at checkout are redeemed to balance, which is then spent on the cart
+	if isGiftCardCode(code) {
+		b.applyGiftCardCode(message, code)
+		return
+	}
+
+	cartItems, err := b.getPricedCart(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get cart for user %d: %v", userID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat keranjang.")
+		return
+	}
+	if len(cartItems) == 0 {
+		b.sendMessage(message.Chat.ID, "❌ Keranjang Anda kosong. Tambahkan produk dulu sebelum memakai kupon.")
+		return
+	}
+
+	coupon, discount, reason := b.evaluateCoupon(userID, code, cartItems)
+	if reason != "" {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Kupon `%s` tidak bisa dipakai: %s.",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, code), reason))
+		return
+	}
+
+	if err := b.db.SetCartCoupon(userID, coupon.Code); err != nil {
+		logrus.Errorf("Failed to apply coupon %s for user %d: %v", coupon.Code, userID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memakai kupon.")
+		return
+	}
+
+	b.db.LogUserInteraction(userID, "coupon_applied", coupon.Code)
+	b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Kupon `%s` dipakai! Anda hemat %s.",
+		coupon.Code, models.FormatPrice(discount, b.config.CurrencySymbol)))
+	b.handleCart(message)
+}
+
+// applyGiftCardCode redeems a gift card to the user's balance and turns on paying with balance
+func (b *Bot) applyGiftCardCode(message *tgbotapi.Message, code string) {
+	userID := message.From.ID
+	lang := b.userLanguage(userID)
+	card, ok := b.redeemGiftCard(lang, message.Chat.ID, userID, code, 0)
+	if !ok {
+		return
+	}
+
+	if err := b.db.SetCartUseBalance(userID, true); err != nil {
+		logrus.Errorf("Failed to set balance option for user %d: %v", userID, err)
+	}
+
+	b.sendMessage(message.Chat.ID, b.t(lang, "gift_card.applied", card.Code))
+	b.handleCart(message)
+}
+
+// Admin coupon management
+
+// processAddCouponCommand handles /addcoupon KODE persen|nominal NILAI [opsi...]
+func (b *Bot) processAddCouponCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	usage := `❌ Format salah!
+
+Gunakan:
+/addcoupon [KODE] [persen|nominal] [nilai] [opsi...]
+
+Opsi:
+• min=50000 - minimal belanja
+• maks=20000 - maksimal diskon (kupon persen)
+• produk=3 - hanya untuk produk ID 3
+• kategori=music - hanya untuk kategori
+• mulai=2026-11-01 - tanggal mulai
+• sampai=2026-11-30 - tanggal berakhir
+• kuota=100 - total pemakaian (0 = tanpa batas)
+• peruser=1 - pemakaian per user (0 = tanpa batas)
+• baru - hanya untuk pembelian pertama
+
+Contoh:
+/addcoupon HEMAT10 persen 10 maks=20000 sampai=2026-11-30 kuota=100
+/addcoupon NEWUSER nominal 5000 min=25000 baru`
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) < 3 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	coupon := &models.Coupon{
+		Code:         strings.ToUpper(args[0]),
+		PerUserLimit: 1,
+		CreatedBy:    message.From.ID,
+	}
+
+	switch strings.ToLower(args[1]) {
+	case "persen", "percent", "%":
+		coupon.DiscountType = models.CouponDiscountPercent
+	case "nominal", "fixed":
+		coupon.DiscountType = models.CouponDiscountFixed
+	default:
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	value, err := strconv.Atoi(args[2])
+	if err != nil || value <= 0 || (coupon.DiscountType == models.CouponDiscountPercent && value > 100) {
+		b.sendMessage(message.Chat.ID, "❌ Nilai diskon tidak valid! Persen 1-100, nominal lebih dari 0.")
+		return
+	}
+	coupon.DiscountValue = value
+
+	for _, opt := range args[3:] {
+		if strings.EqualFold(opt, "baru") {
+			coupon.FirstPurchaseOnly = true
+			continue
+		}
+
+		key, val, found := strings.Cut(opt, "=")
+		if !found || val == "" {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Opsi tidak dikenal: %s\n\n%s", opt, usage))
+			return
+		}
+
+		switch strings.ToLower(key) {
+		case "kategori":
+			category := strings.ToLower(val)
+			coupon.Category = &category
+		case "mulai", "sampai":
+			date, err := time.ParseInLocation("2006-01-02", val, time.Local)
+			if err != nil {
+				b.sendMessage(message.Chat.ID, "❌ Format tanggal salah! Gunakan YYYY-MM-DD, contoh: 2026-11-30")
+				return
+			}
+			if key == "mulai" {
+				coupon.StartsAt = &date
+			} else {
+				// The end date is inclusive
+				end := date.Add(24*time.Hour - time.Second)
+				coupon.EndsAt = &end
+			}
+		case "min", "maks", "produk", "kuota", "peruser":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Nilai %s harus berupa angka!", key))
+				return
+			}
+			switch key {
+			case "min":
+				coupon.MinSpend = n
+			case "maks":
+				coupon.MaxDiscount = n
+			case "produk":
+				product, err := b.db.GetProduct(n)
+				if err != nil || product == nil {
+					b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Produk dengan ID %d tidak ditemukan!", n))
+					return
+				}
+				coupon.ProductID = &n
+			case "kuota":
+				coupon.UsageLimit = n
+			case "peruser":
+				coupon.PerUserLimit = n
+			}
+		default:
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Opsi tidak dikenal: %s\n\n%s", opt, usage))
+			return
+		}
+	}
+
+	if coupon.StartsAt != nil && coupon.EndsAt != nil && coupon.EndsAt.Before(*coupon.StartsAt) {
+		b.sendMessage(message.Chat.ID, "❌ Tanggal berakhir harus setelah tanggal mulai!")
+		return
+	}
+
+	if err := b.db.CreateCoupon(coupon); err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Kode kupon %s sudah ada!", coupon.Code))
+			return
+		}
+		logrus.Errorf("Failed to create coupon %s: %v", coupon.Code, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal membuat kupon!")
+		return
+	}
+
+	logrus.Infof("Admin %d created coupon %s", message.From.ID, coupon.Code)
+	b.sendMessage(message.Chat.ID, "✅ *Kupon berhasil dibuat!*\n\n"+b.formatCouponDetail(coupon))
+}
+
+// processCouponCommand handles /coupon KODE [on|off]
+func (b *Bot) processCouponCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		b.sendMessage(message.Chat.ID, "❌ Format salah!\n\nGunakan:\n/coupon [KODE] - Detail dan pemakaian kupon\n/coupon [KODE] on|off - Aktifkan/nonaktifkan kupon")
+		return
+	}
+
+	code := strings.ToUpper(args[0])
+	if len(args) > 1 {
+		active := strings.EqualFold(args[1], "on")
+		if !active && !strings.EqualFold(args[1], "off") {
+			b.sendMessage(message.Chat.ID, "❌ Gunakan on atau off.")
+			return
+		}
+
+		found, err := b.db.SetCouponActive(code, active)
+		if err != nil {
+			logrus.Errorf("Failed to update coupon %s: %v", code, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal memperbarui kupon!")
+			return
+		}
+		if !found {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Kupon %s tidak ditemukan!", code))
+			return
+		}
+
+		status := "dinonaktifkan"
+		if active {
+			status = "diaktifkan"
+		}
+		logrus.Infof("Admin %d set coupon %s active=%v", message.From.ID, code, active)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Kupon `%s` %s.", code, status))
+		return
+	}
+
+	coupon, err := b.db.GetCouponByCode(code)
+	if err != nil || coupon == nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Kupon %s tidak ditemukan!", code))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString("🎟️ *DETAIL KUPON*\n\n")
+	text.WriteString(b.formatCouponDetail(coupon))
+
+	redemptions, err := b.db.GetCouponRedemptions(coupon.ID, 10)
+	if err != nil {
+		logrus.Errorf("Failed to get redemptions for coupon %s: %v", code, err)
+	}
+	if len(redemptions) > 0 {
+		text.WriteString("\n\n🧾 *Pemakaian Terakhir:*\n")
+		for _, r := range redemptions {
+			text.WriteString(fmt.Sprintf("• #%s - user %d - -%s %s\n",
+				shortOrderID(r.OrderID), r.UserID,
+				models.FormatPrice(r.DiscountAmount, b.config.CurrencySymbol),
+				b.getStatusEmoji(r.PaymentStatus)))
+		}
+	}
+
+	b.sendMessage(message.Chat.ID, text.String())
+}
+
+// formatCouponDetail describes a coupon's rules and usage
+func (b *Bot) formatCouponDetail(coupon *models.Coupon) string {
+	var text strings.Builder
+
+	status := "✅ Aktif"
+	if !coupon.IsActive {
+		status = "⛔ Nonaktif"
+	}
+	text.WriteString(fmt.Sprintf("🏷️ Kode: `%s`\n", coupon.Code))
+	text.WriteString(fmt.Sprintf("📊 Status: %s\n", status))
+	text.WriteString(fmt.Sprintf("💸 Diskon: %s\n", coupon.FormatValue(b.config.CurrencySymbol)))
+	if coupon.MinSpend > 0 {
+		text.WriteString(fmt.Sprintf("🧾 Minimal belanja: %s\n", models.FormatPrice(coupon.MinSpend, b.config.CurrencySymbol)))
+	}
+	if coupon.ProductID != nil {
+		text.WriteString(fmt.Sprintf("📦 Produk: ID %d\n", *coupon.ProductID))
+	}
+	if coupon.Category != nil {
+		text.WriteString(fmt.Sprintf("🏷️ Kategori: %s\n", *coupon.Category))
+	}
+	if coupon.StartsAt != nil {
+		text.WriteString(fmt.Sprintf("📅 Mulai: %s\n", coupon.StartsAt.Format("02/01/2006")))
+	}
+	if coupon.EndsAt != nil {
+		text.WriteString(fmt.Sprintf("📅 Berakhir: %s\n", coupon.EndsAt.Format("02/01/2006")))
+	}
+	if coupon.FirstPurchaseOnly {
+		text.WriteString("🆕 Hanya pembelian pertama\n")
+	}
+
+	quota := "tanpa batas"
+	if coupon.UsageLimit > 0 {
+		quota = strconv.Itoa(coupon.UsageLimit)
+	}
+	perUser := "tanpa batas"
+	if coupon.PerUserLimit > 0 {
+		perUser = fmt.Sprintf("%dx", coupon.PerUserLimit)
+	}
+	text.WriteString(fmt.Sprintf("👥 Terpakai: %d / %s (per user: %s)\n", coupon.UsedCount, quota, perUser))
+	text.WriteString(fmt.Sprintf("💰 Total diskon diberikan: %s", models.FormatPrice(coupon.TotalDiscount, b.config.CurrencySymbol)))
+
+	return text.String()
+}
+
+// buildCouponListText lists all coupons with their usage for the admin report
+func (b *Bot) buildCouponListText() (string, error) {
+	coupons, err := b.db.GetCoupons()
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	text.WriteString("🎟️ *KUPON & VOUCHER*\n\n")
+	if len(coupons) == 0 {
+		text.WriteString("Belum ada kupon.\n\n")
+	}
+
+	totalUsed, totalDiscount := 0, 0
+	for _, coupon := range coupons {
+		icon := "✅"
+		if !coupon.IsActive {
+			icon = "⛔"
+		} else if coupon.EndsAt != nil && time.Now().After(*coupon.EndsAt) {
+			icon = "⏰"
+		}
+
+		quota := "∞"
+		if coupon.UsageLimit > 0 {
+			quota = strconv.Itoa(coupon.UsageLimit)
+		}
+		text.WriteString(fmt.Sprintf("%s `%s` - %s\n", icon, coupon.Code, coupon.FormatValue(b.config.CurrencySymbol)))
+		text.WriteString(fmt.Sprintf("   Terpakai %d/%s • Diskon %s\n", coupon.UsedCount, quota,
+			models.FormatPrice(coupon.TotalDiscount, b.config.CurrencySymbol)))
+
+		totalUsed += coupon.UsedCount
+		totalDiscount += coupon.TotalDiscount
+	}
+
+	if len(coupons) > 0 {
+		text.WriteString(fmt.Sprintf("\n📊 Total pemakaian: %d • Total diskon: %s\n\n", totalUsed,
+			models.FormatPrice(totalDiscount, b.config.CurrencySymbol)))
+	}
+	text.WriteString("💡 /addcoupon untuk membuat kupon\n💡 /coupon [KODE] untuk detail, /coupon [KODE] off untuk menonaktifkan")
+
+	return text.String(), nil
+}
+
+// handleCouponsCommand handles /coupons
+func (b *Bot) handleCouponsCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	text, err := b.buildCouponListText()
+	if err != nil {
+		logrus.Errorf("Failed to get coupons: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat kupon!")
+		return
+	}
+	b.sendMessage(message.Chat.ID, text)
+}
+
+// handleAdminCoupons shows the coupon report from the admin panel
+func (b *Bot) handleAdminCoupons(callback *tgbotapi.CallbackQuery) {
+	text, err := b.buildCouponListText()
+	if err != nil {
+		logrus.Errorf("Failed to get coupons: %v", err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat kupon"))
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Refresh", "admin:coupons"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
@@ -0,0 +1,364 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// parseFlashSaleTime parses "2026-11-11T20:00" or a date only "2026-11-11" in local time.
+// A date-only end time covers the whole day.
+func parseFlashSaleTime(value string, end bool) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02T15:04", value, time.Local); err == nil {
+		return t, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		return date.Add(24 * time.Hour), nil
+	}
+	return date, nil
+}
+
+// processAddFlashSaleCommand handles /addflashsale [NAMA] [harga|persen] [nilai] [opsi...]
+func (b *Bot) processAddFlashSaleCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	usage := `❌ Format salah!
+
+Gunakan:
+/addflashsale [NAMA] [harga|persen] [nilai] [opsi...]
+
+Nama memakai _ sebagai spasi. Harga tetap wajib memakai produk=.
+
+Opsi:
+• produk=3 - hanya untuk produk ID 3
+• kategori=music - semua produk di kategori
+• mulai=2026-11-11T20:00 - waktu mulai (default: sekarang)
+• sampai=2026-11-11T23:59 - waktu berakhir
+• durasi=3h - lama sale, pengganti sampai=
+• kuota=50 - jumlah unit dengan harga sale (0 = tanpa batas)
+• senyap - tanpa pengumuman ke pelanggan
+
+Contoh:
+/addflashsale Promo_11.11 persen 30 kategori=music mulai=2026-11-11T00:00 durasi=24h
+/addflashsale Netflix_Murah harga 25000 produk=3 durasi=2h kuota=20`
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) < 3 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	sale := &models.FlashSale{
+		Name:      strings.ReplaceAll(args[0], "_", " "),
+		StartsAt:  time.Now(),
+		Announce:  true,
+		CreatedBy: message.From.ID,
+	}
+
+	value, err := strconv.Atoi(args[2])
+	if err != nil || value <= 0 {
+		b.sendMessage(message.Chat.ID, "❌ Nilai sale harus berupa angka lebih dari 0!")
+		return
+	}
+
+	switch strings.ToLower(args[1]) {
+	case "persen", "percent", "%":
+		if value >= 100 {
+			b.sendMessage(message.Chat.ID, "❌ Diskon persen harus 1-99!")
+			return
+		}
+		sale.DiscountPercent = value
+	case "harga", "price":
+		sale.SalePrice = value
+	default:
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	var product *models.Product
+	var duration time.Duration
+	for _, opt := range args[3:] {
+		if strings.EqualFold(opt, "senyap") {
+			sale.Announce = false
+			continue
+		}
+
+		key, val, found := strings.Cut(opt, "=")
+		if !found || val == "" {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Opsi tidak dikenal: %s\n\n%s", opt, usage))
+			return
+		}
+
+		switch strings.ToLower(key) {
+		case "kategori":
+			category := strings.ToLower(val)
+			sale.Category = &category
+		case "mulai", "sampai":
+			t, err := parseFlashSaleTime(val, key == "sampai")
+			if err != nil {
+				b.sendMessage(message.Chat.ID, "❌ Format waktu salah! Gunakan YYYY-MM-DDTHH:MM, contoh: 2026-11-11T20:00")
+				return
+			}
+			if key == "mulai" {
+				sale.StartsAt = t
+			} else {
+				sale.EndsAt = t
+			}
+		case "durasi":
+			duration, err = time.ParseDuration(val)
+			if err != nil || duration <= 0 {
+				b.sendMessage(message.Chat.ID, "❌ Format durasi salah! Contoh: 90m, 3h, 24h")
+				return
+			}
+		case "produk", "kuota":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Nilai %s harus berupa angka!", key))
+				return
+			}
+			if key == "kuota" {
+				sale.QuantityCap = n
+				continue
+			}
+			product, err = b.db.GetProduct(n)
+			if err != nil || product == nil {
+				b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Produk dengan ID %d tidak ditemukan!", n))
+				return
+			}
+			sale.ProductID = &n
+		default:
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Opsi tidak dikenal: %s\n\n%s", opt, usage))
+			return
+		}
+	}
+
+	if duration > 0 {
+		sale.EndsAt = sale.StartsAt.Add(duration)
+	}
+	if sale.EndsAt.IsZero() {
+		b.sendMessage(message.Chat.ID, "❌ Tentukan waktu berakhir dengan sampai= atau durasi=!\n\n"+usage)
+		return
+	}
+	if !sale.EndsAt.After(sale.StartsAt) {
+		b.sendMessage(message.Chat.ID, "❌ Waktu berakhir harus setelah waktu mulai!")
+		return
+	}
+	if sale.SalePrice > 0 {
+		if product == nil {
+			b.sendMessage(message.Chat.ID, "❌ Harga tetap hanya bisa untuk satu produk, tambahkan produk=ID!")
+			return
+		}
+		if sale.SalePrice >= product.Price {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Harga sale harus di bawah harga normal (%s)!",
+				models.FormatPrice(product.Price, b.config.CurrencySymbol)))
+			return
+		}
+	}
+
+	if err := b.db.CreateFlashSale(sale); err != nil {
+		logrus.Errorf("Failed to create flash sale %s: %v", sale.Name, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal membuat flash sale!")
+		return
+	}
+
+	logrus.Infof("Admin %d created flash sale %d (%s)", message.From.ID, sale.ID, sale.Name)
+	b.sendMessage(message.Chat.ID, "✅ *Flash sale berhasil dibuat!*\n\n"+b.formatFlashSaleDetail(sale))
+}
+
+// processFlashSaleCommand handles /flashsale ID [on|off]
+func (b *Bot) processFlashSaleCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		b.sendMessage(message.Chat.ID, "❌ Format salah!\n\nGunakan:\n/flashsale [ID] - Detail flash sale\n/flashsale [ID] on|off - Aktifkan/nonaktifkan flash sale")
+		return
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		b.sendMessage(message.Chat.ID, "❌ ID flash sale harus berupa angka!")
+		return
+	}
+
+	if len(args) > 1 {
+		active := strings.EqualFold(args[1], "on")
+		if !active && !strings.EqualFold(args[1], "off") {
+			b.sendMessage(message.Chat.ID, "❌ Gunakan on atau off.")
+			return
+		}
+
+		found, err := b.db.SetFlashSaleActive(id, active)
+		if err != nil {
+			logrus.Errorf("Failed to update flash sale %d: %v", id, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal memperbarui flash sale!")
+			return
+		}
+		if !found {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Flash sale #%d tidak ditemukan!", id))
+			return
+		}
+
+		status := "dinonaktifkan"
+		if active {
+			status = "diaktifkan"
+		}
+		logrus.Infof("Admin %d set flash sale %d active=%v", message.From.ID, id, active)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Flash sale #%d %s.", id, status))
+		return
+	}
+
+	sale, err := b.db.GetFlashSale(id)
+	if err != nil || sale == nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Flash sale #%d tidak ditemukan!", id))
+		return
+	}
+	b.sendMessage(message.Chat.ID, "⚡ *DETAIL FLASH SALE*\n\n"+b.formatFlashSaleDetail(sale))
+}
+
+// flashSaleStatus returns the status icon and label of a flash sale at time t
+func flashSaleStatus(sale *models.FlashSale, t time.Time) (string, string) {
+	switch {
+	case !sale.IsActive:
+		return "⛔", "Nonaktif"
+	case sale.IsRunning(t):
+		return "⚡", "Berjalan"
+	case t.Before(sale.StartsAt):
+		return "⏳", "Terjadwal"
+	default:
+		return "⏰", "Selesai"
+	}
+}
+
+// formatFlashSaleDetail describes a flash sale's scope, schedule and sales
+func (b *Bot) formatFlashSaleDetail(sale *models.FlashSale) string {
+	var text strings.Builder
+
+	icon, status := flashSaleStatus(sale, time.Now())
+	text.WriteString(fmt.Sprintf("🆔 ID: %d\n", sale.ID))
+	text.WriteString(fmt.Sprintf("🏷️ Nama: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, sale.Name)))
+	text.WriteString(fmt.Sprintf("📊 Status: %s %s\n", icon, status))
+	if sale.DiscountPercent > 0 {
+		text.WriteString(fmt.Sprintf("💸 Diskon: %s\n", sale.FormatValue(b.config.CurrencySymbol)))
+	} else {
+		text.WriteString(fmt.Sprintf("💸 Harga sale: %s\n", sale.FormatValue(b.config.CurrencySymbol)))
+	}
+
+	scope := "Semua produk"
+	if sale.ProductID != nil {
+		scope = fmt.Sprintf("Produk ID %d", *sale.ProductID)
+	}
+	if sale.Category != nil {
+		scope = fmt.Sprintf("Kategori %s", *sale.Category)
+		if sale.ProductID != nil {
+			scope = fmt.Sprintf("Produk ID %d (kategori %s)", *sale.ProductID, *sale.Category)
+		}
+	}
+	text.WriteString(fmt.Sprintf("📦 Berlaku untuk: %s\n", scope))
+	text.WriteString(fmt.Sprintf("📅 Mulai: %s\n", sale.StartsAt.Format("02/01/2006 15:04")))
+	text.WriteString(fmt.Sprintf("📅 Berakhir: %s\n", sale.EndsAt.Format("02/01/2006 15:04")))
+
+	quota := "tanpa batas"
+	if sale.QuantityCap > 0 {
+		quota = strconv.Itoa(sale.QuantityCap)
+	}
+	text.WriteString(fmt.Sprintf("🛍️ Terjual: %d / %s\n", sale.SoldCount, quota))
+
+	switch {
+	case !sale.Announce:
+		text.WriteString("📢 Pengumuman: tidak")
+	case sale.AnnouncedAt != nil:
+		text.WriteString(fmt.Sprintf("📢 Pengumuman: terkirim %s", sale.AnnouncedAt.Local().Format("02/01/2006 15:04")))
+	default:
+		text.WriteString("📢 Pengumuman: otomatis saat sale dimulai")
+	}
+
+	return text.String()
+}
+
+// buildFlashSaleListText lists flash sales with their status for the admin report
+func (b *Bot) buildFlashSaleListText() (string, error) {
+	sales, err := b.db.GetFlashSales(false)
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	text.WriteString("⚡ *FLASH SALE*\n\n")
+	if len(sales) == 0 {
+		text.WriteString("Belum ada flash sale.\n\n")
+	}
+
+	now := time.Now()
+	for _, sale := range sales {
+		icon, _ := flashSaleStatus(&sale, now)
+		quota := "∞"
+		if sale.QuantityCap > 0 {
+			quota = strconv.Itoa(sale.QuantityCap)
+		}
+		text.WriteString(fmt.Sprintf("%s #%d %s - %s\n", icon, sale.ID,
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, sale.Name), sale.FormatValue(b.config.CurrencySymbol)))
+		text.WriteString(fmt.Sprintf("   %s - %s • Terjual %d/%s\n",
+			sale.StartsAt.Format("02/01 15:04"), sale.EndsAt.Format("02/01 15:04"), sale.SoldCount, quota))
+	}
+
+	text.WriteString("\n💡 /addflashsale untuk membuat flash sale\n💡 /flashsale [ID] untuk detail, /flashsale [ID] off untuk menghentikan")
+
+	return text.String(), nil
+}
+
+// handleFlashSalesCommand handles /flashsales
+func (b *Bot) handleFlashSalesCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	text, err := b.buildFlashSaleListText()
+	if err != nil {
+		logrus.Errorf("Failed to get flash sales: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat flash sale!")
+		return
+	}
+	b.sendMessage(message.Chat.ID, text)
+}
+
+// handleAdminFlashSales shows the flash sale list from the admin panel
+func (b *Bot) handleAdminFlashSales(callback *tgbotapi.CallbackQuery) {
+	text, err := b.buildFlashSaleListText()
+	if err != nil {
+		logrus.Errorf("Failed to get flash sales: %v", err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat flash sale"))
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔄 Refresh", "admin:flashsales"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
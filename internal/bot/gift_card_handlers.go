@@ -0,0 +1,339 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// isGiftCardCode returns true if a code looks like an issued gift card code rather than a coupon
+func isGiftCardCode(code string) bool {
+	return strings.HasPrefix(strings.ToUpper(code), "GC-")
+}
+
+// giftCardStateLabel returns the status icon and label of a gift card state
+func (b *Bot) giftCardStateLabel(lang string, state models.GiftCardState) (string, string) {
+	switch state {
+	case models.GiftCardActive:
+		return "✅", b.t(lang, "gift_card.state.active")
+	case models.GiftCardPending:
+		return "⏳", b.t(lang, "gift_card.state.pending")
+	case models.GiftCardUsed:
+		return "☑️", b.t(lang, "gift_card.state.used")
+	case models.GiftCardExpired:
+		return "⌛", b.t(lang, "gift_card.state.expired")
+	default:
+		return "⛔", b.t(lang, "gift_card.state.voided")
+	}
+}
+
+// formatGiftCardValidity returns how long a gift card product stays valid after purchase
+func (b *Bot) formatGiftCardValidity(lang string, days int) string {
+	if days <= 0 {
+		return b.t(lang, "gift_card.validity_unlimited")
+	}
+	return b.tn(lang, "gift_card.validity_days", days, days)
+}
+
+// writeGiftCardTerms adds the value and validity of a gift card product to its detail page
+func (b *Bot) writeGiftCardTerms(text *strings.Builder, lang string, product *models.Product) {
+	text.WriteString(b.t(lang, "gift_card.value", b.formatPrice(lang, product.GiftCardValue)) + "\n")
+	text.WriteString(b.t(lang, "gift_card.validity", b.formatGiftCardValidity(lang, product.GiftCardDays)) + "\n")
+	text.WriteString(b.t(lang, "gift_card.terms_hint") + "\n")
+}
+
+// giftCardDeliveryNote explains how to use the gift cards delivered with an order, as HTML for the
+// payment success template. Empty if the order has no gift cards.
+func (b *Bot) giftCardDeliveryNote(lang, orderID string) string {
+	cards, err := b.db.GetGiftCardsForOrder(orderID)
+	if err != nil {
+		logrus.Errorf("Failed to get gift cards for order %s: %v", orderID, err)
+		return ""
+	}
+	if len(cards) == 0 {
+		return ""
+	}
+
+	var text strings.Builder
+	text.WriteString(b.t(lang, "gift_card.delivery_title") + "\n")
+	for _, card := range cards {
+		line := b.t(lang, "gift_card.delivery_line", escapeHTML(card.Code), escapeHTML(b.formatPrice(lang, card.Value)))
+		if card.ExpiresAt != nil {
+			line += b.t(lang, "gift_card.delivery_expiry", b.i18n.FormatDate(lang, *card.ExpiresAt))
+		}
+		text.WriteString(line + "\n")
+	}
+	text.WriteString(b.t(lang, "gift_card.delivery_hint") + "\n\n")
+	return text.String()
+}
+
+// handleRedeemCommand handles /redeem KODE [jumlah]
+func (b *Bot) handleRedeemCommand(message *tgbotapi.Message) {
+	lang := b.userLanguage(message.From.ID)
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 || len(args) > 2 {
+		balance, _ := b.db.GetBalance(message.From.ID)
+		b.sendMessage(message.Chat.ID, b.t(lang, "gift_card.redeem_usage", b.formatPrice(lang, balance)))
+		return
+	}
+
+	amount := 0
+	if len(args) == 2 {
+		var err error
+		amount, err = strconv.Atoi(args[1])
+		if err != nil || amount <= 0 {
+			b.sendMessage(message.Chat.ID, b.t(lang, "gift_card.invalid_amount"))
+			return
+		}
+	}
+
+	card, ok := b.redeemGiftCard(lang, message.Chat.ID, message.From.ID, args[0], amount)
+	if !ok {
+		return
+	}
+
+	balance, _ := b.db.GetBalance(message.From.ID)
+	text := b.t(lang, "gift_card.redeemed", b.formatPrice(lang, balance))
+	if card.Balance > 0 {
+		text += "\n" + b.t(lang, "gift_card.remaining", b.formatPrice(lang, card.Balance))
+	}
+	text += "\n\n" + b.t(lang, "gift_card.balance_hint")
+	b.sendMessage(message.Chat.ID, text)
+}
+
+// redeemGiftCard moves value from a gift card to the user's balance, replying with the reason when it fails.
+// amount 0 redeems everything left on the card.
+func (b *Bot) redeemGiftCard(lang string, chatID int64, userID int64, code string, amount int) (*models.GiftCard, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	card, err := b.db.RedeemGiftCard(code, userID, amount)
+	if err == nil {
+		logrus.Infof("User %d redeemed gift card %d (%d left)", userID, card.ID, card.Balance)
+		b.db.LogUserInteraction(userID, "gift_card_redeemed", card.Code)
+		return card, true
+	}
+
+	escaped := tgbotapi.EscapeText(tgbotapi.ModeMarkdown, code)
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		b.sendMessage(chatID, b.t(lang, "gift_card.not_found", escaped))
+	case strings.Contains(err.Error(), "not redeemable"):
+		_, label := b.giftCardStateLabel(lang, card.State(time.Now()))
+		b.sendMessage(chatID, b.t(lang, "gift_card.not_redeemable", escaped, strings.ToLower(label)))
+	case strings.Contains(err.Error(), "exceeds balance"):
+		b.sendMessage(chatID, b.t(lang, "gift_card.exceeds_balance", b.formatPrice(lang, card.Balance)))
+	default:
+		logrus.Errorf("Failed to redeem gift card %s for user %d: %v", code, userID, err)
+		b.sendMessage(chatID, b.t(lang, "gift_card.redeem_failed"))
+	}
+	return nil, false
+}
+
+// Admin gift card management
+
+// processAddGiftCardCommand handles /addgiftcard <nama> | <deskripsi> | <nilai> | <harga> | <masa berlaku>
+func (b *Bot) processAddGiftCardCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	usage := `❌ Format salah!
+
+Gunakan:
+/addgiftcard <nama> | <deskripsi> | <nilai> | <harga> | <masa berlaku hari>
+
+Nilai adalah saldo yang didapat penerima, harga adalah yang dibayar pembeli. Masa berlaku 0 = tanpa batas.
+
+Contoh:
+/addgiftcard Gift Card 50K | Hadiah saldo belanja 50.000 | 50000 | 50000 | 365
+/addgiftcard Gift Card 100K | Bonus 5rb untuk pembeli | 105000 | 100000 | 0`
+
+	parts := strings.Split(message.CommandArguments(), "|")
+	if len(parts) != 5 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	value, err := strconv.Atoi(parts[2])
+	if err != nil || value <= 0 {
+		b.sendMessage(message.Chat.ID, "❌ Nilai gift card harus berupa angka lebih dari 0!")
+		return
+	}
+	price, err := strconv.Atoi(parts[3])
+	if err != nil || price <= 0 {
+		b.sendMessage(message.Chat.ID, "❌ Harga harus berupa angka lebih dari 0!")
+		return
+	}
+	days, err := strconv.Atoi(parts[4])
+	if err != nil || days < 0 {
+		b.sendMessage(message.Chat.ID, "❌ Masa berlaku harus berupa angka hari, 0 untuk tanpa batas!")
+		return
+	}
+
+	product := &models.Product{
+		Name:          parts[0],
+		Description:   parts[1],
+		Price:         price,
+		GiftCardValue: value,
+		GiftCardDays:  days,
+	}
+	if product.Name == "" || product.Description == "" {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	if err := b.db.CreateGiftCardProduct(product); err != nil {
+		logrus.Errorf("Failed to create gift card product %s: %v", product.Name, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal membuat produk gift card!")
+		return
+	}
+
+	logrus.Infof("Admin %d created gift card product %d (%s)", message.From.ID, product.ID, product.Name)
+	b.sendMessage(message.Chat.ID, fmt.Sprintf(`✅ *Produk gift card berhasil dibuat!*
+
+🆔 ID: %d
+📦 Nama: *%s*
+💰 Harga: %s
+🎁 Nilai: %s
+⏳ Masa berlaku: %s
+
+Kode unik dibuat otomatis untuk setiap pembelian, tanpa perlu menambah stok.`,
+		product.ID, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name),
+		models.FormatPrice(product.Price, b.config.CurrencySymbol),
+		models.FormatPrice(product.GiftCardValue, b.config.CurrencySymbol),
+		b.formatGiftCardValidity(b.i18n.Default(), product.GiftCardDays)))
+}
+
+// formatGiftCard describes an issued gift card for admins
+func (b *Bot) formatGiftCard(card *models.GiftCard) string {
+	lang := b.i18n.Default()
+	icon, label := b.giftCardStateLabel(lang, card.State(time.Now()))
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("%s #%d `%s` • %s\n", icon, card.ID, card.Code, label))
+	text.WriteString(b.t(lang, "gift_card.admin_balance", b.formatPrice(lang, card.Balance), b.formatPrice(lang, card.Value)))
+	if card.RedemptionCount > 0 {
+		text.WriteString(b.t(lang, "gift_card.admin_redemptions", card.RedemptionCount))
+	}
+	text.WriteString("\n")
+	if card.BuyerID != nil {
+		text.WriteString(b.t(lang, "gift_card.admin_buyer", *card.BuyerID))
+		if card.OrderID != nil {
+			text.WriteString(fmt.Sprintf(" • Order #%s", (*card.OrderID)[:8]))
+		}
+		text.WriteString("\n")
+	}
+	if card.ExpiresAt != nil {
+		text.WriteString(b.t(lang, "gift_card.admin_expiry", b.i18n.FormatDateTime(lang, *card.ExpiresAt)) + "\n")
+	}
+	return text.String()
+}
+
+// handleGiftCardsCommand handles /giftcards
+func (b *Bot) handleGiftCardsCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	cards, err := b.db.GetGiftCards(20)
+	if err != nil {
+		logrus.Errorf("Failed to get gift cards: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat gift card!")
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString("🎁 *GIFT CARD TERBARU*\n\n")
+	if len(cards) == 0 {
+		text.WriteString("Belum ada gift card yang diterbitkan.\n\n")
+	}
+	for i := range cards {
+		text.WriteString(b.formatGiftCard(&cards[i]))
+		text.WriteString("\n")
+	}
+	text.WriteString("💡 /addgiftcard untuk membuat produk gift card\n💡 /giftcard KODE untuk detail, /giftcard KODE void untuk membatalkan")
+
+	b.sendMessage(message.Chat.ID, text.String())
+}
+
+// processGiftCardCommand handles /giftcard KODE|ID [void]
+func (b *Bot) processGiftCardCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 || len(args) > 2 {
+		b.sendMessage(message.Chat.ID, "❌ Format salah!\n\nGunakan:\n/giftcard [KODE|ID] - Detail gift card\n/giftcard [KODE|ID] void - Batalkan sisa nilai gift card")
+		return
+	}
+
+	var card *models.GiftCard
+	var err error
+	if id, convErr := strconv.Atoi(args[0]); convErr == nil {
+		card, err = b.db.GetGiftCard(id)
+	} else {
+		card, err = b.db.GetGiftCardByCode(args[0])
+	}
+	if err != nil {
+		logrus.Errorf("Failed to get gift card %s: %v", args[0], err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat gift card!")
+		return
+	}
+	if card == nil {
+		b.sendMessage(message.Chat.ID, "❌ Gift card tidak ditemukan!")
+		return
+	}
+
+	if len(args) == 2 {
+		if !strings.EqualFold(args[1], "void") {
+			b.sendMessage(message.Chat.ID, "❌ Gunakan void untuk membatalkan gift card.")
+			return
+		}
+
+		voided, err := b.db.VoidGiftCard(card.ID)
+		if err != nil {
+			logrus.Errorf("Failed to void gift card %d: %v", card.ID, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal membatalkan gift card!")
+			return
+		}
+		if !voided {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Gift card #%d sudah dibatalkan sebelumnya.", card.ID))
+			return
+		}
+
+		logrus.Infof("Admin %d voided gift card %d with %d left", message.From.ID, card.ID, card.Balance)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Gift card #%d dibatalkan. Sisa nilai %s tidak bisa ditukar lagi; saldo yang sudah ditukar tidak berubah.",
+			card.ID, models.FormatPrice(card.Balance, b.config.CurrencySymbol)))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString("🎁 *DETAIL GIFT CARD*\n\n")
+	text.WriteString(b.formatGiftCard(card))
+
+	redemptions, err := b.db.GetGiftCardRedemptions(card.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get redemptions of gift card %d: %v", card.ID, err)
+	}
+	if len(redemptions) > 0 {
+		text.WriteString("\n📜 *Riwayat penukaran:*\n")
+		for _, r := range redemptions {
+			text.WriteString(fmt.Sprintf("• %s • user %d • %s\n", r.CreatedAt.Format("02/01/2006 15:04"), r.UserID,
+				models.FormatPrice(r.Amount, b.config.CurrencySymbol)))
+		}
+	}
+
+	b.sendMessage(message.Chat.ID, text.String())
+}
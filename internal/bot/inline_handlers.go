@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"telegram-premium-store/internal/database"
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// inlineResultsPerPage is the number of products returned per inline query page
+const inlineResultsPerPage = 20
+
+// productLink returns the /start deep link that opens a product in the bot
+func (b *Bot) productLink(productID int) string {
+	return b.startLink(fmt.Sprintf("p_%d", productID))
+}
+
+// handleProductStart handles /start p_<id> from a shared product and shows the product
+func (b *Bot) handleProductStart(message *tgbotapi.Message, payload string) {
+	lang := b.userLanguage(message.From.ID)
+
+	productID, err := strconv.Atoi(payload)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, b.t(lang, "product.not_found"))
+		return
+	}
+	product, err := b.db.GetProduct(productID)
+	if err != nil {
+		logrus.Errorf("Failed to get product %d: %v", productID, err)
+		b.sendMessage(message.Chat.ID, b.t(lang, "catalog.products_failed"))
+		return
+	}
+	if product == nil {
+		b.sendMessage(message.Chat.ID, b.t(lang, "product.not_found"))
+		return
+	}
+
+	text, keyboard := b.buildProductDetail(message.From.ID, lang, product)
+	b.sendProductPhoto(message.Chat.ID, lang, product)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
+
+// handleInlineQuery answers "@bot spotify" with matching products that can be shared into any chat.
+// Shared results show retail prices, since they are seen by people outside the sender's customer group.
+func (b *Bot) handleInlineQuery(query *tgbotapi.InlineQuery) {
+	lang := b.userLanguage(query.From.ID)
+	offset, _ := strconv.Atoi(query.Offset)
+
+	var products []models.Product
+	var err error
+	if len(database.NormalizeSearchQuery(query.Query)) == 0 {
+		products, err = b.db.GetProducts("", inlineResultsPerPage, offset)
+	} else {
+		var result *models.SearchResult
+		result, err = b.db.SearchProducts(query.Query, inlineResultsPerPage, offset)
+		if result != nil {
+			products = result.Products
+		}
+	}
+	if err != nil {
+		logrus.Errorf("Failed to get products for inline query %q: %v", query.Query, err)
+		return
+	}
+
+	// The user ID 0 belongs to no customer group, so it gets retail prices
+	b.applyPricing(0, products)
+	b.translateProducts(lang, products)
+
+	results := make([]interface{}, 0, len(products))
+	for i := range products {
+		results = append(results, b.inlineProductResult(lang, &products[i]))
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID:     query.ID,
+		Results:           results,
+		CacheTime:         30,
+		IsPersonal:        true, // Results are in the sender's language
+		SwitchPMText:      b.t(lang, "inline.open_store"),
+		SwitchPMParameter: "inline",
+	}
+	if len(products) == inlineResultsPerPage {
+		answer.NextOffset = strconv.Itoa(offset + inlineResultsPerPage)
+	}
+
+	if _, err := b.api.Request(answer); err != nil {
+		logrus.Errorf("Failed to answer inline query %q: %v", query.Query, err)
+	}
+}
+
+// inlineProductStatus returns a short stock status of a product for inline results
+func (b *Bot) inlineProductStatus(lang string, product *models.Product) string {
+	if product.IsGiftCard() {
+		return b.t(lang, "inline.available")
+	}
+
+	available, err := b.db.GetAvailableAccountCount(product.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get available accounts for product %d: %v", product.ID, err)
+	}
+	switch {
+	case available > 0:
+		return b.t(lang, "inline.in_stock", available)
+	case b.preorderSlotsLeft(product) > 0:
+		return b.t(lang, "inline.preorder")
+	default:
+		return b.t(lang, "inline.sold_out")
+	}
+}
+
+// inlineProductResult returns the inline result of a product: a message with its price and status
+// and a button that opens the product in the bot
+func (b *Bot) inlineProductResult(lang string, product *models.Product) tgbotapi.InlineQueryResultArticle {
+	price := b.formatSalePrice(lang, product.Price, product.OriginalPrice)
+	status := b.inlineProductStatus(lang, product)
+
+	desc := product.Description
+	if len(desc) > 200 {
+		desc = desc[:200] + "..."
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📱 *%s*\n\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+	text.WriteString(b.t(lang, "product.price", price) + "\n")
+	if product.FlashSale != nil {
+		text.WriteString(b.formatFlashSaleNote(lang, product.FlashSale) + "\n")
+	}
+	text.WriteString(status + "\n\n")
+	text.WriteString(fmt.Sprintf("📝 %s", desc))
+
+	result := tgbotapi.NewInlineQueryResultArticleMarkdown(fmt.Sprintf("p%d", product.ID), product.Name, text.String())
+	result.Description = fmt.Sprintf("%s • %s", price, status)
+	// Thumbnails must be URLs; uploaded photos only have Telegram file IDs
+	if product.ImageURL != nil && strings.HasPrefix(*product.ImageURL, "http") {
+		result.ThumbURL = *product.ImageURL
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL(b.t(lang, "inline.buy"), b.productLink(product.ID)),
+		),
+	)
+	result.ReplyMarkup = &keyboard
+	return result
+}
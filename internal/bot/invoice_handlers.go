@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/invoice"
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// isInvoiceable reports whether an order has been paid and can have an invoice
+func isInvoiceable(order *models.Order) bool {
+	return order.PaymentStatus == models.PaymentStatusPaid || order.PaymentStatus == models.PaymentStatusAwaitingStock
+}
+
+// buildOrderInvoice issues the order's invoice number and collects what is printed on it
+func (b *Bot) buildOrderInvoice(order *models.Order) (*invoice.Invoice, error) {
+	record, err := b.db.IssueInvoice(order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue invoice: %w", err)
+	}
+
+	// The invoice is the buyer's document, so it is printed in their language even when an admin downloads it
+	lang := b.userLanguage(order.UserID)
+	inv := &invoice.Invoice{
+		Language:      lang,
+		Number:        record.Number,
+		IssuedAt:      record.CreatedAt,
+		OrderID:       order.ID,
+		PaidAt:        record.CreatedAt,
+		PaymentMethod: b.paymentMethodName(lang, order.PaymentMethod),
+		Customer:      b.t(lang, "invoice.customer", order.UserID),
+		CustomerID:    order.UserID,
+		Currency:      b.config.CurrencySymbol,
+		Store: invoice.Store{
+			Name:        b.config.StoreName,
+			Description: b.config.StoreDescription,
+			Email:       b.config.AdminEmail,
+			Phone:       b.config.SupportPhone,
+			Telegram:    b.config.AdminUsername,
+		},
+	}
+	if order.CompletedAt != nil {
+		inv.PaidAt = *order.CompletedAt
+	}
+	if loc, err := time.LoadLocation(b.config.Timezone); err == nil {
+		inv.IssuedAt = inv.IssuedAt.In(loc)
+		inv.PaidAt = inv.PaidAt.In(loc)
+	}
+
+	if buyer, err := b.db.GetUser(order.UserID); err == nil && buyer != nil {
+		var name []string
+		if buyer.FirstName != nil && *buyer.FirstName != "" {
+			name = append(name, *buyer.FirstName)
+		}
+		if buyer.LastName != nil && *buyer.LastName != "" {
+			name = append(name, *buyer.LastName)
+		}
+		if buyer.Username != nil && *buyer.Username != "" {
+			name = append(name, "(@"+*buyer.Username+")")
+		}
+		if len(name) > 0 {
+			inv.Customer = strings.Join(name, " ")
+		}
+	}
+
+	b.addInvoiceLines(inv, order)
+	return inv, nil
+}
+
+// addInvoiceLines adds the order's items, discounts, fees and payments to its invoice, in the invoice's language
+func (b *Bot) addInvoiceLines(inv *invoice.Invoice, order *models.Order) {
+	lang := inv.Language
+	for _, item := range order.Items {
+		inv.Items = append(inv.Items, invoice.Item{
+			Name:      item.ProductName,
+			Quantity:  item.Quantity,
+			UnitPrice: item.Price,
+		})
+	}
+
+	if order.DiscountAmount > 0 {
+		label := b.t(lang, "invoice.coupon_discount")
+		if order.CouponCode != nil {
+			label = b.t(lang, "invoice.coupon_discount_code", *order.CouponCode)
+		}
+		inv.Adjustments = append(inv.Adjustments, invoice.Adjustment{Label: label, Amount: -order.DiscountAmount})
+	}
+	if order.PointsDiscount > 0 {
+		inv.Adjustments = append(inv.Adjustments, invoice.Adjustment{
+			Label:  b.tn(lang, "invoice.points_discount", order.PointsUsed, order.PointsUsed),
+			Amount: -order.PointsDiscount,
+		})
+	}
+
+	// Whatever the buyer paid beyond the discounted subtotal is a fee
+	if fee := order.TotalAmount + order.BalanceUsed - inv.Total(); fee > 0 {
+		inv.Adjustments = append(inv.Adjustments, invoice.Adjustment{Label: b.t(lang, "invoice.fee"), Amount: fee})
+	} else if fee < 0 {
+		inv.Adjustments = append(inv.Adjustments, invoice.Adjustment{Label: b.t(lang, "invoice.other_discount"), Amount: fee})
+	}
+
+	if order.BalanceUsed > 0 {
+		inv.Payments = append(inv.Payments, invoice.Payment{Method: b.paymentMethodName(lang, "balance"), Amount: order.BalanceUsed})
+	}
+	if order.TotalAmount > 0 || len(inv.Payments) == 0 {
+		method := inv.PaymentMethod
+		if order.PaymentMethod == "balance" {
+			method = b.paymentMethodName(lang, "qris")
+		}
+		inv.Payments = append(inv.Payments, invoice.Payment{Method: method, Amount: order.TotalAmount})
+	}
+}
+
+// sendOrderInvoice renders the order's invoice and sends it to chatID as a PDF document
+func (b *Bot) sendOrderInvoice(chatID int64, order *models.Order) error {
+	inv, err := b.buildOrderInvoice(order)
+	if err != nil {
+		return err
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+		Name:  inv.FileName(),
+		Bytes: invoice.RenderPDF(inv, b.i18n),
+	})
+	doc.Caption = b.t(inv.Language, "invoice.caption", inv.Number, shortOrderID(order.ID))
+	doc.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := b.send(doc); err != nil {
+		return fmt.Errorf("failed to send invoice: %w", err)
+	}
+	return nil
+}
+
+// deliverOrderInvoice sends the invoice to the buyer once their order has been delivered
+func (b *Bot) deliverOrderInvoice(order *models.Order) {
+	if err := b.sendOrderInvoice(order.UserID, order); err != nil {
+		logrus.Errorf("Failed to send invoice for order %s: %v", order.ID, err)
+	}
+}
+
+// handleInvoiceCallback re-sends the invoice of a paid order from the order history
+func (b *Bot) handleInvoiceCallback(callback *tgbotapi.CallbackQuery, orderID string) {
+	lang := b.userLanguage(callback.From.ID)
+	order, err := b.db.GetOrder(orderID)
+	if err != nil {
+		logrus.Errorf("Failed to get order %s: %v", orderID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.load_failed")))
+		return
+	}
+
+	if order == nil || (order.UserID != callback.From.ID && !b.config.IsAdmin(callback.From.ID)) {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.not_found")))
+		return
+	}
+
+	if !isInvoiceable(order) {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, b.t(lang, "invoice.unpaid")))
+		return
+	}
+
+	if err := b.sendOrderInvoice(callback.Message.Chat.ID, order); err != nil {
+		logrus.Errorf("Failed to send invoice for order %s: %v", orderID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "invoice.failed")))
+		return
+	}
+	b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "invoice.sent")))
+}
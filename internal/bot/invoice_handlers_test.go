@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"reflect"
+	"testing"
+
+	"telegram-premium-store/internal/i18n"
+	"telegram-premium-store/internal/invoice"
+	"telegram-premium-store/internal/models"
+)
+
+func TestAddInvoiceLines(t *testing.T) {
+	catalog, err := i18n.Load("", "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bot{i18n: catalog}
+
+	coupon := "HEMAT10"
+	items := []models.OrderItem{
+		{ProductName: "Netflix 1 Bulan", Quantity: 2, Price: 25000},
+		{ProductName: "Spotify 1 Bulan", Quantity: 1, Price: 15000},
+	}
+
+	tests := []struct {
+		name            string
+		lang            string
+		order           models.Order
+		wantAdjustments []invoice.Adjustment
+		wantPayments    []invoice.Payment
+	}{
+		{
+			name:         "paid in full with QRIS",
+			lang:         "id",
+			order:        models.Order{PaymentMethod: "qris", TotalAmount: 65000, Items: items},
+			wantPayments: []invoice.Payment{{Method: "QRIS", Amount: 65000}},
+		},
+		{
+			name: "coupon and points discounts",
+			lang: "id",
+			order: models.Order{
+				PaymentMethod: "qris", TotalAmount: 53500, Items: items,
+				DiscountAmount: 6500, CouponCode: &coupon, PointsUsed: 50, PointsDiscount: 5000,
+			},
+			wantAdjustments: []invoice.Adjustment{
+				{Label: "Diskon kupon (HEMAT10)", Amount: -6500},
+				{Label: "Tukar 50 poin", Amount: -5000},
+			},
+			wantPayments: []invoice.Payment{{Method: "QRIS", Amount: 53500}},
+		},
+		{
+			name:            "paid more than the discounted subtotal",
+			lang:            "id",
+			order:           models.Order{PaymentMethod: "qris", TotalAmount: 65123, Items: items},
+			wantAdjustments: []invoice.Adjustment{{Label: "Biaya transaksi", Amount: 123}},
+			wantPayments:    []invoice.Payment{{Method: "QRIS", Amount: 65123}},
+		},
+		{
+			name:            "paid less than the discounted subtotal",
+			lang:            "id",
+			order:           models.Order{PaymentMethod: "qris", TotalAmount: 64000, Items: items},
+			wantAdjustments: []invoice.Adjustment{{Label: "Potongan lainnya", Amount: -1000}},
+			wantPayments:    []invoice.Payment{{Method: "QRIS", Amount: 64000}},
+		},
+		{
+			name:         "partly paid with balance",
+			lang:         "id",
+			order:        models.Order{PaymentMethod: "qris", TotalAmount: 45000, BalanceUsed: 20000, Items: items},
+			wantPayments: []invoice.Payment{{Method: "Saldo", Amount: 20000}, {Method: "QRIS", Amount: 45000}},
+		},
+		{
+			name:         "paid with balance only",
+			lang:         "id",
+			order:        models.Order{PaymentMethod: "balance", BalanceUsed: 65000, Items: items},
+			wantPayments: []invoice.Payment{{Method: "Saldo", Amount: 65000}},
+		},
+		{
+			name: "buyer's language",
+			lang: "en",
+			order: models.Order{
+				PaymentMethod: "qris", TotalAmount: 43600, BalanceUsed: 10000, Items: items,
+				DiscountAmount: 6500, PointsUsed: 1, PointsDiscount: 5000, // 100 more than the discounted subtotal
+			},
+			wantAdjustments: []invoice.Adjustment{
+				{Label: "Coupon discount", Amount: -6500},
+				{Label: "1 point redeemed", Amount: -5000},
+				{Label: "Transaction fee", Amount: 100},
+			},
+			wantPayments: []invoice.Payment{{Method: "Balance", Amount: 10000}, {Method: "QRIS", Amount: 43600}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &invoice.Invoice{
+				Language:      tt.lang,
+				PaymentMethod: b.paymentMethodName(tt.lang, tt.order.PaymentMethod),
+			}
+			b.addInvoiceLines(inv, &tt.order)
+
+			if got := inv.Subtotal(); got != 65000 {
+				t.Errorf("Subtotal() = %d, want 65000", got)
+			}
+			if !reflect.DeepEqual(inv.Adjustments, tt.wantAdjustments) {
+				t.Errorf("adjustments = %+v, want %+v", inv.Adjustments, tt.wantAdjustments)
+			}
+			if !reflect.DeepEqual(inv.Payments, tt.wantPayments) {
+				t.Errorf("payments = %+v, want %+v", inv.Payments, tt.wantPayments)
+			}
+
+			// The printed total always matches what the buyer paid
+			paid := 0
+			for _, payment := range inv.Payments {
+				paid += payment.Amount
+			}
+			if paid != inv.Total() {
+				t.Errorf("payments add up to %d, total is %d", paid, inv.Total())
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// userLanguage returns the language the user picked, or the default language
+func (b *Bot) userLanguage(userID int64) string {
+	lang, err := b.db.GetUserLanguage(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get language of user %d: %v", userID, err)
+	}
+	if lang = b.i18n.Match(lang); lang == "" {
+		return b.i18n.Default()
+	}
+	return lang
+}
+
+// t returns the message for key in lang, formatted with args
+func (b *Bot) t(lang, key string, args ...interface{}) string {
+	return b.i18n.T(lang, key, args...)
+}
+
+// tn returns the message for key in lang about n things, in its singular form when n is 1
+func (b *Bot) tn(lang, key string, n int, args ...interface{}) string {
+	return b.i18n.TN(lang, key, n, args...)
+}
+
+// formatPrice formats an amount with the store currency in the language's number format
+func (b *Bot) formatPrice(lang string, amount int) string {
+	return b.i18n.FormatPrice(lang, amount, b.config.CurrencySymbol)
+}
+
+// categoryLabel returns the category name in lang, falling back to its stored display name
+func (b *Bot) categoryLabel(lang string, category models.ProductCategory) string {
+	if key := "category." + category.Name; b.i18n.Has(lang, key) {
+		return b.t(lang, key)
+	}
+	return category.DisplayName
+}
+
+// translateProducts replaces product names and descriptions with their translations in lang, where there is one
+func (b *Bot) translateProducts(lang string, products []models.Product) {
+	if lang == b.i18n.Default() || len(products) == 0 {
+		return
+	}
+
+	ids := make([]int, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+	translations, err := b.db.GetProductTranslationMap(lang, ids)
+	if err != nil {
+		logrus.Errorf("Failed to get %s product translations: %v", lang, err)
+		return
+	}
+
+	for i := range products {
+		if t, ok := translations[products[i].ID]; ok {
+			products[i].Name = t.Name
+			if t.Description != "" {
+				products[i].Description = t.Description
+			}
+		}
+	}
+}
+
+// translateCartItems replaces cart item product names with their translations in lang
+func (b *Bot) translateCartItems(lang string, items []models.CartItem) {
+	if lang == b.i18n.Default() || len(items) == 0 {
+		return
+	}
+
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	translations, err := b.db.GetProductTranslationMap(lang, ids)
+	if err != nil {
+		logrus.Errorf("Failed to get %s product translations: %v", lang, err)
+		return
+	}
+
+	for i := range items {
+		if t, ok := translations[items[i].ProductID]; ok {
+			items[i].ProductName = t.Name
+		}
+	}
+}
+
+// languageKeyboard returns one button per loaded language, marking the current one
+func (b *Bot) languageKeyboard(current string) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, lang := range b.i18n.Languages() {
+		label := b.i18n.Name(lang)
+		if lang == current {
+			label = "✅ " + label
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, "lang:"+lang),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(current, "button.main_menu"), "start"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleLanguageCommand handles /bahasa and /language
+func (b *Bot) handleLanguageCommand(message *tgbotapi.Message) {
+	lang := b.userLanguage(message.From.ID)
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.t(lang, "language.title", b.i18n.Name(lang)))
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = b.languageKeyboard(lang)
+	b.send(msg)
+}
+
+// handleLanguageCallback shows the language picker ("lang:menu") or switches to the chosen language
+func (b *Bot) handleLanguageCallback(callback *tgbotapi.CallbackQuery, choice string) {
+	userID := callback.From.ID
+
+	if choice != "menu" {
+		lang := b.i18n.Match(choice)
+		if lang == "" {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(b.userLanguage(userID), "language.failed")))
+			return
+		}
+		if _, err := b.db.SetUserLanguage(userID, lang); err != nil {
+			logrus.Errorf("Failed to set language of user %d: %v", userID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "language.failed")))
+			return
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "language.changed", b.i18n.Name(lang))))
+		b.handleStartCallback(callback)
+		return
+	}
+
+	lang := b.userLanguage(userID)
+	keyboard := b.languageKeyboard(lang)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		b.t(lang, "language.title", b.i18n.Name(lang)))
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// processTranslateCommand handles /translate PRODUCT_ID [BAHASA Nama | Deskripsi | hapus]
+func (b *Bot) processTranslateCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	usage := fmt.Sprintf(`❌ Format salah!
+
+Gunakan:
+/translate [ID] - Lihat terjemahan produk
+/translate [ID] [BAHASA] [Nama] | [Deskripsi] - Simpan terjemahan
+/translate [ID] [BAHASA] hapus - Hapus terjemahan
+
+Bahasa: %s
+
+Contoh:
+/translate 3 en Spotify Premium 1 Month | Ad-free music for one month`, strings.Join(b.i18n.Languages(), ", "))
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	productID, err := strconv.Atoi(args[0])
+	if err != nil {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+	product, err := b.db.GetProduct(productID)
+	if err != nil || product == nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Produk dengan ID %d tidak ditemukan!", productID))
+		return
+	}
+
+	if len(args) == 1 {
+		b.sendProductTranslations(message.Chat.ID, product)
+		return
+	}
+
+	lang := b.i18n.Match(args[1])
+	if lang == "" {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Bahasa tidak dikenal! Pilih salah satu: %s", strings.Join(b.i18n.Languages(), ", ")))
+		return
+	}
+	if lang == b.i18n.Default() {
+		b.sendMessage(message.Chat.ID, "❌ Nama dan deskripsi produk sudah memakai bahasa default. Ubah produknya langsung untuk bahasa ini.")
+		return
+	}
+
+	if len(args) == 3 && strings.EqualFold(args[2], "hapus") {
+		found, err := b.db.DeleteProductTranslation(productID, lang)
+		if err != nil {
+			logrus.Errorf("Failed to delete %s translation of product %d: %v", lang, productID, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal menghapus terjemahan!")
+			return
+		}
+		if !found {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Produk #%d belum punya terjemahan %s.", productID, lang))
+			return
+		}
+		logrus.Infof("Admin %d deleted %s translation of product %d", message.From.ID, lang, productID)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Terjemahan %s untuk produk #%d dihapus.", lang, productID))
+		return
+	}
+
+	// Everything after the product ID and language is "Nama | Deskripsi"
+	rest := strings.TrimSpace(message.CommandArguments())
+	for _, arg := range args[:2] {
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, arg))
+	}
+	name, description, _ := strings.Cut(rest, "|")
+	translation := &models.ProductTranslation{
+		ProductID:   productID,
+		Language:    lang,
+		Name:        strings.TrimSpace(name),
+		Description: strings.TrimSpace(description),
+	}
+	if translation.Name == "" {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	if err := b.db.SetProductTranslation(translation); err != nil {
+		logrus.Errorf("Failed to save %s translation of product %d: %v", lang, productID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal menyimpan terjemahan!")
+		return
+	}
+
+	logrus.Infof("Admin %d saved %s translation of product %d", message.From.ID, lang, productID)
+	b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Terjemahan %s untuk *%s* disimpan.\n\n📱 %s\n📝 %s",
+		lang,
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name),
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, translation.Name),
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, translation.Description)))
+}
+
+// sendProductTranslations lists a product's translations
+func (b *Bot) sendProductTranslations(chatID int64, product *models.Product) {
+	translations, err := b.db.GetProductTranslations(product.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get translations of product %d: %v", product.ID, err)
+		b.sendMessage(chatID, "❌ Gagal memuat terjemahan!")
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🌐 *TERJEMAHAN PRODUK #%d*\n\n", product.ID))
+	text.WriteString(fmt.Sprintf("*%s* (default): %s\n\n", b.i18n.Default(), tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+	if len(translations) == 0 {
+		text.WriteString("Belum ada terjemahan.\n")
+	}
+	for _, t := range translations {
+		text.WriteString(fmt.Sprintf("*%s*: %s\n", t.Language, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, t.Name)))
+		if t.Description != "" {
+			text.WriteString(fmt.Sprintf("   %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, t.Description)))
+		}
+		text.WriteString("\n")
+	}
+	text.WriteString("💡 /translate [ID] [BAHASA] [Nama] | [Deskripsi]")
+	b.sendMessage(chatID, text.String())
+}
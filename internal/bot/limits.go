@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"telegram-premium-store/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// formatLimitPeriod returns a readable label for the purchase limit period
+func (b *Bot) formatLimitPeriod(lang string, hours int) string {
+	if hours%24 == 0 {
+		return b.tn(lang, "limit.period.days", hours/24, hours/24)
+	}
+	return b.tn(lang, "limit.period.hours", hours, hours)
+}
+
+// checkProductLimit checks the per-user per-product purchase limit for a quantity about to be ordered.
+// Returns an error message in the given language, or an empty string when allowed.
+func (b *Bot) checkProductLimit(lang string, userID int64, product *models.Product, quantity int) string {
+	if b.config.MaxQtyPerProduct <= 0 || b.config.PurchaseLimitPeriodHrs <= 0 {
+		return ""
+	}
+
+	purchased, err := b.db.GetUserPurchasedQuantity(userID, product.ID, b.config.PurchaseLimitPeriodHrs)
+	if err != nil {
+		logrus.Errorf("Failed to get purchased quantity for user %d product %d: %v", userID, product.ID, err)
+		return b.t(lang, "limit.check_failed")
+	}
+
+	if purchased+quantity > b.config.MaxQtyPerProduct {
+		remaining := b.config.MaxQtyPerProduct - purchased
+		if remaining < 0 {
+			remaining = 0
+		}
+		return b.t(lang, "limit.product",
+			product.Name, b.config.MaxQtyPerProduct, b.formatLimitPeriod(lang, b.config.PurchaseLimitPeriodHrs), remaining)
+	}
+
+	return ""
+}
+
+// checkCartLimits validates adding a quantity of a product to the user's cart.
+// Returns an error message in the given language, or an empty string when allowed.
+func (b *Bot) checkCartLimits(lang string, userID int64, product *models.Product, quantity int) string {
+	inCart, err := b.db.GetCartQuantity(userID, product.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get cart quantity for user %d product %d: %v", userID, product.ID, err)
+		return b.t(lang, "limit.cart_check_failed")
+	}
+
+	// A new product takes a cart slot, adding to an existing one does not
+	if b.config.MaxCartItems > 0 && inCart == 0 {
+		count, err := b.db.CountCartItems(userID)
+		if err != nil {
+			logrus.Errorf("Failed to count cart items for user %d: %v", userID, err)
+			return b.t(lang, "limit.cart_check_failed")
+		}
+		if count >= b.config.MaxCartItems {
+			return b.t(lang, "limit.cart_full", count, b.config.MaxCartItems)
+		}
+	}
+
+	if b.config.MaxOrderStock > 0 && inCart+quantity > b.config.MaxOrderStock {
+		return b.t(lang, "limit.cart_stock", b.config.MaxOrderStock)
+	}
+
+	return b.checkProductLimit(lang, userID, product, inCart+quantity)
+}
+
+// checkOrderLimits validates a new order against the pending-order cap, the per-order stock cap
+// and the per-product purchase limits. Returns an error message in the given language, or an empty string when allowed.
+func (b *Bot) checkOrderLimits(lang string, userID int64, items []models.OrderItem) string {
+	if b.config.MaxPendingOrders > 0 {
+		pending, err := b.db.CountPendingOrders(userID)
+		if err != nil {
+			logrus.Errorf("Failed to count pending orders for user %d: %v", userID, err)
+			return b.t(lang, "limit.order_check_failed")
+		}
+		if pending >= b.config.MaxPendingOrders {
+			return b.tn(lang, "limit.pending_orders", pending, pending)
+		}
+	}
+
+	totalQuantity := 0
+	for _, item := range items {
+		totalQuantity += item.Quantity
+	}
+	if b.config.MaxOrderStock > 0 && totalQuantity > b.config.MaxOrderStock {
+		return b.t(lang, "limit.order_stock", b.config.MaxOrderStock, totalQuantity)
+	}
+
+	for _, item := range items {
+		product, err := b.db.GetProduct(item.ProductID)
+		if err != nil || product == nil {
+			return b.t(lang, "product.not_found")
+		}
+		if msg := b.checkProductLimit(lang, userID, product, item.Quantity); msg != "" {
+			return msg
+		}
+	}
+
+	return ""
+}
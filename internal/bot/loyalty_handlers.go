@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// awardLoyaltyPoints credits the points a paid order earned and tells the buyer.
+// Points are earned on the amount actually paid, so coupon discounts and spent points or balance
+// earn nothing; each item's share is weighted by its category multiplier.
+func (b *Bot) awardLoyaltyPoints(order *models.Order) {
+	if b.config.LoyaltySpendPerPoint <= 0 || order.TotalAmount <= 0 {
+		return
+	}
+
+	soldAccounts, err := b.db.GetProductAccountsForOrder(order.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get accounts of order %s for loyalty points: %v", order.ID, err)
+		return
+	}
+
+	subtotal := 0
+	for _, account := range soldAccounts {
+		subtotal += account.SoldPrice
+	}
+	if subtotal <= 0 {
+		return
+	}
+
+	categories := make(map[int]string)
+	points := make(map[int]int)
+	for _, account := range soldAccounts {
+		category, ok := categories[account.ProductID]
+		if !ok {
+			if product, err := b.db.GetProduct(account.ProductID); err == nil && product != nil {
+				category = product.Category
+			}
+			categories[account.ProductID] = category
+		}
+
+		paid := float64(account.SoldPrice) * float64(order.TotalAmount) / float64(subtotal)
+		points[account.ID] = int(paid * b.config.LoyaltyMultiplier(category) / float64(b.config.LoyaltySpendPerPoint))
+	}
+
+	awarded, err := b.db.AwardOrderPoints(order.ID, order.UserID, points, b.config.LoyaltyPointsExpiryDays)
+	if err != nil {
+		logrus.Errorf("Failed to award loyalty points for order %s: %v", order.ID, err)
+		return
+	}
+	if awarded == 0 {
+		return
+	}
+
+	logrus.Infof("Awarded %d loyalty points to user %d for order %s", awarded, order.UserID, order.ID)
+
+	total, _ := b.db.GetLoyaltyPoints(order.UserID)
+	msg := tgbotapi.NewMessage(order.UserID, b.tn(b.userLanguage(order.UserID), "loyalty.awarded", awarded,
+		awarded, awarded, shortOrderID(order.ID), total))
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := b.send(msg); err != nil {
+		logrus.Errorf("Failed to notify user %d about loyalty points: %v", order.UserID, err)
+	}
+}
+
+// loyaltyTxLabel returns the display label of a points history entry
+func (b *Bot) loyaltyTxLabel(lang string, txType models.LoyaltyTransactionType) string {
+	switch txType {
+	case models.LoyaltyTxEarn, models.LoyaltyTxRedeem, models.LoyaltyTxRestore, models.LoyaltyTxReversal, models.LoyaltyTxExpire:
+		return b.t(lang, "loyalty.tx."+string(txType))
+	default:
+		return string(txType)
+	}
+}
+
+// handlePointsCommand handles /poin and shows the user's points, their value and recent history
+func (b *Bot) handlePointsCommand(message *tgbotapi.Message) {
+	userID := message.From.ID
+	lang := b.userLanguage(userID)
+
+	summary, err := b.db.GetLoyaltySummary(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get loyalty points of user %d: %v", userID, err)
+		b.sendMessage(message.Chat.ID, b.t(lang, "loyalty.load_failed"))
+		return
+	}
+	history, err := b.db.GetLoyaltyHistory(userID, 10)
+	if err != nil {
+		logrus.Errorf("Failed to get loyalty history of user %d: %v", userID, err)
+	}
+
+	var text strings.Builder
+	text.WriteString(b.t(lang, "loyalty.title") + "\n\n")
+	text.WriteString(b.t(lang, "loyalty.points", summary.Points) + "\n")
+	if b.config.LoyaltyPointValue > 0 {
+		text.WriteString(b.t(lang, "loyalty.value", b.formatPrice(lang, summary.Points*b.config.LoyaltyPointValue)) + "\n")
+	}
+	if summary.NextExpiry != nil {
+		text.WriteString(b.tn(lang, "loyalty.expiring", summary.ExpiringPoints,
+			summary.ExpiringPoints, b.i18n.FormatDate(lang, *summary.NextExpiry)) + "\n")
+	}
+
+	if b.config.LoyaltySpendPerPoint > 0 {
+		spend := b.formatPrice(lang, b.config.LoyaltySpendPerPoint)
+		if days := b.config.LoyaltyPointsExpiryDays; days > 0 {
+			text.WriteString("\n" + b.tn(lang, "loyalty.earn_rule_expiry", days, spend, days) + "\n")
+		} else {
+			text.WriteString("\n" + b.t(lang, "loyalty.earn_rule", spend) + "\n")
+		}
+	}
+	if b.config.LoyaltyPointValue > 0 {
+		text.WriteString(b.t(lang, "loyalty.redeem_hint") + "\n")
+	}
+
+	text.WriteString("\n" + b.t(lang, "loyalty.history_title") + "\n")
+	if len(history) == 0 {
+		text.WriteString(b.t(lang, "loyalty.history_empty") + "\n")
+	}
+	for _, entry := range history {
+		text.WriteString(fmt.Sprintf("• %s %+d • %s", b.i18n.FormatDateTime(lang, entry.CreatedAt), entry.Points, b.loyaltyTxLabel(lang, entry.Type)))
+		if entry.Type == models.LoyaltyTxEarn || entry.Type == models.LoyaltyTxRedeem || entry.Type == models.LoyaltyTxRestore {
+			if entry.Reference != nil {
+				text.WriteString(fmt.Sprintf(" #%s", shortOrderID(*entry.Reference)))
+			}
+		}
+		text.WriteString("\n")
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text.String())
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.cart"), "cart"),
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
+		),
+	)
+	b.send(msg)
+}
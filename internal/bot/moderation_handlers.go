@@ -0,0 +1,615 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	moderationHistorySize = 10 // Moderation actions shown on a user's moderation screen
+	bannedUserListSize    = 20 // Banned users listed in the admin panel
+	maxBanReasonLength    = 200
+)
+
+// updateUserID returns who sent an update, or 0 for updates without a sender
+func updateUserID(update tgbotapi.Update) int64 {
+	switch {
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	case update.InlineQuery != nil:
+		return update.InlineQuery.From.ID
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.From.ID
+	}
+	return 0
+}
+
+// rejectBanned is the ban check of handleUpdate. Updates from banned users get the ban notice and are dropped.
+// Admins are never blocked.
+func (b *Bot) rejectBanned(update tgbotapi.Update) bool {
+	userID := updateUserID(update)
+	if userID == 0 || b.config.IsAdmin(userID) {
+		return false
+	}
+
+	ban, err := b.db.GetUserBan(userID)
+	if err != nil {
+		// Do not lock everyone out because of a database hiccup
+		logrus.Errorf("Failed to check ban of user %d: %v", userID, err)
+		return false
+	}
+	if ban == nil {
+		return false
+	}
+
+	lang := b.userLanguage(userID)
+	switch {
+	case update.CallbackQuery != nil:
+		b.api.Request(tgbotapi.NewCallbackWithAlert(update.CallbackQuery.ID, b.banAlertText(lang, ban)))
+	case update.InlineQuery != nil:
+		b.api.Request(tgbotapi.InlineConfig{
+			InlineQueryID:     update.InlineQuery.ID,
+			Results:           []interface{}{},
+			CacheTime:         5,
+			IsPersonal:        true,
+			SwitchPMText:      b.t(lang, "ban.blocked"),
+			SwitchPMParameter: "banned",
+		})
+	case update.Message != nil:
+		b.sendMessage(update.Message.Chat.ID, b.banNoticeText(lang, ban))
+	}
+	return true
+}
+
+// banNoticeText tells a banned user why and for how long they are blocked
+func (b *Bot) banNoticeText(lang string, ban *models.UserBan) string {
+	var text strings.Builder
+	text.WriteString(b.t(lang, "ban.notice_title") + "\n\n")
+	if ban.Reason != "" {
+		text.WriteString(b.t(lang, "ban.reason", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, ban.Reason)) + "\n")
+	}
+	text.WriteString(b.t(lang, "ban.until", b.formatBanUntil(lang, ban.Until)) + "\n\n")
+	text.WriteString(b.t(lang, "ban.contact", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, b.config.AdminUsername)))
+	return text.String()
+}
+
+// banAlertText is the plain text ban notice for callback alerts, which are limited to 200 characters
+func (b *Bot) banAlertText(lang string, ban *models.UserBan) string {
+	reason := ban.Reason
+	if utf8.RuneCountInString(reason) > 80 {
+		reason = string([]rune(reason)[:80]) + "..."
+	}
+	text := b.t(lang, "ban.blocked")
+	if reason != "" {
+		text += ": " + reason
+	}
+	return text + "\n\n" + b.t(lang, "ban.alert_details", b.formatBanUntil(lang, ban.Until), b.config.AdminUsername)
+}
+
+// formatBanUntil renders the end of a ban in lang, where nil means permanent
+func (b *Bot) formatBanUntil(lang string, until *time.Time) string {
+	if until == nil {
+		return b.t(lang, "ban.permanent")
+	}
+	return b.i18n.FormatDateTime(lang, until.Local())
+}
+
+// parseBanDuration parses a ban duration like "30m", "12h" or "7d". "perm" and "permanen" give 0 for a permanent ban.
+func parseBanDuration(s string) (time.Duration, bool) {
+	s = strings.ToLower(s)
+	if s == "perm" || s == "permanen" {
+		return 0, true
+	}
+	if len(s) < 2 {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	switch s[len(s)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute, true
+	case 'h', 'j':
+		return time.Duration(n) * time.Hour, true
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// formatBanDuration renders a ban duration in lang for admins and users
+func (b *Bot) formatBanDuration(lang string, d time.Duration) string {
+	switch {
+	case d == 0:
+		return b.t(lang, "ban.duration.permanent")
+	case d%(24*time.Hour) == 0:
+		days := int(d / (24 * time.Hour))
+		return b.tn(lang, "ban.duration.days", days, days)
+	case d%time.Hour == 0:
+		hours := int(d / time.Hour)
+		return b.tn(lang, "ban.duration.hours", hours, hours)
+	default:
+		minutes := int(d / time.Minute)
+		return b.tn(lang, "ban.duration.minutes", minutes, minutes)
+	}
+}
+
+// checkBanTarget returns why a user cannot be banned, or an empty string if they can
+func (b *Bot) checkBanTarget(userID int64) string {
+	if b.config.IsAdmin(userID) {
+		return "❌ Admin tidak dapat diblokir."
+	}
+	user, err := b.db.GetUser(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get user %d: %v", userID, err)
+		return "❌ Gagal memuat data pengguna."
+	}
+	if user == nil {
+		return "❌ Pengguna tidak ditemukan. Pengguna harus pernah memulai bot."
+	}
+	return ""
+}
+
+// banUser bans a user, cancels their unpaid orders and tells them about it. A zero duration bans permanently.
+// Returns how many orders were cancelled.
+func (b *Bot) banUser(adminID, userID int64, duration time.Duration, reason string) (int, error) {
+	var until *time.Time
+	if duration > 0 {
+		t := time.Now().Add(duration)
+		until = &t
+	}
+	if err := b.db.BanUser(userID, adminID, reason, until); err != nil {
+		return 0, err
+	}
+	logrus.Infof("Admin %d banned user %d (%s): %s", adminID, userID, b.formatBanDuration(b.i18n.Default(), duration), reason)
+
+	// Unpaid orders hold stock that other buyers are waiting for
+	orderIDs, err := b.db.GetPendingOrderIDs(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get pending orders of banned user %d: %v", userID, err)
+	}
+	cancelled := 0
+	for _, orderID := range orderIDs {
+		if err := b.db.UpdateOrderStatus(orderID, models.PaymentStatusCancelled); err != nil {
+			logrus.Errorf("Failed to cancel order %s of banned user %d: %v", orderID, userID, err)
+			continue
+		}
+		if err := b.db.RestoreStockFromOrder(orderID); err != nil {
+			logrus.Errorf("Failed to restore stock for order %s: %v", orderID, err)
+		}
+		cancelled++
+	}
+
+	// Whatever the user was doing cannot be finished anymore
+	if _, err := b.db.ClearConversation(userID); err != nil {
+		logrus.Errorf("Failed to clear conversation state for user %d: %v", userID, err)
+	}
+
+	lang := b.userLanguage(userID)
+	text := b.banNoticeText(lang, &models.UserBan{UserID: userID, Reason: reason, Until: until})
+	if cancelled > 0 {
+		text += "\n\n" + b.tn(lang, "ban.orders_cancelled", cancelled, cancelled)
+	}
+	b.sendMessage(userID, text)
+	return cancelled, nil
+}
+
+// unbanUser lifts a user's ban and tells them. Returns false if they were not banned.
+func (b *Bot) unbanUser(adminID, userID int64, reason string) (bool, error) {
+	unbanned, err := b.db.UnbanUser(userID, adminID, reason)
+	if err != nil || !unbanned {
+		return false, err
+	}
+	logrus.Infof("Admin %d unbanned user %d", adminID, userID)
+	b.sendMessage(userID, b.t(b.userLanguage(userID), "ban.lifted"))
+	return true, nil
+}
+
+// banResultText confirms a ban to the admin
+func (b *Bot) banResultText(userID int64, duration time.Duration, cancelled int) string {
+	text := fmt.Sprintf("🚫 User `%d` diblokir (%s).", userID, b.formatBanDuration(b.i18n.Default(), duration))
+	if cancelled > 0 {
+		text += fmt.Sprintf(" %d pesanan belum dibayar dibatalkan.", cancelled)
+	}
+	return text
+}
+
+// handleBanCommand handles the admin /ban USER_ID [durasi] alasan command
+func (b *Bot) handleBanCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	usage := "❌ Format salah!\n\nGunakan:\n/ban [USER ID] [durasi] [alasan]\n\nDurasi opsional, contoh: 30m, 12h, 7d. Tanpa durasi blokir berlaku permanen.\n\nContoh:\n/ban 123456789 7d Spam checkout"
+	args := strings.Fields(message.CommandArguments())
+	if len(args) < 2 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, "❌ User ID tidak valid.")
+		return
+	}
+
+	var duration time.Duration
+	reasonArgs := args[1:]
+	if d, ok := parseBanDuration(args[1]); ok {
+		duration = d
+		reasonArgs = args[2:]
+	}
+	reason := strings.Join(reasonArgs, " ")
+	if reason == "" {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+	if utf8.RuneCountInString(reason) > maxBanReasonLength {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Alasan terlalu panjang (maksimal %d karakter).", maxBanReasonLength))
+		return
+	}
+
+	if errText := b.checkBanTarget(userID); errText != "" {
+		b.sendMessage(message.Chat.ID, errText)
+		return
+	}
+	cancelled, err := b.banUser(message.From.ID, userID, duration, reason)
+	if err != nil {
+		logrus.Errorf("Failed to ban user %d: %v", userID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memblokir pengguna.")
+		return
+	}
+	b.sendModerationDetail(message.Chat.ID, userID, b.banResultText(userID, duration, cancelled))
+}
+
+// handleUnbanCommand handles the admin /unban USER_ID [alasan] command
+func (b *Bot) handleUnbanCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) < 1 {
+		b.sendMessage(message.Chat.ID, "❌ Format salah!\n\nGunakan:\n/unban [USER ID] [alasan]")
+		return
+	}
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, "❌ User ID tidak valid.")
+		return
+	}
+
+	unbanned, err := b.unbanUser(message.From.ID, userID, strings.Join(args[1:], " "))
+	if err != nil {
+		logrus.Errorf("Failed to unban user %d: %v", userID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal membuka blokir pengguna.")
+		return
+	}
+	if !unbanned {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("ℹ️ User `%d` sedang tidak diblokir.", userID))
+		return
+	}
+	b.sendModerationDetail(message.Chat.ID, userID, fmt.Sprintf("✅ Blokir user `%d` dibuka.", userID))
+}
+
+// handleModerationCommand handles the admin /moderasi USER_ID command showing a user's ban status and history
+func (b *Bot) handleModerationCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	arg := strings.TrimSpace(message.CommandArguments())
+	if arg == "" {
+		text, keyboard, err := b.buildBannedUserList()
+		if err != nil {
+			logrus.Errorf("Failed to build banned user list: %v", err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal memuat daftar pengguna diblokir.")
+			return
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = keyboard
+		b.send(msg)
+		return
+	}
+
+	userID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, "❌ User ID tidak valid.\n\nGunakan:\n/moderasi [USER ID]")
+		return
+	}
+	b.sendModerationDetail(message.Chat.ID, userID, "")
+}
+
+// sendModerationDetail sends a user's moderation screen, optionally below a result line
+func (b *Bot) sendModerationDetail(chatID, userID int64, result string) {
+	text, keyboard, err := b.buildModerationDetail(userID)
+	if err != nil {
+		logrus.Errorf("Failed to build moderation detail for user %d: %v", userID, err)
+		if result != "" {
+			b.sendMessage(chatID, result)
+		} else {
+			b.sendMessage(chatID, "❌ Gagal memuat data moderasi.")
+		}
+		return
+	}
+	if result != "" {
+		text = result + "\n\n" + text
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
+
+// moderationUserLabel shows a user ID with their name or username when known
+func moderationUserLabel(userID int64, username, firstName *string) string {
+	label := fmt.Sprintf("`%d`", userID)
+	if username != nil && *username != "" {
+		label += " @" + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *username)
+	} else if firstName != nil && *firstName != "" {
+		label += " " + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *firstName)
+	}
+	return label
+}
+
+// moderationActionLabel describes a moderation history entry
+func moderationActionLabel(action models.ModerationActionType) string {
+	switch action {
+	case models.ModerationBan:
+		return "🚫 Diblokir"
+	case models.ModerationUnban:
+		return "✅ Blokir dibuka"
+	case models.ModerationExpired:
+		return "⌛ Blokir berakhir"
+	default:
+		return string(action)
+	}
+}
+
+// buildModerationDetail renders a user's ban status and moderation history with ban or unban buttons
+func (b *Bot) buildModerationDetail(userID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	user, err := b.db.GetUser(userID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("user %d not found", userID)
+	}
+	ban, err := b.db.GetUserBan(userID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get ban: %w", err)
+	}
+	history, err := b.db.GetModerationHistory(userID, moderationHistorySize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get moderation history: %w", err)
+	}
+
+	var text strings.Builder
+	text.WriteString("👤 *MODERASI PENGGUNA*\n\n")
+	text.WriteString(fmt.Sprintf("🆔 %s\n", moderationUserLabel(user.UserID, user.Username, user.FirstName)))
+	text.WriteString(fmt.Sprintf("📅 Bergabung: %s\n", user.JoinDate.Format("02/01/2006")))
+	if pending, err := b.db.CountPendingOrders(userID); err == nil {
+		text.WriteString(fmt.Sprintf("⏳ Pesanan belum dibayar: %d\n", pending))
+	}
+
+	switch {
+	case b.config.IsAdmin(userID):
+		text.WriteString("📊 Status: 👨‍💼 Admin\n")
+	case ban != nil:
+		text.WriteString("📊 Status: 🚫 Diblokir\n")
+		if ban.Reason != "" {
+			text.WriteString(fmt.Sprintf("📝 Alasan: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, ban.Reason)))
+		}
+		text.WriteString(fmt.Sprintf("⏰ Sampai: %s\n", b.formatBanUntil(b.i18n.Default(), ban.Until)))
+		text.WriteString(fmt.Sprintf("👨‍💼 Oleh: `%d` pada %s\n", ban.BannedBy, ban.BannedAt.Local().Format("02/01/2006 15:04")))
+	default:
+		text.WriteString("📊 Status: ✅ Aktif\n")
+	}
+
+	text.WriteString("\n📜 *Riwayat moderasi:*\n")
+	if len(history) == 0 {
+		text.WriteString("_Belum ada riwayat._\n")
+	}
+	for _, action := range history {
+		text.WriteString(fmt.Sprintf("• %s %s", action.CreatedAt.Local().Format("02/01/2006 15:04"), moderationActionLabel(action.Action)))
+		if action.Action == models.ModerationBan {
+			text.WriteString(fmt.Sprintf(" (%s)", strings.ToLower(b.formatBanUntil(b.i18n.Default(), action.Until))))
+		}
+		if action.AdminID != nil {
+			text.WriteString(fmt.Sprintf(" oleh `%d`", *action.AdminID))
+		}
+		if action.Reason != nil && *action.Reason != "" {
+			text.WriteString(fmt.Sprintf("\n  _%s_", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *action.Reason)))
+		}
+		text.WriteString("\n")
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	switch {
+	case b.config.IsAdmin(userID):
+	case ban != nil:
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Buka Blokir", fmt.Sprintf("admin:unbanuser:%d", userID)),
+		))
+	default:
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚫 1 Hari", fmt.Sprintf("admin:banuser:%d:1d", userID)),
+			tgbotapi.NewInlineKeyboardButtonData("🚫 7 Hari", fmt.Sprintf("admin:banuser:%d:7d", userID)),
+			tgbotapi.NewInlineKeyboardButtonData("⛔ Permanen", fmt.Sprintf("admin:banuser:%d:perm", userID)),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔄 Refresh", fmt.Sprintf("admin:moduser:%d", userID)),
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Pengguna Diblokir", "admin:users"),
+	))
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// buildBannedUserList lists the banned users with a button to each user's moderation screen
+func (b *Bot) buildBannedUserList() (string, tgbotapi.InlineKeyboardMarkup, error) {
+	total, err := b.db.CountBannedUsers()
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to count banned users: %w", err)
+	}
+	bans, err := b.db.GetBannedUsers(bannedUserListSize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get banned users: %w", err)
+	}
+
+	var text strings.Builder
+	text.WriteString("👥 *KELOLA PENGGUNA*\n\n")
+	text.WriteString(fmt.Sprintf("🚫 Pengguna diblokir: %d\n", total))
+	if total > len(bans) {
+		text.WriteString(fmt.Sprintf("_Menampilkan %d blokir terbaru_\n", len(bans)))
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, ban := range bans {
+		text.WriteString(fmt.Sprintf("\n• %s - sampai %s", moderationUserLabel(ban.UserID, ban.Username, ban.FirstName),
+			strings.ToLower(b.formatBanUntil(b.i18n.Default(), ban.Until))))
+		if ban.Reason != "" {
+			text.WriteString(fmt.Sprintf("\n  _%s_", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, ban.Reason)))
+		}
+
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("👤 %d", ban.UserID), fmt.Sprintf("admin:moduser:%d", ban.UserID)),
+		))
+	}
+	text.WriteString("\n\n💡 Perintah:\n/ban [USER ID] [durasi] [alasan]\n/unban [USER ID] [alasan]\n/moderasi [USER ID] - Status & riwayat")
+
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
+	))
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// handleAdminUsers shows the banned user list from the admin panel
+func (b *Bot) handleAdminUsers(callback *tgbotapi.CallbackQuery) {
+	text, keyboard, err := b.buildBannedUserList()
+	if err != nil {
+		logrus.Errorf("Failed to build banned user list: %v", err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat pengguna"))
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// handleAdminModerationAction handles the moderation screen and its ban and unban buttons
+func (b *Bot) handleAdminModerationAction(callback *tgbotapi.CallbackQuery, action string, userID int64, arg string) {
+	switch action {
+	case "banuser":
+		duration, ok := parseBanDuration(arg)
+		if !ok {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Durasi tidak valid"))
+			return
+		}
+		if errText := b.checkBanTarget(userID); errText != "" {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, errText))
+			return
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.setConversation(callback.From.ID, models.StateBanReason, fmt.Sprintf("%d:%s", userID, arg))
+		b.sendMessage(callback.Message.Chat.ID, fmt.Sprintf(
+			"🚫 Ketik alasan blokir untuk user `%d` (%s). Alasan ini ditampilkan kepada pengguna.\n\nKetik /batal untuk membatalkan.",
+			userID, b.formatBanDuration(b.i18n.Default(), duration)))
+		return
+	case "unbanuser":
+		unbanned, err := b.unbanUser(callback.From.ID, userID, "")
+		if err != nil {
+			logrus.Errorf("Failed to unban user %d: %v", userID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal membuka blokir"))
+			return
+		}
+		if unbanned {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Blokir dibuka"))
+		} else {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "ℹ️ Pengguna sedang tidak diblokir"))
+		}
+	}
+
+	text, keyboard, err := b.buildModerationDetail(userID)
+	if err != nil {
+		logrus.Errorf("Failed to build moderation detail for user %d: %v", userID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat data moderasi"))
+		return
+	}
+
+	// Opened from another screen, such as a support ticket, which should stay as it is
+	if arg == "new" {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = keyboard
+		b.send(msg)
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// processBanReason bans the user once the admin has typed the reason. The conversation state is kept after
+// a mistake so the admin can send the reason again.
+func (b *Bot) processBanReason(message *tgbotapi.Message, payload string) {
+	var userID int64
+	var duration time.Duration
+	parts := strings.SplitN(payload, ":", 2)
+	ok := len(parts) == 2
+	if ok {
+		var err error
+		userID, err = strconv.ParseInt(parts[0], 10, 64)
+		duration, ok = parseBanDuration(parts[1])
+		ok = ok && err == nil
+	}
+	if !ok {
+		b.takeConversation(message.From.ID, models.StateBanReason)
+		b.sendMessage(message.Chat.ID, "❌ Data blokir tidak valid. Ulangi dari menu moderasi.")
+		return
+	}
+
+	reason := strings.TrimSpace(message.Text)
+	if reason == "" {
+		b.sendMessage(message.Chat.ID, "❌ Kirim alasan sebagai pesan teks, atau /batal untuk membatalkan.")
+		return
+	}
+	if utf8.RuneCountInString(reason) > maxBanReasonLength {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(
+			"❌ Alasan terlalu panjang (maksimal %d karakter). Kirim ulang, atau /batal untuk membatalkan.", maxBanReasonLength))
+		return
+	}
+
+	if _, ok := b.takeConversation(message.From.ID, models.StateBanReason); !ok {
+		return
+	}
+	if errText := b.checkBanTarget(userID); errText != "" {
+		b.sendMessage(message.Chat.ID, errText)
+		return
+	}
+	cancelled, err := b.banUser(message.From.ID, userID, duration, reason)
+	if err != nil {
+		logrus.Errorf("Failed to ban user %d: %v", userID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memblokir pengguna.")
+		return
+	}
+	b.sendModerationDetail(message.Chat.ID, userID, b.banResultText(userID, duration, cancelled))
+}
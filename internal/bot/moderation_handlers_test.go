@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"telegram-premium-store/internal/i18n"
+)
+
+func TestParseBanDuration(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"30m", 30 * time.Minute, true},
+		{"12h", 12 * time.Hour, true},
+		{"12j", 12 * time.Hour, true},
+		{"7d", 7 * 24 * time.Hour, true},
+		{"7D", 7 * 24 * time.Hour, true},
+		{"perm", 0, true},
+		{"Permanen", 0, true},
+		{"", 0, false},
+		{"d", 0, false},
+		{"0d", 0, false},
+		{"-1h", 0, false},
+		{"7w", 0, false},
+		{"1.5h", 0, false},
+		{"abc", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseBanDuration(tt.input)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseBanDuration(%q) = %s, %v, want %s, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFormatBanDuration(t *testing.T) {
+	catalog, err := i18n.Load("", "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &Bot{i18n: catalog}
+
+	tests := []struct {
+		lang string
+		d    time.Duration
+		want string
+	}{
+		{"id", 0, "permanen"},
+		{"id", 30 * time.Minute, "30 menit"},
+		{"id", 90 * time.Minute, "90 menit"},
+		{"id", 12 * time.Hour, "12 jam"},
+		{"id", 48 * time.Hour, "2 hari"},
+		{"en", 0, "permanent"},
+		{"en", time.Minute, "1 minute"},
+		{"en", 90 * time.Minute, "90 minutes"},
+		{"en", time.Hour, "1 hour"},
+		{"en", 12 * time.Hour, "12 hours"},
+		{"en", 24 * time.Hour, "1 day"},
+		{"en", 48 * time.Hour, "2 days"},
+	}
+
+	for _, tt := range tests {
+		if got := b.formatBanDuration(tt.lang, tt.d); got != tt.want {
+			t.Errorf("formatBanDuration(%q, %s) = %q, want %q", tt.lang, tt.d, got, tt.want)
+		}
+	}
+}
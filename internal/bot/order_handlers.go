@@ -29,7 +29,7 @@ Anda akan diberitahu ketika *%s* tersedia kembali.
 
 📧 Notifikasi akan dikirim melalui bot ini ketika stok sudah tersedia.
 
-💡 *Tips:* Bookmark produk ini atau kembali lagi nanti untuk mengecek ketersediaan.`, product.Name)
+💡 *Tips:* Bookmark produk ini atau kembali lagi nanti untuk mengecek ketersediaan.`, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name))
 
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
@@ -44,7 +44,7 @@ Anda akan diberitahu ketika *%s* tersedia kembali.
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 
 	b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Notifikasi diatur!"))
 }
@@ -107,7 +107,7 @@ Apakah Anda yakin ingin membatalkan pesanan ini?
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleConfirmCancel confirms order cancellation
@@ -164,7 +164,7 @@ Terima kasih telah menggunakan layanan kami. Anda dapat melakukan pemesanan baru
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 
 	b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Pesanan dibatalkan"))
 
@@ -215,7 +215,7 @@ Terima kasih atas pengertiannya.`, orderID[:8])
 	msg.ParseMode = tgbotapi.ModeMarkdown
 	msg.ReplyMarkup = keyboard
 
-	b.api.Send(msg)
+	b.send(msg)
 
 	logrus.Infof("Order %s expired and user %d notified", orderID, order.UserID)
 }
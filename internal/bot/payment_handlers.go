@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"telegram-premium-store/internal/models"
+	"telegram-premium-store/internal/templates"
 	"telegram-premium-store/internal/payment"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -61,11 +62,16 @@ func (b *Bot) handlePaymentSuccess(orderID string, paidAmount int) error {
 	}
 
 	// Check if order is already paid
-	if order.PaymentStatus == models.PaymentStatusPaid {
+	if order.PaymentStatus == models.PaymentStatusPaid || order.PaymentStatus == models.PaymentStatusAwaitingStock {
 		logrus.Warnf("Order %s already marked as paid", orderID)
 		return nil
 	}
 
+	// Pre-orders have no accounts yet and wait for stock instead of being delivered now
+	if order.IsPreorder {
+		return b.handlePreorderPaid(order, paidAmount)
+	}
+
 	// Update order status to paid
 	if err := b.db.UpdateOrderStatus(orderID, models.PaymentStatusPaid); err != nil {
 		logrus.Errorf("Failed to update order status for %s: %v", orderID, err)
@@ -91,22 +97,26 @@ func (b *Bot) handlePaymentSuccess(orderID string, paidAmount int) error {
 		return fmt.Errorf("failed to send accounts to buyer: %w", err)
 	}
 
+	// Proof of purchase for the buyer's records
+	b.deliverOrderInvoice(order)
+
 	// Send comprehensive notification to admin
 	b.sendAdminSaleNotification(order, soldAccounts, buyer, paidAmount)
 
+	// Reward whoever referred the buyer, on their first paid order
+	b.rewardReferrer(order)
+
+	// Credit the buyer's loyalty points for what they paid
+	b.awardLoyaltyPoints(order)
+
 	logrus.Infof("✅ Payment successful for order %s, accounts delivered to user %d", orderID, order.UserID)
 	return nil
 }
 
 // sendAccountsToBuyer sends purchased accounts to the buyer with copy functionality
 func (b *Bot) sendAccountsToBuyer(order *models.Order, accounts []models.SoldAccount) error {
-	var message strings.Builder
-	message.WriteString("🎉 *PEMBAYARAN BERHASIL!*\n\n")
-	message.WriteString(fmt.Sprintf("✅ Pembayaran Anda untuk Order #%s telah dikonfirmasi.\n\n", order.ID[:8]))
-	message.WriteString(fmt.Sprintf("💰 Total Pembayaran: %s\n", models.FormatPrice(order.TotalAmount, b.config.CurrencySymbol)))
-	message.WriteString(fmt.Sprintf("📅 Tanggal: %s\n\n", time.Now().Format("02/01/2006 15:04")))
-	message.WriteString("━━━━━━━━━━━━━━━━━━━━━\n\n")
-	message.WriteString("🔐 *AKUN PREMIUM ANDA:*\n\n")
+	lang := b.userLanguage(order.UserID)
+	var items strings.Builder
 
 	// Group accounts by product
 	productAccounts := make(map[string][]models.SoldAccount)
@@ -117,34 +127,36 @@ func (b *Bot) sendAccountsToBuyer(order *models.Order, accounts []models.SoldAcc
 	// Build message with accounts (supports multiple formats)
 	accountIndex := 1
 	for productName, prodAccounts := range productAccounts {
-		message.WriteString(fmt.Sprintf("📦 *%s*\n", productName))
-		message.WriteString(fmt.Sprintf("   Jumlah: %d item\n\n", len(prodAccounts)))
+		items.WriteString(fmt.Sprintf("📦 <b>%s</b>\n", escapeHTML(productName)))
+		items.WriteString(b.t(lang, "payment.delivery_quantity", len(prodAccounts)) + "\n\n")
 
 		for _, account := range prodAccounts {
 			contentLabel := account.GetContentLabel()
 			contentData := account.FormatContent()
 			
-			message.WriteString(fmt.Sprintf("   %s #%d:\n", contentLabel, accountIndex))
-			message.WriteString(fmt.Sprintf("   `%s`\n\n", contentData))
+			items.WriteString(fmt.Sprintf("   %s #%d:\n", contentLabel, accountIndex))
+			items.WriteString(fmt.Sprintf("   <code>%s</code>\n\n", escapeHTML(contentData)))
 			accountIndex++
 		}
+
+		// Product-specific instructions set by the admin
+		product, err := b.db.GetProductAnyStatus(prodAccounts[0].ProductID)
+		if err != nil {
+			logrus.Errorf("Failed to get product %d for delivery instructions: %v", prodAccounts[0].ProductID, err)
+		}
+		if product != nil && product.DeliveryInstructions != nil {
+			items.WriteString(b.t(lang, "payment.delivery_instructions", escapeHTML(*product.DeliveryInstructions)) + "\n\n")
+		}
 	}
 
-	message.WriteString("━━━━━━━━━━━━━━━━━━━━━\n\n")
-	message.WriteString("📋 *CARA MENGGUNAKAN:*\n")
-	message.WriteString("1. Tap/klik pada data produk untuk menyalin\n")
-	message.WriteString("2. 🔐 Akun: Login dengan email | password\n")
-	message.WriteString("3. 🔗 Link: Klik atau salin link untuk redeem\n")
-	message.WriteString("4. 🎫 Kode: Gunakan kode untuk aktivasi\n\n")
-	message.WriteString("⚠️ *PENTING:*\n")
-	message.WriteString("• Simpan data ini dengan aman\n")
-	message.WriteString("• Jangan share ke orang lain\n")
-	message.WriteString("• Segera gunakan sesuai petunjuk produk\n\n")
-	message.WriteString("💬 Butuh bantuan? Hubungi /contact\n")
-	message.WriteString("⭐️ Terima kasih telah berbelanja!")
-
-	msg := tgbotapi.NewMessage(order.UserID, message.String())
-	msg.ParseMode = tgbotapi.ModeMarkdown
+	rendered := b.templates.Render(templates.PaymentSuccess, lang, templates.Vars{
+		"order_id":   order.ID[:8],
+		"total":      b.formatPrice(lang, order.TotalAmount),
+		"date":       b.i18n.FormatDateTime(lang, time.Now()),
+		"items":      templates.Raw(items.String()),
+		"gift_cards": templates.Raw(b.giftCardDeliveryNote(lang, order.ID)),
+	})
+	msg := rendered.Message(order.UserID)
 
 	// Add keyboard with copy buttons for each account
 	var keyboard [][]tgbotapi.InlineKeyboardButton
@@ -166,30 +178,31 @@ func (b *Bot) sendAccountsToBuyer(order *models.Order, accounts []models.SoldAcc
 			
 			keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
 				tgbotapi.NewInlineKeyboardButtonData(
-					fmt.Sprintf("📋 Copy %s #%d", contentLabel, accountButtonIndex),
+					b.t(lang, "payment.copy_button", contentLabel, accountButtonIndex),
 					fmt.Sprintf("copy_account:%d:%s", account.ID, order.ID),
 				),
 			))
 			accountButtonIndex++
 
 			// Send individual copyable content message
-			accountMsg := fmt.Sprintf("%s #%d - %s*\n\n`%s`\n\n_Tap untuk menyalin_", 
-				contentLabel, accountButtonIndex-1, productName, contentData)
+			accountMsg := fmt.Sprintf("<b>%s #%d - %s</b>\n\n<code>%s</code>\n\n%s",
+				contentLabel, accountButtonIndex-1, escapeHTML(productName), escapeHTML(contentData), b.t(lang, "payment.copy_hint"))
 			copyMsg := tgbotapi.NewMessage(order.UserID, accountMsg)
-			copyMsg.ParseMode = tgbotapi.ModeMarkdown
-			b.api.Send(copyMsg)
+			copyMsg.ParseMode = tgbotapi.ModeHTML
+			templates.SendWithFallback(b.api, copyMsg, fmt.Sprintf("%s #%d - %s\n\n%s",
+				contentLabel, accountButtonIndex-1, productName, contentData))
 		}
 	}
 
 	// Add help buttons
 	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("📞 Hubungi Admin", "contact"),
-		tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.contact_admin"), "contact"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
 	))
 
 	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
 
-	_, err := b.api.Send(msg)
+	_, err := templates.SendWithFallback(b.api, msg, rendered.Plain)
 	if err != nil {
 		logrus.Errorf("Failed to send accounts message to user %d: %v", order.UserID, err)
 		return err
@@ -204,41 +217,23 @@ func (b *Bot) sendAdminSaleNotification(order *models.Order, soldAccounts []mode
 	// Get all admin IDs
 	adminIDs := b.config.AdminIDs
 
-	var notification strings.Builder
-	notification.WriteString("💰 *PEMBERITAHUAN PENJUALAN BARU!*\n\n")
-	notification.WriteString("━━━━━━━━━━━━━━━━━━━━━\n\n")
-	
-	// Order information
-	notification.WriteString("📋 *INFORMASI PESANAN*\n")
-	notification.WriteString(fmt.Sprintf("🆔 Order ID: `%s`\n", order.ID))
-	notification.WriteString(fmt.Sprintf("📅 Waktu: %s\n", time.Now().Format("02/01/2006 15:04:05")))
-	notification.WriteString(fmt.Sprintf("💰 Total: *%s*\n", models.FormatPrice(paidAmount, b.config.CurrencySymbol)))
-	notification.WriteString(fmt.Sprintf("✅ Status: LUNAS\n\n"))
-
 	// Buyer information
-	notification.WriteString("👤 *DATA PEMBELI*\n")
+	buyerName := "Unknown"
+	buyerUsername := "-"
+	buyerID := order.UserID
 	if buyer != nil {
-		buyerName := "Unknown"
 		if buyer.FirstName != nil && *buyer.FirstName != "" {
 			buyerName = *buyer.FirstName
 			if buyer.LastName != nil && *buyer.LastName != "" {
 				buyerName += " " + *buyer.LastName
 			}
 		}
-		notification.WriteString(fmt.Sprintf("📛 Nama: %s\n", buyerName))
-		
 		if buyer.Username != nil && *buyer.Username != "" {
-			notification.WriteString(fmt.Sprintf("👤 Username: @%s\n", *buyer.Username))
+			buyerUsername = "@" + *buyer.Username
 		}
-		notification.WriteString(fmt.Sprintf("🆔 User ID: `%d`\n", buyer.UserID))
-	} else {
-		notification.WriteString(fmt.Sprintf("🆔 User ID: `%d`\n", order.UserID))
+		buyerID = buyer.UserID
 	}
-	notification.WriteString("\n")
 
-	// Products and accounts sold
-	notification.WriteString("📦 *DETAIL PEMBELIAN*\n\n")
-	
 	// Group accounts by product
 	productAccountsMap := make(map[string][]models.SoldAccount)
 	productPriceMap := make(map[string]int)
@@ -248,39 +243,37 @@ func (b *Bot) sendAdminSaleNotification(order *models.Order, soldAccounts []mode
 		productPriceMap[account.ProductName] = account.SoldPrice
 	}
 
+	// Products bought
+	var items strings.Builder
 	itemNo := 1
 	for productName, accounts := range productAccountsMap {
 		quantity := len(accounts)
 		price := productPriceMap[productName]
 		subtotal := price * quantity
 
-		notification.WriteString(fmt.Sprintf("%d. *%s*\n", itemNo, productName))
-		notification.WriteString(fmt.Sprintf("   • Jumlah: %d akun\n", quantity))
-		notification.WriteString(fmt.Sprintf("   • Harga satuan: %s\n", models.FormatPrice(price, b.config.CurrencySymbol)))
-		notification.WriteString(fmt.Sprintf("   • Subtotal: %s\n\n", models.FormatPrice(subtotal, b.config.CurrencySymbol)))
+		items.WriteString(fmt.Sprintf("%d. <b>%s</b>\n", itemNo, escapeHTML(productName)))
+		items.WriteString(fmt.Sprintf("   • Jumlah: %d akun\n", quantity))
+		items.WriteString(fmt.Sprintf("   • Harga satuan: %s\n", models.FormatPrice(price, b.config.CurrencySymbol)))
+		items.WriteString(fmt.Sprintf("   • Subtotal: %s\n\n", models.FormatPrice(subtotal, b.config.CurrencySymbol)))
 		itemNo++
 	}
 
-	notification.WriteString("━━━━━━━━━━━━━━━━━━━━━\n\n")
-
 	// Sold accounts details (supports multiple formats)
-	notification.WriteString("📦 *PRODUK YANG TERJUAL*\n\n")
+	var sold strings.Builder
 	accountNo := 1
 	for productName, accounts := range productAccountsMap {
-		notification.WriteString(fmt.Sprintf("📦 *%s* (%d item):\n", productName, len(accounts)))
+		sold.WriteString(fmt.Sprintf("📦 <b>%s</b> (%d item):\n", escapeHTML(productName), len(accounts)))
 		for _, account := range accounts {
 			contentLabel := account.GetContentLabel()
 			contentData := account.FormatContent()
-			notification.WriteString(fmt.Sprintf("   %d. %s: `%s`\n", accountNo, contentLabel, contentData))
+			sold.WriteString(fmt.Sprintf("   %d. %s: <code>%s</code>\n", accountNo, contentLabel, escapeHTML(contentData)))
 			accountNo++
 		}
-		notification.WriteString("\n")
+		sold.WriteString("\n")
 	}
 
-	notification.WriteString("━━━━━━━━━━━━━━━━━━━━━\n\n")
-
 	// Stock status
-	notification.WriteString("📊 *STATUS STOK TERKINI*\n")
+	var stock strings.Builder
 	for productName, accounts := range productAccountsMap {
 		// Get product ID from first account
 		productID := accounts[0].ProductID
@@ -288,33 +281,38 @@ func (b *Bot) sendAdminSaleNotification(order *models.Order, soldAccounts []mode
 		// Get stock summary
 		stockSummary, err := b.db.GetProductStockSummary(productID)
 		if err == nil {
-			notification.WriteString(fmt.Sprintf("• %s:\n", productName))
-			notification.WriteString(fmt.Sprintf("  ✅ Tersedia: %d akun\n", stockSummary.AvailableStock))
-			notification.WriteString(fmt.Sprintf("  💰 Terjual: %d akun\n", stockSummary.SoldStock))
-			notification.WriteString(fmt.Sprintf("  📊 Total: %d akun\n\n", stockSummary.TotalStock))
+			stock.WriteString(fmt.Sprintf("• %s:\n", escapeHTML(productName)))
+			stock.WriteString(fmt.Sprintf("  ✅ Tersedia: %d akun\n", stockSummary.AvailableStock))
+			stock.WriteString(fmt.Sprintf("  💰 Terjual: %d akun\n", stockSummary.SoldStock))
+			stock.WriteString(fmt.Sprintf("  📊 Total: %d akun\n\n", stockSummary.TotalStock))
 		}
 	}
 
-	notification.WriteString("━━━━━━━━━━━━━━━━━━━━━\n\n")
-	notification.WriteString("✨ *Transaksi berhasil diproses!*")
+	vars := templates.Vars{
+		"order_id":       order.ID,
+		"date":           time.Now().Format("02/01/2006 15:04:05"),
+		"total":          models.FormatPrice(paidAmount, b.config.CurrencySymbol),
+		"buyer_name":     buyerName,
+		"buyer_username": buyerUsername,
+		"buyer_id":       buyerID,
+		"items":          templates.Raw(items.String()),
+		"accounts":       templates.Raw(sold.String()),
+		"stock":          templates.Raw(stock.String()),
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📊 Lihat Stok", "admin:stock"),
+			tgbotapi.NewInlineKeyboardButtonData("💰 Kelola Pesanan", "admin:orders"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏠 Panel Admin", "admin:main"),
+		),
+	)
 
 	// Send to all admins
 	for _, adminID := range adminIDs {
-		msg := tgbotapi.NewMessage(adminID, notification.String())
-		msg.ParseMode = tgbotapi.ModeMarkdown
-
-		keyboard := tgbotapi.NewInlineKeyboardMarkup(
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("📊 Lihat Stok", "admin:stock"),
-				tgbotapi.NewInlineKeyboardButtonData("💰 Kelola Pesanan", "admin:orders"),
-			),
-			tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonData("🏠 Panel Admin", "admin:main"),
-			),
-		)
-		msg.ReplyMarkup = keyboard
-
-		_, err := b.api.Send(msg)
+		_, err := b.templates.Send(b.api, adminID, templates.AdminSale, b.i18n.Default(), vars, keyboard)
 		if err != nil {
 			logrus.Errorf("Failed to send sale notification to admin %d: %v", adminID, err)
 		}
@@ -366,7 +364,7 @@ func (b *Bot) notifyAdminManipulationAttempt(orderID string, expectedAmount, pai
 		)
 		msg.ReplyMarkup = keyboard
 
-		b.api.Send(msg)
+		b.send(msg)
 	}
 
 	logrus.Warnf("⚠️ Manipulation attempt detected for order %s: expected %d, got %d", 
@@ -399,7 +397,7 @@ func (b *Bot) handleSimulatePayment(callback *tgbotapi.CallbackQuery, orderID st
 	text := fmt.Sprintf("✅ *PEMBAYARAN DISIMULASI*\n\nOrder #%s berhasil diproses.\nAkun telah dikirim ke pembeli.", orderID[:8])
 	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
 	edit.ParseMode = tgbotapi.ModeMarkdown
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // abs returns absolute value of integer
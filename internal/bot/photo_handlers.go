@@ -0,0 +1,343 @@
+package bot
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// maxGalleryPhotos is the most photos Telegram accepts in one media group
+const maxGalleryPhotos = 10
+
+// storeProductPhoto adds an uploaded photo to a product's gallery, keeping a local copy next to the file ID.
+// A failed download only loses the local copy; the photo is still stored.
+func (b *Bot) storeProductPhoto(productID int, fileID string) (*models.ProductPhoto, error) {
+	photo := &models.ProductPhoto{ProductID: productID, FileID: fileID}
+	if path, err := b.saveProductPhotoCopy(productID, fileID); err != nil {
+		logrus.Warnf("Failed to keep a local copy of photo for product %d: %v", productID, err)
+	} else {
+		photo.LocalPath = &path
+	}
+
+	if err := b.db.AddProductPhoto(photo); err != nil {
+		if photo.LocalPath != nil {
+			os.Remove(*photo.LocalPath)
+		}
+		return nil, err
+	}
+	return photo, nil
+}
+
+// saveProductPhotoCopy downloads a Telegram file into ProductPhotoDir and returns its path
+func (b *Bot) saveProductPhotoCopy(productID int, fileID string) (string, error) {
+	file, err := b.api.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	resp, err := http.Get(file.Link(b.config.BotToken))
+	if err != nil {
+		return "", fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if err := os.MkdirAll(b.config.ProductPhotoDir, 0755); err != nil {
+		return "", err
+	}
+	ext := filepath.Ext(file.FilePath)
+	if ext == "" {
+		ext = ".jpg"
+	}
+	path := filepath.Join(b.config.ProductPhotoDir, fmt.Sprintf("product_%d_%d%s", productID, time.Now().UnixNano(), ext))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// removeProductPhotoCopy deletes a photo's local copy, if it has one
+func removeProductPhotoCopy(photo *models.ProductPhoto) {
+	if photo.LocalPath == nil {
+		return
+	}
+	if err := os.Remove(*photo.LocalPath); err != nil && !os.IsNotExist(err) {
+		logrus.Warnf("Failed to remove local copy of photo %d: %v", photo.ID, err)
+	}
+}
+
+// imageFile returns the request file for a stored image, which is either a URL or a Telegram file ID
+func imageFile(image string) tgbotapi.RequestFileData {
+	if strings.HasPrefix(image, "http://") || strings.HasPrefix(image, "https://") {
+		return tgbotapi.FileURL(image)
+	}
+	return tgbotapi.FileID(image)
+}
+
+// sendProductPhoto sends the product's primary photo with a gallery button when it has more than one.
+// It falls back to the product's image URL and then DefaultImageURL, and returns false when nothing could be sent.
+func (b *Bot) sendProductPhoto(chatID int64, lang string, product *models.Product) bool {
+	photos, err := b.db.GetProductPhotos(product.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get photos of product %d: %v", product.ID, err)
+	}
+
+	display := []models.Product{*product}
+	b.translateProducts(lang, display)
+
+	photoMsg := func(file tgbotapi.RequestFileData) tgbotapi.PhotoConfig {
+		msg := tgbotapi.NewPhoto(chatID, file)
+		msg.Caption = fmt.Sprintf("📱 *%s*", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, display[0].Name))
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		if len(photos) > 1 {
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData(b.tn(lang, "product.gallery", len(photos), len(photos)), fmt.Sprintf("gallery:%d", product.ID)),
+				),
+			)
+		}
+		return msg
+	}
+
+	if len(photos) > 0 {
+		primary := photos[0]
+		_, err := b.send(photoMsg(tgbotapi.FileID(primary.FileID)))
+		if err == nil {
+			return true
+		}
+		logrus.Warnf("Failed to send photo %d of product %d: %v", primary.ID, product.ID, err)
+
+		// The file ID stops working e.g. after changing the bot token; upload the local copy and keep its new ID
+		if primary.LocalPath != nil {
+			sent, err := b.send(photoMsg(tgbotapi.FilePath(*primary.LocalPath)))
+			if err == nil {
+				if len(sent.Photo) > 0 {
+					if err := b.db.UpdateProductPhotoFileID(primary.ID, sent.Photo[len(sent.Photo)-1].FileID); err != nil {
+						logrus.Errorf("Failed to update file ID of photo %d: %v", primary.ID, err)
+					}
+				}
+				return true
+			}
+			logrus.Warnf("Failed to send local copy of photo %d of product %d: %v", primary.ID, product.ID, err)
+		}
+	}
+
+	var fallbacks []string
+	if product.ImageURL != nil && *product.ImageURL != "" {
+		fallbacks = append(fallbacks, *product.ImageURL)
+	}
+	if b.config.DefaultImageURL != "" {
+		fallbacks = append(fallbacks, b.config.DefaultImageURL)
+	}
+	for _, image := range fallbacks {
+		_, err := b.send(photoMsg(imageFile(image)))
+		if err == nil {
+			return true
+		}
+		logrus.Debugf("Failed to send image %s of product %d: %v", image, product.ID, err)
+	}
+	return false
+}
+
+// handleGalleryCallback sends all photos of a product as an album
+func (b *Bot) handleGalleryCallback(callback *tgbotapi.CallbackQuery, productID int) {
+	lang := b.userLanguage(callback.From.ID)
+
+	photos, err := b.db.GetProductPhotos(productID)
+	if err != nil || len(photos) == 0 {
+		if err != nil {
+			logrus.Errorf("Failed to get photos of product %d: %v", productID, err)
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "product.gallery_failed")))
+		return
+	}
+	if len(photos) > maxGalleryPhotos {
+		photos = photos[:maxGalleryPhotos]
+	}
+
+	media := make([]interface{}, 0, len(photos))
+	for _, photo := range photos {
+		media = append(media, tgbotapi.NewInputMediaPhoto(tgbotapi.FileID(photo.FileID)))
+	}
+	if _, err := b.api.SendMediaGroup(tgbotapi.NewMediaGroup(callback.Message.Chat.ID, media)); err != nil {
+		logrus.Errorf("Failed to send gallery of product %d: %v", productID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "product.gallery_failed")))
+		return
+	}
+	b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+}
+
+// handleAdminProductPhotos shows a product's photos with buttons to add, reorder and delete them
+func (b *Bot) handleAdminProductPhotos(callback *tgbotapi.CallbackQuery, product *models.Product) {
+	text, keyboard, err := b.buildAdminProductPhotos(product)
+	if err != nil {
+		logrus.Errorf("Failed to get photos of product %d: %v", product.ID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat foto"))
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// buildAdminProductPhotos renders the photo manager of a product
+func (b *Bot) buildAdminProductPhotos(product *models.Product) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	photos, err := b.db.GetProductPhotos(product.ID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🖼️ *FOTO PRODUK*\n📱 %s\n\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if len(photos) == 0 {
+		text.WriteString("Belum ada foto. ")
+		if product.ImageURL != nil && *product.ImageURL != "" {
+			text.WriteString("Detail produk memakai URL gambar produk.\n")
+		} else {
+			text.WriteString("Detail produk memakai gambar default.\n")
+		}
+	} else {
+		for i, photo := range photos {
+			label := fmt.Sprintf("Foto %d", i+1)
+			if i == 0 {
+				label += " ⭐ utama"
+			}
+			if photo.LocalPath == nil {
+				label += " (tanpa salinan lokal)"
+			}
+			text.WriteString("• " + label + "\n")
+
+			row := []tgbotapi.InlineKeyboardButton{}
+			if i > 0 {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("⭐ Utamakan #%d", i+1), fmt.Sprintf("admin:primaryphoto:%d", photo.ID)))
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🗑️ Hapus #%d", i+1), fmt.Sprintf("admin:deletephoto:%d", photo.ID)))
+			rows = append(rows, row)
+		}
+	}
+	text.WriteString("\nFoto utama tampil di detail produk; semua foto bisa dilihat pembeli lewat tombol galeri.")
+
+	actions := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("➕ Tambah Foto", fmt.Sprintf("admin:addphoto:%d", product.ID)),
+	)
+	if len(photos) > 0 {
+		actions = append(actions, tgbotapi.NewInlineKeyboardButtonData("👀 Lihat Galeri", fmt.Sprintf("gallery:%d", product.ID)))
+	}
+	rows = append(rows, actions, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Detail Produk", fmt.Sprintf("admin:product:%d", product.ID)),
+	))
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// startProductPhotoUpload waits for the admin to send photos for a product
+func (b *Bot) startProductPhotoUpload(callback *tgbotapi.CallbackQuery, product *models.Product) {
+	b.setConversation(callback.From.ID, models.StateProductPhotos, strconv.Itoa(product.ID))
+
+	text := fmt.Sprintf("📤 Kirim satu atau beberapa foto untuk *%s* (boleh sekaligus sebagai album).\n\nTekan ✅ Selesai bila sudah.",
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Selesai", fmt.Sprintf("admin:photosdone:%d", product.ID)),
+		),
+	)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// finishProductPhotoUpload leaves the upload state and shows the photo manager again
+func (b *Bot) finishProductPhotoUpload(callback *tgbotapi.CallbackQuery, product *models.Product) {
+	b.takeConversation(callback.From.ID, models.StateProductPhotos)
+	b.handleAdminProductPhotos(callback, product)
+}
+
+// processProductPhotoUpload stores a photo sent while uploading product photos; payload is the product ID
+func (b *Bot) processProductPhotoUpload(message *tgbotapi.Message, payload string) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.takeConversation(message.From.ID, models.StateProductPhotos)
+		return
+	}
+
+	productID, err := strconv.Atoi(payload)
+	if err != nil {
+		logrus.Errorf("Invalid product photo payload %q", payload)
+		b.takeConversation(message.From.ID, models.StateProductPhotos)
+		return
+	}
+	if len(message.Photo) == 0 {
+		b.sendMessage(message.Chat.ID, "❌ Kirim foto (bukan file/dokumen), atau tekan ✅ Selesai.")
+		return
+	}
+
+	// The largest size comes last
+	photo, err := b.storeProductPhoto(productID, message.Photo[len(message.Photo)-1].FileID)
+	if err != nil {
+		logrus.Errorf("Failed to store photo for product %d: %v", productID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal menyimpan foto, coba kirim lagi.")
+		return
+	}
+
+	logrus.Infof("Admin %d added photo %d to product %d", message.From.ID, photo.ID, productID)
+	b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Foto #%d tersimpan.", photo.Position+1))
+}
+
+// handleAdminPhotoAction handles the per-photo admin buttons ("admin:<action>:<photoID>")
+func (b *Bot) handleAdminPhotoAction(callback *tgbotapi.CallbackQuery, action string, photoID int) {
+	photo, err := b.db.GetProductPhoto(photoID)
+	if err != nil {
+		logrus.Errorf("Failed to get photo %d: %v", photoID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat foto"))
+		return
+	}
+	if photo == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Foto tidak ditemukan"))
+		return
+	}
+	product, err := b.db.GetProductAnyStatus(photo.ProductID)
+	if err != nil || product == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Produk tidak ditemukan"))
+		return
+	}
+
+	switch action {
+	case "primaryphoto":
+		if err := b.db.SetPrimaryProductPhoto(photo.ID); err != nil {
+			logrus.Errorf("Failed to set primary photo %d: %v", photo.ID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal mengubah foto utama"))
+			return
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "⭐ Foto utama diubah"))
+	case "deletephoto":
+		if _, err := b.db.DeleteProductPhoto(photo.ID); err != nil {
+			logrus.Errorf("Failed to delete photo %d: %v", photo.ID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal menghapus foto"))
+			return
+		}
+		removeProductPhotoCopy(photo)
+		logrus.Infof("Admin %d deleted photo %d of product %d", callback.From.ID, photo.ID, product.ID)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "🗑️ Foto dihapus"))
+	}
+	b.handleAdminProductPhotos(callback, product)
+}
@@ -0,0 +1,369 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// preorderSlotsLeft returns how many more units of a product can be pre-ordered
+func (b *Bot) preorderSlotsLeft(product *models.Product) int {
+	if !product.PreorderEnabled || product.IsBundle {
+		return 0
+	}
+
+	reserved, err := b.db.GetPreorderReservedCount(product.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get pre-order count for product %d: %v", product.ID, err)
+		return 0
+	}
+
+	if left := product.PreorderLimit - reserved; left > 0 {
+		return left
+	}
+	return 0
+}
+
+// formatPreorderETA returns the expected restock date shown to buyers
+func (b *Bot) formatPreorderETA(lang string, product *models.Product) string {
+	if product.PreorderETA == nil {
+		return b.t(lang, "preorder.eta_soon")
+	}
+	return b.i18n.FormatDate(lang, *product.PreorderETA)
+}
+
+// handlePreorder shows pre-order terms for an out-of-stock product
+func (b *Bot) handlePreorder(callback *tgbotapi.CallbackQuery, productID int) {
+	lang := b.userLanguage(callback.From.ID)
+	product, err := b.db.GetProduct(productID)
+	if err != nil || product == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "product.not_found")))
+		return
+	}
+
+	slotsLeft := b.preorderSlotsLeft(product)
+	if slotsLeft == 0 {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "preorder.unavailable")))
+		return
+	}
+	b.loadPriceContext(callback.From.ID).applyProduct(product, 1)
+
+	text := b.t(lang, "preorder.terms",
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name),
+		b.formatSalePrice(lang, product.Price, product.OriginalPrice),
+		b.formatPreorderETA(lang, product),
+		slotsLeft)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "preorder.pay"), fmt.Sprintf("preorder_pay:%d", product.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "preorder.back_to_product"), fmt.Sprintf("product:%d", product.ID)),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+
+	b.send(edit)
+}
+
+// handlePreorderPay creates a pre-order and starts the QRIS payment
+func (b *Bot) handlePreorderPay(callback *tgbotapi.CallbackQuery, productID int) {
+	lang := b.userLanguage(callback.From.ID)
+	product, err := b.db.GetProduct(productID)
+	if err != nil || product == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "product.not_found")))
+		return
+	}
+
+	if !b.realQRISService.IsConfigured() {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "payment.not_configured")))
+		return
+	}
+
+	// Stock may have arrived since the product page was opened
+	available, err := b.db.GetAvailableAccountCount(productID)
+	if err == nil && available > 0 {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "preorder.in_stock")))
+		b.handleProductDetail(callback, productID)
+		return
+	}
+
+	if b.preorderSlotsLeft(product) == 0 {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.preorder_full")))
+		return
+	}
+
+	b.loadPriceContext(callback.From.ID).applyProduct(product, 1)
+	orderItems := []models.OrderItem{{
+		ProductID: product.ID,
+		Quantity:  1,
+		Price:     product.Price,
+	}}
+	if product.FlashSale != nil {
+		orderItems[0].FlashSaleID = &product.FlashSale.ID
+	}
+
+	if msg := b.checkOrderLimits(lang, callback.From.ID, orderItems); msg != "" {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, msg))
+		return
+	}
+
+	orderID, ok := b.startQRISPayment(callback, &models.Order{TotalAmount: product.Price, Items: orderItems}, true)
+	if !ok {
+		return
+	}
+
+	b.db.LogUserInteraction(callback.From.ID, "preorder_created", orderID)
+	b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "preorder.created")))
+}
+
+// handlePreorderPaid moves a paid pre-order to the waiting list and tries to fulfill it right away
+func (b *Bot) handlePreorderPaid(order *models.Order, paidAmount int) error {
+	if err := b.db.UpdateOrderStatus(order.ID, models.PaymentStatusAwaitingStock); err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	var items strings.Builder
+	for _, item := range order.Items {
+		items.WriteString(fmt.Sprintf("• %s x%d\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, item.ProductName), item.Quantity))
+	}
+
+	lang := b.userLanguage(order.UserID)
+	buyerText := b.t(lang, "preorder.paid",
+		shortOrderID(order.ID),
+		b.formatPrice(lang, paidAmount),
+		items.String())
+
+	msg := tgbotapi.NewMessage(order.UserID, buyerText)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := b.send(msg); err != nil {
+		logrus.Errorf("Failed to send pre-order confirmation to user %d: %v", order.UserID, err)
+	}
+
+	adminText := fmt.Sprintf(`📝 *PRE-ORDER BARU DIBAYAR*
+
+🆔 Order: `+"`%s`"+`
+🆔 User ID: `+"`%d`"+`
+💰 Total: %s
+
+📦 *Item:*
+%s
+Tambahkan stok untuk memenuhi pre-order secara otomatis.`,
+		order.ID,
+		order.UserID,
+		models.FormatPrice(paidAmount, b.config.CurrencySymbol),
+		items.String())
+
+	for _, adminID := range b.config.AdminIDs {
+		msg := tgbotapi.NewMessage(adminID, adminText)
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		if _, err := b.send(msg); err != nil {
+			logrus.Errorf("Failed to send pre-order notification to admin %d: %v", adminID, err)
+		}
+	}
+
+	logrus.Infof("Pre-order %s paid by user %d, awaiting stock", order.ID, order.UserID)
+
+	b.rewardReferrer(order)
+
+	// Stock may already be available, e.g. restocked while the buyer was paying
+	for _, item := range order.Items {
+		b.fulfillPreorders(item.ProductID)
+	}
+
+	return nil
+}
+
+// fulfillPreorders delivers stock to waiting pre-orders of a product, oldest first.
+// Stops at the first pre-order that cannot be fully served so earlier buyers keep priority.
+func (b *Bot) fulfillPreorders(productID int) int {
+	orderIDs, err := b.db.GetAwaitingPreorderIDs(productID)
+	if err != nil {
+		logrus.Errorf("Failed to get awaiting pre-orders for product %d: %v", productID, err)
+		return 0
+	}
+
+	fulfilled := 0
+	for _, orderID := range orderIDs {
+		ok, err := b.db.FulfillPreorder(orderID)
+		if err != nil {
+			logrus.Errorf("Failed to fulfill pre-order %s: %v", orderID, err)
+			break
+		}
+		if !ok {
+			break
+		}
+
+		fulfilled++
+		b.deliverPreorder(orderID)
+	}
+
+	if fulfilled > 0 {
+		logrus.Infof("Fulfilled %d pre-orders for product %d", fulfilled, productID)
+	}
+	return fulfilled
+}
+
+// fulfillPreordersAfterRestock fulfills waiting pre-orders after stock was added and reports it to the admin
+func (b *Bot) fulfillPreordersAfterRestock(chatID int64, productID int) {
+	if fulfilled := b.fulfillPreorders(productID); fulfilled > 0 {
+		b.sendMessage(chatID, fmt.Sprintf("📝 %d pre-order otomatis dipenuhi dari stok baru.", fulfilled))
+	}
+}
+
+// deliverPreorder sends the accounts of a fulfilled pre-order through the regular delivery path
+func (b *Bot) deliverPreorder(orderID string) {
+	order, err := b.db.GetOrder(orderID)
+	if err != nil || order == nil {
+		logrus.Errorf("Failed to load fulfilled pre-order %s: %v", orderID, err)
+		return
+	}
+
+	soldAccounts, err := b.db.GetProductAccountsForOrder(orderID)
+	if err != nil {
+		logrus.Errorf("Failed to get accounts for pre-order %s: %v", orderID, err)
+		return
+	}
+
+	notice := tgbotapi.NewMessage(order.UserID, b.t(b.userLanguage(order.UserID), "preorder.delivered", shortOrderID(order.ID)))
+	notice.ParseMode = tgbotapi.ModeMarkdown
+	b.send(notice)
+
+	if err := b.sendAccountsToBuyer(order, soldAccounts); err != nil {
+		logrus.Errorf("Failed to deliver pre-order %s to user %d: %v", orderID, order.UserID, err)
+	}
+	b.deliverOrderInvoice(order)
+
+	buyer, _ := b.db.GetUser(order.UserID)
+	b.sendAdminSaleNotification(order, soldAccounts, buyer, order.TotalAmount)
+
+	// Points are awarded on delivery, once the pre-order has its items
+	b.awardLoyaltyPoints(order)
+}
+
+// processPreorderCommand processes /preorder command
+func (b *Bot) processPreorderCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		b.sendPreorderList(message.Chat.ID)
+		return
+	}
+
+	usage := `❌ Format salah!
+
+Gunakan:
+/preorder - Lihat produk dengan pre-order aktif
+/preorder [product_id] [kuota] [YYYY-MM-DD] - Aktifkan pre-order
+/preorder [product_id] off - Nonaktifkan pre-order
+
+Contoh:
+/preorder 3 20 2026-11-01`
+
+	productID, err := strconv.Atoi(args[0])
+	if err != nil || len(args) < 2 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	product, err := b.db.GetProduct(productID)
+	if err != nil || product == nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Produk dengan ID %d tidak ditemukan!", productID))
+		return
+	}
+	if product.IsBundle {
+		b.sendMessage(message.Chat.ID, "❌ Pre-order tidak tersedia untuk paket bundling.")
+		return
+	}
+	if product.IsGiftCard() {
+		b.sendMessage(message.Chat.ID, "❌ Gift card selalu tersedia dan tidak perlu pre-order.")
+		return
+	}
+
+	if strings.EqualFold(args[1], "off") {
+		if err := b.db.SetProductPreorder(productID, false, product.PreorderLimit, product.PreorderETA); err != nil {
+			logrus.Errorf("Failed to disable pre-order for product %d: %v", productID, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal menonaktifkan pre-order!")
+			return
+		}
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Pre-order untuk *%s* dinonaktifkan.\n\nPre-order yang sudah dibayar tetap akan dipenuhi saat stok ditambahkan.",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+		logrus.Infof("Admin %d disabled pre-order for product %d", message.From.ID, productID)
+		return
+	}
+
+	limit, err := strconv.Atoi(args[1])
+	if err != nil || limit <= 0 || len(args) < 3 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	eta, err := time.Parse("2006-01-02", args[2])
+	if err != nil {
+		b.sendMessage(message.Chat.ID, "❌ Format tanggal salah! Gunakan YYYY-MM-DD, contoh: 2026-11-01")
+		return
+	}
+
+	if err := b.db.SetProductPreorder(productID, true, limit, &eta); err != nil {
+		logrus.Errorf("Failed to enable pre-order for product %d: %v", productID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal mengaktifkan pre-order!")
+		return
+	}
+
+	reserved, _ := b.db.GetPreorderReservedCount(productID)
+
+	b.sendMessage(message.Chat.ID, fmt.Sprintf(`✅ *PRE-ORDER DIAKTIFKAN!*
+
+📦 Produk: *%s*
+🎟️ Kuota: %d (terpakai %d)
+📅 Estimasi tersedia: %s
+
+Pembeli dapat melakukan pre-order saat stok habis. Pre-order otomatis dipenuhi saat stok ditambahkan.`,
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name), limit, reserved, eta.Format("02/01/2006")))
+
+	logrus.Infof("Admin %d enabled pre-order for product %d (limit %d, eta %s)",
+		message.From.ID, productID, limit, eta.Format("2006-01-02"))
+}
+
+// sendPreorderList sends the list of products with pre-orders enabled
+func (b *Bot) sendPreorderList(chatID int64) {
+	products, err := b.db.GetPreorderProducts()
+	if err != nil {
+		logrus.Errorf("Failed to get pre-order products: %v", err)
+		b.sendMessage(chatID, "❌ Gagal memuat daftar pre-order!")
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString("📝 *PRODUK PRE-ORDER*\n\n")
+
+	if len(products) == 0 {
+		text.WriteString("Belum ada produk dengan pre-order aktif.\n\n")
+	}
+
+	for _, product := range products {
+		reserved, _ := b.db.GetPreorderReservedCount(product.ID)
+		text.WriteString(fmt.Sprintf("🔸 *%s* (ID: %d)\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name), product.ID))
+		text.WriteString(fmt.Sprintf("   Kuota: %d/%d | Estimasi: %s\n\n",
+			reserved, product.PreorderLimit, b.formatPreorderETA(b.i18n.Default(), &product)))
+	}
+
+	text.WriteString("💡 Aktifkan: /preorder [product_id] [kuota] [YYYY-MM-DD]\n")
+	text.WriteString("💡 Nonaktifkan: /preorder [product_id] off")
+
+	b.sendMessage(chatID, text.String())
+}
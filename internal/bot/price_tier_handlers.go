@@ -0,0 +1,250 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// groupNames returns the customer group names joined for usage messages, e.g. "retail|reseller|vip"
+func groupNames() string {
+	names := make([]string, len(models.CustomerGroups))
+	for i, group := range models.CustomerGroups {
+		names[i] = string(group)
+	}
+	return strings.Join(names, "|")
+}
+
+// processSetGroupCommand handles /setgroup USER_ID retail|reseller|vip
+func (b *Bot) processSetGroupCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 2 {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Format salah!\n\nGunakan:\n/setgroup [USER_ID] [%s]\n\nContoh:\n/setgroup 123456789 reseller", groupNames()))
+		return
+	}
+
+	userID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		b.sendMessage(message.Chat.ID, "❌ User ID harus berupa angka!")
+		return
+	}
+	group, ok := models.ParseCustomerGroup(args[1])
+	if !ok {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Grup tidak dikenal! Pilih salah satu: %s", groupNames()))
+		return
+	}
+
+	found, err := b.db.SetCustomerGroup(userID, group)
+	if err != nil {
+		logrus.Errorf("Failed to set customer group of user %d: %v", userID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal mengubah grup pelanggan!")
+		return
+	}
+	if !found {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ User %d belum pernah memakai bot!", userID))
+		return
+	}
+
+	logrus.Infof("Admin %d moved user %d to customer group %s", message.From.ID, userID, group)
+	b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ User %d sekarang masuk grup *%s*.", userID, group.Label()))
+
+	if group != models.CustomerRetail {
+		b.sendMessage(userID, fmt.Sprintf("🎉 Akun kamu sekarang terdaftar sebagai *%s*!\n\nHarga khusus %s otomatis berlaku di katalog dan keranjang. Ketik /catalog untuk melihat harga barumu.",
+			group.Label(), group.Label()))
+	}
+}
+
+// processAddTierCommand handles /addtier GRUP PRODUK_ID|semua MIN_QTY HARGA|N%
+func (b *Bot) processAddTierCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	usage := fmt.Sprintf(`❌ Format salah!
+
+Gunakan:
+/addtier [%s] [PRODUK_ID|semua] [MIN_QTY] [HARGA|N%%]
+
+HARGA adalah harga satuan tetap, N%% adalah diskon dari harga normal.
+
+Contoh:
+/addtier reseller semua 1 10%%
+/addtier reseller 3 10 35000
+/addtier vip 3 1 5%%`, groupNames())
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) != 4 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	group, ok := models.ParseCustomerGroup(args[0])
+	if !ok {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Grup tidak dikenal! Pilih salah satu: %s", groupNames()))
+		return
+	}
+
+	tier := &models.PriceTier{CustomerGroup: group}
+
+	var product *models.Product
+	if !strings.EqualFold(args[1], "semua") {
+		productID, err := strconv.Atoi(args[1])
+		if err != nil {
+			b.sendMessage(message.Chat.ID, "❌ ID produk harus berupa angka atau 'semua'!")
+			return
+		}
+		product, err = b.db.GetProduct(productID)
+		if err != nil || product == nil {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Produk dengan ID %d tidak ditemukan!", productID))
+			return
+		}
+		tier.ProductID = &product.ID
+		tier.ProductName = &product.Name
+	}
+
+	minQuantity, err := strconv.Atoi(args[2])
+	if err != nil || minQuantity <= 0 {
+		b.sendMessage(message.Chat.ID, "❌ Jumlah minimum harus berupa angka lebih dari 0!")
+		return
+	}
+	tier.MinQuantity = minQuantity
+
+	if percent, isPercent := strings.CutSuffix(args[3], "%"); isPercent {
+		tier.DiscountPercent, err = strconv.Atoi(percent)
+		if err != nil || tier.DiscountPercent < 1 || tier.DiscountPercent > 99 {
+			b.sendMessage(message.Chat.ID, "❌ Diskon persen harus antara 1 dan 99!")
+			return
+		}
+	} else {
+		tier.Price, err = strconv.Atoi(args[3])
+		if err != nil || tier.Price <= 0 {
+			b.sendMessage(message.Chat.ID, "❌ Harga harus berupa angka lebih dari 0!")
+			return
+		}
+		if product == nil {
+			b.sendMessage(message.Chat.ID, "❌ Harga tetap hanya bisa untuk satu produk, gunakan diskon persen untuk semua produk!")
+			return
+		}
+		if tier.Price >= product.Price {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Harga grup harus di bawah harga normal (%s)!",
+				models.FormatPrice(product.Price, b.config.CurrencySymbol)))
+			return
+		}
+	}
+
+	if err := b.db.CreatePriceTier(tier); err != nil {
+		logrus.Errorf("Failed to create price tier for %s: %v", group, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal membuat harga grup!")
+		return
+	}
+
+	logrus.Infof("Admin %d created price tier %d for group %s", message.From.ID, tier.ID, group)
+	b.sendMessage(message.Chat.ID, "✅ *Harga grup berhasil dibuat!*\n\n"+b.formatPriceTier(tier))
+}
+
+// processDeleteTierCommand handles /deltier ID
+func (b *Bot) processDeleteTierCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(message.CommandArguments()))
+	if err != nil {
+		b.sendMessage(message.Chat.ID, "❌ Format salah!\n\nGunakan:\n/deltier [ID]\n\nLihat ID di /tiers")
+		return
+	}
+
+	found, err := b.db.DeletePriceTier(id)
+	if err != nil {
+		logrus.Errorf("Failed to delete price tier %d: %v", id, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal menghapus harga grup!")
+		return
+	}
+	if !found {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Harga grup #%d tidak ditemukan!", id))
+		return
+	}
+
+	logrus.Infof("Admin %d deleted price tier %d", message.From.ID, id)
+	b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Harga grup #%d dihapus.", id))
+}
+
+// formatPriceTier describes a price tier in one or two lines
+func (b *Bot) formatPriceTier(tier *models.PriceTier) string {
+	target := "Semua produk"
+	if tier.ProductName != nil {
+		target = tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *tier.ProductName)
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("#%d • *%s* • %s\n", tier.ID, tier.CustomerGroup.Label(), target))
+	text.WriteString(fmt.Sprintf("   %s", tier.FormatValue(b.config.CurrencySymbol)))
+	if tier.DiscountPercent > 0 {
+		text.WriteString(" dari harga normal")
+	} else {
+		text.WriteString(" per item")
+	}
+	if tier.MinQuantity > 1 {
+		text.WriteString(fmt.Sprintf(" • min. %d item", tier.MinQuantity))
+	}
+	return text.String()
+}
+
+// handleTiersCommand handles /tiers [grup]
+func (b *Bot) handleTiersCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	var group models.CustomerGroup
+	if arg := strings.TrimSpace(message.CommandArguments()); arg != "" {
+		var ok bool
+		group, ok = models.ParseCustomerGroup(arg)
+		if !ok {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Grup tidak dikenal! Pilih salah satu: %s", groupNames()))
+			return
+		}
+	}
+
+	tiers, err := b.db.GetPriceTiers(group)
+	if err != nil {
+		logrus.Errorf("Failed to get price tiers: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat harga grup!")
+		return
+	}
+	counts, err := b.db.CountUsersByGroup()
+	if err != nil {
+		logrus.Errorf("Failed to count users by group: %v", err)
+	}
+
+	var text strings.Builder
+	text.WriteString("👥 *GRUP PELANGGAN*\n\n")
+	for _, g := range models.CustomerGroups {
+		text.WriteString(fmt.Sprintf("• %s: %d user\n", g.Label(), counts[g]))
+	}
+
+	text.WriteString("\n🏷️ *HARGA GRUP*\n\n")
+	if len(tiers) == 0 {
+		text.WriteString("Belum ada harga grup.\n")
+	}
+	for i := range tiers {
+		text.WriteString(b.formatPriceTier(&tiers[i]))
+		text.WriteString("\n\n")
+	}
+	text.WriteString("\n💡 /setgroup untuk memindahkan user ke grup\n💡 /addtier untuk menambah harga grup, /deltier untuk menghapus")
+
+	b.sendMessage(message.Chat.ID, text.String())
+}
@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// runningFlashSales returns the flash sales running right now. Errors are logged and
+// treated as no sale, so normal prices are shown instead of failing the screen.
+func (b *Bot) runningFlashSales() []models.FlashSale {
+	sales, err := b.db.GetFlashSales(true)
+	if err != nil {
+		logrus.Errorf("Failed to get running flash sales: %v", err)
+		return nil
+	}
+	return sales
+}
+
+// priceContext holds what decides a user's prices: running flash sales and their group's price tiers
+type priceContext struct {
+	sales []models.FlashSale
+	tiers []models.PriceTier
+	group models.CustomerGroup
+}
+
+// loadPriceContext loads the running flash sales and the price tiers of the user's group.
+// Errors are logged and fall back to retail prices.
+func (b *Bot) loadPriceContext(userID int64) *priceContext {
+	ctx := &priceContext{sales: b.runningFlashSales(), group: models.CustomerRetail}
+
+	group, err := b.db.GetCustomerGroup(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get customer group of user %d: %v", userID, err)
+		return ctx
+	}
+	ctx.group = group
+
+	tiers, err := b.db.GetPriceTiers(group)
+	if err != nil {
+		logrus.Errorf("Failed to get price tiers for group %s: %v", group, err)
+		return ctx
+	}
+	ctx.tiers = tiers
+	return ctx
+}
+
+// applyProduct sets the price the user pays for the given quantity of a product
+func (ctx *priceContext) applyProduct(product *models.Product, quantity int) {
+	product.ApplyFlashSale(ctx.sales)
+	product.ApplyPriceTier(ctx.tiers, ctx.group, quantity)
+}
+
+// applyCartItem sets the price the user pays for a cart line
+func (ctx *priceContext) applyCartItem(item *models.CartItem) {
+	item.ApplyFlashSale(ctx.sales)
+	item.ApplyPriceTier(ctx.tiers, ctx.group)
+}
+
+// quantityTiers returns the group's tiers for a product that only start at more than one unit
+func (ctx *priceContext) quantityTiers(productID int) []models.PriceTier {
+	var tiers []models.PriceTier
+	for _, tier := range ctx.tiers {
+		if tier.MinQuantity > 1 && tier.AppliesTo(productID, ctx.group, tier.MinQuantity) {
+			tiers = append(tiers, tier)
+		}
+	}
+	return tiers
+}
+
+// applyPricing lowers product prices to the running flash sales and the user's group prices
+func (b *Bot) applyPricing(userID int64, products []models.Product) {
+	ctx := b.loadPriceContext(userID)
+	for i := range products {
+		ctx.applyProduct(&products[i], 1)
+	}
+}
+
+// getPricedCart returns the user's cart with flash sale and group prices applied
+func (b *Bot) getPricedCart(userID int64) ([]models.CartItem, error) {
+	cartItems, err := b.db.GetCart(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := b.loadPriceContext(userID)
+	for i := range cartItems {
+		ctx.applyCartItem(&cartItems[i])
+	}
+	return cartItems, nil
+}
+
+// formatSalePrice formats a price, preceded by the struck-through original price when a sale lowered it
+func (b *Bot) formatSalePrice(lang string, price, originalPrice int) string {
+	formatted := b.formatPrice(lang, price)
+	if originalPrice > price {
+		return fmt.Sprintf("%s %s", models.Strikethrough(b.formatPrice(lang, originalPrice)), formatted)
+	}
+	return formatted
+}
+
+// formatFlashSaleNote returns a short line like "⚡ Flash Sale s/d 20/10/2026 21:00 • sisa 5"
+func (b *Bot) formatFlashSaleNote(lang string, sale *models.FlashSale) string {
+	note := b.t(lang, "pricing.flash_sale", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, sale.Name), b.i18n.FormatDateTime(lang, sale.EndsAt))
+	if remaining := sale.Remaining(); remaining >= 0 {
+		note += b.t(lang, "pricing.flash_sale_remaining", remaining)
+	}
+	return note
+}
+
+// formatPriceTierNote returns a short line like "🏷️ Harga Reseller" or "🏷️ Harga Reseller (min. 5)"
+func (b *Bot) formatPriceTierNote(lang string, tier *models.PriceTier) string {
+	note := b.t(lang, "pricing.tier", tier.CustomerGroup.Label())
+	if tier.MinQuantity > 1 {
+		note += b.t(lang, "pricing.tier_min_quantity", tier.MinQuantity)
+	}
+	return note
+}
+
+// writeCartItems writes the cart lines, showing sale and group prices next to the original price
+func (b *Bot) writeCartItems(text *strings.Builder, lang string, cartItems []models.CartItem) {
+	for _, item := range cartItems {
+		subtotal := item.ProductPrice * item.Quantity
+
+		text.WriteString(fmt.Sprintf("🔸 *%s*\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, item.ProductName)))
+		text.WriteString(b.t(lang, "cart.quantity",
+			item.Quantity,
+			b.formatSalePrice(lang, item.ProductPrice, item.OriginalPrice),
+			b.formatPrice(lang, subtotal)) + "\n")
+		if item.FlashSale != nil {
+			text.WriteString(fmt.Sprintf("   %s\n", b.formatFlashSaleNote(lang, item.FlashSale)))
+		}
+		if item.PriceTier != nil {
+			text.WriteString(fmt.Sprintf("   %s\n", b.formatPriceTierNote(lang, item.PriceTier)))
+		}
+		text.WriteString("\n")
+	}
+}
+
+// writeQuantityTiers lists the cheaper prices the user's group gets when buying more units
+func (b *Bot) writeQuantityTiers(text *strings.Builder, lang string, pricing *priceContext, product *models.Product) {
+	tiers := pricing.quantityTiers(product.ID)
+	if len(tiers) == 0 {
+		return
+	}
+
+	base := product.Price
+	if product.OriginalPrice > 0 {
+		base = product.OriginalPrice
+	}
+
+	text.WriteString(b.t(lang, "pricing.quantity_tiers", pricing.group.Label()) + "\n")
+	for _, tier := range tiers {
+		text.WriteString(b.t(lang, "pricing.quantity_tier", tier.MinQuantity, b.formatPrice(lang, tier.PriceFor(base))) + "\n")
+	}
+}
@@ -0,0 +1,808 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Product wizard steps, in the order a new product goes through them
+const (
+	wizardStepName        = "name"
+	wizardStepDescription = "description"
+	wizardStepPrice       = "price"
+	wizardStepCategory    = "category"
+	wizardStepPhoto       = "photo"
+	wizardStepDelivery    = "delivery"
+	wizardStepPreview     = "preview"
+)
+
+var productWizardSteps = []string{
+	wizardStepName, wizardStepDescription, wizardStepPrice, wizardStepCategory,
+	wizardStepPhoto, wizardStepDelivery, wizardStepPreview,
+}
+
+const (
+	adminProductsPageSize         = 8
+	maxProductNameLength          = 100
+	maxProductDescriptionLength   = 1000
+	maxDeliveryInstructionsLength = 1000
+)
+
+// handleAddProduct handles /addproduct and starts the product wizard
+func (b *Bot) handleAddProduct(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Anda tidak memiliki akses admin!")
+		return
+	}
+
+	b.startProductWizard(message.Chat.ID, message.From.ID, &models.ProductDraft{
+		Step:     wizardStepName,
+		IsActive: true,
+	})
+}
+
+// startProductWizard saves the draft as the admin's conversation and shows its current step
+func (b *Bot) startProductWizard(chatID, userID int64, draft *models.ProductDraft) {
+	b.saveProductDraft(userID, draft)
+	b.sendProductWizardStep(chatID, draft)
+}
+
+// saveProductDraft stores the draft in the admin's conversation state
+func (b *Bot) saveProductDraft(userID int64, draft *models.ProductDraft) {
+	payload, err := json.Marshal(draft)
+	if err != nil {
+		logrus.Errorf("Failed to encode product draft for user %d: %v", userID, err)
+		return
+	}
+	b.setConversation(userID, models.StateProductWizard, string(payload))
+}
+
+// loadProductDraft returns the admin's product draft, or nil when they are not in the wizard
+func (b *Bot) loadProductDraft(userID int64) *models.ProductDraft {
+	conv := b.getConversation(userID)
+	if conv == nil || conv.State != models.StateProductWizard {
+		return nil
+	}
+	return decodeProductDraft(conv.Payload)
+}
+
+// decodeProductDraft parses a conversation payload, returning nil if it is not a valid draft
+func decodeProductDraft(payload string) *models.ProductDraft {
+	var draft models.ProductDraft
+	if err := json.Unmarshal([]byte(payload), &draft); err != nil {
+		logrus.Errorf("Invalid product draft payload: %v", err)
+		return nil
+	}
+	return &draft
+}
+
+// advanceProductWizard moves the draft to its next step, or back to the preview after editing one field
+func (b *Bot) advanceProductWizard(chatID, userID int64, draft *models.ProductDraft) {
+	next := wizardStepPreview
+	if !draft.Editing {
+		for i, step := range productWizardSteps {
+			if step == draft.Step && i+1 < len(productWizardSteps) {
+				next = productWizardSteps[i+1]
+				break
+			}
+		}
+	}
+	draft.Step = next
+	b.startProductWizard(chatID, userID, draft)
+}
+
+// productCategory returns the store category with the given name
+func (b *Bot) productCategory(name string) (models.ProductCategory, bool) {
+	categories, err := b.db.GetCategories()
+	if err != nil {
+		logrus.Errorf("Failed to get categories: %v", err)
+	}
+	for _, cat := range categories {
+		if strings.EqualFold(cat.Name, name) {
+			return cat, true
+		}
+	}
+	return models.ProductCategory{}, false
+}
+
+// productCategoryLabel returns the display name of a category, or its name if it is unknown
+func (b *Bot) productCategoryLabel(name string) string {
+	if cat, ok := b.productCategory(name); ok {
+		return cat.DisplayName
+	}
+	return name
+}
+
+// sendProductWizardStep asks for the draft's current field, or shows the preview
+func (b *Bot) sendProductWizardStep(chatID int64, draft *models.ProductDraft) {
+	if draft.Step == wizardStepPreview {
+		text, keyboard := b.buildProductDraftPreview(draft)
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = keyboard
+		b.send(msg)
+		return
+	}
+
+	var text strings.Builder
+	if draft.Editing {
+		text.WriteString("✏️ *EDIT PRODUK*\n\n")
+	} else {
+		for i, step := range productWizardSteps {
+			if step == draft.Step {
+				text.WriteString(fmt.Sprintf("➕ *TAMBAH PRODUK* — Langkah %d/%d\n\n", i+1, len(productWizardSteps)-1))
+				break
+			}
+		}
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	optional := false
+	hasValue := false
+
+	switch draft.Step {
+	case wizardStepName:
+		text.WriteString(fmt.Sprintf("📱 Ketik *nama produk* (maks. %d karakter).", maxProductNameLength))
+		if draft.Name != "" {
+			text.WriteString(fmt.Sprintf("\n\nSaat ini: %s", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, draft.Name)))
+		}
+	case wizardStepDescription:
+		text.WriteString(fmt.Sprintf("📝 Ketik *deskripsi produk* (maks. %d karakter).", maxProductDescriptionLength))
+		if draft.Description != "" {
+			text.WriteString(fmt.Sprintf("\n\nSaat ini: %s", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, draft.Description)))
+		}
+	case wizardStepPrice:
+		text.WriteString("💰 Ketik *harga jual* dalam Rupiah, contoh: 25000")
+		if draft.Price > 0 {
+			text.WriteString(fmt.Sprintf("\n\nSaat ini: %s", models.FormatPrice(draft.Price, b.config.CurrencySymbol)))
+		}
+	case wizardStepCategory:
+		text.WriteString("📂 Pilih *kategori* produk:")
+		categories, err := b.db.GetCategories()
+		if err != nil {
+			logrus.Errorf("Failed to get categories: %v", err)
+		}
+		var row []tgbotapi.InlineKeyboardButton
+		for _, cat := range categories {
+			label := cat.DisplayName
+			if cat.Name == draft.Category {
+				label = "✅ " + label
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, "pwiz:cat:"+cat.Name))
+			if len(row) == 2 {
+				rows = append(rows, row)
+				row = nil
+			}
+		}
+		if len(row) > 0 {
+			rows = append(rows, row)
+		}
+	case wizardStepPhoto:
+		text.WriteString("🖼️ Kirim satu atau beberapa *foto produk* (foto pertama jadi foto utama), atau ketik URL gambar. Tekan Lewati jika tidak ada foto.")
+		if draft.ProductID != 0 {
+			text.WriteString("\n\nFoto yang sudah tersimpan dikelola lewat tombol 🖼️ Foto di detail produk.")
+		}
+		optional = true
+		hasValue = draft.ImageURL != "" || len(draft.Photos) > 0
+		if hasValue {
+			text.WriteString("\n\nSaat ini: " + b.draftPhotoSummary(draft))
+		}
+	case wizardStepDelivery:
+		text.WriteString("📌 Ketik *petunjuk pengiriman* yang dikirim ke pembeli bersama akun/kode, contoh: cara login atau masa garansi. Tekan Lewati jika tidak perlu.")
+		optional = true
+		hasValue = draft.DeliveryInstructions != ""
+		if hasValue {
+			text.WriteString(fmt.Sprintf("\n\nSaat ini: %s", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, draft.DeliveryInstructions)))
+		}
+	}
+
+	if optional {
+		skip := "⏭️ Lewati"
+		if len(draft.Photos) > 0 && draft.Step == wizardStepPhoto {
+			skip = "➡️ Lanjut"
+		}
+		row := tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(skip, "pwiz:skip"))
+		if draft.Editing && hasValue {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("🗑️ Hapus", "pwiz:clear"))
+		}
+		rows = append(rows, row)
+	}
+	if draft.Editing {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👀 Kembali ke Preview", "pwiz:preview"),
+		))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("❌ Batal", "pwiz:cancel"),
+	))
+
+	msg := tgbotapi.NewMessage(chatID, text.String())
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	b.send(msg)
+}
+
+// buildProductDraftPreview returns the draft as it will be saved, with buttons to edit each field
+func (b *Bot) buildProductDraftPreview(draft *models.ProductDraft) (string, tgbotapi.InlineKeyboardMarkup) {
+	var text strings.Builder
+	if draft.ProductID == 0 {
+		text.WriteString("👀 *PREVIEW PRODUK BARU*\n\n")
+	} else {
+		text.WriteString(fmt.Sprintf("👀 *PREVIEW PRODUK #%d*\n\n", draft.ProductID))
+	}
+	text.WriteString(fmt.Sprintf("📱 *Nama:* %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, draft.Name)))
+	text.WriteString(fmt.Sprintf("📝 *Deskripsi:* %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, draft.Description)))
+	text.WriteString(fmt.Sprintf("💰 *Harga:* %s\n", models.FormatPrice(draft.Price, b.config.CurrencySymbol)))
+	text.WriteString(fmt.Sprintf("📂 *Kategori:* %s\n", b.productCategoryLabel(draft.Category)))
+	text.WriteString(fmt.Sprintf("🖼️ *Foto:* %s\n", b.draftPhotoSummary(draft)))
+	if draft.DeliveryInstructions != "" {
+		text.WriteString(fmt.Sprintf("📌 *Petunjuk Pengiriman:* %s\n",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, draft.DeliveryInstructions)))
+	} else {
+		text.WriteString("📌 *Petunjuk Pengiriman:* —\n")
+	}
+	status := "🟢 Aktif (tampil di katalog)"
+	toggle := "🔴 Nonaktifkan"
+	if !draft.IsActive {
+		status = "🔴 Nonaktif (disembunyikan)"
+		toggle = "🟢 Aktifkan"
+	}
+	text.WriteString(fmt.Sprintf("⚙️ *Status:* %s\n\n", status))
+	text.WriteString("Periksa kembali, ubah bila perlu, lalu tekan 💾 Simpan.")
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Nama", "pwiz:edit:"+wizardStepName),
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Deskripsi", "pwiz:edit:"+wizardStepDescription),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Harga", "pwiz:edit:"+wizardStepPrice),
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Kategori", "pwiz:edit:"+wizardStepCategory),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🖼️ Foto", "pwiz:edit:"+wizardStepPhoto),
+			tgbotapi.NewInlineKeyboardButtonData("📌 Petunjuk", "pwiz:edit:"+wizardStepDelivery),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(toggle, "pwiz:active"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("💾 Simpan", "pwiz:save"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Batal", "pwiz:cancel"),
+		),
+	)
+	return text.String(), keyboard
+}
+
+// draftPhotoSummary describes the photos a draft will have once saved
+func (b *Bot) draftPhotoSummary(draft *models.ProductDraft) string {
+	var notes []string
+	if draft.ProductID != 0 {
+		photos, err := b.db.GetProductPhotos(draft.ProductID)
+		if err != nil {
+			logrus.Errorf("Failed to get photos of product %d: %v", draft.ProductID, err)
+		}
+		if len(photos) > 0 {
+			notes = append(notes, fmt.Sprintf("%d tersimpan", len(photos)))
+		}
+	}
+	if len(draft.Photos) > 0 {
+		notes = append(notes, fmt.Sprintf("%d foto baru", len(draft.Photos)))
+	}
+	if draft.ImageURL != "" {
+		notes = append(notes, "URL gambar")
+	}
+	if len(notes) == 0 {
+		return "—"
+	}
+	return strings.Join(notes, " + ")
+}
+
+// parsePriceInput parses a typed price such as "25000", "25.000" or "Rp 25.000"
+func parsePriceInput(input string) (int, error) {
+	cleaned := strings.TrimSpace(input)
+	cleaned = strings.TrimPrefix(strings.TrimPrefix(cleaned, "Rp"), "rp")
+	cleaned = strings.NewReplacer(".", "", ",", "", " ", "").Replace(cleaned)
+	return strconv.Atoi(cleaned)
+}
+
+// processProductWizardInput handles a message typed (or photo sent) during the product wizard
+func (b *Bot) processProductWizardInput(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.takeConversation(message.From.ID, models.StateProductWizard)
+		return
+	}
+
+	// Reload the draft under the lock so photos of one album don't overwrite each other
+	b.wizardMu.Lock()
+	defer b.wizardMu.Unlock()
+
+	draft := b.loadProductDraft(message.From.ID)
+	if draft == nil {
+		b.takeConversation(message.From.ID, models.StateProductWizard)
+		b.sendMessage(message.Chat.ID, "❌ Data wizard produk rusak. Mulai lagi dengan /addproduct")
+		return
+	}
+
+	input := strings.TrimSpace(message.Text)
+	if input == "" && draft.Step != wizardStepPhoto {
+		b.sendMessage(message.Chat.ID, "❌ Kirim jawaban berupa teks, atau /batal untuk keluar dari wizard.")
+		return
+	}
+
+	switch draft.Step {
+	case wizardStepName:
+		if len([]rune(input)) > maxProductNameLength {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Nama terlalu panjang (maks. %d karakter). Ketik ulang:", maxProductNameLength))
+			return
+		}
+		draft.Name = input
+	case wizardStepDescription:
+		if len([]rune(input)) > maxProductDescriptionLength {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Deskripsi terlalu panjang (maks. %d karakter). Ketik ulang:", maxProductDescriptionLength))
+			return
+		}
+		draft.Description = input
+	case wizardStepPrice:
+		price, err := parsePriceInput(input)
+		if err != nil || price <= 0 {
+			b.sendMessage(message.Chat.ID, "❌ Harga tidak valid. Ketik angka lebih dari 0, contoh: 25000")
+			return
+		}
+		draft.Price = price
+	case wizardStepCategory:
+		// Accept a typed category name as well as the picker buttons
+		cat, ok := b.productCategory(input)
+		if !ok {
+			b.sendProductWizardStep(message.Chat.ID, draft)
+			return
+		}
+		draft.Category = cat.Name
+	case wizardStepPhoto:
+		switch {
+		case len(message.Photo) > 0:
+			// Stay on this step so more photos can follow; the largest size comes last
+			draft.Photos = append(draft.Photos, message.Photo[len(message.Photo)-1].FileID)
+			b.saveProductDraft(message.From.ID, draft)
+
+			msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf("✅ Foto ke-%d diterima. Kirim foto lain, atau tekan ➡️ Lanjut.", len(draft.Photos)))
+			msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+				tgbotapi.NewInlineKeyboardRow(
+					tgbotapi.NewInlineKeyboardButtonData("➡️ Lanjut", "pwiz:skip"),
+					tgbotapi.NewInlineKeyboardButtonData("❌ Batal", "pwiz:cancel"),
+				),
+			)
+			b.send(msg)
+			return
+		case strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://"):
+			draft.ImageURL = input
+		default:
+			b.sendMessage(message.Chat.ID, "❌ Kirim foto atau URL gambar (http/https), atau tekan Lewati.")
+			return
+		}
+	case wizardStepDelivery:
+		if len([]rune(input)) > maxDeliveryInstructionsLength {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Petunjuk terlalu panjang (maks. %d karakter). Ketik ulang:", maxDeliveryInstructionsLength))
+			return
+		}
+		draft.DeliveryInstructions = input
+	default:
+		// Waiting on the preview buttons; show them again
+		b.sendProductWizardStep(message.Chat.ID, draft)
+		return
+	}
+
+	b.advanceProductWizard(message.Chat.ID, message.From.ID, draft)
+}
+
+// handleProductWizardCallback handles the wizard buttons ("pwiz:<action>[:arg]")
+func (b *Bot) handleProductWizardCallback(callback *tgbotapi.CallbackQuery, action, arg string) {
+	if !b.config.IsAdmin(callback.From.ID) {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Akses ditolak"))
+		return
+	}
+
+	chatID := callback.Message.Chat.ID
+	userID := callback.From.ID
+
+	b.wizardMu.Lock()
+	defer b.wizardMu.Unlock()
+
+	draft := b.loadProductDraft(userID)
+	if draft == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "⏰ Sesi wizard sudah berakhir. Mulai lagi dengan /addproduct"))
+		return
+	}
+
+	switch action {
+	case "cat":
+		cat, ok := b.productCategory(arg)
+		if draft.Step != wizardStepCategory || !ok {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Kategori tidak valid"))
+			return
+		}
+		draft.Category = cat.Name
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.closeWizardPrompt(callback, "📂 Kategori: "+cat.DisplayName)
+		b.advanceProductWizard(chatID, userID, draft)
+	case "skip":
+		if draft.Step != wizardStepPhoto && draft.Step != wizardStepDelivery {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Langkah ini wajib diisi"))
+			return
+		}
+		note := "⏭️ Dilewati"
+		if draft.Step == wizardStepPhoto && len(draft.Photos) > 0 {
+			note = fmt.Sprintf("🖼️ %d foto ditambahkan", len(draft.Photos))
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.closeWizardPrompt(callback, note)
+		b.advanceProductWizard(chatID, userID, draft)
+	case "clear":
+		switch draft.Step {
+		case wizardStepPhoto:
+			draft.ImageURL = ""
+			draft.Photos = nil
+		case wizardStepDelivery:
+			draft.DeliveryInstructions = ""
+		default:
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Langkah ini wajib diisi"))
+			return
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "🗑️ Dihapus"))
+		b.closeWizardPrompt(callback, "🗑️ Dihapus")
+		b.advanceProductWizard(chatID, userID, draft)
+	case "edit":
+		valid := false
+		for _, step := range productWizardSteps {
+			if step == arg && step != wizardStepPreview {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Aksi tidak dikenal"))
+			return
+		}
+		draft.Step = arg
+		draft.Editing = true
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.startProductWizard(chatID, userID, draft)
+	case "preview":
+		draft.Step = wizardStepPreview
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.startProductWizard(chatID, userID, draft)
+	case "active":
+		draft.IsActive = !draft.IsActive
+		b.saveProductDraft(userID, draft)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+
+		text, keyboard := b.buildProductDraftPreview(draft)
+		edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, text)
+		edit.ParseMode = tgbotapi.ModeMarkdown
+		edit.ReplyMarkup = &keyboard
+		b.send(edit)
+	case "save":
+		if _, ok := b.takeConversation(userID, models.StateProductWizard); !ok {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "⏰ Sesi wizard sudah berakhir. Mulai lagi dengan /addproduct"))
+			return
+		}
+		b.saveProductDraftToStore(callback, draft)
+	case "cancel":
+		b.takeConversation(userID, models.StateProductWizard)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.closeWizardPrompt(callback, "❌ Wizard produk dibatalkan. Tidak ada perubahan yang disimpan.")
+	default:
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Aksi tidak dikenal"))
+	}
+}
+
+// closeWizardPrompt replaces an answered wizard message with a short note so its buttons can't be pressed again
+func (b *Bot) closeWizardPrompt(callback *tgbotapi.CallbackQuery, note string) {
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, note)
+	b.send(edit)
+}
+
+// saveProductDraftToStore creates or updates the product from a finished draft
+func (b *Bot) saveProductDraftToStore(callback *tgbotapi.CallbackQuery, draft *models.ProductDraft) {
+	if draft.Name == "" || draft.Description == "" || draft.Price <= 0 || draft.Category == "" {
+		// Only reachable with an incomplete draft; keep it so nothing typed is lost
+		b.saveProductDraft(callback.From.ID, draft)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Nama, deskripsi, harga & kategori wajib diisi"))
+		return
+	}
+
+	product := &models.Product{}
+	if draft.ProductID != 0 {
+		existing, err := b.db.GetProductAnyStatus(draft.ProductID)
+		if err != nil {
+			logrus.Errorf("Failed to get product %d: %v", draft.ProductID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal menyimpan produk"))
+			return
+		}
+		if existing == nil {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Produk sudah dihapus"))
+			return
+		}
+		product = existing
+	}
+
+	product.Name = draft.Name
+	product.Description = draft.Description
+	product.Price = draft.Price
+	product.Category = draft.Category
+	product.IsActive = draft.IsActive
+	product.ImageURL = nil
+	if draft.ImageURL != "" {
+		product.ImageURL = &draft.ImageURL
+	}
+	product.DeliveryInstructions = nil
+	if draft.DeliveryInstructions != "" {
+		product.DeliveryInstructions = &draft.DeliveryInstructions
+	}
+
+	var err error
+	if draft.ProductID == 0 {
+		err = b.db.CreateProduct(product)
+	} else {
+		err = b.db.UpdateProduct(product)
+	}
+	if err != nil {
+		logrus.Errorf("Failed to save product %q: %v", product.Name, err)
+		b.saveProductDraft(callback.From.ID, draft)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal menyimpan produk, coba lagi"))
+		return
+	}
+
+	for _, fileID := range draft.Photos {
+		if _, err := b.storeProductPhoto(product.ID, fileID); err != nil {
+			logrus.Errorf("Failed to store photo for product %d: %v", product.ID, err)
+		}
+	}
+
+	if draft.ProductID == 0 {
+		logrus.Infof("Admin %d created product %d (%s)", callback.From.ID, product.ID, product.Name)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Produk dibuat"))
+	} else {
+		logrus.Infof("Admin %d updated product %d (%s)", callback.From.ID, product.ID, product.Name)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Produk diperbarui"))
+	}
+
+	b.editAdminProductDetail(callback, product)
+}
+
+// handleProductManagement lists products for admins, inactive ones included, with a button per product
+func (b *Bot) handleProductManagement(callback *tgbotapi.CallbackQuery, page int) {
+	if !b.config.IsAdmin(callback.From.ID) {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Akses ditolak"))
+		return
+	}
+
+	products, err := b.db.GetAdminProducts(adminProductsPageSize+1, page*adminProductsPageSize)
+	if err != nil {
+		logrus.Errorf("Failed to get products: %v", err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat produk"))
+		return
+	}
+	hasNext := len(products) > adminProductsPageSize
+	if hasNext {
+		products = products[:adminProductsPageSize]
+	}
+
+	var text strings.Builder
+	text.WriteString("📦 *KELOLA PRODUK*\n\n")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if len(products) == 0 {
+		text.WriteString("Belum ada produk. Tekan ➕ Tambah Produk untuk membuat produk pertama.")
+	} else {
+		text.WriteString("Pilih produk untuk melihat detail, mengedit, menonaktifkan atau menghapusnya.\n\n")
+		text.WriteString("✅ stok aman • ⚠️ stok menipis • ❌ stok habis • 🔴 nonaktif")
+		for _, product := range products {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(
+					fmt.Sprintf("%s %s • %s", adminProductStatusIcon(&product), product.Name,
+						models.FormatPrice(product.Price, b.config.CurrencySymbol)),
+					fmt.Sprintf("admin:product:%d", product.ID),
+				),
+			))
+		}
+	}
+
+	var nav []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("⬅️ Sebelumnya", fmt.Sprintf("admin:products:%d", page-1)))
+	}
+	if hasNext {
+		nav = append(nav, tgbotapi.NewInlineKeyboardButtonData("Berikutnya ➡️", fmt.Sprintf("admin:products:%d", page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("➕ Tambah Produk", "admin:addproduct"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📊 Kelola Stok", "admin:stock"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
+		),
+	)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+
+	b.send(edit)
+}
+
+// adminProductStatusIcon returns the stock status icon of a product, or 🔴 when it is inactive
+func adminProductStatusIcon(product *models.Product) string {
+	switch {
+	case !product.IsActive:
+		return "🔴"
+	case product.IsGiftCard():
+		// Gift card codes are issued on purchase, so they never run out
+		return "✅"
+	case product.Stock == 0:
+		return "❌"
+	case product.Stock <= 5:
+		return "⚠️"
+	default:
+		return "✅"
+	}
+}
+
+// handleAdminProductAction handles the per-product admin buttons ("admin:<action>:<productID>")
+func (b *Bot) handleAdminProductAction(callback *tgbotapi.CallbackQuery, action string, productID int) {
+	product, err := b.db.GetProductAnyStatus(productID)
+	if err != nil {
+		logrus.Errorf("Failed to get product %d: %v", productID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat produk"))
+		return
+	}
+	if product == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Produk tidak ditemukan"))
+		return
+	}
+
+	switch action {
+	case "product":
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.editAdminProductDetail(callback, product)
+	case "editproduct":
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		draft := &models.ProductDraft{
+			ProductID:   product.ID,
+			Step:        wizardStepPreview,
+			Editing:     true,
+			Name:        product.Name,
+			Description: product.Description,
+			Price:       product.Price,
+			Category:    product.Category,
+			IsActive:    product.IsActive,
+		}
+		if product.ImageURL != nil {
+			draft.ImageURL = *product.ImageURL
+		}
+		if product.DeliveryInstructions != nil {
+			draft.DeliveryInstructions = *product.DeliveryInstructions
+		}
+		b.startProductWizard(callback.Message.Chat.ID, callback.From.ID, draft)
+	case "photos":
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.handleAdminProductPhotos(callback, product)
+	case "addphoto":
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.startProductPhotoUpload(callback, product)
+	case "photosdone":
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.finishProductPhotoUpload(callback, product)
+	case "toggleproduct":
+		if err := b.db.SetProductActive(product.ID, !product.IsActive); err != nil {
+			logrus.Errorf("Failed to toggle product %d: %v", product.ID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal mengubah status produk"))
+			return
+		}
+		product.IsActive = !product.IsActive
+		logrus.Infof("Admin %d set product %d active=%t", callback.From.ID, product.ID, product.IsActive)
+		if product.IsActive {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "🟢 Produk diaktifkan"))
+		} else {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "🔴 Produk dinonaktifkan"))
+		}
+		b.editAdminProductDetail(callback, product)
+	case "deleteproduct":
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		text := fmt.Sprintf("🗑️ *HAPUS PRODUK*\n\nYakin ingin menghapus *%s*?\n\nProduk hilang dari katalog dan daftar admin. Riwayat pesanan tetap tersimpan.",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name))
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("✅ Ya, Hapus", fmt.Sprintf("admin:confirmdeleteproduct:%d", product.ID)),
+				tgbotapi.NewInlineKeyboardButtonData("🔙 Batal", fmt.Sprintf("admin:product:%d", product.ID)),
+			),
+		)
+		edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+		edit.ParseMode = tgbotapi.ModeMarkdown
+		edit.ReplyMarkup = &keyboard
+		b.send(edit)
+	case "confirmdeleteproduct":
+		if err := b.db.DeleteProduct(product.ID); err != nil {
+			logrus.Errorf("Failed to delete product %d: %v", product.ID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal menghapus produk"))
+			return
+		}
+		logrus.Infof("Admin %d deleted product %d (%s)", callback.From.ID, product.ID, product.Name)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Produk dihapus"))
+		b.handleProductManagement(callback, 0)
+	}
+}
+
+// editAdminProductDetail shows a product's admin card with its edit buttons
+func (b *Bot) editAdminProductDetail(callback *tgbotapi.CallbackQuery, product *models.Product) {
+	available, err := b.db.GetAvailableAccountCount(product.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get available accounts for product %d: %v", product.ID, err)
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📦 *PRODUK #%d*\n\n", product.ID))
+	text.WriteString(fmt.Sprintf("📱 *Nama:* %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+	text.WriteString(fmt.Sprintf("📝 *Deskripsi:* %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Description)))
+	text.WriteString(fmt.Sprintf("💰 *Harga:* %s\n", models.FormatPrice(product.Price, b.config.CurrencySymbol)))
+	text.WriteString(fmt.Sprintf("📂 *Kategori:* %s\n", b.productCategoryLabel(product.Category)))
+	text.WriteString(fmt.Sprintf("📊 *Stok tersedia:* %d\n", available))
+	photos, err := b.db.GetProductPhotos(product.ID)
+	if err != nil {
+		logrus.Errorf("Failed to get photos of product %d: %v", product.ID, err)
+	}
+	switch {
+	case len(photos) > 0:
+		text.WriteString(fmt.Sprintf("🖼️ *Foto:* %d\n", len(photos)))
+	case product.ImageURL != nil && *product.ImageURL != "":
+		text.WriteString("🖼️ *Foto:* URL gambar\n")
+	default:
+		text.WriteString("🖼️ *Foto:* — (memakai gambar default)\n")
+	}
+	if product.DeliveryInstructions != nil {
+		text.WriteString(fmt.Sprintf("📌 *Petunjuk Pengiriman:* %s\n",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *product.DeliveryInstructions)))
+	}
+
+	toggle := "🔴 Nonaktifkan"
+	if product.IsActive {
+		text.WriteString("⚙️ *Status:* 🟢 Aktif\n")
+	} else {
+		text.WriteString("⚙️ *Status:* 🔴 Nonaktif\n")
+		toggle = "🟢 Aktifkan"
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Edit Produk", fmt.Sprintf("admin:editproduct:%d", product.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(toggle, fmt.Sprintf("admin:toggleproduct:%d", product.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🖼️ Foto (%d)", len(photos)), fmt.Sprintf("admin:photos:%d", product.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🗑️ Hapus Produk", fmt.Sprintf("admin:deleteproduct:%d", product.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔙 Kelola Produk", "admin:products"),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
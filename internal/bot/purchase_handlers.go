@@ -0,0 +1,310 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+	"telegram-premium-store/internal/templates"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// purchasedOrdersPageSize is the number of orders on one /produkku page
+const purchasedOrdersPageSize = 5
+
+// handleMyProductsCommand handles /produkku, listing the orders whose items the user can view again
+func (b *Bot) handleMyProductsCommand(message *tgbotapi.Message) {
+	text, keyboard, err := b.buildPurchasedOrders(message.From.ID, 0)
+	if err != nil {
+		logrus.Errorf("Failed to build purchased items of user %d: %v", message.From.ID, err)
+		b.sendMessage(message.Chat.ID, b.t(b.userLanguage(message.From.ID), "purchases.load_failed"))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
+
+// handleMyProductsCallback shows a page of /produkku
+func (b *Bot) handleMyProductsCallback(callback *tgbotapi.CallbackQuery, page int) {
+	text, keyboard, err := b.buildPurchasedOrders(callback.From.ID, page)
+	if err != nil {
+		logrus.Errorf("Failed to build purchased items of user %d: %v", callback.From.ID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(b.userLanguage(callback.From.ID), "purchases.load_failed")))
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// buildPurchasedOrders renders one page of the user's orders with delivered items
+func (b *Bot) buildPurchasedOrders(userID int64, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	lang := b.userLanguage(userID)
+	orders, err := b.db.GetUserPurchasedOrders(userID, purchasedOrdersPageSize+1, page*purchasedOrdersPageSize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	hasNext := len(orders) > purchasedOrdersPageSize
+	if hasNext {
+		orders = orders[:purchasedOrdersPageSize]
+	}
+
+	mainMenuRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
+	)
+
+	if len(orders) == 0 {
+		return b.t(lang, "purchases.empty"), tgbotapi.NewInlineKeyboardMarkup(mainMenuRow), nil
+	}
+
+	var text strings.Builder
+	text.WriteString(b.t(lang, "purchases.title") + "\n\n")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, order := range orders {
+		text.WriteString(b.tn(lang, "purchases.order_line", order.ItemCount,
+			shortOrderID(order.OrderID),
+			b.i18n.FormatDate(lang, order.PurchasedAt),
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, strings.Join(order.ProductNames, ", ")),
+			order.ItemCount) + "\n\n")
+
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				b.tn(lang, "purchases.order_button", order.ItemCount, shortOrderID(order.OrderID), order.ItemCount),
+				"myp_order:"+order.OrderID),
+		))
+	}
+
+	navRow := b.pageNavRow(lang, page, hasNext, func(p int) string {
+		return fmt.Sprintf("myp:%d", p)
+	})
+	if len(navRow) > 0 {
+		keyboard = append(keyboard, navRow)
+	}
+	keyboard = append(keyboard, mainMenuRow)
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// isPaidUserOrder reports whether an order belongs to the user and has been paid. Items are assigned when the
+// order is created, so they must not be shown before payment or after the order was cancelled.
+func (b *Bot) isPaidUserOrder(userID int64, orderID string) (bool, error) {
+	order, err := b.db.GetOrder(orderID)
+	if err != nil {
+		return false, err
+	}
+	return order != nil && order.UserID == userID && order.PaymentStatus == models.PaymentStatusPaid, nil
+}
+
+// userOrderItems returns the items delivered to the user in an order, or none if the order is not theirs or unpaid
+func (b *Bot) userOrderItems(userID int64, orderID string) ([]models.SoldAccount, error) {
+	if paid, err := b.isPaidUserOrder(userID, orderID); err != nil || !paid {
+		return nil, err
+	}
+
+	accounts, err := b.db.GetProductAccountsForOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []models.SoldAccount
+	for _, account := range accounts {
+		if account.UserID == userID {
+			items = append(items, account)
+		}
+	}
+	return items, nil
+}
+
+// soldAccountStatusNote returns why an item can no longer be revealed, or "" if it can
+func (b *Bot) soldAccountStatusNote(lang string, account *models.SoldAccount) string {
+	switch account.Status {
+	case models.SoldAccountReplaced:
+		return b.t(lang, "purchases.item_replaced")
+	case models.SoldAccountRefunded:
+		return b.t(lang, "purchases.item_refunded")
+	default:
+		return ""
+	}
+}
+
+// handlePurchasedOrderCallback lists the items of one order with a reveal button per item
+func (b *Bot) handlePurchasedOrderCallback(callback *tgbotapi.CallbackQuery, orderID string) {
+	lang := b.userLanguage(callback.From.ID)
+	items, err := b.userOrderItems(callback.From.ID, orderID)
+	if err != nil {
+		logrus.Errorf("Failed to get items of order %s: %v", orderID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.load_failed")))
+		return
+	}
+	if len(items) == 0 {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.not_found")))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(b.t(lang, "purchases.order_title", shortOrderID(orderID), b.i18n.FormatDateTime(lang, items[0].SoldAt)) + "\n\n")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, item := range items {
+		text.WriteString(fmt.Sprintf("%d. %s · %s", i+1, item.GetContentLabel(),
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, item.ProductName)))
+		if note := b.soldAccountStatusNote(lang, &item); note != "" {
+			text.WriteString(fmt.Sprintf(" _(%s)_\n", note))
+			continue
+		}
+		text.WriteString("\n")
+
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			b.t(lang, "purchases.reveal_button", i+1), fmt.Sprintf("myp_reveal:%d", item.ID)))
+		if len(row) == 2 {
+			keyboard = append(keyboard, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		keyboard = append(keyboard, row)
+	}
+
+	text.WriteString("\n" + b.t(lang, "purchases.spoiler_hint"))
+	if b.config.RevealDeleteMinutes > 0 {
+		text.WriteString("\n" + b.t(lang, "purchases.auto_delete", b.config.RevealDeleteMinutes))
+	}
+
+	keyboard = append(keyboard,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "purchases.download_all"), "myp_file:"+orderID),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "purchases.back"), "myp:0"),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	edit.ReplyMarkup = &markup
+	b.send(edit)
+}
+
+// revealPurchasedItem sends one delivered item hidden in a spoiler, deleted again after RevealDeleteMinutes
+func (b *Bot) revealPurchasedItem(callback *tgbotapi.CallbackQuery, soldAccountID int) {
+	lang := b.userLanguage(callback.From.ID)
+	account, err := b.db.GetSoldAccount(soldAccountID)
+	if err != nil {
+		logrus.Errorf("Failed to get sold account %d: %v", soldAccountID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "purchases.product_load_failed")))
+		return
+	}
+	if account == nil || account.UserID != callback.From.ID {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "product.not_found")))
+		return
+	}
+	paid, err := b.isPaidUserOrder(callback.From.ID, account.OrderID)
+	if err != nil {
+		logrus.Errorf("Failed to get order %s: %v", account.OrderID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "purchases.product_load_failed")))
+		return
+	}
+	if !paid {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "product.not_found")))
+		return
+	}
+	if note := b.soldAccountStatusNote(lang, account); note != "" {
+		b.api.Request(tgbotapi.NewCallbackWithAlert(callback.ID, b.t(lang, "purchases.item_unavailable", note)))
+		return
+	}
+
+	escape := func(s string) string {
+		return templates.Escape(templates.FormatMarkdownV2, s)
+	}
+	text := fmt.Sprintf("%s · *%s*\n%s\n\n||%s||",
+		escape(account.GetContentLabel()), escape(account.ProductName),
+		escape("Order #"+shortOrderID(account.OrderID)), escape(account.FormatContent()))
+	if b.config.RevealDeleteMinutes > 0 {
+		text += "\n\n" + escape(b.t(lang, "purchases.reveal_delete", b.config.RevealDeleteMinutes))
+	}
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdownV2
+	sent, err := b.send(msg)
+	if err != nil {
+		logrus.Errorf("Failed to reveal sold account %d: %v", soldAccountID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "purchases.reveal_failed")))
+		return
+	}
+	b.scheduleRevealDeletion(sent.Chat.ID, sent.MessageID)
+
+	b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "purchases.revealed")))
+}
+
+// handlePurchasedFileCallback sends all items of an order as a text file
+func (b *Bot) handlePurchasedFileCallback(callback *tgbotapi.CallbackQuery, orderID string) {
+	lang := b.userLanguage(callback.From.ID)
+	items, err := b.userOrderItems(callback.From.ID, orderID)
+	if err != nil {
+		logrus.Errorf("Failed to get items of order %s: %v", orderID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.load_failed")))
+		return
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("%s\nOrder #%s\n", b.config.StoreName, orderID))
+	count := 0
+	lastProduct := ""
+	for _, item := range items {
+		if b.soldAccountStatusNote(lang, &item) != "" {
+			continue
+		}
+		if item.ProductName != lastProduct {
+			content.WriteString(fmt.Sprintf("\n[%s]\n", item.ProductName))
+			lastProduct = item.ProductName
+		}
+		count++
+		content.WriteString(fmt.Sprintf("%d. %s\n", count, item.FormatContent()))
+	}
+	if count == 0 {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "purchases.nothing_to_download")))
+		return
+	}
+	content.WriteString("\n" + b.t(lang, "purchases.file_notice") + "\n")
+
+	doc := tgbotapi.NewDocument(callback.Message.Chat.ID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("produk-%s.txt", shortOrderID(orderID)),
+		Bytes: []byte(content.String()),
+	})
+	doc.Caption = b.tn(lang, "purchases.file_caption", count, count, shortOrderID(orderID))
+	if b.config.RevealDeleteMinutes > 0 {
+		doc.Caption += "\n" + b.t(lang, "purchases.file_delete", b.config.RevealDeleteMinutes)
+	}
+	sent, err := b.send(doc)
+	if err != nil {
+		logrus.Errorf("Failed to send items file of order %s: %v", orderID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "purchases.file_failed")))
+		return
+	}
+	b.scheduleRevealDeletion(sent.Chat.ID, sent.MessageID)
+
+	b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "purchases.file_sent")))
+}
+
+// scheduleRevealDeletion deletes a message with revealed items once RevealDeleteMinutes have passed
+func (b *Bot) scheduleRevealDeletion(chatID int64, messageID int) {
+	if b.config.RevealDeleteMinutes <= 0 {
+		return
+	}
+	deleteAt := time.Now().Add(time.Duration(b.config.RevealDeleteMinutes) * time.Minute)
+	if err := b.db.ScheduleMessageDeletion(chatID, messageID, deleteAt); err != nil {
+		logrus.Errorf("Failed to schedule deletion of message %d: %v", messageID, err)
+	}
+}
@@ -72,5 +72,5 @@ func (b *Bot) handleQRISSetupCallback(callback *tgbotapi.CallbackQuery) {
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
\ No newline at end of file
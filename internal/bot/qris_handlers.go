@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"telegram-premium-store/internal/models"
 	"telegram-premium-store/internal/qris"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -60,7 +61,7 @@ func (b *Bot) handleQRISSetup(message *tgbotapi.Message) {
 	msg.ParseMode = tgbotapi.ModeMarkdown
 	msg.ReplyMarkup = keyboard
 
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 // handleQRISUpload handles QRIS image upload process
@@ -103,10 +104,10 @@ Kirim gambar sekarang...`
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 
-	// Set user state to waiting for QRIS upload
-	b.setUserState(callback.From.ID, "waiting_qris_upload")
+	// Wait for the QRIS image upload
+	b.setConversation(callback.From.ID, models.StateQRISUpload, "")
 }
 
 // handleQRISTest handles QRIS test generation
@@ -171,7 +172,7 @@ func (b *Bot) handleQRISTest(callback *tgbotapi.CallbackQuery) {
 	)
 	qrMsg.ReplyMarkup = keyboard
 
-	b.api.Send(qrMsg)
+	b.send(qrMsg)
 	b.api.Request(tgbotapi.NewCallback(callback.ID, "✅ Test QRIS generated"))
 }
 
@@ -228,7 +229,7 @@ func (b *Bot) handleQRISInfo(callback *tgbotapi.CallbackQuery) {
 	edit.ParseMode = tgbotapi.ModeMarkdown
 	edit.ReplyMarkup = &keyboard
 
-	b.api.Send(edit)
+	b.send(edit)
 }
 
 // handleQRISImageUpload processes uploaded QRIS image
@@ -238,14 +239,11 @@ func (b *Bot) handleQRISImageUpload(message *tgbotapi.Message) {
 		return
 	}
 
-	// Check if user is in upload state
-	if !b.isUserInState(message.From.ID, "waiting_qris_upload") {
+	// Leave the upload state, unless another message already did
+	if _, ok := b.takeConversation(message.From.ID, models.StateQRISUpload); !ok {
 		return
 	}
 
-	// Clear user state
-	b.clearUserState(message.From.ID)
-
 	// Initialize real QRIS service if not already done
 	if b.realQRISService == nil {
 		b.realQRISService = qris.NewRealQRISService(b.config)
@@ -295,7 +293,7 @@ func (b *Bot) handleQRISImageUpload(message *tgbotapi.Message) {
 	// Send processing message
 	msg := tgbotapi.NewMessage(message.Chat.ID, "🔄 Memproses QR Code... Mohon tunggu...")
 	msg.ParseMode = tgbotapi.ModeMarkdown
-	processingMsg, _ := b.api.Send(msg)
+	processingMsg, _ := b.send(msg)
 
 	// Process QRIS image
 	filename := fmt.Sprintf("qris_static_%d.jpg", message.Date)
@@ -306,7 +304,7 @@ func (b *Bot) handleQRISImageUpload(message *tgbotapi.Message) {
 		// Edit processing message with error
 		editMsg := tgbotapi.NewEditMessageText(message.Chat.ID, processingMsg.MessageID, 
 			fmt.Sprintf("❌ Gagal memproses QR Code: %s\n\n💡 Pastikan gambar berisi QR Code QRIS yang valid.", err.Error()))
-		b.api.Send(editMsg)
+		b.send(editMsg)
 		return
 	}
 
@@ -341,7 +339,7 @@ func (b *Bot) handleQRISImageUpload(message *tgbotapi.Message) {
 	editMsg.ParseMode = tgbotapi.ModeMarkdown
 	editMsg.ReplyMarkup = &keyboard
 
-	b.api.Send(editMsg)
+	b.send(editMsg)
 
 	logrus.Info("✅ QRIS static QR successfully processed by admin")
 }
@@ -394,5 +392,5 @@ func (b *Bot) handleQRISTestCommand(message *tgbotapi.Message) {
 
 	qrMsg.ParseMode = tgbotapi.ModeMarkdown
 
-	b.api.Send(qrMsg)
+	b.send(qrMsg)
 }
\ No newline at end of file
@@ -0,0 +1,257 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/config"
+	"telegram-premium-store/internal/ratelimit"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// newRateLimiter creates the per-user rate limiter from the configured per-minute limits
+func newRateLimiter(cfg *config.Config) *ratelimit.Limiter {
+	perMinute := func(n int) ratelimit.Rate {
+		return ratelimit.Rate{Burst: n, Per: time.Minute}
+	}
+	return ratelimit.New(ratelimit.Config{
+		Rates: map[ratelimit.Action]ratelimit.Rate{
+			ratelimit.ActionGeneral:  perMinute(cfg.RateLimitGeneral),
+			ratelimit.ActionCheckout: perMinute(cfg.RateLimitCheckout),
+			ratelimit.ActionQR:       perMinute(cfg.RateLimitQR),
+			ratelimit.ActionSearch:   perMinute(cfg.RateLimitSearch),
+		},
+		MuteStrikes:  cfg.RateLimitMuteStrikes,
+		StrikeWindow: time.Minute,
+		MuteDuration: time.Duration(cfg.RateLimitMuteMinutes) * time.Minute,
+	})
+}
+
+// updateActions returns who sent an update and which limits it counts against. Every update counts as
+// general; plain text searches and QRIS images are limited where they happen, since only the handler knows.
+func updateActions(update tgbotapi.Update) (int64, []ratelimit.Action) {
+	actions := []ratelimit.Action{ratelimit.ActionGeneral}
+
+	switch {
+	case update.CallbackQuery != nil:
+		switch strings.SplitN(update.CallbackQuery.Data, ":", 2)[0] {
+		case "checkout", "preorder_pay":
+			actions = append(actions, ratelimit.ActionCheckout)
+		case "search":
+			actions = append(actions, ratelimit.ActionSearch)
+		}
+		return update.CallbackQuery.From.ID, actions
+	case update.InlineQuery != nil:
+		return update.InlineQuery.From.ID, append(actions, ratelimit.ActionSearch)
+	case update.Message != nil && update.Message.From != nil:
+		switch update.Message.Command() {
+		case "cari", "search":
+			actions = append(actions, ratelimit.ActionSearch)
+		}
+		return update.Message.From.ID, actions
+	}
+	return 0, nil
+}
+
+// allowUpdate is the rate limiting middleware of handleUpdate. Throttled updates get a short reply
+// and are dropped. Admins are never limited.
+func (b *Bot) allowUpdate(update tgbotapi.Update) bool {
+	userID, actions := updateActions(update)
+	if userID == 0 || b.config.IsAdmin(userID) {
+		return true
+	}
+
+	decision := b.limiter.Allow(userID, actions...)
+	if decision.Allowed {
+		return true
+	}
+	b.logThrottled(userID, decision)
+
+	text := b.throttleMessage(b.userLanguage(userID), decision)
+	switch {
+	case update.CallbackQuery != nil:
+		// Callbacks must be answered anyway to stop the button spinner
+		b.api.Request(tgbotapi.NewCallback(update.CallbackQuery.ID, text))
+	case update.InlineQuery != nil:
+		b.api.Request(tgbotapi.InlineConfig{
+			InlineQueryID:     update.InlineQuery.ID,
+			Results:           []interface{}{},
+			CacheTime:         5,
+			IsPersonal:        true,
+			SwitchPMText:      text,
+			SwitchPMParameter: "inline",
+		})
+	case update.Message != nil:
+		// Reply once per burst, otherwise the replies would flood the chat as well
+		if decision.Notify || decision.JustMuted {
+			b.sendMessage(update.Message.Chat.ID, text)
+		}
+	}
+	return false
+}
+
+// allowAction checks a limit inside a handler, for requests the middleware cannot classify.
+// Returns the reply for the user when throttled, empty if they were already told.
+func (b *Bot) allowAction(userID int64, action ratelimit.Action) (string, bool) {
+	if b.config.IsAdmin(userID) {
+		return "", true
+	}
+	decision := b.limiter.Allow(userID, action)
+	if decision.Allowed {
+		return "", true
+	}
+	b.logThrottled(userID, decision)
+	if !decision.Notify && !decision.JustMuted {
+		return "", false
+	}
+	return b.throttleMessage(b.userLanguage(userID), decision), false
+}
+
+// logThrottled logs a throttled request; mutes are logged as warnings so they stand out
+func (b *Bot) logThrottled(userID int64, decision ratelimit.Decision) {
+	if decision.JustMuted {
+		logrus.Warnf("User %d muted for %s after repeatedly hitting the %s rate limit",
+			userID, decision.RetryAfter, decision.Action)
+		return
+	}
+	if !decision.Muted {
+		logrus.Debugf("Throttled %s request from user %d, retry in %s", decision.Action, userID, decision.RetryAfter)
+	}
+}
+
+// throttleMessage returns the reply in lang for a throttled or muted user
+func (b *Bot) throttleMessage(lang string, decision ratelimit.Decision) string {
+	wait := b.formatRetryAfter(lang, decision.RetryAfter)
+	if decision.JustMuted {
+		return b.t(lang, "ratelimit.just_muted", wait)
+	}
+	if decision.Muted {
+		return b.t(lang, "ratelimit.muted", wait)
+	}
+
+	switch decision.Action {
+	case ratelimit.ActionCheckout:
+		return b.t(lang, "ratelimit.checkout", wait)
+	case ratelimit.ActionQR:
+		return b.t(lang, "ratelimit.qr", wait)
+	case ratelimit.ActionSearch:
+		return b.t(lang, "ratelimit.search", wait)
+	default:
+		return b.t(lang, "ratelimit.general", wait)
+	}
+}
+
+// formatRetryAfter renders a wait like "12 detik" or "3 menit" in lang, rounding up
+func (b *Bot) formatRetryAfter(lang string, d time.Duration) string {
+	if d < time.Minute {
+		seconds := int((d + time.Second - 1) / time.Second)
+		return b.tn(lang, "ratelimit.seconds", seconds, seconds)
+	}
+	minutes := int((d + time.Minute - 1) / time.Minute)
+	return b.tn(lang, "ratelimit.minutes", minutes, minutes)
+}
+
+// rateLimitActionLabel returns the admin-facing name of a rate limited action
+func rateLimitActionLabel(action ratelimit.Action) string {
+	switch action {
+	case ratelimit.ActionCheckout:
+		return "Checkout"
+	case ratelimit.ActionQR:
+		return "QR pembayaran"
+	case ratelimit.ActionSearch:
+		return "Pencarian"
+	default:
+		return "Umum"
+	}
+}
+
+// handleRateLimitCommand handles the admin /ratelimit command showing throttling metrics and lifting mutes
+func (b *Bot) handleRateLimitCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) > 0 {
+		if len(args) != 2 || args[0] != "unmute" {
+			b.sendMessage(message.Chat.ID, "❌ Format salah!\n\nGunakan:\n/ratelimit - Statistik pembatasan\n/ratelimit unmute [USER ID] - Cabut bisu otomatis")
+			return
+		}
+		userID, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			b.sendMessage(message.Chat.ID, "❌ User ID tidak valid.")
+			return
+		}
+		if !b.limiter.Unmute(userID) {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("ℹ️ User `%d` sedang tidak dibisukan.", userID))
+			return
+		}
+		logrus.Infof("Admin %d unmuted user %d", message.From.ID, userID)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Bisu otomatis untuk user `%d` dicabut.", userID))
+		return
+	}
+
+	stats := b.limiter.Stats(5)
+	var text strings.Builder
+	text.WriteString("🚦 *PEMBATASAN PERMINTAAN*\n\n")
+	text.WriteString(fmt.Sprintf("Sejak bot berjalan (%s):\n", stats.Since.Format("02/01/2006 15:04")))
+	text.WriteString(fmt.Sprintf("✅ Diizinkan: %d\n", stats.Allowed))
+
+	total := 0
+	for _, action := range ratelimit.Actions {
+		total += stats.Throttled[action]
+	}
+	text.WriteString(fmt.Sprintf("⏳ Dibatasi: %d\n", total))
+	for _, action := range ratelimit.Actions {
+		text.WriteString(fmt.Sprintf("   • %s: %d\n", rateLimitActionLabel(action), stats.Throttled[action]))
+	}
+	text.WriteString(fmt.Sprintf("🔇 Bisu otomatis: %d\n", stats.Mutes))
+
+	text.WriteString("\n*Batas per menit:* ")
+	text.WriteString(fmt.Sprintf("umum %s, checkout %s, QR %s, pencarian %s\n",
+		formatRateLimit(b.config.RateLimitGeneral), formatRateLimit(b.config.RateLimitCheckout),
+		formatRateLimit(b.config.RateLimitQR), formatRateLimit(b.config.RateLimitSearch)))
+	if b.config.RateLimitMuteStrikes > 0 {
+		text.WriteString(fmt.Sprintf("Bisu %d menit setelah %d kali dibatasi dalam 1 menit\n",
+			b.config.RateLimitMuteMinutes, b.config.RateLimitMuteStrikes))
+	}
+
+	if len(stats.MutedUsers) > 0 {
+		text.WriteString("\n🔇 *Sedang dibisukan:*\n")
+		for _, muted := range stats.MutedUsers {
+			text.WriteString(fmt.Sprintf("• %s - sisa %s\n", b.rateLimitUserLabel(muted.UserID),
+				b.formatRetryAfter(b.i18n.Default(), time.Until(muted.Until))))
+		}
+	}
+	if len(stats.TopUsers) > 0 {
+		text.WriteString("\n📈 *Paling sering dibatasi:*\n")
+		for _, user := range stats.TopUsers {
+			text.WriteString(fmt.Sprintf("• %s - %d kali\n", b.rateLimitUserLabel(user.UserID), user.Throttled))
+		}
+	}
+	text.WriteString("\n💡 /ratelimit unmute [USER ID] untuk mencabut bisu otomatis")
+
+	b.sendMessage(message.Chat.ID, text.String())
+}
+
+// formatRateLimit renders a per-minute limit, where 0 means unlimited
+func formatRateLimit(perMinute int) string {
+	if perMinute <= 0 {
+		return "tanpa batas"
+	}
+	return strconv.Itoa(perMinute)
+}
+
+// rateLimitUserLabel shows a user ID with their username when known
+func (b *Bot) rateLimitUserLabel(userID int64) string {
+	label := fmt.Sprintf("`%d`", userID)
+	if user, err := b.db.GetUser(userID); err == nil && user != nil && user.Username != nil && *user.Username != "" {
+		label += " @" + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *user.Username)
+	}
+	return label
+}
@@ -0,0 +1,230 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// startLink returns the t.me link that opens the bot with /start payload
+func (b *Bot) startLink(payload string) string {
+	username := b.api.Self.UserName
+	if username == "" {
+		username = b.config.BotUsername
+	}
+	return fmt.Sprintf("https://t.me/%s?start=%s", username, payload)
+}
+
+// referralLink returns the personal /start deep link for a referral code
+func (b *Bot) referralLink(code string) string {
+	return b.startLink("ref_" + code)
+}
+
+// describeReferralReward returns a readable description of the configured referral reward
+func (b *Bot) describeReferralReward() string {
+	value := b.config.ReferralRewardValue
+	switch models.ReferralRewardType(b.config.ReferralRewardType) {
+	case models.ReferralRewardCoupon:
+		return fmt.Sprintf("kupon belanja %s (berlaku %d hari)",
+			models.FormatPrice(value, b.config.CurrencySymbol), b.config.ReferralCouponDays)
+	case models.ReferralRewardPercent:
+		return fmt.Sprintf("saldo %d%% dari pembelian pertama teman Anda", value)
+	default:
+		return fmt.Sprintf("saldo %s", models.FormatPrice(value, b.config.CurrencySymbol))
+	}
+}
+
+// handleReferralStart attributes a new user who opened someone's referral link
+func (b *Bot) handleReferralStart(message *tgbotapi.Message, code string) {
+	userID := message.From.ID
+	referrerID, err := b.db.AttributeReferral(userID, code)
+	if err != nil {
+		logrus.Errorf("Failed to attribute referral %s for user %d: %v", code, userID, err)
+		return
+	}
+	if referrerID == 0 {
+		return
+	}
+
+	b.db.LogUserInteraction(userID, "referral_joined", code)
+	logrus.Infof("User %d joined via referral of user %d", userID, referrerID)
+
+	name := tgbotapi.EscapeText(tgbotapi.ModeMarkdown, message.From.FirstName)
+	text := fmt.Sprintf("👋 *%s* bergabung lewat link referral Anda!\n\n🎁 Anda akan mendapat %s setelah teman Anda menyelesaikan pembelian pertama.",
+		name, b.describeReferralReward())
+	if b.config.ReferralRewardValue <= 0 {
+		text = fmt.Sprintf("👋 *%s* bergabung lewat link referral Anda!", name)
+	}
+
+	msg := tgbotapi.NewMessage(referrerID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := b.send(msg); err != nil {
+		logrus.Errorf("Failed to notify referrer %d: %v", referrerID, err)
+	}
+}
+
+// rewardReferrer grants the referral reward for a paid order when it is the buyer's first one
+func (b *Bot) rewardReferrer(order *models.Order) {
+	if b.config.ReferralRewardValue <= 0 {
+		return
+	}
+
+	rewardType := models.ReferralRewardType(b.config.ReferralRewardType)
+	amount := b.config.ReferralRewardValue
+	var coupon *models.Coupon
+
+	switch rewardType {
+	case models.ReferralRewardCoupon:
+		endsAt := time.Now().AddDate(0, 0, b.config.ReferralCouponDays)
+		coupon = &models.Coupon{
+			Code:          fmt.Sprintf("REF%d", order.UserID),
+			DiscountType:  models.CouponDiscountFixed,
+			DiscountValue: amount,
+			EndsAt:        &endsAt,
+			UsageLimit:    1,
+			PerUserLimit:  1,
+		}
+	case models.ReferralRewardPercent:
+		amount = (order.TotalAmount + order.BalanceUsed) * b.config.ReferralRewardValue / 100
+		if amount <= 0 {
+			return
+		}
+	default:
+		rewardType = models.ReferralRewardBalance
+	}
+
+	referrerID, err := b.db.GrantReferralReward(order.UserID, order.ID, rewardType, amount, coupon)
+	if err != nil {
+		logrus.Errorf("Failed to grant referral reward for order %s: %v", order.ID, err)
+		return
+	}
+	if referrerID == 0 {
+		return
+	}
+
+	logrus.Infof("Referral reward (%s, %d) granted to user %d for order %s", rewardType, amount, referrerID, order.ID)
+
+	var text string
+	if coupon != nil {
+		text = fmt.Sprintf("🎉 *BONUS REFERRAL!*\n\nTeman yang Anda ajak baru saja menyelesaikan pembelian pertama.\n\n🎟️ Kupon Anda: `%s`\n💸 Potongan: %s\n📅 Berlaku sampai: %s\n\nPakai dengan /kupon %s di keranjang.",
+			coupon.Code,
+			models.FormatPrice(coupon.DiscountValue, b.config.CurrencySymbol),
+			coupon.EndsAt.Format("02/01/2006"),
+			coupon.Code)
+	} else {
+		balance, _ := b.db.GetBalance(referrerID)
+		text = fmt.Sprintf("🎉 *BONUS REFERRAL!*\n\nTeman yang Anda ajak baru saja menyelesaikan pembelian pertama.\n\n💰 Bonus: +%s\n💳 Saldo Anda: %s\n\nSaldo bisa dipakai saat checkout dari keranjang.",
+			models.FormatPrice(amount, b.config.CurrencySymbol),
+			models.FormatPrice(balance, b.config.CurrencySymbol))
+	}
+
+	msg := tgbotapi.NewMessage(referrerID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	if _, err := b.send(msg); err != nil {
+		logrus.Errorf("Failed to notify referrer %d about reward: %v", referrerID, err)
+	}
+}
+
+// buildReferralText builds the referral screen with the user's link, invitees and earnings
+func (b *Bot) buildReferralText(userID int64) (string, error) {
+	code, err := b.db.GetOrCreateReferralCode(userID)
+	if err != nil {
+		return "", err
+	}
+	stats, err := b.db.GetReferralStats(userID)
+	if err != nil {
+		return "", err
+	}
+	balance, err := b.db.GetBalance(userID)
+	if err != nil {
+		return "", err
+	}
+	invitees, err := b.db.GetReferredUsers(userID, 10)
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	text.WriteString("🎁 *AJAK TEMAN, DAPAT BONUS!*\n\n")
+	if b.config.ReferralRewardValue > 0 {
+		text.WriteString(fmt.Sprintf("Bagikan link di bawah. Setiap teman baru yang menyelesaikan pembelian pertama memberi Anda %s.\n\n",
+			b.describeReferralReward()))
+	} else {
+		text.WriteString("Bagikan link di bawah untuk mengajak teman berbelanja.\n\n")
+	}
+
+	text.WriteString("🔗 *Link Anda:*\n")
+	text.WriteString(fmt.Sprintf("`%s`\n\n", b.referralLink(code)))
+
+	text.WriteString("📊 *Statistik:*\n")
+	text.WriteString(fmt.Sprintf("👥 Teman bergabung: %d\n", stats.Invited))
+	text.WriteString(fmt.Sprintf("🛍️ Sudah belanja: %d\n", stats.Converted))
+	text.WriteString(fmt.Sprintf("💰 Bonus saldo: %s\n", models.FormatPrice(stats.BalanceEarned, b.config.CurrencySymbol)))
+	if stats.CouponsEarned > 0 {
+		text.WriteString(fmt.Sprintf("🎟️ Kupon bonus: %d\n", stats.CouponsEarned))
+	}
+	text.WriteString(fmt.Sprintf("💳 Saldo saat ini: %s\n", models.FormatPrice(balance, b.config.CurrencySymbol)))
+
+	if len(invitees) > 0 {
+		text.WriteString("\n👥 *Teman Terbaru:*\n")
+		for _, invitee := range invitees {
+			status := "⏳ belum belanja"
+			if invitee.Rewarded {
+				status = "✅ sudah belanja"
+			}
+			text.WriteString(fmt.Sprintf("• %s - %s (%s)\n",
+				tgbotapi.EscapeText(tgbotapi.ModeMarkdown, invitee.DisplayName()),
+				status,
+				invitee.ReferredAt.Format("02/01/2006")))
+		}
+	}
+
+	return text.String(), nil
+}
+
+// handleReferralCommand handles /referral
+func (b *Bot) handleReferralCommand(message *tgbotapi.Message) {
+	text, err := b.buildReferralText(message.From.ID)
+	if err != nil {
+		logrus.Errorf("Failed to build referral screen for user %d: %v", message.From.ID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat info referral.")
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.DisableWebPagePreview = true
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+		),
+	)
+	b.send(msg)
+}
+
+// handleReferralCallback shows the referral screen from the main menu
+func (b *Bot) handleReferralCallback(callback *tgbotapi.CallbackQuery) {
+	text, err := b.buildReferralText(callback.From.ID)
+	if err != nil {
+		logrus.Errorf("Failed to build referral screen for user %d: %v", callback.From.ID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat info referral"))
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.DisableWebPagePreview = true
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
@@ -0,0 +1,175 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// profitPeriods lists the report periods shown in the admin panel (days, 0 = all time)
+var profitPeriods = []struct {
+	Days  int
+	Label string
+}{
+	{1, "Hari Ini"},
+	{7, "7 Hari"},
+	{30, "30 Hari"},
+	{0, "Semua"},
+}
+
+// profitPeriodLabel returns a readable label for a report period
+func profitPeriodLabel(days int) string {
+	for _, period := range profitPeriods {
+		if period.Days == days {
+			return period.Label
+		}
+	}
+	return fmt.Sprintf("%d Hari", days)
+}
+
+// profitGroupLabel returns a readable label for a report grouping
+func profitGroupLabel(groupBy models.ProfitGroupBy) string {
+	switch groupBy {
+	case models.ProfitByProduct:
+		return "Produk"
+	case models.ProfitByCategory:
+		return "Kategori"
+	case models.ProfitBySupplier:
+		return "Supplier"
+	case models.ProfitByDay:
+		return "Periode Harian"
+	case models.ProfitByGroup:
+		return "Grup Pelanggan"
+	default:
+		return string(groupBy)
+	}
+}
+
+// profitReportKeyboard builds the keyboard for switching between profit report groupings
+func profitReportKeyboard(days int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📦 Per Produk", fmt.Sprintf("admin:profit:%s:%d", models.ProfitByProduct, days)),
+			tgbotapi.NewInlineKeyboardButtonData("🏷️ Per Kategori", fmt.Sprintf("admin:profit:%s:%d", models.ProfitByCategory, days)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚚 Per Supplier", fmt.Sprintf("admin:profit:%s:%d", models.ProfitBySupplier, days)),
+			tgbotapi.NewInlineKeyboardButtonData("📅 Per Hari", fmt.Sprintf("admin:profit:%s:%d", models.ProfitByDay, days)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👥 Per Grup", fmt.Sprintf("admin:profit:%s:%d", models.ProfitByGroup, days)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
+		),
+	)
+}
+
+// buildProfitSummaryText builds the revenue, cost and profit overview for all periods
+func (b *Bot) buildProfitSummaryText() (string, error) {
+	var text strings.Builder
+	text.WriteString("📊 *STATISTIK PENJUALAN & PROFIT*\n\n")
+
+	for _, period := range profitPeriods {
+		summary, err := b.db.GetProfitSummary(period.Days)
+		if err != nil {
+			return "", err
+		}
+
+		text.WriteString(fmt.Sprintf("📅 *%s*\n", period.Label))
+		text.WriteString(fmt.Sprintf("   Terjual: %d item\n", summary.ItemsSold))
+		text.WriteString(fmt.Sprintf("   Omzet: %s\n", models.FormatPrice(summary.Revenue, b.config.CurrencySymbol)))
+		text.WriteString(fmt.Sprintf("   Modal: %s\n", models.FormatPrice(summary.Cost, b.config.CurrencySymbol)))
+		text.WriteString(fmt.Sprintf("   Profit: *%s* (%.1f%%)\n\n", models.FormatPrice(summary.Profit(), b.config.CurrencySymbol), summary.Margin()))
+	}
+
+	text.WriteString("💡 Pilih rincian laporan profit (30 hari terakhir):")
+	return text.String(), nil
+}
+
+// handleAdminStats shows the sales and profit overview
+func (b *Bot) handleAdminStats(callback *tgbotapi.CallbackQuery) {
+	text, err := b.buildProfitSummaryText()
+	if err != nil {
+		logrus.Errorf("Failed to build profit summary: %v", err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat statistik"))
+		return
+	}
+
+	keyboard := profitReportKeyboard(30)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+
+	b.send(edit)
+}
+
+// handleProfitReport shows profit and margin grouped by product, category, supplier or day
+func (b *Bot) handleProfitReport(callback *tgbotapi.CallbackQuery, groupBy models.ProfitGroupBy, days int) {
+	report, err := b.db.GetProfitReport(groupBy, days)
+	if err != nil {
+		logrus.Errorf("Failed to get profit report by %s: %v", groupBy, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat laporan"))
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📈 *LAPORAN PROFIT PER %s*\n", strings.ToUpper(profitGroupLabel(groupBy))))
+	text.WriteString(fmt.Sprintf("📅 Periode: %s\n\n", profitPeriodLabel(days)))
+
+	if len(report) == 0 {
+		text.WriteString("Belum ada penjualan pada periode ini.")
+	} else {
+		total := models.ProfitReportRow{}
+		for i, row := range report {
+			total.ItemsSold += row.ItemsSold
+			total.Revenue += row.Revenue
+			total.Cost += row.Cost
+
+			if i >= 15 { // Keep message below Telegram length limit
+				continue
+			}
+
+			text.WriteString(fmt.Sprintf("🔸 *%s*\n", row.Label))
+			text.WriteString(fmt.Sprintf("   %d item | Omzet %s | Modal %s\n",
+				row.ItemsSold,
+				models.FormatPrice(row.Revenue, b.config.CurrencySymbol),
+				models.FormatPrice(row.Cost, b.config.CurrencySymbol)))
+			text.WriteString(fmt.Sprintf("   Profit: %s (%.1f%%)\n\n",
+				models.FormatPrice(row.Profit(), b.config.CurrencySymbol), row.Margin()))
+		}
+
+		if len(report) > 15 {
+			text.WriteString(fmt.Sprintf("... dan %d lainnya\n\n", len(report)-15))
+		}
+
+		text.WriteString("━━━━━━━━━━━━━━━━━━━━━\n")
+		text.WriteString(fmt.Sprintf("💰 *Total Profit: %s* (%.1f%%)",
+			models.FormatPrice(total.Profit(), b.config.CurrencySymbol), total.Margin()))
+	}
+
+	// Period switcher for the current grouping
+	var periodRow []tgbotapi.InlineKeyboardButton
+	for _, period := range profitPeriods {
+		label := period.Label
+		if period.Days == days {
+			label = "• " + label
+		}
+		periodRow = append(periodRow, tgbotapi.NewInlineKeyboardButtonData(
+			label, fmt.Sprintf("admin:profit:%s:%d", groupBy, period.Days)))
+	}
+
+	keyboard := profitReportKeyboard(days)
+	keyboard.InlineKeyboard = append([][]tgbotapi.InlineKeyboardButton{periodRow}, keyboard.InlineKeyboard...)
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text.String())
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+
+	b.send(edit)
+}
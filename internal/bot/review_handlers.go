@@ -0,0 +1,250 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// maxReviewCommentLength caps review comments, in characters
+const maxReviewCommentLength = 500
+
+// writeProductReviews appends a product's average rating and its most recent reviews with a comment
+func (b *Bot) writeProductReviews(text *strings.Builder, lang string, productID int) {
+	rating, err := b.db.GetProductRating(productID)
+	if err != nil {
+		logrus.Errorf("Failed to get rating of product %d: %v", productID, err)
+		return
+	}
+	if rating.Count == 0 {
+		return
+	}
+	text.WriteString(b.tn(lang, "product.rating", rating.Count, b.i18n.FormatDecimal(lang, rating.Average), rating.Count) + "\n")
+
+	reviews, err := b.db.GetProductReviews(productID, 10)
+	if err != nil {
+		logrus.Errorf("Failed to get reviews of product %d: %v", productID, err)
+		return
+	}
+
+	shown := 0
+	for _, review := range reviews {
+		if review.Comment == nil || shown == 3 {
+			continue
+		}
+		if shown == 0 {
+			text.WriteString("\n" + b.t(lang, "product.reviews") + "\n")
+		}
+		text.WriteString(fmt.Sprintf("%s _%s_: %s\n", review.Stars(),
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, review.ReviewerName()),
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *review.Comment)))
+		shown++
+	}
+	text.WriteString("\n")
+}
+
+// handleRateCallback saves a rating from a review prompt ("rate:<order>:<product>:<stars>") and asks for a comment
+func (b *Bot) handleRateCallback(callback *tgbotapi.CallbackQuery, orderID string, productID, rating int) {
+	userID := callback.From.ID
+	lang := b.userLanguage(userID)
+
+	reviewID, err := b.db.SaveReview(orderID, productID, userID, rating)
+	if err != nil {
+		if strings.Contains(err.Error(), "review not allowed") {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "review.not_allowed")))
+			return
+		}
+		logrus.Errorf("Failed to save review of product %d in order %s: %v", productID, orderID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "review.save_failed")))
+		return
+	}
+
+	logrus.Infof("User %d rated product %d from order %s with %d stars", userID, productID, orderID, rating)
+	b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "review.saved", rating)))
+
+	productName := fmt.Sprintf("#%d", productID)
+	if product, err := b.db.GetProduct(productID); err == nil && product != nil {
+		productName = product.Name
+	}
+
+	b.setConversation(userID, models.StateReviewComment, strconv.Itoa(reviewID))
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.t(lang, "review.comment_prompt",
+		strings.Repeat("⭐", rating), tgbotapi.EscapeText(tgbotapi.ModeMarkdown, productName), maxReviewCommentLength))
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "review.skip"), "review_skip"),
+		),
+	)
+	b.send(msg)
+}
+
+// handleReviewSkipCallback ends the review without a comment
+func (b *Bot) handleReviewSkipCallback(callback *tgbotapi.CallbackQuery) {
+	b.takeConversation(callback.From.ID, models.StateReviewComment)
+	b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		b.t(b.userLanguage(callback.From.ID), "review.thanks_rating"))
+	b.send(edit)
+}
+
+// processReviewComment saves the comment typed after rating a product; payload is the review ID
+func (b *Bot) processReviewComment(message *tgbotapi.Message, payload string) {
+	reviewID, err := strconv.Atoi(payload)
+	if err != nil {
+		logrus.Errorf("Invalid review comment payload %q", payload)
+		return
+	}
+
+	lang := b.userLanguage(message.From.ID)
+	comment := strings.TrimSpace(message.Text)
+	if comment == "" {
+		b.sendMessage(message.Chat.ID, b.t(lang, "review.comment_text_only"))
+		return
+	}
+	if runes := []rune(comment); len(runes) > maxReviewCommentLength {
+		comment = string(runes[:maxReviewCommentLength])
+	}
+
+	found, err := b.db.SetReviewComment(reviewID, message.From.ID, comment)
+	if err != nil {
+		logrus.Errorf("Failed to save comment of review %d: %v", reviewID, err)
+		b.sendMessage(message.Chat.ID, b.t(lang, "review.comment_failed"))
+		return
+	}
+	if !found {
+		b.sendMessage(message.Chat.ID, b.t(lang, "review.not_found"))
+		return
+	}
+
+	logrus.Infof("User %d commented on review %d", message.From.ID, reviewID)
+	b.sendMessage(message.Chat.ID, b.t(lang, "review.thanks_comment"))
+}
+
+// formatReviewAdmin returns a review as shown in the admin review list
+func formatReviewAdmin(review *models.Review) string {
+	status := "✅"
+	if review.IsHidden {
+		status = "🙈 disembunyikan"
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🆔 *%d* • %s • %s\n", review.ID, review.Stars(), status))
+	text.WriteString(fmt.Sprintf("📱 %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, review.ProductName)))
+	text.WriteString(fmt.Sprintf("👤 %s (`%d`) • #%s • %s\n",
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, review.ReviewerName()), review.UserID,
+		shortOrderID(review.OrderID), review.UpdatedAt.Format("02/01/2006 15:04")))
+	if review.Comment != nil {
+		text.WriteString(fmt.Sprintf("💬 %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *review.Comment)))
+	}
+	return text.String()
+}
+
+// handleReviewsCommand handles /reviews [PRODUCT_ID] and lists recent reviews, including hidden ones
+func (b *Bot) handleReviewsCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	productID := 0
+	if arg := strings.TrimSpace(message.CommandArguments()); arg != "" {
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			b.sendMessage(message.Chat.ID, "❌ Format salah!\n\nGunakan:\n/reviews - Ulasan terbaru semua produk\n/reviews [ID] - Ulasan terbaru satu produk")
+			return
+		}
+		productID = id
+	}
+
+	reviews, err := b.db.GetReviews(productID, 15)
+	if err != nil {
+		logrus.Errorf("Failed to get reviews: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat ulasan!")
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString("⭐ *ULASAN TERBARU*\n\n")
+	if productID != 0 {
+		if rating, err := b.db.GetProductRating(productID); err == nil && rating.Count > 0 {
+			text.WriteString(fmt.Sprintf("Rata-rata produk #%d: *%.1f/5* dari %d ulasan tampil\n\n", productID, rating.Average, rating.Count))
+		}
+	}
+	if len(reviews) == 0 {
+		text.WriteString("Belum ada ulasan.\n\n")
+	}
+	for i := range reviews {
+		text.WriteString(formatReviewAdmin(&reviews[i]))
+		text.WriteString("\n")
+	}
+	text.WriteString("💡 /review ID hide untuk menyembunyikan, /review ID show untuk menampilkan, /review ID hapus untuk menghapus")
+
+	b.sendMessage(message.Chat.ID, text.String())
+}
+
+// processReviewCommand handles /review ID [hide|show|hapus]
+func (b *Bot) processReviewCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	usage := "❌ Format salah!\n\nGunakan:\n/review [ID] - Detail ulasan\n/review [ID] hide - Sembunyikan ulasan\n/review [ID] show - Tampilkan lagi ulasan\n/review [ID] hapus - Hapus ulasan"
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 || len(args) > 2 {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+	reviewID, err := strconv.Atoi(args[0])
+	if err != nil {
+		b.sendMessage(message.Chat.ID, usage)
+		return
+	}
+
+	review, err := b.db.GetReview(reviewID)
+	if err != nil {
+		logrus.Errorf("Failed to get review %d: %v", reviewID, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat ulasan!")
+		return
+	}
+	if review == nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Ulasan dengan ID %d tidak ditemukan!", reviewID))
+		return
+	}
+
+	if len(args) == 1 {
+		b.sendMessage(message.Chat.ID, "⭐ *DETAIL ULASAN*\n\n"+formatReviewAdmin(review))
+		return
+	}
+
+	switch strings.ToLower(args[1]) {
+	case "hide", "show":
+		hidden := strings.EqualFold(args[1], "hide")
+		if _, err := b.db.SetReviewHidden(reviewID, hidden); err != nil {
+			logrus.Errorf("Failed to update review %d: %v", reviewID, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal mengubah ulasan!")
+			return
+		}
+		review.IsHidden = hidden
+		logrus.Infof("Admin %d set review %d hidden=%t", message.From.ID, reviewID, hidden)
+		b.sendMessage(message.Chat.ID, "✅ Ulasan diperbarui.\n\n"+formatReviewAdmin(review))
+	case "hapus", "delete":
+		if _, err := b.db.DeleteReview(reviewID); err != nil {
+			logrus.Errorf("Failed to delete review %d: %v", reviewID, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal menghapus ulasan!")
+			return
+		}
+		logrus.Infof("Admin %d deleted review %d", message.From.ID, reviewID)
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Ulasan #%d dihapus.", reviewID))
+	default:
+		b.sendMessage(message.Chat.ID, usage)
+	}
+}
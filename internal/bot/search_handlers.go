@@ -0,0 +1,160 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"telegram-premium-store/internal/database"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// maxCallbackData is Telegram's limit on the callback data of an inline button, in bytes
+const maxCallbackData = 64
+
+// searchCallback returns the callback data of a search results page. The query is dropped a word at
+// a time until it fits the callback data limit.
+func searchCallback(query string, page int) string {
+	prefix := fmt.Sprintf("search:%d:", page)
+	for len(prefix)+len(query) > maxCallbackData {
+		i := strings.LastIndex(query, " ")
+		if i < 0 {
+			return prefix
+		}
+		query = query[:i]
+	}
+	return prefix + query
+}
+
+// handleSearchCommand handles /cari and /search
+func (b *Bot) handleSearchCommand(message *tgbotapi.Message) {
+	lang := b.userLanguage(message.From.ID)
+	if len(database.NormalizeSearchQuery(message.CommandArguments())) == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.t(lang, "search.prompt"))
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.catalog"), "catalog:0"),
+			),
+		)
+		b.send(msg)
+		return
+	}
+
+	b.sendSearchResults(message, message.CommandArguments())
+}
+
+// sendSearchResults replies with the first page of results for query
+func (b *Bot) sendSearchResults(message *tgbotapi.Message, query string) {
+	lang := b.userLanguage(message.From.ID)
+	text, keyboard, err := b.buildSearchResults(message.From.ID, lang, query, 0)
+	if err != nil {
+		logrus.Errorf("Failed to search products for %q: %v", query, err)
+		b.sendMessage(message.Chat.ID, b.t(lang, "search.failed"))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
+
+// handleSearchCallback shows another page of search results ("search:<page>:<query>")
+func (b *Bot) handleSearchCallback(callback *tgbotapi.CallbackQuery, page int, query string) {
+	lang := b.userLanguage(callback.From.ID)
+	text, keyboard, err := b.buildSearchResults(callback.From.ID, lang, query, page)
+	if err != nil {
+		logrus.Errorf("Failed to search products for %q: %v", query, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "search.failed")))
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// buildSearchResults renders one page of search results with the catalog's product list and page buttons.
+// A first page without results is logged so the admin can see what buyers look for.
+func (b *Bot) buildSearchResults(userID int64, lang, query string, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	result, err := b.db.SearchProducts(query, catalogPageSize, page*catalogPageSize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	bottomRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "menu.catalog"), "catalog:0"),
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "button.main_menu"), "start"),
+	)
+
+	if len(result.Products) == 0 {
+		if page == 0 {
+			if err := b.db.LogSearchMiss(userID, result.Query); err != nil {
+				logrus.Errorf("Failed to log search miss %q: %v", result.Query, err)
+			}
+		}
+		return b.t(lang, "search.empty", result.Query), tgbotapi.NewInlineKeyboardMarkup(bottomRow), nil
+	}
+
+	var text strings.Builder
+	text.WriteString(b.t(lang, "search.title", result.Query) + "\n")
+	if result.Corrected != "" {
+		text.WriteString(b.t(lang, "search.corrected", result.Corrected) + "\n")
+	}
+	text.WriteString("\n")
+
+	keyboard := b.writeProductList(&text, userID, lang, result.Products)
+	navRow := b.pageNavRow(lang, page, len(result.Products) == catalogPageSize, func(p int) string {
+		return searchCallback(result.Query, p)
+	})
+	if len(navRow) > 0 {
+		keyboard = append(keyboard, navRow)
+	}
+	keyboard = append(keyboard, bottomRow)
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// handleSearchLogCommand handles /searchlog [HARI] and lists searches that found nothing
+func (b *Bot) handleSearchLogCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	days := 30
+	if arg := strings.TrimSpace(message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n <= 0 {
+			b.sendMessage(message.Chat.ID, "❌ Format salah!\n\nGunakan:\n/searchlog - Pencarian tanpa hasil 30 hari terakhir\n/searchlog [HARI] - Pencarian tanpa hasil dalam jumlah hari tertentu")
+			return
+		}
+		days = n
+	}
+
+	misses, err := b.db.GetSearchMisses(days, 20)
+	if err != nil {
+		logrus.Errorf("Failed to get search misses: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat log pencarian!")
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🔍 *PENCARIAN TANPA HASIL (%d HARI)*\n\n", days))
+	if len(misses) == 0 {
+		text.WriteString("Semua pencarian menemukan produk.\n")
+	}
+	for i, miss := range misses {
+		text.WriteString(fmt.Sprintf("%d. `%s` • %dx oleh %d user • terakhir %s\n",
+			i+1, miss.Query, miss.Count, miss.Users, miss.LastSearched.Format("02/01 15:04")))
+	}
+	if len(misses) > 0 {
+		text.WriteString("\n💡 Pertimbangkan menambah produk yang sering dicari ke katalog.")
+	}
+
+	b.sendMessage(message.Chat.ID, text.String())
+}
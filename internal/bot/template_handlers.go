@@ -0,0 +1,280 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+	"telegram-premium-store/internal/templates"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// maxTemplateDetailBody caps the template text shown in the detail screen, leaving room for the rest of the message
+const maxTemplateDetailBody = 3000
+
+// handleTemplateCommand handles the admin /template [nama] [bahasa] command listing or showing message templates
+func (b *Bot) handleTemplateCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 0 {
+		text, keyboard := b.buildTemplateList()
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.ReplyMarkup = keyboard
+		b.send(msg)
+		return
+	}
+
+	def := templates.Lookup(args[0])
+	if def == nil {
+		b.sendMessage(message.Chat.ID, "❌ Template tidak ditemukan. Ketik /template untuk melihat daftar template.")
+		return
+	}
+	lang := b.i18n.Default()
+	if len(args) > 1 {
+		if lang = b.i18n.Match(args[1]); lang == "" {
+			b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ Bahasa tidak dikenal. Pilihan: %s", strings.Join(b.i18n.Languages(), ", ")))
+			return
+		}
+	}
+
+	text, keyboard := b.buildTemplateDetail(def, lang)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
+
+// buildTemplateList lists every message template and whether admins have changed it
+func (b *Bot) buildTemplateList() (string, tgbotapi.InlineKeyboardMarkup) {
+	var text strings.Builder
+	text.WriteString("📝 <b>TEMPLATE PESAN</b>\n\n")
+	text.WriteString("Teks pesan otomatis yang bisa diubah tanpa update bot. Variabel seperti nama produk di-escape otomatis.\n\n")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, def := range templates.All() {
+		var changed []string
+		for _, lang := range b.i18n.Languages() {
+			_, custom, err := b.templates.Body(def, lang)
+			if err != nil {
+				logrus.Errorf("Failed to load message template %s (%s): %v", def.Name, lang, err)
+			}
+			if custom {
+				changed = append(changed, lang)
+			}
+		}
+		status := "📄 bawaan"
+		if len(changed) > 0 {
+			status = "✏️ diubah: " + strings.Join(changed, ", ")
+		}
+		text.WriteString(fmt.Sprintf("• <b>%s</b> (<code>%s</code>) - %s\n  %s\n",
+			escapeHTML(def.Title), def.Name, status, escapeHTML(def.Description)))
+
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📝 "+def.Title, "admin:template:"+def.Name),
+		))
+	}
+	text.WriteString("\n💡 /template [nama] [bahasa] untuk melihat, mengubah, pratinjau atau mengembalikan template. Teks tiap bahasa diubah terpisah.")
+
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🏠 Panel Admin", "admin:main"),
+	))
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
+
+// buildTemplateDetail shows a template's variables and current text in a language with its actions
+func (b *Bot) buildTemplateDetail(def *templates.Definition, lang string) (string, tgbotapi.InlineKeyboardMarkup) {
+	custom, err := b.db.GetMessageTemplate(def.Name, lang)
+	if err != nil {
+		logrus.Errorf("Failed to load message template %s (%s): %v", def.Name, lang, err)
+	}
+
+	body := def.DefaultBody(lang)
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("📝 <b>TEMPLATE: %s</b>\n<code>%s</code>\n\n", escapeHTML(def.Title), def.Name))
+	text.WriteString(escapeHTML(def.Description) + "\n\n")
+	text.WriteString(fmt.Sprintf("Bahasa: %s\n", escapeHTML(b.i18n.Name(lang))))
+	if custom != nil {
+		body = custom.Body
+		text.WriteString(fmt.Sprintf("Status: ✏️ Diubah oleh admin <code>%d</code> pada %s\n\n",
+			custom.UpdatedBy, custom.UpdatedAt.Format("02/01/2006 15:04")))
+	} else {
+		text.WriteString("Status: 📄 Teks bawaan\n\n")
+	}
+
+	text.WriteString("<b>Variabel:</b>\n")
+	for _, v := range def.Vars {
+		text.WriteString(fmt.Sprintf("• <code>{%s}</code> - %s", v.Name, escapeHTML(v.Description)))
+		if v.Raw {
+			text.WriteString(" <i>(sudah berformat)</i>")
+		}
+		text.WriteString("\n")
+	}
+
+	if runes := []rune(body); len(runes) > maxTemplateDetailBody {
+		body = string(runes[:maxTemplateDetailBody]) + "\n... (dipotong)"
+	}
+	text.WriteString(fmt.Sprintf("\n<b>Teks saat ini:</b>\n<pre>%s</pre>", escapeHTML(body)))
+
+	target := def.Name + ":" + lang
+	keyboard := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👁️ Pratinjau", "admin:tplpreview:"+target),
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Ubah", "admin:tpledit:"+target),
+		),
+	}
+	if custom != nil {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("♻️ Kembalikan Bawaan", "admin:tplreset:"+target),
+		))
+	}
+
+	// Switch to the text of the other languages
+	var langRow []tgbotapi.InlineKeyboardButton
+	for _, other := range b.i18n.Languages() {
+		if other != lang {
+			langRow = append(langRow, tgbotapi.NewInlineKeyboardButtonData(
+				"🌐 "+b.i18n.Name(other), "admin:template:"+def.Name+":"+other))
+		}
+	}
+	if len(langRow) > 0 {
+		keyboard = append(keyboard, langRow)
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Daftar Template", "admin:templates"),
+	))
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
+
+// handleAdminTemplates shows the template list from the admin panel
+func (b *Bot) handleAdminTemplates(callback *tgbotapi.CallbackQuery) {
+	text, keyboard := b.buildTemplateList()
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeHTML
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// handleAdminTemplateAction handles the detail, preview, edit and reset buttons of a template in a language
+func (b *Bot) handleAdminTemplateAction(callback *tgbotapi.CallbackQuery, action, name, lang string) {
+	def := templates.Lookup(name)
+	if def == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Template tidak ditemukan"))
+		return
+	}
+	if lang = b.i18n.Match(lang); lang == "" {
+		lang = b.i18n.Default()
+	}
+
+	switch action {
+	case "tplpreview":
+		body, _, err := b.templates.Body(def, lang)
+		if err != nil {
+			logrus.Errorf("Failed to load message template %s (%s): %v", name, lang, err)
+		}
+		rendered := def.Render(body, def.SampleVars())
+		if _, err := templates.SendWithFallback(b.api, rendered.Message(callback.Message.Chat.ID), rendered.Plain); err != nil {
+			logrus.Errorf("Failed to send preview of template %s: %v", name, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal mengirim pratinjau"))
+			return
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "👁️ Pratinjau dengan contoh data dikirim"))
+		return
+	case "tpledit":
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.setConversation(callback.From.ID, models.StateTemplateEdit, name+":"+lang)
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, fmt.Sprintf(`✏️ Kirim teks baru untuk template <b>%s</b> dalam bahasa %s.
+
+Gunakan format HTML Telegram: &lt;b&gt;tebal&lt;/b&gt;, &lt;i&gt;miring&lt;/i&gt;, &lt;code&gt;kode&lt;/code&gt;, &lt;pre&gt;blok&lt;/pre&gt;, &lt;a href="..."&gt;tautan&lt;/a&gt;. Tulis variabel seperti <code>{%s}</code>; nilainya di-escape otomatis.
+
+Teks saat ini bisa disalin dari tampilan template. Pratinjau dikirim sebelum template disimpan.
+
+Ketik /batal untuk membatalkan.`, escapeHTML(def.Title), escapeHTML(b.i18n.Name(lang)), def.Vars[0].Name))
+		msg.ParseMode = tgbotapi.ModeHTML
+		b.send(msg)
+		return
+	case "tplreset":
+		deleted, err := b.db.DeleteMessageTemplate(name, lang)
+		if err != nil {
+			logrus.Errorf("Failed to reset message template %s (%s): %v", name, lang, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal mengembalikan template"))
+			return
+		}
+		if deleted {
+			logrus.Infof("Admin %d reset message template %s (%s)", callback.From.ID, name, lang)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "♻️ Template dikembalikan ke teks bawaan"))
+		} else {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "ℹ️ Template sudah memakai teks bawaan"))
+		}
+	}
+
+	text, keyboard := b.buildTemplateDetail(def, lang)
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeHTML
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// processTemplateEdit validates the admin's new template text, sends a preview and saves it when Telegram accepts
+// the formatting. The conversation state is kept after a mistake so the admin can send corrected text.
+// The payload is the template name and language, e.g. "payment_success:en".
+func (b *Bot) processTemplateEdit(message *tgbotapi.Message, payload string) {
+	name, lang, _ := strings.Cut(payload, ":")
+	if lang = b.i18n.Match(lang); lang == "" {
+		lang = b.i18n.Default()
+	}
+	def := templates.Lookup(name)
+	if def == nil {
+		b.takeConversation(message.From.ID, models.StateTemplateEdit)
+		b.sendMessage(message.Chat.ID, "❌ Template tidak ditemukan.")
+		return
+	}
+
+	body := message.Text
+	if body == "" {
+		b.sendMessage(message.Chat.ID, "❌ Kirim teks template sebagai pesan teks, atau /batal untuk membatalkan.")
+		return
+	}
+	if err := def.Validate(body); err != nil {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("❌ %s\n\nPerbaiki lalu kirim ulang teks template, atau /batal untuk membatalkan.",
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, err.Error())))
+		return
+	}
+
+	// Telegram is the judge of the formatting: the preview must go through before the text is saved
+	rendered := def.Render(body, def.SampleVars())
+	if _, err := b.send(rendered.Message(message.Chat.ID)); err != nil {
+		if templates.IsFormattingError(err) {
+			msg := tgbotapi.NewMessage(message.Chat.ID, fmt.Sprintf(
+				"❌ Telegram menolak format template:\n<code>%s</code>\n\nTemplate belum disimpan. Perbaiki lalu kirim ulang, atau /batal untuk membatalkan.",
+				escapeHTML(err.Error())))
+			msg.ParseMode = tgbotapi.ModeHTML
+			b.send(msg)
+			return
+		}
+		logrus.Errorf("Failed to send preview of template %s: %v", name, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal mengirim pratinjau. Kirim ulang teks template, atau /batal untuk membatalkan.")
+		return
+	}
+
+	if err := b.db.SaveMessageTemplate(name, lang, body, message.From.ID); err != nil {
+		logrus.Errorf("Failed to save message template %s (%s): %v", name, lang, err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal menyimpan template. Kirim ulang teks template, atau /batal untuk membatalkan.")
+		return
+	}
+	b.takeConversation(message.From.ID, models.StateTemplateEdit)
+	logrus.Infof("Admin %d changed message template %s (%s)", message.From.ID, name, lang)
+
+	text, keyboard := b.buildTemplateDetail(def, lang)
+	msg := tgbotapi.NewMessage(message.Chat.ID, "✅ <b>Template disimpan.</b> Pesan di atas adalah pratinjau dengan contoh data.\n\n"+text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
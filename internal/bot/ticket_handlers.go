@@ -0,0 +1,696 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"telegram-premium-store/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxTicketMessageLength = 3000
+	ticketQueueSize        = 15
+	ticketHistorySize      = 10
+	ticketStatsPeriod      = 30 * 24 * time.Hour
+)
+
+// newTicketPayload is the conversation payload of a ticket that is created with the buyer's first message
+const newTicketPayload = "new:"
+
+// handleSupportCommand handles /bantuan, opening a support ticket or continuing the active one
+func (b *Bot) handleSupportCommand(message *tgbotapi.Message) {
+	b.startSupportTicket(message.Chat.ID, message.From.ID)
+}
+
+// startSupportTicket asks the buyer which order the ticket is about, or lets them continue their active ticket
+func (b *Bot) startSupportTicket(chatID, userID int64) {
+	lang := b.userLanguage(userID)
+	ticket, err := b.db.GetActiveTicket(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get active ticket of user %d: %v", userID, err)
+		b.sendMessage(chatID, b.t(lang, "ticket.load_failed"))
+		return
+	}
+
+	if ticket != nil {
+		b.setConversation(userID, models.StateSupportTicket, strconv.Itoa(ticket.ID))
+		status := b.t(lang, "ticket.status_waiting_admin")
+		if ticket.Status == models.TicketStatusAnswered {
+			status = b.t(lang, "ticket.status_answered")
+		}
+		msg := tgbotapi.NewMessage(chatID, b.t(lang, "ticket.active", ticket.ID, status))
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "ticket.close_button"), fmt.Sprintf("ticket_close:%d", ticket.ID)),
+		))
+		b.send(msg)
+		return
+	}
+
+	orders, err := b.db.GetUserOrders(userID, 5, 0)
+	if err != nil {
+		logrus.Errorf("Failed to get orders of user %d: %v", userID, err)
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, order := range orders {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("📦 #%s · %s", shortOrderID(order.ID), b.formatPrice(lang, order.TotalAmount)),
+				"ticket_new:"+order.ID),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "ticket.general_question"), "ticket_new:"),
+	))
+
+	text := b.t(lang, "ticket.choose_order")
+	if len(orders) == 0 {
+		text = b.t(lang, "ticket.start")
+	}
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	b.send(msg)
+}
+
+// handleTicketNewCallback waits for the first message of a new ticket, optionally about an order
+func (b *Bot) handleTicketNewCallback(callback *tgbotapi.CallbackQuery, orderID string) {
+	userID := callback.From.ID
+	lang := b.userLanguage(userID)
+
+	if orderID != "" {
+		order, err := b.db.GetOrder(orderID)
+		if err != nil || order == nil || order.UserID != userID {
+			b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "order.not_found")))
+			return
+		}
+	}
+
+	b.setConversation(userID, models.StateSupportTicket, newTicketPayload+orderID)
+
+	text := b.t(lang, "ticket.write_title") + "\n\n"
+	if orderID != "" {
+		text += b.t(lang, "ticket.write_order", shortOrderID(orderID)) + "\n\n"
+	}
+	text += b.t(lang, "ticket.write_prompt")
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	b.send(edit)
+}
+
+// ticketMessageText returns the text stored for a ticket message; media without a caption is stored as a placeholder
+func ticketMessageText(message *tgbotapi.Message) string {
+	if text := strings.TrimSpace(message.Text); text != "" {
+		return text
+	}
+	if caption := strings.TrimSpace(message.Caption); caption != "" {
+		return caption
+	}
+	if message.Photo != nil || message.Document != nil || message.Video != nil || message.Voice != nil {
+		return "[lampiran]"
+	}
+	return ""
+}
+
+// hasTicketMedia reports whether a ticket message has an attachment that is relayed as a copy
+func hasTicketMedia(message *tgbotapi.Message) bool {
+	return message.Text == "" && (message.Photo != nil || message.Document != nil || message.Video != nil || message.Voice != nil)
+}
+
+// processSupportMessage relays a buyer's message to the admins, creating the ticket on the first message
+func (b *Bot) processSupportMessage(message *tgbotapi.Message, payload string) {
+	userID := message.From.ID
+	lang := b.userLanguage(userID)
+
+	text := ticketMessageText(message)
+	if text == "" {
+		b.sendMessage(message.Chat.ID, b.t(lang, "ticket.empty_message"))
+		return
+	}
+	if utf8.RuneCountInString(text) > maxTicketMessageLength {
+		b.sendMessage(message.Chat.ID, b.t(lang, "ticket.message_too_long", maxTicketMessageLength))
+		return
+	}
+
+	var ticket *models.SupportTicket
+	isNew := false
+	if orderID, ok := strings.CutPrefix(payload, newTicketPayload); ok {
+		// A ticket may have been opened in the meantime, e.g. from another device
+		active, err := b.db.GetActiveTicket(userID)
+		if err != nil {
+			logrus.Errorf("Failed to get active ticket of user %d: %v", userID, err)
+			b.sendMessage(message.Chat.ID, b.t(lang, "ticket.create_failed"))
+			return
+		}
+		ticket = active
+		if ticket == nil {
+			var order *string
+			if orderID != "" {
+				order = &orderID
+			}
+			id, err := b.db.CreateTicket(userID, order)
+			if err != nil {
+				logrus.Errorf("Failed to create ticket for user %d: %v", userID, err)
+				b.sendMessage(message.Chat.ID, b.t(lang, "ticket.create_failed"))
+				return
+			}
+			isNew = true
+			if ticket, err = b.db.GetTicket(id); err != nil || ticket == nil {
+				logrus.Errorf("Failed to load new ticket %d: %v", id, err)
+				b.sendMessage(message.Chat.ID, b.t(lang, "ticket.create_failed"))
+				return
+			}
+		}
+	} else {
+		ticketID, _ := strconv.Atoi(payload)
+		var err error
+		ticket, err = b.db.GetTicket(ticketID)
+		if err != nil {
+			logrus.Errorf("Failed to get ticket %d: %v", ticketID, err)
+			b.sendMessage(message.Chat.ID, b.t(lang, "ticket.send_failed"))
+			return
+		}
+	}
+
+	if ticket == nil || ticket.UserID != userID || ticket.Status == models.TicketStatusClosed {
+		b.takeConversation(userID, models.StateSupportTicket)
+		b.sendMessage(message.Chat.ID, b.t(lang, "ticket.already_closed"))
+		return
+	}
+
+	if err := b.db.AddTicketMessage(ticket.ID, userID, false, text); err != nil {
+		logrus.Errorf("Failed to save message of ticket %d: %v", ticket.ID, err)
+		b.sendMessage(message.Chat.ID, b.t(lang, "ticket.send_failed"))
+		return
+	}
+
+	// Keep the buyer in the ticket so that follow-up messages reach the admins too
+	b.setConversation(userID, models.StateSupportTicket, strconv.Itoa(ticket.ID))
+
+	b.relayTicketToAdmins(ticket, message, text, isNew)
+
+	if isNew {
+		b.sendMessage(message.Chat.ID, b.t(lang, "ticket.created", ticket.ID))
+		return
+	}
+	b.sendMessage(message.Chat.ID, b.t(lang, "ticket.sent", ticket.ID))
+}
+
+// relayTicketToAdmins forwards a buyer's message to the admin handling the ticket, or to all admins if nobody has it yet
+func (b *Bot) relayTicketToAdmins(ticket *models.SupportTicket, message *tgbotapi.Message, text string, isNew bool) {
+	var header strings.Builder
+	if isNew {
+		header.WriteString(fmt.Sprintf("🆕 *TIKET BARU #%d*\n", ticket.ID))
+	} else {
+		header.WriteString(fmt.Sprintf("🎫 *TIKET #%d*\n", ticket.ID))
+	}
+	header.WriteString(fmt.Sprintf("👤 %s · ID `%d`\n",
+		tgbotapi.EscapeText(tgbotapi.ModeMarkdown, ticket.CustomerName()), ticket.UserID))
+	if ticket.OrderID != nil {
+		header.WriteString(fmt.Sprintf("📦 Order: `%s`\n", *ticket.OrderID))
+	}
+	if !hasTicketMedia(message) {
+		header.WriteString("\n" + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, text) + "\n")
+	} else {
+		header.WriteString("\n📎 _Lampiran dari pembeli di bawah ini._\n")
+	}
+	header.WriteString("\n↩️ _Balas (reply) pesan ini untuk menjawab pembeli._")
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🙋 Ambil", fmt.Sprintf("admin:claimticket:%d", ticket.ID)),
+		tgbotapi.NewInlineKeyboardButtonData("📋 Detail", fmt.Sprintf("admin:ticket:%d", ticket.ID)),
+		tgbotapi.NewInlineKeyboardButtonData("✅ Tutup", fmt.Sprintf("admin:closeticket:%d", ticket.ID)),
+	))
+
+	recipients := b.config.AdminIDs
+	if ticket.AssignedTo != nil {
+		recipients = []int64{*ticket.AssignedTo}
+	}
+
+	for _, adminID := range recipients {
+		msg := tgbotapi.NewMessage(adminID, header.String())
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = keyboard
+		sent, err := b.send(msg)
+		if err != nil {
+			logrus.Errorf("Failed to relay ticket %d to admin %d: %v", ticket.ID, adminID, err)
+			continue
+		}
+		b.saveTicketRelay(adminID, sent.MessageID, ticket.ID)
+
+		if hasTicketMedia(message) {
+			copied, err := b.send(tgbotapi.NewCopyMessage(adminID, message.Chat.ID, message.MessageID))
+			if err != nil {
+				logrus.Errorf("Failed to copy ticket %d attachment to admin %d: %v", ticket.ID, adminID, err)
+				continue
+			}
+			b.saveTicketRelay(adminID, copied.MessageID, ticket.ID)
+		}
+	}
+}
+
+// saveTicketRelay remembers a relayed message so that replying to it continues the ticket
+func (b *Bot) saveTicketRelay(chatID int64, messageID, ticketID int) {
+	if err := b.db.SaveTicketRelay(chatID, messageID, ticketID); err != nil {
+		logrus.Errorf("Failed to save relay of ticket %d: %v", ticketID, err)
+	}
+}
+
+// handleTicketReply routes a reply to a relayed ticket message: admins answer the buyer, the buyer continues the ticket.
+// Returns false when the message is not a reply to a ticket message.
+func (b *Bot) handleTicketReply(message *tgbotapi.Message) bool {
+	if message.ReplyToMessage == nil {
+		return false
+	}
+
+	ticketID, err := b.db.GetTicketIDByRelay(message.Chat.ID, message.ReplyToMessage.MessageID)
+	if err != nil {
+		logrus.Errorf("Failed to look up ticket relay: %v", err)
+		return false
+	}
+	if ticketID == 0 {
+		return false
+	}
+
+	ticket, err := b.db.GetTicket(ticketID)
+	if err != nil || ticket == nil {
+		logrus.Errorf("Failed to get ticket %d: %v", ticketID, err)
+		return false
+	}
+
+	if message.Chat.ID == ticket.UserID && message.From.ID == ticket.UserID {
+		b.processSupportMessage(message, strconv.Itoa(ticket.ID))
+		return true
+	}
+	if b.config.IsAdmin(message.From.ID) {
+		b.answerTicket(message, ticket)
+		return true
+	}
+	return false
+}
+
+// processTicketReply sends the answer an admin typed after pressing Balas in the ticket queue
+func (b *Bot) processTicketReply(message *tgbotapi.Message, payload string) {
+	ticketID, _ := strconv.Atoi(payload)
+	ticket, err := b.db.GetTicket(ticketID)
+	if err != nil || ticket == nil {
+		logrus.Errorf("Failed to get ticket %d: %v", ticketID, err)
+		b.sendMessage(message.Chat.ID, "❌ Tiket tidak ditemukan.")
+		return
+	}
+	b.answerTicket(message, ticket)
+}
+
+// answerTicket delivers an admin's answer to the buyer and records it on the ticket
+func (b *Bot) answerTicket(message *tgbotapi.Message, ticket *models.SupportTicket) {
+	if ticket.Status == models.TicketStatusClosed {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf(
+			"⚠️ Tiket #%d sudah ditutup. Buka kembali dari antrian tiket (/tiket) untuk membalas.", ticket.ID))
+		return
+	}
+
+	text := ticketMessageText(message)
+	if text == "" {
+		b.sendMessage(message.Chat.ID, "⚠️ Balasan harus berupa teks atau foto.")
+		return
+	}
+	if utf8.RuneCountInString(text) > maxTicketMessageLength {
+		b.sendMessage(message.Chat.ID, fmt.Sprintf("⚠️ Balasan terlalu panjang (maksimal %d karakter).", maxTicketMessageLength))
+		return
+	}
+
+	lang := b.userLanguage(ticket.UserID)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(b.t(lang, "ticket.solved_button"), fmt.Sprintf("ticket_close:%d", ticket.ID)),
+	))
+
+	var sentIDs []int
+	if hasTicketMedia(message) {
+		copyMsg := tgbotapi.NewCopyMessage(ticket.UserID, message.Chat.ID, message.MessageID)
+		copyMsg.ReplyMarkup = keyboard
+		sent, err := b.send(copyMsg)
+		if err != nil {
+			logrus.Errorf("Failed to send answer of ticket %d to user %d: %v", ticket.ID, ticket.UserID, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal mengirim balasan ke pembeli (mungkin bot diblokir).")
+			return
+		}
+		sentIDs = append(sentIDs, sent.MessageID)
+	} else {
+		msg := tgbotapi.NewMessage(ticket.UserID, b.t(lang, "ticket.admin_reply",
+			ticket.ID, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, text)))
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = keyboard
+		sent, err := b.send(msg)
+		if err != nil {
+			logrus.Errorf("Failed to send answer of ticket %d to user %d: %v", ticket.ID, ticket.UserID, err)
+			b.sendMessage(message.Chat.ID, "❌ Gagal mengirim balasan ke pembeli (mungkin bot diblokir).")
+			return
+		}
+		sentIDs = append(sentIDs, sent.MessageID)
+	}
+	for _, id := range sentIDs {
+		b.saveTicketRelay(ticket.UserID, id, ticket.ID)
+	}
+
+	if err := b.db.AddTicketMessage(ticket.ID, message.From.ID, true, text); err != nil {
+		logrus.Errorf("Failed to save answer of ticket %d: %v", ticket.ID, err)
+	}
+
+	// Let the buyer simply type their follow-up, unless they are in the middle of another flow
+	if conv := b.getConversation(ticket.UserID); conv == nil {
+		b.setConversation(ticket.UserID, models.StateSupportTicket, strconv.Itoa(ticket.ID))
+	}
+
+	b.sendMessage(message.Chat.ID, fmt.Sprintf("✅ Balasan terkirim ke pembeli (Tiket #%d).", ticket.ID))
+}
+
+// handleTicketCloseCallback lets the buyer close their ticket once their problem is solved
+func (b *Bot) handleTicketCloseCallback(callback *tgbotapi.CallbackQuery, ticketID int) {
+	lang := b.userLanguage(callback.From.ID)
+	ticket, err := b.db.GetTicket(ticketID)
+	if err != nil || ticket == nil || ticket.UserID != callback.From.ID {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "ticket.not_found")))
+		return
+	}
+
+	closed, err := b.db.CloseTicket(ticketID)
+	if err != nil {
+		logrus.Errorf("Failed to close ticket %d: %v", ticketID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "ticket.close_failed")))
+		return
+	}
+	b.takeConversation(ticket.UserID, models.StateSupportTicket)
+	if !closed {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "ticket.already_closed_short")))
+		return
+	}
+
+	b.api.Request(tgbotapi.NewCallback(callback.ID, b.t(lang, "ticket.closed_short")))
+	b.sendMessage(callback.Message.Chat.ID, b.t(lang, "ticket.closed", ticketID))
+
+	recipients := b.config.AdminIDs
+	if ticket.AssignedTo != nil {
+		recipients = []int64{*ticket.AssignedTo}
+	}
+	for _, adminID := range recipients {
+		b.sendMessage(adminID, fmt.Sprintf("✅ Tiket #%d ditutup oleh pembeli %s.",
+			ticketID, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, ticket.CustomerName())))
+	}
+}
+
+// handleTicketsCommand handles the admin /tiket command showing the ticket queue
+func (b *Bot) handleTicketsCommand(message *tgbotapi.Message) {
+	if !b.config.IsAdmin(message.From.ID) {
+		b.sendMessage(message.Chat.ID, "❌ Akses ditolak. Command ini hanya untuk admin.")
+		return
+	}
+
+	text, keyboard, err := b.buildTicketQueue(message.From.ID)
+	if err != nil {
+		logrus.Errorf("Failed to build ticket queue: %v", err)
+		b.sendMessage(message.Chat.ID, "❌ Gagal memuat antrian tiket.")
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
+
+// ticketStatusIcon returns the queue icon of a ticket status
+func ticketStatusIcon(status models.TicketStatus) string {
+	switch status {
+	case models.TicketStatusOpen:
+		return "🔴"
+	case models.TicketStatusAnswered:
+		return "🟡"
+	default:
+		return "✅"
+	}
+}
+
+// ticketStatusLabel returns the admin-facing name of a ticket status
+func ticketStatusLabel(status models.TicketStatus) string {
+	switch status {
+	case models.TicketStatusOpen:
+		return "Menunggu admin"
+	case models.TicketStatusAnswered:
+		return "Menunggu pembeli"
+	default:
+		return "Ditutup"
+	}
+}
+
+// formatWaitDuration renders a duration like "3 hari 2 jam" or "15 mnt"
+func formatWaitDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "<1 mnt"
+	}
+	minutes := int(d.Minutes())
+	days, hours := minutes/(24*60), minutes/60%24
+	minutes %= 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%d hari %d jam", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%d jam %d mnt", hours, minutes)
+	default:
+		return fmt.Sprintf("%d mnt", minutes)
+	}
+}
+
+// ticketAssigneeName returns who handles a ticket, as seen by the admin viewing it
+func (b *Bot) ticketAssigneeName(ticket *models.SupportTicket, viewerID int64) string {
+	if ticket.AssignedTo == nil {
+		return "belum diambil"
+	}
+	if *ticket.AssignedTo == viewerID {
+		return "Anda"
+	}
+	if admin, err := b.db.GetUser(*ticket.AssignedTo); err == nil && admin != nil && admin.FirstName != nil && *admin.FirstName != "" {
+		return tgbotapi.EscapeText(tgbotapi.ModeMarkdown, *admin.FirstName)
+	}
+	return fmt.Sprintf("Admin %d", *ticket.AssignedTo)
+}
+
+// buildTicketQueue renders the tickets waiting for an answer with response time statistics
+func (b *Bot) buildTicketQueue(viewerID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	stats, err := b.db.GetTicketStats(time.Now().Add(-ticketStatsPeriod))
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get ticket stats: %w", err)
+	}
+	tickets, err := b.db.GetTicketQueue(ticketQueueSize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get ticket queue: %w", err)
+	}
+
+	var text strings.Builder
+	text.WriteString("🎫 *ANTRIAN TIKET BANTUAN*\n\n")
+	text.WriteString(fmt.Sprintf("🔴 Menunggu admin: %d\n🟡 Menunggu pembeli: %d\n\n", stats.Open, stats.Answered))
+	text.WriteString("📈 *30 hari terakhir:*\n")
+	if stats.Responded > 0 {
+		text.WriteString(fmt.Sprintf("⏱️ Rata-rata respons pertama: %s (%d tiket)\n", formatWaitDuration(stats.AvgFirstResponse), stats.Responded))
+	} else {
+		text.WriteString("⏱️ Rata-rata respons pertama: -\n")
+	}
+	if stats.ClosedRecently > 0 {
+		text.WriteString(fmt.Sprintf("✅ Rata-rata penyelesaian: %s (%d tiket)\n", formatWaitDuration(stats.AvgResolution), stats.ClosedRecently))
+	} else {
+		text.WriteString("✅ Rata-rata penyelesaian: -\n")
+	}
+	text.WriteString("\n")
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if len(tickets) == 0 {
+		text.WriteString("🎉 Tidak ada tiket yang menunggu.")
+	}
+	for _, ticket := range tickets {
+		text.WriteString(fmt.Sprintf("%s *#%d* %s\n", ticketStatusIcon(ticket.Status), ticket.ID,
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, ticket.CustomerName())))
+		if ticket.Status == models.TicketStatusOpen {
+			text.WriteString(fmt.Sprintf("   ⏳ Menunggu %s", formatWaitDuration(time.Since(ticket.LastCustomerMessageAt))))
+		} else {
+			text.WriteString(fmt.Sprintf("   💬 Dibalas, %d pesan", ticket.MessageCount))
+		}
+		text.WriteString(fmt.Sprintf(" · 👨‍💼 %s\n", b.ticketAssigneeName(&ticket, viewerID)))
+
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%s #%d · %s", ticketStatusIcon(ticket.Status), ticket.ID, ticket.CustomerName()),
+				fmt.Sprintf("admin:ticket:%d", ticket.ID)),
+		))
+	}
+
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔄 Refresh", "admin:tickets"),
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Panel Admin", "admin:main"),
+	))
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// handleAdminTickets shows the ticket queue in the admin panel
+func (b *Bot) handleAdminTickets(callback *tgbotapi.CallbackQuery) {
+	text, keyboard, err := b.buildTicketQueue(callback.From.ID)
+	if err != nil {
+		logrus.Errorf("Failed to build ticket queue: %v", err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat antrian tiket"))
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
+
+// buildTicketDetail renders a ticket with its latest messages for admins
+func (b *Bot) buildTicketDetail(ticket *models.SupportTicket, viewerID int64) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	messages, err := b.db.GetTicketMessages(ticket.ID, ticketHistorySize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, fmt.Errorf("failed to get ticket messages: %w", err)
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🎫 *TIKET #%d*\n\n", ticket.ID))
+	text.WriteString(fmt.Sprintf("👤 %s · ID `%d`\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, ticket.CustomerName()), ticket.UserID))
+	if ticket.OrderID != nil {
+		text.WriteString(fmt.Sprintf("📦 Order: `%s`\n", *ticket.OrderID))
+	}
+	text.WriteString(fmt.Sprintf("📊 Status: %s %s\n", ticketStatusIcon(ticket.Status), ticketStatusLabel(ticket.Status)))
+	text.WriteString(fmt.Sprintf("👨‍💼 Ditangani: %s\n", b.ticketAssigneeName(ticket, viewerID)))
+	text.WriteString(fmt.Sprintf("🕐 Dibuka: %s lalu\n", formatWaitDuration(time.Since(ticket.CreatedAt))))
+	if ticket.FirstResponseAt != nil {
+		text.WriteString(fmt.Sprintf("⏱️ Respons pertama: %s\n", formatWaitDuration(ticket.FirstResponseAt.Sub(ticket.CreatedAt))))
+	} else {
+		text.WriteString(fmt.Sprintf("⏱️ Belum dibalas (%s)\n", formatWaitDuration(time.Since(ticket.CreatedAt))))
+	}
+	if ticket.ClosedAt != nil {
+		text.WriteString(fmt.Sprintf("✅ Selesai dalam: %s\n", formatWaitDuration(ticket.ClosedAt.Sub(ticket.CreatedAt))))
+	}
+
+	text.WriteString(fmt.Sprintf("\n💬 *Percakapan* (%d pesan):\n", ticket.MessageCount))
+	if len(messages) < ticket.MessageCount {
+		text.WriteString(fmt.Sprintf("_...%d pesan sebelumnya tidak ditampilkan_\n", ticket.MessageCount-len(messages)))
+	}
+	for _, m := range messages {
+		sender := "👤"
+		if m.FromAdmin {
+			sender = "👨‍💼"
+		}
+		body := m.Text
+		if utf8.RuneCountInString(body) > 300 {
+			body = string([]rune(body)[:300]) + "..."
+		}
+		text.WriteString(fmt.Sprintf("\n%s _%s lalu_\n%s\n", sender, formatWaitDuration(time.Since(m.CreatedAt)),
+			tgbotapi.EscapeText(tgbotapi.ModeMarkdown, body)))
+	}
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	if ticket.Status != models.TicketStatusClosed {
+		row := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("↩️ Balas", fmt.Sprintf("admin:replyticket:%d", ticket.ID)),
+		)
+		if ticket.AssignedTo == nil || *ticket.AssignedTo != viewerID {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("🙋 Ambil", fmt.Sprintf("admin:claimticket:%d", ticket.ID)))
+		}
+		keyboard = append(keyboard, row, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Tutup Tiket", fmt.Sprintf("admin:closeticket:%d", ticket.ID)),
+		))
+	} else {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔓 Buka Kembali", fmt.Sprintf("admin:reopenticket:%d", ticket.ID)),
+		))
+	}
+	keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("👤 Moderasi", fmt.Sprintf("admin:moduser:%d:new", ticket.UserID)),
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Antrian Tiket", "admin:tickets"),
+	))
+
+	return text.String(), tgbotapi.NewInlineKeyboardMarkup(keyboard...), nil
+}
+
+// handleAdminTicketAction handles the ticket actions of the admin panel and of relayed ticket messages
+func (b *Bot) handleAdminTicketAction(callback *tgbotapi.CallbackQuery, action string, ticketID int) {
+	ticket, err := b.db.GetTicket(ticketID)
+	if err != nil || ticket == nil {
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Tiket tidak ditemukan"))
+		return
+	}
+
+	switch action {
+	case "claimticket":
+		if err := b.db.AssignTicket(ticketID, callback.From.ID); err != nil {
+			logrus.Errorf("Failed to assign ticket %d: %v", ticketID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal mengambil tiket"))
+			return
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, fmt.Sprintf("🙋 Tiket #%d sekarang Anda tangani", ticketID)))
+	case "closeticket":
+		closed, err := b.db.CloseTicket(ticketID)
+		if err != nil {
+			logrus.Errorf("Failed to close ticket %d: %v", ticketID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal menutup tiket"))
+			return
+		}
+		if closed {
+			b.takeConversation(ticket.UserID, models.StateSupportTicket)
+			b.sendMessage(ticket.UserID, b.t(b.userLanguage(ticket.UserID), "ticket.closed_by_admin", ticketID))
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, fmt.Sprintf("✅ Tiket #%d ditutup", ticketID)))
+	case "reopenticket":
+		if _, err := b.db.ReopenTicket(ticketID); err != nil {
+			logrus.Errorf("Failed to reopen ticket %d: %v", ticketID, err)
+			b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal membuka tiket"))
+			return
+		}
+		b.api.Request(tgbotapi.NewCallback(callback.ID, fmt.Sprintf("🔓 Tiket #%d dibuka kembali", ticketID)))
+	case "replyticket":
+		b.api.Request(tgbotapi.NewCallback(callback.ID, ""))
+		b.setConversation(callback.From.ID, models.StateTicketReply, strconv.Itoa(ticketID))
+		b.sendMessage(callback.Message.Chat.ID, fmt.Sprintf(
+			"↩️ Ketik balasan untuk *Tiket #%d* (teks atau foto).\n\nKetik /batal untuk membatalkan.", ticketID))
+		return
+	}
+
+	ticket, err = b.db.GetTicket(ticketID)
+	if err != nil || ticket == nil {
+		logrus.Errorf("Failed to reload ticket %d: %v", ticketID, err)
+		return
+	}
+	text, keyboard, err := b.buildTicketDetail(ticket, callback.From.ID)
+	if err != nil {
+		logrus.Errorf("Failed to build ticket %d: %v", ticketID, err)
+		b.api.Request(tgbotapi.NewCallback(callback.ID, "❌ Gagal memuat tiket"))
+		return
+	}
+
+	// Relayed messages must keep their text, since admins reply to them; show the ticket in a new message instead
+	relayTicketID, err := b.db.GetTicketIDByRelay(callback.Message.Chat.ID, callback.Message.MessageID)
+	if err != nil {
+		logrus.Errorf("Failed to look up ticket relay: %v", err)
+	}
+	if relayTicketID != 0 {
+		if action == "ticket" {
+			msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+			msg.ParseMode = tgbotapi.ModeMarkdown
+			msg.ReplyMarkup = keyboard
+			b.send(msg)
+		}
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeMarkdown
+	edit.ReplyMarkup = &keyboard
+	b.send(edit)
+}
@@ -38,25 +38,51 @@ type Config struct {
 	Timezone         string
 
 	// Application Settings
-	LogLevel        string
-	MaxCartItems    int
-	CurrencySymbol  string
-	DefaultImageURL string
+	LogLevel          string
+	MaxCartItems      int
+	CartExpiryHours   int // Cart items untouched this long are removed (0 disables)
+	CartReminderHours int // Idle cart age before a reminder is sent (0 disables)
+	CurrencySymbol    string
+	DefaultImageURL   string
+	ProductPhotoDir   string // Local copies of product photos uploaded from Telegram
+	DefaultLanguage   string // Language for users who have not picked one
+	LocalesDir        string // Optional directory of locale files overriding the bundled ones
+
+	// Purchase Limits (0 disables a limit)
+	MaxQtyPerProduct       int // Max units of one product a user may buy per period
+	PurchaseLimitPeriodHrs int // Length of the per-product purchase period in hours
+	MaxPendingOrders       int // Max unpaid orders a user may have at once
+	MaxOrderStock          int // Max units of stock a single pending order may hold
+
+	// Referral Program
+	ReferralRewardType  string // balance, coupon or percent
+	ReferralRewardValue int    // Balance/coupon amount, or percent of the first purchase (0 disables rewards)
+	ReferralCouponDays  int    // Validity of reward coupons in days
+
+	// Loyalty Points
+	LoyaltySpendPerPoint       int                // Amount spent per point earned (0 disables earning)
+	LoyaltyPointValue          int                // Discount per redeemed point (0 disables redeeming)
+	LoyaltyPointsExpiryDays    int                // Days until earned points expire (0 = never)
+	LoyaltyCategoryMultipliers map[string]float64 // Earning multiplier per category, e.g. music=2
+
+	// Reviews
+	ReviewPromptDelayHours int // Hours after payment before buyers are asked to review (0 disables prompts)
+
+	// Purchased Items
+	RevealDeleteMinutes int // Minutes before an item revealed from /produkku is deleted from the chat (0 keeps it)
+
+	// Rate Limiting (requests per minute per user, 0 disables a limit)
+	RateLimitGeneral     int // Any message, button or inline query
+	RateLimitCheckout    int // Checkouts and pre-order payments
+	RateLimitQR          int // QRIS payment images generated
+	RateLimitSearch      int // Searches, including inline queries
+	RateLimitMuteStrikes int // Throttled requests within a minute that mute the user (0 disables mutes)
+	RateLimitMuteMinutes int // Length of an automatic mute
 
 	// Payment Security
 	PaymentSecretKey string
 }
 
-// Messages contains all Indonesian messages for the bot
-type Messages struct {
-	Welcome     string
-	Help        string
-	Contact     string
-	CartEmpty   string
-	OrderSuccess string
-	PaymentInstructions string
-}
-
 // Load creates a new Config instance from environment variables
 func Load() *Config {
 	return &Config{
@@ -90,90 +116,52 @@ func Load() *Config {
 		Timezone:         getEnv("TIMEZONE", "Asia/Jakarta"),
 
 		// Application Settings
-		LogLevel:        getEnv("LOG_LEVEL", "INFO"),
-		MaxCartItems:    getEnvAsInt("MAX_CART_ITEMS", 10),
-		CurrencySymbol:  getEnv("CURRENCY_SYMBOL", "Rp"),
-		DefaultImageURL: getEnv("DEFAULT_PRODUCT_IMAGE", "https://via.placeholder.com/300x200?text=Premium+App"),
+		LogLevel:          getEnv("LOG_LEVEL", "INFO"),
+		MaxCartItems:      getEnvAsInt("MAX_CART_ITEMS", 10),
+		CartExpiryHours:   getEnvAsInt("CART_EXPIRY_HOURS", 72),
+		CartReminderHours: getEnvAsInt("CART_REMINDER_HOURS", 6),
+		CurrencySymbol:    getEnv("CURRENCY_SYMBOL", "Rp"),
+		DefaultImageURL:   getEnv("DEFAULT_PRODUCT_IMAGE", "https://via.placeholder.com/300x200?text=Premium+App"),
+		ProductPhotoDir:   getEnv("PRODUCT_PHOTO_DIR", "uploads/products"),
+		DefaultLanguage:   getEnv("DEFAULT_LANGUAGE", "id"),
+		LocalesDir:        getEnv("LOCALES_DIR", ""),
+
+		// Purchase Limits
+		MaxQtyPerProduct:       getEnvAsInt("MAX_QTY_PER_PRODUCT", 5),
+		PurchaseLimitPeriodHrs: getEnvAsInt("PURCHASE_LIMIT_PERIOD_HOURS", 24),
+		MaxPendingOrders:       getEnvAsInt("MAX_PENDING_ORDERS", 2),
+		MaxOrderStock:          getEnvAsInt("MAX_ORDER_STOCK", 20),
+
+		// Referral Program
+		ReferralRewardType:  getEnv("REFERRAL_REWARD_TYPE", "balance"),
+		ReferralRewardValue: getEnvAsInt("REFERRAL_REWARD_VALUE", 5000),
+		ReferralCouponDays:  getEnvAsInt("REFERRAL_COUPON_DAYS", 30),
+
+		// Loyalty Points
+		LoyaltySpendPerPoint:       getEnvAsInt("LOYALTY_SPEND_PER_POINT", 1000),
+		LoyaltyPointValue:          getEnvAsInt("LOYALTY_POINT_VALUE", 10),
+		LoyaltyPointsExpiryDays:    getEnvAsInt("LOYALTY_POINTS_EXPIRY_DAYS", 365),
+		LoyaltyCategoryMultipliers: parseCategoryMultipliers(getEnv("LOYALTY_CATEGORY_MULTIPLIERS", "")),
+
+		// Reviews
+		ReviewPromptDelayHours: getEnvAsInt("REVIEW_PROMPT_DELAY_HOURS", 24),
+
+		// Purchased Items
+		RevealDeleteMinutes: getEnvAsInt("REVEAL_DELETE_MINUTES", 5),
+
+		// Rate Limiting
+		RateLimitGeneral:     getEnvAsInt("RATE_LIMIT_GENERAL", 30),
+		RateLimitCheckout:    getEnvAsInt("RATE_LIMIT_CHECKOUT", 3),
+		RateLimitQR:          getEnvAsInt("RATE_LIMIT_QR", 2),
+		RateLimitSearch:      getEnvAsInt("RATE_LIMIT_SEARCH", 20),
+		RateLimitMuteStrikes: getEnvAsInt("RATE_LIMIT_MUTE_STRIKES", 15),
+		RateLimitMuteMinutes: getEnvAsInt("RATE_LIMIT_MUTE_MINUTES", 10),
 
 		// Payment Security
 		PaymentSecretKey: getEnv("PAYMENT_SECRET_KEY", ""),
 	}
 }
 
-// GetMessages returns all Indonesian messages
-func GetMessages() *Messages {
-	return &Messages{
-		Welcome: `🎉 *Selamat datang di Premium Apps Store!* 🎉
-
-Kami menyediakan berbagai aplikasi premium berkualitas tinggi dengan harga terjangkau dan pembayaran mudah melalui QRIS.
-
-📱 *Fitur Unggulan:*
-• Katalog aplikasi premium lengkap
-• Pembayaran QRIS dinamis & aman
-• Sistem keranjang belanja
-• Support 24/7
-• Garansi aplikasi
-
-Ketik /help untuk melihat semua perintah yang tersedia.`,
-
-		Help: `📋 *DAFTAR PERINTAH:*
-
-🏠 /start - Mulai menggunakan bot
-📱 /catalog - Lihat katalog aplikasi
-🛒 /cart - Lihat keranjang belanja
-💰 /history - Riwayat pembelian
-💳 /payment - Status pembayaran
-📞 /contact - Hubungi admin
-ℹ️ /help - Bantuan
-
-👨‍💼 *PERINTAH ADMIN:*
-/admin - Panel admin
-/addproduct - Tambah produk baru
-/users - Lihat daftar pengguna
-/orders - Kelola pesanan
-/stats - Statistik penjualan`,
-
-		Contact: `📞 *HUBUNGI KAMI:*
-
-👨‍💼 Admin: @%s
-📧 Email: %s
-📱 WhatsApp: %s
-⏰ Jam Operasional: %s
-
-💬 Untuk pertanyaan lebih lanjut, silakan hubungi admin di atas.
-🔄 Atau gunakan menu bantuan dengan mengetik /help`,
-
-		CartEmpty: `🛒 *KERANJANG BELANJA*
-
-Keranjang Anda kosong.
-Silakan pilih produk dari katalog terlebih dahulu.
-
-📱 Gunakan /catalog untuk melihat produk yang tersedia.`,
-
-		OrderSuccess: `✅ *PESANAN BERHASIL DIBUAT!*
-
-🆔 Order ID: #%s
-💰 Total: %s %s
-📅 Tanggal: %s
-
-Silakan lakukan pembayaran melalui QRIS yang telah digenerate.
-Pembayaran akan otomatis terverifikasi setelah berhasil.`,
-
-		PaymentInstructions: `💳 *INSTRUKSI PEMBAYARAN QRIS*
-
-1️⃣ Scan QR Code di bawah ini dengan aplikasi e-wallet atau mobile banking Anda
-2️⃣ Pastikan nominal pembayaran sesuai: *%s %s*
-3️⃣ Konfirmasi pembayaran di aplikasi Anda
-4️⃣ Pembayaran akan otomatis terverifikasi dalam 1-2 menit
-5️⃣ Anda akan menerima notifikasi setelah pembayaran berhasil
-
-⚠️ *PENTING:*
-• QR Code ini hanya berlaku untuk 15 menit
-• Jangan ubah nominal pembayaran
-• Simpan Order ID untuk referensi: *#%s*`,
-	}
-}
-
 // Helper functions
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -207,6 +195,29 @@ func parseAdminIDs(adminIDsStr string) []int64 {
 	return adminIDs
 }
 
+// parseCategoryMultipliers parses "music=2,entertainment=1.5" into a multiplier per category
+func parseCategoryMultipliers(value string) map[string]float64 {
+	multipliers := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		category, factor, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(factor), 64); err == nil && f >= 0 {
+			multipliers[strings.ToLower(strings.TrimSpace(category))] = f
+		}
+	}
+	return multipliers
+}
+
+// LoyaltyMultiplier returns the points multiplier of a category, 1 when none is configured
+func (c *Config) LoyaltyMultiplier(category string) float64 {
+	if f, ok := c.LoyaltyCategoryMultipliers[category]; ok {
+		return f
+	}
+	return 1
+}
+
 // IsAdmin checks if the given user ID is an admin
 func (c *Config) IsAdmin(userID int64) bool {
 	for _, adminID := range c.AdminIDs {
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 
 	"telegram-premium-store/internal/models"
@@ -8,14 +9,10 @@ import (
 
 // Product Account Management
 
-// GetAvailableAccountCount returns count of available accounts for a product
+// GetAvailableAccountCount returns count of available accounts for a product.
+// For bundles it is the number of complete bundles the component stock can fill.
 func (db *DB) GetAvailableAccountCount(productID int) (int, error) {
-	var count int
-	err := db.QueryRow(`
-		SELECT COUNT(*) FROM product_accounts 
-		WHERE product_id = ? AND is_sold = FALSE
-	`, productID).Scan(&count)
-	return count, err
+	return availableAccountCount(db, productID)
 }
 
 // GetAvailableAccounts returns available accounts for a product
@@ -23,7 +20,7 @@ func (db *DB) GetAvailableAccounts(productID int) ([]models.ProductAccount, erro
 	rows, err := db.Query(`
 		SELECT id, product_id, content_type, content_data, email, password, created_at
 		FROM product_accounts 
-		WHERE product_id = ? AND is_sold = FALSE
+		WHERE product_id = ? AND is_sold = FALSE AND is_revoked = FALSE
 		ORDER BY created_at ASC
 	`, productID)
 	if err != nil {
@@ -57,11 +54,7 @@ func (db *DB) CreateOrderWithAccounts(order *models.Order) ([]models.ProductAcco
 
 	// Check account availability for all items
 	for _, item := range order.Items {
-		var availableAccounts int
-		err = tx.QueryRow(`
-			SELECT COUNT(*) FROM product_accounts 
-			WHERE product_id = ? AND is_sold = FALSE
-		`, item.ProductID).Scan(&availableAccounts)
+		availableAccounts, err := availableAccountCount(tx, item.ProductID)
 		if err != nil {
 			return nil, err
 		}
@@ -72,93 +65,176 @@ func (db *DB) CreateOrderWithAccounts(order *models.Order) ([]models.ProductAcco
 		}
 	}
 
+	if err := checkOrderLimitsTx(tx, order); err != nil {
+		return nil, err
+	}
+
 	// Insert order
 	_, err = tx.Exec(`
-		INSERT INTO orders (id, user_id, total_amount, payment_method, payment_status, qris_code, qris_expiry)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO orders (id, user_id, total_amount, payment_method, payment_status, qris_code, qris_expiry,
+			discount_amount, coupon_id, coupon_code, balance_used, points_used, points_discount, customer_group)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, (SELECT customer_group FROM users WHERE user_id = ?))
 	`, order.ID, order.UserID, order.TotalAmount, order.PaymentMethod,
-		order.PaymentStatus, order.QRISCode, order.QRISExpiry)
+		order.PaymentStatus, order.QRISCode, order.QRISExpiry,
+		order.DiscountAmount, order.CouponID, order.CouponCode, order.BalanceUsed, order.PointsUsed, order.PointsDiscount, order.UserID)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := recordCouponRedemptionTx(tx, order); err != nil {
+		return nil, err
+	}
+
+	if err := debitOrderBalanceTx(tx, order); err != nil {
+		return nil, err
+	}
+
+	if err := spendOrderPointsTx(tx, order); err != nil {
+		return nil, err
+	}
+
+	if err := checkFlashSaleCapsTx(tx, order); err != nil {
+		return nil, err
+	}
+
 	// Insert order items and assign accounts
 	for _, item := range order.Items {
 		// Insert order item
 		_, err = tx.Exec(`
-			INSERT INTO order_items (order_id, product_id, quantity, price)
-			VALUES (?, ?, ?, ?)
-		`, order.ID, item.ProductID, item.Quantity, item.Price)
+			INSERT INTO order_items (order_id, product_id, quantity, price, flash_sale_id)
+			VALUES (?, ?, ?, ?, ?)
+		`, order.ID, item.ProductID, item.Quantity, item.Price, item.FlashSaleID)
 		if err != nil {
 			return nil, err
 		}
 
 		// Get and assign accounts
-		rows, err := tx.Query(`
-			SELECT id, content_type, content_data, email, password FROM product_accounts 
-			WHERE product_id = ? AND is_sold = FALSE
-			ORDER BY created_at ASC
-			LIMIT ?
-		`, item.ProductID, item.Quantity)
+		accounts, err := assignAccountsTx(tx, order, item)
 		if err != nil {
 			return nil, err
 		}
+		assignedAccounts = append(assignedAccounts, accounts...)
+	}
 
-		for rows.Next() {
-			var account models.ProductAccount
-			err := rows.Scan(&account.ID, &account.ContentType, &account.ContentData,
-				&account.Email, &account.Password)
-			if err != nil {
-				rows.Close()
-				return nil, err
-			}
-
-			// Mark account as sold
-			_, err = tx.Exec(`
-				UPDATE product_accounts 
-				SET is_sold = TRUE, sold_to_user_id = ?, sold_order_id = ?, sold_at = CURRENT_TIMESTAMP
-				WHERE id = ?
-			`, order.UserID, order.ID, account.ID)
-			if err != nil {
-				rows.Close()
-				return nil, err
-			}
-
-			// Add to sold accounts tracking
-			_, err = tx.Exec(`
-				INSERT INTO sold_accounts (order_id, product_id, account_id, user_id, content_type, content_data, email, password, sold_price)
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-			`, order.ID, item.ProductID, account.ID, order.UserID, account.ContentType, account.ContentData, account.Email, account.Password, item.Price)
-			if err != nil {
-				rows.Close()
-				return nil, err
-			}
-
-			account.ProductID = item.ProductID
-			assignedAccounts = append(assignedAccounts, account)
+	return assignedAccounts, tx.Commit()
+}
+
+// assignAccountsTx reserves the oldest available accounts of an order item for the buyer
+// and records them in sold_accounts. Bundle items reserve accounts of each component,
+// with the bundle price split among them; gift card items issue new codes instead.
+func assignAccountsTx(tx *sql.Tx, order *models.Order, item models.OrderItem) ([]models.ProductAccount, error) {
+	components, err := getBundleItems(tx, item.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(components) == 0 {
+		prices := make([]int, item.Quantity)
+		for i := range prices {
+			prices[i] = item.Price
 		}
-		rows.Close()
+
+		var giftCardValue, giftCardDays int
+		err := tx.QueryRow(`
+			SELECT COALESCE(gift_card_value, 0), COALESCE(gift_card_days, 0) FROM products WHERE id = ?
+		`, item.ProductID).Scan(&giftCardValue, &giftCardDays)
+		if err != nil {
+			return nil, err
+		}
+		if giftCardValue > 0 {
+			return issueGiftCardsTx(tx, order, item.ProductID, giftCardValue, giftCardDays, prices)
+		}
+		return assignProductAccountsTx(tx, order, item.ProductID, prices)
 	}
 
-	return assignedAccounts, tx.Commit()
+	shares := models.SplitBundlePrice(item.Price, components)
+	var assigned []models.ProductAccount
+	for _, component := range components {
+		var prices []int
+		for i := 0; i < item.Quantity; i++ {
+			prices = append(prices, shares[component.ProductID]...)
+		}
+		accounts, err := assignProductAccountsTx(tx, order, component.ProductID, prices)
+		if err != nil {
+			return nil, err
+		}
+		assigned = append(assigned, accounts...)
+	}
+	return assigned, nil
 }
 
-// GetProductAccountsForOrder returns accounts assigned to an order
-func (db *DB) GetProductAccountsForOrder(orderID string) ([]models.SoldAccount, error) {
-	rows, err := db.Query(`
-		SELECT sa.id, sa.order_id, sa.product_id, sa.account_id, sa.user_id, 
-			   sa.content_type, sa.content_data, sa.email, sa.password, 
-			   sa.sold_price, sa.sold_at, p.name as product_name,
-			   u.first_name, u.last_name, u.username
-		FROM sold_accounts sa
-		JOIN products p ON sa.product_id = p.id
-		LEFT JOIN users u ON sa.user_id = u.user_id
-		WHERE sa.order_id = ?
-		ORDER BY sa.sold_at ASC
-	`, orderID)
+// assignProductAccountsTx reserves one account of a product per entry in prices, recording each sale at that price
+func assignProductAccountsTx(tx *sql.Tx, order *models.Order, productID int, prices []int) ([]models.ProductAccount, error) {
+	quantity := len(prices)
+	rows, err := tx.Query(`
+		SELECT id, content_type, content_data, email, password, cost_price, supplier, batch_id FROM product_accounts 
+		WHERE product_id = ? AND is_sold = FALSE AND is_revoked = FALSE
+		ORDER BY created_at ASC
+		LIMIT ?
+	`, productID, quantity)
 	if err != nil {
 		return nil, err
 	}
+
+	var accounts []models.ProductAccount
+	for rows.Next() {
+		var account models.ProductAccount
+		err := rows.Scan(&account.ID, &account.ContentType, &account.ContentData,
+			&account.Email, &account.Password, &account.CostPrice, &account.Supplier, &account.BatchID)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		account.ProductID = productID
+		accounts = append(accounts, account)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(accounts) < quantity {
+		return nil, fmt.Errorf("insufficient accounts for product ID %d: available %d, requested %d",
+			productID, len(accounts), quantity)
+	}
+
+	for i, account := range accounts {
+		// Mark account as sold
+		_, err = tx.Exec(`
+			UPDATE product_accounts 
+			SET is_sold = TRUE, sold_to_user_id = ?, sold_order_id = ?, sold_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, order.UserID, order.ID, account.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Add to sold accounts tracking
+		_, err = tx.Exec(`
+			INSERT INTO sold_accounts (order_id, product_id, account_id, user_id, content_type, content_data, email, password, sold_price, cost_price, supplier, batch_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, order.ID, productID, account.ID, order.UserID, account.ContentType, account.ContentData, account.Email, account.Password, prices[i], account.CostPrice, account.Supplier, account.BatchID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return accounts, nil
+}
+
+// soldAccountSelect is the shared column list and joins for sold account queries
+const soldAccountSelect = `
+	SELECT sa.id, sa.order_id, sa.product_id, sa.account_id, sa.user_id,
+		   sa.content_type, sa.content_data, sa.email, sa.password,
+		   sa.sold_price, sa.cost_price, sa.supplier, sa.batch_id, sa.status, sa.sold_at,
+		   p.name as product_name, u.first_name, u.last_name, u.username
+	FROM sold_accounts sa
+	JOIN products p ON sa.product_id = p.id
+	LEFT JOIN users u ON sa.user_id = u.user_id
+`
+
+// scanSoldAccounts scans rows produced by a soldAccountSelect query
+func scanSoldAccounts(rows *sql.Rows) ([]models.SoldAccount, error) {
 	defer rows.Close()
 
 	var accounts []models.SoldAccount
@@ -166,8 +242,9 @@ func (db *DB) GetProductAccountsForOrder(orderID string) ([]models.SoldAccount,
 		var account models.SoldAccount
 		err := rows.Scan(&account.ID, &account.OrderID, &account.ProductID, &account.AccountID,
 			&account.UserID, &account.ContentType, &account.ContentData, &account.Email, &account.Password,
-			&account.SoldPrice, &account.SoldAt, &account.ProductName, &account.BuyerFirstName,
-			&account.BuyerLastName, &account.BuyerUsername)
+			&account.SoldPrice, &account.CostPrice, &account.Supplier, &account.BatchID, &account.Status,
+			&account.SoldAt, &account.ProductName, &account.BuyerFirstName, &account.BuyerLastName,
+			&account.BuyerUsername)
 		if err != nil {
 			return nil, err
 		}
@@ -177,16 +254,21 @@ func (db *DB) GetProductAccountsForOrder(orderID string) ([]models.SoldAccount,
 	return accounts, rows.Err()
 }
 
+// GetProductAccountsForOrder returns accounts assigned to an order
+func (db *DB) GetProductAccountsForOrder(orderID string) ([]models.SoldAccount, error) {
+	rows, err := db.Query(soldAccountSelect+`
+		WHERE sa.order_id = ?
+		ORDER BY sa.sold_at ASC, sa.id ASC
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSoldAccounts(rows)
+}
+
 // GetSoldAccountsByProduct returns sold accounts for a specific product
 func (db *DB) GetSoldAccountsByProduct(productID int, limit, offset int) ([]models.SoldAccount, error) {
-	rows, err := db.Query(`
-		SELECT sa.id, sa.order_id, sa.product_id, sa.account_id, sa.user_id,
-			   sa.content_type, sa.content_data, sa.email, sa.password,
-			   sa.sold_price, sa.sold_at, p.name as product_name,
-			   u.first_name, u.last_name, u.username
-		FROM sold_accounts sa
-		JOIN products p ON sa.product_id = p.id
-		LEFT JOIN users u ON sa.user_id = u.user_id
+	rows, err := db.Query(soldAccountSelect+`
 		WHERE sa.product_id = ?
 		ORDER BY sa.sold_at DESC
 		LIMIT ? OFFSET ?
@@ -194,22 +276,22 @@ func (db *DB) GetSoldAccountsByProduct(productID int, limit, offset int) ([]mode
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanSoldAccounts(rows)
+}
 
-	var accounts []models.SoldAccount
-	for rows.Next() {
-		var account models.SoldAccount
-		err := rows.Scan(&account.ID, &account.OrderID, &account.ProductID, &account.AccountID,
-			&account.UserID, &account.ContentType, &account.ContentData, &account.Email, &account.Password,
-			&account.SoldPrice, &account.SoldAt, &account.ProductName, &account.BuyerFirstName,
-			&account.BuyerLastName, &account.BuyerUsername)
-		if err != nil {
-			return nil, err
-		}
-		accounts = append(accounts, account)
+// GetSoldAccount returns a single sold account record
+func (db *DB) GetSoldAccount(id int) (*models.SoldAccount, error) {
+	rows, err := db.Query(soldAccountSelect+`
+		WHERE sa.id = ?
+	`, id)
+	if err != nil {
+		return nil, err
 	}
-
-	return accounts, rows.Err()
+	accounts, err := scanSoldAccounts(rows)
+	if err != nil || len(accounts) == 0 {
+		return nil, err
+	}
+	return &accounts[0], nil
 }
 
 // AddProductAccount adds new account to product stock (legacy format - deprecated)
@@ -227,6 +309,15 @@ func (db *DB) AddProductContent(productID int, contentType, contentData string)
 	return err
 }
 
+// AddProductContentWithCost adds a single stock item with its cost price and supplier
+func (db *DB) AddProductContentWithCost(productID int, contentType, contentData string, costPrice int, supplier string) error {
+	_, err := db.Exec(`
+		INSERT INTO product_accounts (product_id, content_type, content_data, cost_price, supplier)
+		VALUES (?, ?, ?, ?, ?)
+	`, productID, contentType, contentData, costPrice, nullableString(supplier))
+	return err
+}
+
 // GetProductStockSummary returns stock summary including available and sold accounts
 func (db *DB) GetProductStockSummary(productID int) (*models.StockSummary, error) {
 	var summary models.StockSummary
@@ -234,7 +325,7 @@ func (db *DB) GetProductStockSummary(productID int) (*models.StockSummary, error
 	// Get available accounts count
 	err := db.QueryRow(`
 		SELECT COUNT(*) FROM product_accounts 
-		WHERE product_id = ? AND is_sold = FALSE
+		WHERE product_id = ? AND is_sold = FALSE AND is_revoked = FALSE
 	`, productID).Scan(&summary.AvailableStock)
 	if err != nil {
 		return nil, err
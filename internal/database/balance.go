@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Store Balance
+
+// GetBalance returns the store balance of a user
+func (db *DB) GetBalance(userID int64) (int, error) {
+	var balance int
+	err := db.QueryRow(`SELECT COALESCE(balance, 0) FROM users WHERE user_id = ?`, userID).Scan(&balance)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return balance, err
+}
+
+// AdjustBalance credits (positive amount) or debits (negative amount) a user's balance and records it in the ledger
+func (db *DB) AdjustBalance(userID int64, amount int, txType models.BalanceTransactionType, reference, description string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := adjustBalanceTx(tx, userID, amount, txType, reference, description); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// adjustBalanceTx changes a balance inside a transaction. Debits fail instead of going below zero.
+func adjustBalanceTx(tx *sql.Tx, userID int64, amount int, txType models.BalanceTransactionType, reference, description string) error {
+	result, err := tx.Exec(`
+		UPDATE users SET balance = COALESCE(balance, 0) + ?
+		WHERE user_id = ? AND COALESCE(balance, 0) + ? >= 0
+	`, amount, userID, amount)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("insufficient balance for user %d: need %d", userID, -amount)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO balance_transactions (user_id, amount, type, reference, description)
+		VALUES (?, ?, ?, ?, ?)
+	`, userID, amount, txType, nullableString(reference), nullableString(description))
+	return err
+}
+
+// debitOrderBalanceTx spends the balance used by a new order
+func debitOrderBalanceTx(tx *sql.Tx, order *models.Order) error {
+	if order.BalanceUsed <= 0 {
+		return nil
+	}
+	return adjustBalanceTx(tx, order.UserID, -order.BalanceUsed, models.BalanceTxPurchase, order.ID, "Pembayaran pesanan")
+}
+
+// refundOrderBalanceTx returns the balance spent on an order that expired or was cancelled.
+// It is safe to call more than once; the ledger prevents a double refund.
+func refundOrderBalanceTx(tx *sql.Tx, orderID string) error {
+	var userID int64
+	var balanceUsed int
+	err := tx.QueryRow(`
+		SELECT user_id, COALESCE(balance_used, 0) FROM orders WHERE id = ?
+	`, orderID).Scan(&userID, &balanceUsed)
+	if err == sql.ErrNoRows || (err == nil && balanceUsed <= 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var refunded int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM balance_transactions WHERE type = ? AND reference = ?
+	`, models.BalanceTxOrderRefund, orderID).Scan(&refunded)
+	if err != nil || refunded > 0 {
+		return err
+	}
+
+	return adjustBalanceTx(tx, userID, balanceUsed, models.BalanceTxOrderRefund, orderID, "Pengembalian saldo pesanan batal/kedaluwarsa")
+}
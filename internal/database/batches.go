@@ -0,0 +1,382 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Stock Batch Management
+
+// stockBatchSelect is the shared column list for stock batch queries including item counts
+const stockBatchSelect = `
+	SELECT b.id, b.name, b.product_id, b.content_type, b.cost_price, b.supplier, b.item_count,
+		   b.created_by, b.created_at, b.revoked_at, b.revoke_reason, COALESCE(p.name, ''),
+		   (SELECT COUNT(*) FROM sold_accounts sa WHERE sa.batch_id = b.id),
+		   (SELECT COUNT(*) FROM product_accounts pa WHERE pa.batch_id = b.id AND pa.is_sold = FALSE AND pa.is_revoked = FALSE)
+	FROM stock_batches b
+	LEFT JOIN products p ON b.product_id = p.id
+`
+
+// scanStockBatches scans rows produced by a stockBatchSelect query
+func scanStockBatches(rows *sql.Rows) ([]models.StockBatch, error) {
+	defer rows.Close()
+
+	var batches []models.StockBatch
+	for rows.Next() {
+		var batch models.StockBatch
+		var createdBy sql.NullInt64
+		err := rows.Scan(&batch.ID, &batch.Name, &batch.ProductID, &batch.ContentType, &batch.CostPrice,
+			&batch.Supplier, &batch.ItemCount, &createdBy, &batch.CreatedAt, &batch.RevokedAt,
+			&batch.RevokeReason, &batch.ProductName, &batch.SoldCount, &batch.AvailableCount)
+		if err != nil {
+			return nil, err
+		}
+		batch.CreatedBy = createdBy.Int64
+		batches = append(batches, batch)
+	}
+
+	return batches, rows.Err()
+}
+
+// ImportProductContents adds stock items sharing the same cost price and supplier as a named batch
+func (db *DB) ImportProductContents(productID int, contentType string, contents []string, costPrice int, batchName, supplier string, createdBy int64) (*models.StockBatch, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO stock_batches (name, product_id, content_type, cost_price, supplier, item_count, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, batchName, productID, contentType, costPrice, nullableString(supplier), len(contents), createdBy)
+	if err != nil {
+		return nil, err
+	}
+
+	batchID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, contentData := range contents {
+		_, err := tx.Exec(`
+			INSERT INTO product_accounts (product_id, content_type, content_data, cost_price, supplier, batch_id)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, productID, contentType, contentData, costPrice, nullableString(supplier), batchID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import stock item %d: %w", i+1, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return db.GetStockBatch(int(batchID))
+}
+
+// GetStockBatches returns the most recent stock batches
+func (db *DB) GetStockBatches(limit int) ([]models.StockBatch, error) {
+	rows, err := db.Query(stockBatchSelect+`
+		ORDER BY b.created_at DESC, b.id DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanStockBatches(rows)
+}
+
+// GetStockBatch returns a stock batch by ID
+func (db *DB) GetStockBatch(id int) (*models.StockBatch, error) {
+	rows, err := db.Query(stockBatchSelect+`
+		WHERE b.id = ?
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	batches, err := scanStockBatches(rows)
+	if err != nil || len(batches) == 0 {
+		return nil, err
+	}
+	return &batches[0], nil
+}
+
+// RevokeBatch marks a batch as revoked and pulls its unsold items from sale.
+// Items reserved by unpaid orders are swapped for fresh stock when available.
+// Items already delivered in paid orders are returned for follow-up.
+func (db *DB) RevokeBatch(batchID int, reason string) (*models.BatchRevokeResult, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE stock_batches SET revoked_at = CURRENT_TIMESTAMP, revoke_reason = ?
+		WHERE id = ? AND revoked_at IS NULL
+	`, nullableString(reason), batchID)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("batch %d not found or already revoked", batchID)
+	}
+
+	result := &models.BatchRevokeResult{}
+
+	// Pull unsold items from sale
+	res, err = tx.Exec(`
+		UPDATE product_accounts SET is_revoked = TRUE
+		WHERE batch_id = ? AND is_sold = FALSE
+	`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	pulled, _ := res.RowsAffected()
+	result.PulledCount = int(pulled)
+
+	// Collect items reserved by orders that are still awaiting payment
+	rows, err := tx.Query(`
+		SELECT sa.id, sa.product_id, sa.account_id, sa.user_id, sa.order_id
+		FROM sold_accounts sa
+		JOIN orders o ON sa.order_id = o.id
+		WHERE sa.batch_id = ? AND o.payment_status = 'pending'
+	`, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reserved []models.SoldAccount
+	for rows.Next() {
+		var account models.SoldAccount
+		if err := rows.Scan(&account.ID, &account.ProductID, &account.AccountID, &account.UserID, &account.OrderID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		reserved = append(reserved, account)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, account := range reserved {
+		fresh, err := assignFreshAccount(tx, account.ProductID, account.UserID, account.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		if fresh == nil {
+			result.UnswappedPending++
+			continue
+		}
+
+		_, err = tx.Exec(`
+			UPDATE sold_accounts
+			SET account_id = ?, content_type = ?, content_data = ?, email = ?, password = ?,
+				cost_price = ?, supplier = ?, batch_id = ?
+			WHERE id = ?
+		`, fresh.ID, fresh.ContentType, fresh.ContentData, fresh.Email, fresh.Password,
+			fresh.CostPrice, fresh.Supplier, fresh.BatchID, account.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.SwappedPending++
+	}
+
+	// Revoked items must never return to sale, even if their order expires
+	_, err = tx.Exec(`UPDATE product_accounts SET is_revoked = TRUE WHERE batch_id = ?`, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	result.Affected, err = db.GetAffectedSoldAccounts(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// assignFreshAccount reserves the oldest available non-revoked item of a product for an order.
+// Returns nil when no stock is available.
+func assignFreshAccount(tx *sql.Tx, productID int, userID int64, orderID string) (*models.ProductAccount, error) {
+	var account models.ProductAccount
+	err := tx.QueryRow(`
+		SELECT id, product_id, content_type, content_data, email, password, cost_price, supplier, batch_id
+		FROM product_accounts
+		WHERE product_id = ? AND is_sold = FALSE AND is_revoked = FALSE
+		ORDER BY created_at ASC
+		LIMIT 1
+	`, productID).Scan(&account.ID, &account.ProductID, &account.ContentType, &account.ContentData,
+		&account.Email, &account.Password, &account.CostPrice, &account.Supplier, &account.BatchID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE product_accounts
+		SET is_sold = TRUE, sold_to_user_id = ?, sold_order_id = ?, sold_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, userID, orderID, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// GetAffectedSoldAccounts returns delivered items of a batch in paid orders that are not yet resolved
+func (db *DB) GetAffectedSoldAccounts(batchID int) ([]models.SoldAccount, error) {
+	rows, err := db.Query(soldAccountSelect+`
+		JOIN orders o ON sa.order_id = o.id
+		WHERE sa.batch_id = ? AND o.payment_status = 'paid' AND sa.status = 'active'
+		ORDER BY sa.order_id, sa.id
+	`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSoldAccounts(rows)
+}
+
+// ReplaceSoldAccount swaps a delivered item for a fresh one from stock.
+// The replacement is recorded as a new sold item with no revenue so its cost shows up in profit reports.
+func (db *DB) ReplaceSoldAccount(soldAccountID int) (*models.SoldAccount, error) {
+	old, err := db.GetSoldAccount(soldAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if old == nil {
+		return nil, fmt.Errorf("sold account %d not found", soldAccountID)
+	}
+	if old.Status != models.SoldAccountActive {
+		return nil, fmt.Errorf("sold account %d is already %s", soldAccountID, old.Status)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	fresh, err := assignFreshAccount(tx, old.ProductID, old.UserID, old.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if fresh == nil {
+		return nil, fmt.Errorf("no replacement stock available for product %d", old.ProductID)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO sold_accounts (order_id, product_id, account_id, user_id, content_type, content_data, email, password, sold_price, cost_price, supplier, batch_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?, ?)
+	`, old.OrderID, old.ProductID, fresh.ID, old.UserID, fresh.ContentType, fresh.ContentData,
+		fresh.Email, fresh.Password, fresh.CostPrice, fresh.Supplier, fresh.BatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	newID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE sold_accounts SET status = ?, resolved_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, models.SoldAccountReplaced, soldAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return db.GetSoldAccount(int(newID))
+}
+
+// RefundSoldAccount marks a delivered item as refunded and credits what the buyer paid for it, after coupons and
+// points, to their store balance. The order is marked refunded once none of its items remain active.
+// Returns the item and the refunded amount.
+func (db *DB) RefundSoldAccount(soldAccountID int) (*models.SoldAccount, int, error) {
+	account, err := db.GetSoldAccount(soldAccountID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if account == nil {
+		return nil, 0, fmt.Errorf("sold account %d not found", soldAccountID)
+	}
+	if account.Status != models.SoldAccountActive {
+		return nil, 0, fmt.Errorf("sold account %d is already %s", soldAccountID, account.Status)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	var paid int
+	err = tx.QueryRow(`
+		SELECT `+paidPriceColumn+`
+		FROM sold_accounts sa
+		JOIN orders o ON sa.order_id = o.id
+		`+orderSubtotalJoin+`
+		WHERE sa.id = ?
+	`, soldAccountID).Scan(&paid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE sold_accounts SET status = ?, resolved_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, models.SoldAccountRefunded, soldAccountID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if paid > 0 {
+		if err := adjustBalanceTx(tx, account.UserID, paid, models.BalanceTxItemRefund, account.OrderID,
+			fmt.Sprintf("Refund %s", account.ProductName)); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := reverseSoldAccountPointsTx(tx, soldAccountID); err != nil {
+		return nil, 0, err
+	}
+
+	var remaining int
+	err = tx.QueryRow(`
+		SELECT COUNT(*) FROM sold_accounts WHERE order_id = ? AND status = 'active'
+	`, account.OrderID).Scan(&remaining)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if remaining == 0 {
+		_, err = tx.Exec(`
+			UPDATE orders SET payment_status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+		`, models.PaymentStatusRefunded, account.OrderID)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+
+	account.Status = models.SoldAccountRefunded
+	return account, paid, nil
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Product Bundles
+
+// querier is implemented by both *DB and *sql.Tx
+type querier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// availableAccountCount returns the unsold stock of a product. A bundle is limited by its scarcest
+// component, and an inactive component makes the bundle unavailable. Gift cards are never out of stock.
+func availableAccountCount(q querier, productID int) (int, error) {
+	var count int
+	err := q.QueryRow(`
+		SELECT CASE WHEN COALESCE(p.gift_card_value, 0) > 0 THEN ?
+		WHEN COALESCE(p.is_bundle, FALSE) THEN
+			(SELECT COALESCE(MIN(CASE WHEN c.is_active THEN
+				(SELECT COUNT(*) FROM product_accounts pa
+				 WHERE pa.product_id = bi.product_id AND pa.is_sold = FALSE AND pa.is_revoked = FALSE) / bi.quantity
+				ELSE 0 END), 0)
+			 FROM bundle_items bi JOIN products c ON c.id = bi.product_id
+			 WHERE bi.bundle_id = p.id)
+		ELSE
+			(SELECT COUNT(*) FROM product_accounts pa
+			 WHERE pa.product_id = p.id AND pa.is_sold = FALSE AND pa.is_revoked = FALSE)
+		END
+		FROM products p WHERE p.id = ?
+	`, models.GiftCardStock, productID).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return count, err
+}
+
+// getBundleItems returns the components of a bundle; empty for regular products
+func getBundleItems(q querier, bundleID int) ([]models.BundleItem, error) {
+	rows, err := q.Query(`
+		SELECT bi.bundle_id, bi.product_id, bi.quantity, p.name, p.price
+		FROM bundle_items bi
+		JOIN products p ON p.id = bi.product_id
+		WHERE bi.bundle_id = ?
+		ORDER BY p.name
+	`, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.BundleItem
+	for rows.Next() {
+		var item models.BundleItem
+		if err := rows.Scan(&item.BundleID, &item.ProductID, &item.Quantity,
+			&item.ProductName, &item.ProductPrice); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// GetBundleItems returns the components of a bundle with their current names and prices
+func (db *DB) GetBundleItems(bundleID int) ([]models.BundleItem, error) {
+	return getBundleItems(db, bundleID)
+}
+
+// CreateBundle inserts a bundle product and its components.
+// Components must be active regular products; bundles cannot contain other bundles or gift cards.
+// A bundle holds at least 2 units, e.g. two different products or 2x the same one.
+func (db *DB) CreateBundle(bundle *models.Product, items []models.BundleItem) error {
+	units := 0
+	for _, item := range items {
+		units += item.Quantity
+	}
+	if units < 2 {
+		return fmt.Errorf("a bundle needs at least 2 units")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		var isBundle, isGiftCard bool
+		err := tx.QueryRow(`
+			SELECT COALESCE(is_bundle, FALSE), COALESCE(gift_card_value, 0) > 0 FROM products WHERE id = ? AND is_active = TRUE
+		`, item.ProductID).Scan(&isBundle, &isGiftCard)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("component product %d not found", item.ProductID)
+		}
+		if err != nil {
+			return err
+		}
+		if isBundle {
+			return fmt.Errorf("component product %d is a bundle", item.ProductID)
+		}
+		if isGiftCard {
+			return fmt.Errorf("component product %d is a gift card", item.ProductID)
+		}
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO products (name, description, price, category, image_url, stock, is_bundle)
+		VALUES (?, ?, ?, ?, ?, 0, TRUE)
+	`, bundle.Name, bundle.Description, bundle.Price, bundle.Category, bundle.ImageURL)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		_, err = tx.Exec(`
+			INSERT INTO bundle_items (bundle_id, product_id, quantity) VALUES (?, ?, ?)
+		`, id, item.ProductID, item.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	bundle.ID = int(id)
+	bundle.IsBundle = true
+	bundle.IsActive = true
+	return nil
+}
+
+// GetBundles returns all active bundle products
+func (db *DB) GetBundles() ([]models.Product, error) {
+	rows, err := db.Query(`
+		SELECT ` + productColumns + `
+		FROM products
+		WHERE is_active = TRUE AND is_bundle = TRUE
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var product models.Product
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Conversation State
+//
+// Each user has at most one conversation state. Expired states are ignored on read and purged
+// by the scheduler. Every operation is a single statement, so concurrent updates from the
+// same user cannot interleave.
+
+// SetConversation puts a user in a conversation state, replacing any previous one
+func (db *DB) SetConversation(userID int64, state models.ConversationState, payload string, ttl time.Duration) error {
+	_, err := db.Exec(`
+		INSERT INTO conversation_states (user_id, state, payload, expires_at, updated_at)
+		VALUES (?, ?, ?, datetime('now', ?), CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id) DO UPDATE SET
+			state = excluded.state,
+			payload = excluded.payload,
+			expires_at = excluded.expires_at,
+			updated_at = excluded.updated_at
+	`, userID, state, payload, fmt.Sprintf("%+d seconds", int(ttl.Seconds())))
+	return err
+}
+
+// GetConversation returns the user's conversation state, or nil when they have none or it expired
+func (db *DB) GetConversation(userID int64) (*models.Conversation, error) {
+	var conv models.Conversation
+	err := db.QueryRow(`
+		SELECT user_id, state, payload, expires_at, updated_at
+		FROM conversation_states
+		WHERE user_id = ? AND expires_at > datetime('now')
+	`, userID).Scan(&conv.UserID, &conv.State, &conv.Payload, &conv.ExpiresAt, &conv.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+// TakeConversation ends the user's conversation if it is in the given state and returns its payload.
+// Returns false when the user is not in that state, so of two concurrent messages only one
+// continues the flow.
+func (db *DB) TakeConversation(userID int64, state models.ConversationState) (string, bool, error) {
+	var payload string
+	err := db.QueryRow(`
+		DELETE FROM conversation_states
+		WHERE user_id = ? AND state = ? AND expires_at > datetime('now')
+		RETURNING payload
+	`, userID, state).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return payload, true, nil
+}
+
+// ClearConversation ends the user's conversation. Returns the state that was cleared,
+// or an empty state when the user was not in a flow.
+func (db *DB) ClearConversation(userID int64) (models.ConversationState, error) {
+	var state models.ConversationState
+	err := db.QueryRow(`
+		DELETE FROM conversation_states
+		WHERE user_id = ?
+		RETURNING CASE WHEN expires_at > datetime('now') THEN state ELSE '' END
+	`, userID).Scan(&state)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return state, err
+}
+
+// DeleteExpiredConversations purges expired conversation states and returns how many were removed
+func (db *DB) DeleteExpiredConversations() (int64, error) {
+	result, err := db.Exec(`DELETE FROM conversation_states WHERE expires_at <= datetime('now')`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,250 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Coupons
+
+// couponSelect selects coupons with their usage counters; extend with WHERE/ORDER BY clauses
+const couponSelect = `
+	SELECT c.id, c.code, c.discount_type, c.discount_value, COALESCE(c.max_discount, 0), COALESCE(c.min_spend, 0),
+		   c.product_id, c.category, c.starts_at, c.ends_at, COALESCE(c.usage_limit, 0), COALESCE(c.per_user_limit, 0),
+		   c.first_purchase_only, c.user_id, c.is_active, COALESCE(c.created_by, 0), c.created_at,
+		   (SELECT COUNT(*) FROM coupon_redemptions r JOIN orders o ON r.order_id = o.id
+			WHERE r.coupon_id = c.id AND o.payment_status NOT IN ('expired', 'cancelled')),
+		   (SELECT COALESCE(SUM(r.discount_amount), 0) FROM coupon_redemptions r JOIN orders o ON r.order_id = o.id
+			WHERE r.coupon_id = c.id AND o.payment_status IN ('paid', 'awaiting_stock'))
+	FROM coupons c`
+
+func scanCoupons(rows *sql.Rows) ([]models.Coupon, error) {
+	var coupons []models.Coupon
+	for rows.Next() {
+		var c models.Coupon
+		err := rows.Scan(&c.ID, &c.Code, &c.DiscountType, &c.DiscountValue, &c.MaxDiscount, &c.MinSpend,
+			&c.ProductID, &c.Category, &c.StartsAt, &c.EndsAt, &c.UsageLimit, &c.PerUserLimit,
+			&c.FirstPurchaseOnly, &c.UserID, &c.IsActive, &c.CreatedBy, &c.CreatedAt,
+			&c.UsedCount, &c.TotalDiscount)
+		if err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, c)
+	}
+	return coupons, rows.Err()
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// CreateCoupon inserts a new coupon. Codes are stored upper-case and matched case-insensitively.
+func (db *DB) CreateCoupon(coupon *models.Coupon) error {
+	return insertCoupon(db, coupon)
+}
+
+func insertCoupon(exec execer, coupon *models.Coupon) error {
+	coupon.Code = strings.ToUpper(strings.TrimSpace(coupon.Code))
+	result, err := exec.Exec(`
+		INSERT INTO coupons (code, discount_type, discount_value, max_discount, min_spend, product_id, category,
+			starts_at, ends_at, usage_limit, per_user_limit, first_purchase_only, user_id, is_active, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, TRUE, ?)
+	`, coupon.Code, coupon.DiscountType, coupon.DiscountValue, coupon.MaxDiscount, coupon.MinSpend,
+		coupon.ProductID, coupon.Category, coupon.StartsAt, coupon.EndsAt, coupon.UsageLimit,
+		coupon.PerUserLimit, coupon.FirstPurchaseOnly, coupon.UserID, coupon.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	coupon.ID = int(id)
+	coupon.IsActive = true
+	return nil
+}
+
+// GetCouponByCode returns a coupon by its code, or nil if it does not exist
+func (db *DB) GetCouponByCode(code string) (*models.Coupon, error) {
+	rows, err := db.Query(couponSelect+` WHERE c.code = ?`, strings.TrimSpace(code))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	coupons, err := scanCoupons(rows)
+	if err != nil || len(coupons) == 0 {
+		return nil, err
+	}
+	return &coupons[0], nil
+}
+
+// GetCoupons returns all coupons, active ones first
+func (db *DB) GetCoupons() ([]models.Coupon, error) {
+	rows, err := db.Query(couponSelect + ` ORDER BY c.is_active DESC, c.created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCoupons(rows)
+}
+
+// SetCouponActive enables or disables a coupon. Returns false if the code does not exist.
+func (db *DB) SetCouponActive(code string, active bool) (bool, error) {
+	result, err := db.Exec(`UPDATE coupons SET is_active = ? WHERE code = ?`, active, strings.TrimSpace(code))
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CountUserCouponRedemptions returns how many live orders of a user used a coupon
+func (db *DB) CountUserCouponRedemptions(couponID int, userID int64) (int, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM coupon_redemptions r
+		JOIN orders o ON r.order_id = o.id
+		WHERE r.coupon_id = ? AND r.user_id = ? AND o.payment_status NOT IN ('expired', 'cancelled')
+	`, couponID, userID).Scan(&count)
+	return count, err
+}
+
+// HasCompletedPurchase returns true if the user has at least one paid order
+func (db *DB) HasCompletedPurchase(userID int64) (bool, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM orders
+		WHERE user_id = ? AND payment_status IN ('paid', 'awaiting_stock', 'refunded')
+	`, userID).Scan(&count)
+	return count > 0, err
+}
+
+// GetCouponRedemptions returns the most recent redemptions of a coupon
+func (db *DB) GetCouponRedemptions(couponID, limit int) ([]models.CouponRedemption, error) {
+	rows, err := db.Query(`
+		SELECT r.id, r.coupon_id, r.order_id, r.user_id, r.discount_amount, r.created_at, o.payment_status
+		FROM coupon_redemptions r
+		JOIN orders o ON r.order_id = o.id
+		WHERE r.coupon_id = ?
+		ORDER BY r.created_at DESC
+		LIMIT ?
+	`, couponID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redemptions []models.CouponRedemption
+	for rows.Next() {
+		var r models.CouponRedemption
+		if err := rows.Scan(&r.ID, &r.CouponID, &r.OrderID, &r.UserID, &r.DiscountAmount,
+			&r.CreatedAt, &r.PaymentStatus); err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, r)
+	}
+	return redemptions, rows.Err()
+}
+
+// recordCouponRedemptionTx re-checks the coupon usage limits inside the order transaction
+// and records the redemption. Does nothing for orders without a coupon.
+func recordCouponRedemptionTx(tx *sql.Tx, order *models.Order) error {
+	if order.CouponID == nil {
+		return nil
+	}
+
+	var usageLimit, perUserLimit, used, usedByUser int
+	var active bool
+	err := tx.QueryRow(`
+		SELECT is_active, COALESCE(usage_limit, 0), COALESCE(per_user_limit, 0) FROM coupons WHERE id = ?
+	`, *order.CouponID).Scan(&active, &usageLimit, &perUserLimit)
+	if err != nil {
+		return fmt.Errorf("coupon %d not found: %w", *order.CouponID, err)
+	}
+	if !active {
+		return fmt.Errorf("coupon %d is no longer active", *order.CouponID)
+	}
+
+	err = tx.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN r.user_id = ? THEN 1 ELSE 0 END), 0)
+		FROM coupon_redemptions r
+		JOIN orders o ON r.order_id = o.id
+		WHERE r.coupon_id = ? AND o.payment_status NOT IN ('expired', 'cancelled')
+	`, order.UserID, *order.CouponID).Scan(&used, &usedByUser)
+	if err != nil {
+		return err
+	}
+	if usageLimit > 0 && used >= usageLimit {
+		return fmt.Errorf("coupon %d usage limit reached: %d/%d", *order.CouponID, used, usageLimit)
+	}
+	if perUserLimit > 0 && usedByUser >= perUserLimit {
+		return fmt.Errorf("coupon %d per-user limit reached for user %d", *order.CouponID, order.UserID)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO coupon_redemptions (coupon_id, order_id, user_id, discount_amount)
+		VALUES (?, ?, ?, ?)
+	`, *order.CouponID, order.ID, order.UserID, order.DiscountAmount)
+	return err
+}
+
+// Cart options
+
+// SetCartCoupon stores the coupon code the user applied to their cart
+func (db *DB) SetCartCoupon(userID int64, code string) error {
+	_, err := db.Exec(`
+		INSERT INTO cart_options (user_id, coupon_code, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id) DO UPDATE SET coupon_code = excluded.coupon_code, updated_at = CURRENT_TIMESTAMP
+	`, userID, strings.ToUpper(strings.TrimSpace(code)))
+	return err
+}
+
+// GetCartOptions returns the checkout options of the user's cart; zero values when none were set
+func (db *DB) GetCartOptions(userID int64) (*models.CartOptions, error) {
+	var code sql.NullString
+	var useBalance, usePoints sql.NullBool
+	err := db.QueryRow(`
+		SELECT coupon_code, use_balance, use_points FROM cart_options WHERE user_id = ?
+	`, userID).Scan(&code, &useBalance, &usePoints)
+	if err == sql.ErrNoRows {
+		return &models.CartOptions{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.CartOptions{CouponCode: code.String, UseBalance: useBalance.Bool, UsePoints: usePoints.Bool}, nil
+}
+
+// SetCartUseBalance sets whether the store balance is spent at checkout
+func (db *DB) SetCartUseBalance(userID int64, useBalance bool) error {
+	_, err := db.Exec(`
+		INSERT INTO cart_options (user_id, use_balance, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id) DO UPDATE SET use_balance = excluded.use_balance, updated_at = CURRENT_TIMESTAMP
+	`, userID, useBalance)
+	return err
+}
+
+// SetCartUsePoints sets whether loyalty points are redeemed at checkout
+func (db *DB) SetCartUsePoints(userID int64, usePoints bool) error {
+	_, err := db.Exec(`
+		INSERT INTO cart_options (user_id, use_points, updated_at)
+		VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(user_id) DO UPDATE SET use_points = excluded.use_points, updated_at = CURRENT_TIMESTAMP
+	`, userID, usePoints)
+	return err
+}
+
+// ClearCartCoupon removes the coupon applied to the user's cart
+func (db *DB) ClearCartCoupon(userID int64) error {
+	_, err := db.Exec(`UPDATE cart_options SET coupon_code = NULL, updated_at = CURRENT_TIMESTAMP WHERE user_id = ?`, userID)
+	return err
+}
@@ -15,6 +15,7 @@ import (
 // DB wraps the sql.DB connection
 type DB struct {
 	*sql.DB
+	fullTextSearch bool // Whether the SQLite build has FTS5 for the product search index
 }
 
 // Initialize creates and initializes the database
@@ -29,7 +30,7 @@ func Initialize(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	dbWrapper := &DB{db}
+	dbWrapper := &DB{DB: db}
 
 	// Run migrations
 	if err := dbWrapper.migrate(); err != nil {
@@ -46,6 +47,11 @@ func Initialize(dbPath string) (*DB, error) {
 		logrus.Warn("Failed to insert default categories: ", err)
 	}
 
+	// Build the product search index
+	if err := dbWrapper.setupSearchIndex(); err != nil {
+		logrus.Warn("Failed to set up product search index: ", err)
+	}
+
 	logrus.Info("✅ Database initialized successfully")
 	return dbWrapper, nil
 }
@@ -227,6 +233,377 @@ func (db *DB) migrate() error {
 		`CREATE INDEX IF NOT EXISTS idx_payment_verifications_order ON payment_verifications(order_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_sold_accounts_order ON sold_accounts(order_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_sold_accounts_product ON sold_accounts(product_id)`,
+
+		// Migration: Cost price and supplier tracking per stock item
+		`ALTER TABLE product_accounts ADD COLUMN cost_price INTEGER DEFAULT 0`,
+		`ALTER TABLE product_accounts ADD COLUMN supplier TEXT`,
+		`ALTER TABLE sold_accounts ADD COLUMN cost_price INTEGER DEFAULT 0`,
+		`ALTER TABLE sold_accounts ADD COLUMN supplier TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_sold_accounts_supplier ON sold_accounts(supplier)`,
+
+		// Stock batches table (groups imported stock items for supplier tracking and bulk revoke)
+		`CREATE TABLE IF NOT EXISTS stock_batches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			product_id INTEGER NOT NULL,
+			content_type TEXT DEFAULT 'account',
+			cost_price INTEGER DEFAULT 0,
+			supplier TEXT,
+			item_count INTEGER DEFAULT 0,
+			created_by INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			revoked_at DATETIME,
+			revoke_reason TEXT,
+			FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+		)`,
+
+		// Migration: Batch membership and revoke status per stock item
+		`ALTER TABLE product_accounts ADD COLUMN batch_id INTEGER REFERENCES stock_batches (id) ON DELETE SET NULL`,
+		`ALTER TABLE product_accounts ADD COLUMN is_revoked BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE sold_accounts ADD COLUMN batch_id INTEGER`,
+		`ALTER TABLE sold_accounts ADD COLUMN status TEXT DEFAULT 'active'`,
+		`ALTER TABLE sold_accounts ADD COLUMN resolved_at DATETIME`,
+		`CREATE INDEX IF NOT EXISTS idx_product_accounts_batch ON product_accounts(batch_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_sold_accounts_batch ON sold_accounts(batch_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_stock_batches_product ON stock_batches(product_id)`,
+
+		// Migration: Pre-orders for out-of-stock products
+		`ALTER TABLE products ADD COLUMN preorder_enabled BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE products ADD COLUMN preorder_limit INTEGER DEFAULT 0`,
+		`ALTER TABLE products ADD COLUMN preorder_eta DATETIME`,
+		`ALTER TABLE orders ADD COLUMN is_preorder BOOLEAN DEFAULT FALSE`,
+		`CREATE INDEX IF NOT EXISTS idx_orders_preorder ON orders(is_preorder, payment_status)`,
+
+		// Migration: Cart price snapshot, abandoned-cart reminders and opt-out
+		`ALTER TABLE cart ADD COLUMN price_at_add INTEGER`,
+		`ALTER TABLE cart ADD COLUMN reminder_sent_at DATETIME`,
+		`ALTER TABLE users ADD COLUMN cart_reminders BOOLEAN DEFAULT TRUE`,
+		`CREATE INDEX IF NOT EXISTS idx_cart_added_at ON cart(added_at)`,
+
+		// Coupons table
+		`CREATE TABLE IF NOT EXISTS coupons (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			code TEXT UNIQUE NOT NULL COLLATE NOCASE,
+			discount_type TEXT NOT NULL DEFAULT 'percent',
+			discount_value INTEGER NOT NULL,
+			max_discount INTEGER DEFAULT 0,
+			min_spend INTEGER DEFAULT 0,
+			product_id INTEGER REFERENCES products (id) ON DELETE SET NULL,
+			category TEXT,
+			starts_at DATETIME,
+			ends_at DATETIME,
+			usage_limit INTEGER DEFAULT 0,
+			per_user_limit INTEGER DEFAULT 1,
+			first_purchase_only BOOLEAN DEFAULT FALSE,
+			is_active BOOLEAN DEFAULT TRUE,
+			created_by INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+
+		// Coupon redemptions table (one row per order that used a coupon)
+		`CREATE TABLE IF NOT EXISTS coupon_redemptions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			coupon_id INTEGER NOT NULL,
+			order_id TEXT UNIQUE NOT NULL,
+			user_id INTEGER NOT NULL,
+			discount_amount INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (coupon_id) REFERENCES coupons (id) ON DELETE CASCADE,
+			FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_coupon ON coupon_redemptions(coupon_id, user_id)`,
+
+		// Per-user checkout options kept alongside the cart
+		`CREATE TABLE IF NOT EXISTS cart_options (
+			user_id INTEGER PRIMARY KEY,
+			coupon_code TEXT,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+		)`,
+
+		// Migration: Coupon discount on orders
+		`ALTER TABLE orders ADD COLUMN discount_amount INTEGER DEFAULT 0`,
+		`ALTER TABLE orders ADD COLUMN coupon_id INTEGER`,
+		`ALTER TABLE orders ADD COLUMN coupon_code TEXT`,
+
+		// Migration: Referral program and store balance
+		`ALTER TABLE users ADD COLUMN referral_code TEXT`,
+		`ALTER TABLE users ADD COLUMN referred_by INTEGER`,
+		`ALTER TABLE users ADD COLUMN referred_at DATETIME`,
+		`ALTER TABLE users ADD COLUMN balance INTEGER DEFAULT 0`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_referral_code ON users(referral_code)`,
+		`CREATE INDEX IF NOT EXISTS idx_users_referred_by ON users(referred_by)`,
+		`ALTER TABLE orders ADD COLUMN balance_used INTEGER DEFAULT 0`,
+		`ALTER TABLE cart_options ADD COLUMN use_balance BOOLEAN DEFAULT FALSE`,
+		`ALTER TABLE coupons ADD COLUMN user_id INTEGER`,
+
+		// Balance ledger (every balance change with its reason)
+		`CREATE TABLE IF NOT EXISTS balance_transactions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			amount INTEGER NOT NULL,
+			type TEXT NOT NULL,
+			reference TEXT,
+			description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_balance_transactions_user ON balance_transactions(user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_balance_transactions_ref ON balance_transactions(type, reference)`,
+
+		// Referral rewards (one per referred user, granted on their first paid order)
+		`CREATE TABLE IF NOT EXISTS referral_rewards (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			referrer_id INTEGER NOT NULL,
+			referred_id INTEGER UNIQUE NOT NULL,
+			order_id TEXT NOT NULL,
+			reward_type TEXT NOT NULL,
+			amount INTEGER DEFAULT 0,
+			coupon_code TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (referrer_id) REFERENCES users (user_id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_referral_rewards_referrer ON referral_rewards(referrer_id)`,
+
+		// Flash sales (scheduled price rules for a product or a whole category)
+		`CREATE TABLE IF NOT EXISTS flash_sales (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			product_id INTEGER,
+			category TEXT,
+			sale_price INTEGER DEFAULT 0,
+			discount_percent INTEGER DEFAULT 0,
+			starts_at DATETIME NOT NULL,
+			ends_at DATETIME NOT NULL,
+			quantity_cap INTEGER DEFAULT 0,
+			announce BOOLEAN DEFAULT TRUE,
+			announced_at DATETIME,
+			is_active BOOLEAN DEFAULT TRUE,
+			created_by INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+		)`,
+		`ALTER TABLE order_items ADD COLUMN flash_sale_id INTEGER`,
+		`CREATE INDEX IF NOT EXISTS idx_order_items_flash_sale ON order_items(flash_sale_id)`,
+
+		// Product bundles (a bundle product delivers stock from each component product)
+		`ALTER TABLE products ADD COLUMN is_bundle BOOLEAN DEFAULT FALSE`,
+		`CREATE TABLE IF NOT EXISTS bundle_items (
+			bundle_id INTEGER NOT NULL,
+			product_id INTEGER NOT NULL,
+			quantity INTEGER NOT NULL DEFAULT 1,
+			PRIMARY KEY (bundle_id, product_id),
+			FOREIGN KEY (bundle_id) REFERENCES products (id) ON DELETE CASCADE,
+			FOREIGN KEY (product_id) REFERENCES products (id)
+		)`,
+
+		// Customer groups and group/quantity price tiers
+		`ALTER TABLE users ADD COLUMN customer_group TEXT DEFAULT 'retail'`,
+		`ALTER TABLE orders ADD COLUMN customer_group TEXT`,
+		`CREATE TABLE IF NOT EXISTS price_tiers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			customer_group TEXT NOT NULL,
+			product_id INTEGER,
+			min_quantity INTEGER NOT NULL DEFAULT 1,
+			price INTEGER DEFAULT 0,
+			discount_percent INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_price_tiers_group ON price_tiers(customer_group)`,
+
+		// Gift cards: products with a face value that issue a redeemable code per unit sold
+		`ALTER TABLE products ADD COLUMN gift_card_value INTEGER DEFAULT 0`,
+		`ALTER TABLE products ADD COLUMN gift_card_days INTEGER DEFAULT 0`,
+		`CREATE TABLE IF NOT EXISTS gift_cards (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			code TEXT UNIQUE NOT NULL,
+			product_id INTEGER,
+			order_id TEXT,
+			buyer_id INTEGER,
+			value INTEGER NOT NULL,
+			balance INTEGER NOT NULL,
+			expires_at DATETIME,
+			voided_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (product_id) REFERENCES products (id),
+			FOREIGN KEY (order_id) REFERENCES orders (id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_gift_cards_order ON gift_cards(order_id)`,
+		`CREATE TABLE IF NOT EXISTS gift_card_redemptions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			gift_card_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			amount INTEGER NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (gift_card_id) REFERENCES gift_cards (id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_gift_card_redemptions_card ON gift_card_redemptions(gift_card_id)`,
+
+		// Loyalty points: earned lots with their own expiry, a ledger of every change, and points spent on orders
+		`CREATE TABLE IF NOT EXISTS loyalty_points (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			points INTEGER NOT NULL,
+			remaining INTEGER NOT NULL,
+			expires_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_loyalty_points_user ON loyalty_points(user_id, remaining)`,
+		`CREATE TABLE IF NOT EXISTS loyalty_transactions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			points INTEGER NOT NULL,
+			type TEXT NOT NULL,
+			reference TEXT,
+			description TEXT,
+			expires_at DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_loyalty_transactions_user ON loyalty_transactions(user_id, created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_loyalty_transactions_reference ON loyalty_transactions(type, reference)`,
+		`ALTER TABLE sold_accounts ADD COLUMN loyalty_points INTEGER DEFAULT 0`,
+		`ALTER TABLE orders ADD COLUMN points_used INTEGER DEFAULT 0`,
+		`ALTER TABLE orders ADD COLUMN points_discount INTEGER DEFAULT 0`,
+		`ALTER TABLE cart_options ADD COLUMN use_points BOOLEAN DEFAULT FALSE`,
+		// Conversation state of users in a multi-step flow, kept across restarts
+		`CREATE TABLE IF NOT EXISTS conversation_states (
+			user_id INTEGER PRIMARY KEY,
+			state TEXT NOT NULL,
+			payload TEXT NOT NULL DEFAULT '',
+			expires_at DATETIME NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_conversation_states_expires ON conversation_states(expires_at)`,
+		// Per-user UI language and product names/descriptions in other languages
+		`ALTER TABLE users ADD COLUMN language TEXT`,
+		`CREATE TABLE IF NOT EXISTS product_translations (
+			product_id INTEGER NOT NULL,
+			language TEXT NOT NULL,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (product_id, language),
+			FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+		)`,
+		// Searches that found no products, reviewed by the admin
+		`CREATE TABLE IF NOT EXISTS search_misses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			query TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_search_misses_created ON search_misses(created_at)`,
+		// Product reviews, one per product of a paid order
+		`CREATE TABLE IF NOT EXISTS reviews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			order_id TEXT NOT NULL,
+			product_id INTEGER NOT NULL,
+			user_id INTEGER NOT NULL,
+			rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+			comment TEXT,
+			is_hidden BOOLEAN DEFAULT FALSE,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(order_id, product_id),
+			FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+			FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_reviews_product ON reviews(product_id, is_hidden)`,
+		`ALTER TABLE orders ADD COLUMN review_prompted_at DATETIME`,
+		// Product management from Telegram: instructions sent with delivered items, and deletion
+		// kept apart from deactivation so deleted products leave the admin list
+		`ALTER TABLE products ADD COLUMN delivery_instructions TEXT`,
+		`ALTER TABLE products ADD COLUMN deleted_at DATETIME`,
+		// Product photos uploaded from Telegram; the lowest position is the primary photo
+		`CREATE TABLE IF NOT EXISTS product_photos (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			product_id INTEGER NOT NULL,
+			file_id TEXT NOT NULL,
+			local_path TEXT,
+			position INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_product_photos_product ON product_photos(product_id, position)`,
+		// Invoices of paid orders; the number is derived from the sequential id
+		`CREATE TABLE IF NOT EXISTS invoices (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			order_id TEXT NOT NULL UNIQUE,
+			number TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders (id)
+		)`,
+		// Support tickets opened with /bantuan and the messages relayed between buyer and admins
+		`CREATE TABLE IF NOT EXISTS support_tickets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			order_id TEXT,
+			status TEXT NOT NULL DEFAULT 'open',
+			assigned_to INTEGER,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			last_customer_message_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			first_response_at DATETIME,
+			closed_at DATETIME,
+			FOREIGN KEY (user_id) REFERENCES users (user_id)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_support_tickets_status ON support_tickets(status, last_customer_message_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_support_tickets_user ON support_tickets(user_id, status)`,
+		`CREATE TABLE IF NOT EXISTS ticket_messages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ticket_id INTEGER NOT NULL,
+			sender_id INTEGER NOT NULL,
+			from_admin BOOLEAN NOT NULL DEFAULT FALSE,
+			text TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (ticket_id) REFERENCES support_tickets (id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_ticket_messages_ticket ON ticket_messages(ticket_id)`,
+		// Relayed copies of ticket messages, so that replying to one continues its ticket
+		`CREATE TABLE IF NOT EXISTS ticket_relays (
+			chat_id INTEGER NOT NULL,
+			message_id INTEGER NOT NULL,
+			ticket_id INTEGER NOT NULL,
+			PRIMARY KEY (chat_id, message_id),
+			FOREIGN KEY (ticket_id) REFERENCES support_tickets (id) ON DELETE CASCADE
+		)`,
+		// Bot messages to delete later, e.g. purchased items revealed from /produkku
+		`CREATE TABLE IF NOT EXISTS scheduled_deletions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			chat_id INTEGER NOT NULL,
+			message_id INTEGER NOT NULL,
+			delete_at DATETIME NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_scheduled_deletions_due ON scheduled_deletions(delete_at)`,
+		// Admin edits of message templates per language; templates without a row use the default text from the code
+		`CREATE TABLE IF NOT EXISTS message_templates (
+			name TEXT NOT NULL,
+			lang TEXT NOT NULL,
+			body TEXT NOT NULL,
+			updated_by INTEGER NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (name, lang)
+		)`,
+		// Bans: users.is_active is FALSE while banned; banned_until is NULL for a permanent ban
+		`ALTER TABLE users ADD COLUMN ban_reason TEXT`,
+		`ALTER TABLE users ADD COLUMN banned_until DATETIME`,
+		`ALTER TABLE users ADD COLUMN banned_by INTEGER`,
+		`ALTER TABLE users ADD COLUMN banned_at DATETIME`,
+		// Moderation history per user
+		`CREATE TABLE IF NOT EXISTS moderation_actions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			admin_id INTEGER,
+			action TEXT NOT NULL,
+			reason TEXT,
+			until DATETIME,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_moderation_actions_user ON moderation_actions(user_id)`,
 	}
 
 	for i, migration := range migrations {
@@ -459,22 +836,39 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+// nullableString returns nil for empty strings so they are stored as NULL
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 // User operations
+// CreateUser registers a user or refreshes the profile of an existing one.
+// Other columns (join date, settings, balance, referral data, language) are left untouched;
+// the language is only set for new users.
 func (db *DB) CreateUser(user *models.User) error {
 	_, err := db.Exec(`
-		INSERT OR REPLACE INTO users (user_id, username, first_name, last_name, is_admin)
-		VALUES (?, ?, ?, ?, ?)
-	`, user.UserID, user.Username, user.FirstName, user.LastName, user.IsAdmin)
+		INSERT INTO users (user_id, username, first_name, last_name, is_admin, language)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			username = excluded.username,
+			first_name = excluded.first_name,
+			last_name = excluded.last_name,
+			is_admin = excluded.is_admin
+	`, user.UserID, user.Username, user.FirstName, user.LastName, user.IsAdmin, nullableString(user.Language))
 	return err
 }
 
 func (db *DB) GetUser(userID int64) (*models.User, error) {
 	user := &models.User{}
 	err := db.QueryRow(`
-		SELECT user_id, username, first_name, last_name, join_date, is_admin, is_active
+		SELECT user_id, username, first_name, last_name, join_date, is_admin, is_active,
+			   COALESCE(customer_group, 'retail')
 		FROM users WHERE user_id = ?
 	`, userID).Scan(&user.UserID, &user.Username, &user.FirstName, &user.LastName,
-		&user.JoinDate, &user.IsAdmin, &user.IsActive)
+		&user.JoinDate, &user.IsAdmin, &user.IsActive, &user.CustomerGroup)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -483,14 +877,35 @@ func (db *DB) GetUser(userID int64) (*models.User, error) {
 }
 
 // Product operations
+
+// productColumns is the shared column list for product queries, read with scanProduct
+const productColumns = `id, name, description, price, category, image_url, download_url,
+			   is_active, stock, created_at, updated_at, preorder_enabled, preorder_limit, preorder_eta,
+			   COALESCE(is_bundle, FALSE), COALESCE(gift_card_value, 0), COALESCE(gift_card_days, 0),
+			   delivery_instructions`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanProduct scans a row selected with productColumns
+func scanProduct(row rowScanner, product *models.Product) error {
+	return row.Scan(&product.ID, &product.Name, &product.Description,
+		&product.Price, &product.Category, &product.ImageURL,
+		&product.DownloadURL, &product.IsActive, &product.Stock,
+		&product.CreatedAt, &product.UpdatedAt, &product.PreorderEnabled,
+		&product.PreorderLimit, &product.PreorderETA, &product.IsBundle,
+		&product.GiftCardValue, &product.GiftCardDays, &product.DeliveryInstructions)
+}
+
 func (db *DB) GetProducts(category string, limit, offset int) ([]models.Product, error) {
 	var query string
 	var args []interface{}
 
 	if category != "" {
 		query = `
-			SELECT id, name, description, price, category, image_url, download_url, 
-				   is_active, stock, created_at, updated_at
+			SELECT `+productColumns+`
 			FROM products 
 			WHERE is_active = TRUE AND category = ?
 			ORDER BY name
@@ -499,8 +914,7 @@ func (db *DB) GetProducts(category string, limit, offset int) ([]models.Product,
 		args = []interface{}{category, limit, offset}
 	} else {
 		query = `
-			SELECT id, name, description, price, category, image_url, download_url,
-				   is_active, stock, created_at, updated_at
+			SELECT `+productColumns+`
 			FROM products 
 			WHERE is_active = TRUE
 			ORDER BY category, name
@@ -518,10 +932,7 @@ func (db *DB) GetProducts(category string, limit, offset int) ([]models.Product,
 	var products []models.Product
 	for rows.Next() {
 		var product models.Product
-		err := rows.Scan(&product.ID, &product.Name, &product.Description,
-			&product.Price, &product.Category, &product.ImageURL,
-			&product.DownloadURL, &product.IsActive, &product.Stock,
-			&product.CreatedAt, &product.UpdatedAt)
+		err := scanProduct(rows, &product)
 		if err != nil {
 			return nil, err
 		}
@@ -533,14 +944,10 @@ func (db *DB) GetProducts(category string, limit, offset int) ([]models.Product,
 
 func (db *DB) GetProduct(id int) (*models.Product, error) {
 	product := &models.Product{}
-	err := db.QueryRow(`
-		SELECT id, name, description, price, category, image_url, download_url,
-			   is_active, stock, created_at, updated_at
+	err := scanProduct(db.QueryRow(`
+		SELECT `+productColumns+`
 		FROM products WHERE id = ? AND is_active = TRUE
-	`, id).Scan(&product.ID, &product.Name, &product.Description,
-		&product.Price, &product.Category, &product.ImageURL,
-		&product.DownloadURL, &product.IsActive, &product.Stock,
-		&product.CreatedAt, &product.UpdatedAt)
+	`, id), product)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -585,18 +992,93 @@ func (db *DB) GetCategories() ([]models.ProductCategory, error) {
 // Cart operations
 func (db *DB) AddToCart(userID int64, productID, quantity int) error {
 	_, err := db.Exec(`
-		INSERT INTO cart (user_id, product_id, quantity)
-		VALUES (?, ?, ?)
+		INSERT INTO cart (user_id, product_id, quantity, price_at_add)
+		VALUES (?, ?, ?, (SELECT price FROM products WHERE id = ?))
 		ON CONFLICT(user_id, product_id) 
-		DO UPDATE SET quantity = quantity + ?, added_at = CURRENT_TIMESTAMP
-	`, userID, productID, quantity, quantity)
+		DO UPDATE SET quantity = quantity + ?, added_at = CURRENT_TIMESTAMP,
+			price_at_add = excluded.price_at_add, reminder_sent_at = NULL
+	`, userID, productID, quantity, productID, quantity)
+	return err
+}
+
+// CountCartItems returns the number of distinct products in the user's cart
+func (db *DB) CountCartItems(userID int64) (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM cart WHERE user_id = ?`, userID).Scan(&count)
+	return count, err
+}
+
+// DeleteStaleCartItems removes cart items not touched for the given number of hours
+func (db *DB) DeleteStaleCartItems(hours int) (int64, error) {
+	result, err := db.Exec(`
+		DELETE FROM cart WHERE added_at < datetime('now', '-' || ? || ' hours')
+	`, hours)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetUsersForCartReminder returns users whose cart has been idle for the given number of hours,
+// who have not been reminded since their last cart change and have not opted out
+func (db *DB) GetUsersForCartReminder(hours int) ([]int64, error) {
+	rows, err := db.Query(`
+		SELECT c.user_id
+		FROM cart c
+		JOIN users u ON c.user_id = u.user_id
+		WHERE COALESCE(u.cart_reminders, TRUE) = TRUE AND u.is_active = TRUE
+		GROUP BY c.user_id
+		HAVING MAX(c.added_at) < datetime('now', '-' || ? || ' hours')
+		AND COUNT(c.reminder_sent_at) = 0
+	`, hours)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+// MarkCartReminderSent records that the user's current cart has been reminded
+func (db *DB) MarkCartReminderSent(userID int64) error {
+	_, err := db.Exec(`
+		UPDATE cart SET reminder_sent_at = CURRENT_TIMESTAMP WHERE user_id = ?
+	`, userID)
+	return err
+}
+
+// SetCartReminders enables or disables abandoned-cart reminders for a user
+func (db *DB) SetCartReminders(userID int64, enabled bool) error {
+	_, err := db.Exec(`UPDATE users SET cart_reminders = ? WHERE user_id = ?`, enabled, userID)
 	return err
 }
 
+// GetCartReminders returns whether a user receives abandoned-cart reminders
+func (db *DB) GetCartReminders(userID int64) (bool, error) {
+	var enabled sql.NullBool
+	err := db.QueryRow(`SELECT cart_reminders FROM users WHERE user_id = ?`, userID).Scan(&enabled)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !enabled.Valid || enabled.Bool, nil
+}
+
 func (db *DB) GetCart(userID int64) ([]models.CartItem, error) {
 	rows, err := db.Query(`
-		SELECT c.id, c.user_id, c.product_id, c.quantity, c.added_at,
-			   p.name, p.price, p.image_url
+		SELECT c.id, c.user_id, c.product_id, c.quantity, c.added_at, c.price_at_add,
+			   p.name, p.price, p.image_url, p.category
 		FROM cart c
 		JOIN products p ON c.product_id = p.id
 		WHERE c.user_id = ? AND p.is_active = TRUE
@@ -611,8 +1093,8 @@ func (db *DB) GetCart(userID int64) ([]models.CartItem, error) {
 	for rows.Next() {
 		var item models.CartItem
 		err := rows.Scan(&item.ID, &item.UserID, &item.ProductID,
-			&item.Quantity, &item.AddedAt, &item.ProductName,
-			&item.ProductPrice, &item.ProductImage)
+			&item.Quantity, &item.AddedAt, &item.PriceAtAdd, &item.ProductName,
+			&item.ProductPrice, &item.ProductImage, &item.ProductCategory)
 		if err != nil {
 			return nil, err
 		}
@@ -666,15 +1148,27 @@ func (db *DB) CreateOrder(order *models.Order) error {
 	return tx.Commit()
 }
 
+// orderColumns lists the orders columns read by scanOrder
+const orderColumns = `id, user_id, total_amount, payment_method, payment_status,
+			   qris_code, qris_expiry, created_at, updated_at, completed_at, is_preorder,
+			   COALESCE(discount_amount, 0), coupon_id, coupon_code, COALESCE(balance_used, 0),
+			   COALESCE(points_used, 0), COALESCE(points_discount, 0)`
+
+// scanOrder scans a row selected with orderColumns
+func scanOrder(row rowScanner, order *models.Order) error {
+	return row.Scan(&order.ID, &order.UserID, &order.TotalAmount,
+		&order.PaymentMethod, &order.PaymentStatus, &order.QRISCode,
+		&order.QRISExpiry, &order.CreatedAt, &order.UpdatedAt, &order.CompletedAt,
+		&order.IsPreorder, &order.DiscountAmount, &order.CouponID, &order.CouponCode, &order.BalanceUsed,
+		&order.PointsUsed, &order.PointsDiscount)
+}
+
 func (db *DB) GetOrder(orderID string) (*models.Order, error) {
 	order := &models.Order{}
-	err := db.QueryRow(`
-		SELECT id, user_id, total_amount, payment_method, payment_status,
-			   qris_code, qris_expiry, created_at, updated_at, completed_at
+	err := scanOrder(db.QueryRow(`
+		SELECT `+orderColumns+`
 		FROM orders WHERE id = ?
-	`, orderID).Scan(&order.ID, &order.UserID, &order.TotalAmount,
-		&order.PaymentMethod, &order.PaymentStatus, &order.QRISCode,
-		&order.QRISExpiry, &order.CreatedAt, &order.UpdatedAt, &order.CompletedAt)
+	`, orderID), order)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -738,8 +1232,7 @@ func (db *DB) UpdateOrderStatus(orderID string, status models.PaymentStatus) err
 
 func (db *DB) GetUserOrders(userID int64, limit, offset int) ([]models.Order, error) {
 	rows, err := db.Query(`
-		SELECT id, user_id, total_amount, payment_method, payment_status,
-			   qris_code, qris_expiry, created_at, updated_at, completed_at
+		SELECT `+orderColumns+`
 		FROM orders 
 		WHERE user_id = ?
 		ORDER BY created_at DESC
@@ -753,9 +1246,7 @@ func (db *DB) GetUserOrders(userID int64, limit, offset int) ([]models.Order, er
 	var orders []models.Order
 	for rows.Next() {
 		var order models.Order
-		err := rows.Scan(&order.ID, &order.UserID, &order.TotalAmount,
-			&order.PaymentMethod, &order.PaymentStatus, &order.QRISCode,
-			&order.QRISExpiry, &order.CreatedAt, &order.UpdatedAt, &order.CompletedAt)
+		err := scanOrder(rows, &order)
 		if err != nil {
 			return nil, err
 		}
@@ -794,13 +1285,12 @@ func (db *DB) DecrementProductStock(productID, quantity int) error {
 	return err
 }
 
-// GetLowStockProducts returns products with stock below threshold
+// GetLowStockProducts returns products with stock below threshold. Gift cards never run out and are left out.
 func (db *DB) GetLowStockProducts(threshold int) ([]models.Product, error) {
 	rows, err := db.Query(`
-		SELECT id, name, description, price, category, image_url, download_url,
-			   is_active, stock, created_at, updated_at
+		SELECT `+productColumns+`
 		FROM products 
-		WHERE is_active = TRUE AND stock <= ?
+		WHERE is_active = TRUE AND stock <= ? AND COALESCE(gift_card_value, 0) = 0
 		ORDER BY stock ASC, name
 	`, threshold)
 	if err != nil {
@@ -811,10 +1301,7 @@ func (db *DB) GetLowStockProducts(threshold int) ([]models.Product, error) {
 	var products []models.Product
 	for rows.Next() {
 		var product models.Product
-		err := rows.Scan(&product.ID, &product.Name, &product.Description,
-			&product.Price, &product.Category, &product.ImageURL,
-			&product.DownloadURL, &product.IsActive, &product.Stock,
-			&product.CreatedAt, &product.UpdatedAt)
+		err := scanProduct(rows, &product)
 		if err != nil {
 			return nil, err
 		}
@@ -885,23 +1372,83 @@ func (db *DB) GetCategoriesFromDB() ([]models.ProductCategory, error) {
 
 // Product Management Methods
 
-// DeleteProduct soft deletes a product
+// CreateProduct inserts a product and sets its ID
+func (db *DB) CreateProduct(product *models.Product) error {
+	return db.QueryRow(`
+		INSERT INTO products (name, description, price, category, image_url, download_url, is_active,
+			stock, delivery_instructions)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`, product.Name, product.Description, product.Price, product.Category, product.ImageURL,
+		product.DownloadURL, product.IsActive, product.Stock, product.DeliveryInstructions).Scan(&product.ID)
+}
+
+// GetProductAnyStatus returns a product by ID including inactive ones, or nil if it does not exist or was deleted
+func (db *DB) GetProductAnyStatus(id int) (*models.Product, error) {
+	product := &models.Product{}
+	err := scanProduct(db.QueryRow(`
+		SELECT `+productColumns+`
+		FROM products WHERE id = ? AND deleted_at IS NULL
+	`, id), product)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return product, err
+}
+
+// GetAdminProducts returns products for the admin list, inactive ones included and deleted ones left out
+func (db *DB) GetAdminProducts(limit, offset int) ([]models.Product, error) {
+	rows, err := db.Query(`
+		SELECT `+productColumns+`
+		FROM products
+		WHERE deleted_at IS NULL
+		ORDER BY is_active DESC, category, name
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var product models.Product
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
+
+// DeleteProduct soft deletes a product: it is deactivated and no longer listed for admins,
+// while orders and sold items keep referring to it
 func (db *DB) DeleteProduct(id int) error {
 	_, err := db.Exec(`
-		UPDATE products SET is_active = FALSE WHERE id = ?
+		UPDATE products SET is_active = FALSE, deleted_at = CURRENT_TIMESTAMP WHERE id = ?
 	`, id)
 	return err
 }
 
-// UpdateProduct updates product information
+// UpdateProduct updates product information. Stock is left alone; it follows the product's accounts.
 func (db *DB) UpdateProduct(product *models.Product) error {
 	_, err := db.Exec(`
 		UPDATE products 
 		SET name = ?, description = ?, price = ?, category = ?, 
-			image_url = ?, download_url = ?, stock = ?, updated_at = CURRENT_TIMESTAMP
+			image_url = ?, download_url = ?, is_active = ?, delivery_instructions = ?,
+			updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`, product.Name, product.Description, product.Price, product.Category,
-		product.ImageURL, product.DownloadURL, product.Stock, product.ID)
+		product.ImageURL, product.DownloadURL, product.IsActive, product.DeliveryInstructions, product.ID)
+	return err
+}
+
+// SetProductActive shows or hides a product in the store
+func (db *DB) SetProductActive(id int, active bool) error {
+	_, err := db.Exec(`
+		UPDATE products SET is_active = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, active, id)
 	return err
 }
 
@@ -916,12 +1463,13 @@ func (db *DB) LogUserInteraction(userID int64, interactionType, data string) err
 	return err
 }
 
-// GetActiveUsers returns list of active users for broadcast
+// GetActiveUsers returns list of active users for broadcast, except banned users
 func (db *DB) GetActiveUsers(days int) ([]int64, error) {
 	rows, err := db.Query(`
 		SELECT DISTINCT user_id 
 		FROM user_interactions 
 		WHERE created_at >= datetime('now', '-' || ? || ' days')
+		  AND user_id NOT IN (SELECT user_id FROM users WHERE is_active = FALSE)
 		ORDER BY user_id
 	`, days)
 	if err != nil {
@@ -941,10 +1489,10 @@ func (db *DB) GetActiveUsers(days int) ([]int64, error) {
 	return userIDs, rows.Err()
 }
 
-// GetAllUsers returns all users who ever interacted with bot
+// GetAllUsers returns all users who ever interacted with bot, except banned users
 func (db *DB) GetAllUsers() ([]int64, error) {
 	rows, err := db.Query(`
-		SELECT DISTINCT user_id FROM users ORDER BY user_id
+		SELECT DISTINCT user_id FROM users WHERE is_active = TRUE ORDER BY user_id
 	`)
 	if err != nil {
 		return nil, err
@@ -1057,7 +1605,12 @@ func (db *DB) RestoreStockFromOrder(orderID string) error {
 	}
 	defer tx.Rollback()
 
-	// First, restore accounts that were assigned to this order
+	// Gift card codes of the order are voided instead of going back to stock
+	if err := voidOrderGiftCardsTx(tx, orderID); err != nil {
+		return err
+	}
+
+	// Restore accounts that were assigned to this order
 	// Mark them as not sold so they become available again
 	_, err = tx.Exec(`
 		UPDATE product_accounts 
@@ -1095,6 +1648,14 @@ func (db *DB) RestoreStockFromOrder(orderID string) error {
 		}
 	}
 
+	if err := refundOrderBalanceTx(tx, orderID); err != nil {
+		return err
+	}
+
+	if err := restoreOrderPointsTx(tx, orderID); err != nil {
+		return err
+	}
+
 	logrus.Infof("Restored accounts and stock for cancelled/expired order %s", orderID)
 	return tx.Commit()
 }
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Flash Sales
+
+// flashSaleSelect selects flash sales with the units sold at the sale price; extend with WHERE/ORDER BY clauses
+const flashSaleSelect = `
+	SELECT f.id, f.name, f.product_id, f.category, COALESCE(f.sale_price, 0), COALESCE(f.discount_percent, 0),
+		   f.starts_at, f.ends_at, COALESCE(f.quantity_cap, 0), f.announce, f.announced_at, f.is_active,
+		   COALESCE(f.created_by, 0), f.created_at,
+		   (SELECT COALESCE(SUM(oi.quantity), 0) FROM order_items oi JOIN orders o ON oi.order_id = o.id
+			WHERE oi.flash_sale_id = f.id AND o.payment_status NOT IN ('expired', 'cancelled'))
+	FROM flash_sales f`
+
+func scanFlashSales(rows *sql.Rows) ([]models.FlashSale, error) {
+	var sales []models.FlashSale
+	for rows.Next() {
+		var f models.FlashSale
+		err := rows.Scan(&f.ID, &f.Name, &f.ProductID, &f.Category, &f.SalePrice, &f.DiscountPercent,
+			&f.StartsAt, &f.EndsAt, &f.QuantityCap, &f.Announce, &f.AnnouncedAt, &f.IsActive,
+			&f.CreatedBy, &f.CreatedAt, &f.SoldCount)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, f)
+	}
+	return sales, rows.Err()
+}
+
+// CreateFlashSale inserts a new flash sale
+func (db *DB) CreateFlashSale(sale *models.FlashSale) error {
+	result, err := db.Exec(`
+		INSERT INTO flash_sales (name, product_id, category, sale_price, discount_percent, starts_at, ends_at,
+			quantity_cap, announce, is_active, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, TRUE, ?)
+	`, sale.Name, sale.ProductID, sale.Category, sale.SalePrice, sale.DiscountPercent, sale.StartsAt, sale.EndsAt,
+		sale.QuantityCap, sale.Announce, sale.CreatedBy)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	sale.ID = int(id)
+	sale.IsActive = true
+	return nil
+}
+
+// GetFlashSale returns a flash sale by ID, or nil if it does not exist
+func (db *DB) GetFlashSale(id int) (*models.FlashSale, error) {
+	rows, err := db.Query(flashSaleSelect+` WHERE f.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales, err := scanFlashSales(rows)
+	if err != nil || len(sales) == 0 {
+		return nil, err
+	}
+	return &sales[0], nil
+}
+
+// GetFlashSales returns flash sales ordered by start time. With runningOnly, only sales
+// that are enabled and running right now are returned.
+func (db *DB) GetFlashSales(runningOnly bool) ([]models.FlashSale, error) {
+	query := flashSaleSelect + ` ORDER BY f.starts_at DESC`
+	if runningOnly {
+		query = flashSaleSelect + ` WHERE f.is_active = TRUE ORDER BY f.starts_at ASC`
+	}
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales, err := scanFlashSales(rows)
+	if err != nil || !runningOnly {
+		return sales, err
+	}
+
+	// Times are stored as text, so the running window is checked here rather than in SQL
+	now := time.Now()
+	var running []models.FlashSale
+	for _, sale := range sales {
+		if sale.IsRunning(now) {
+			running = append(running, sale)
+		}
+	}
+	return running, nil
+}
+
+// SetFlashSaleActive enables or disables a flash sale. Returns false if the sale does not exist.
+func (db *DB) SetFlashSaleActive(id int, active bool) (bool, error) {
+	result, err := db.Exec(`UPDATE flash_sales SET is_active = ? WHERE id = ?`, active, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// MarkFlashSaleAnnounced claims the start announcement of a sale.
+// Returns false when it was already announced, so each sale is broadcast only once.
+func (db *DB) MarkFlashSaleAnnounced(id int) (bool, error) {
+	result, err := db.Exec(`
+		UPDATE flash_sales SET announced_at = CURRENT_TIMESTAMP WHERE id = ? AND announced_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// checkFlashSaleCapsTx re-checks, inside the order transaction, that every flash sale used by the
+// order is still running and has enough units left under its quantity cap
+func checkFlashSaleCapsTx(tx *sql.Tx, order *models.Order) error {
+	quantities := make(map[int]int)
+	for _, item := range order.Items {
+		if item.FlashSaleID != nil {
+			quantities[*item.FlashSaleID] += item.Quantity
+		}
+	}
+
+	now := time.Now()
+	for saleID, quantity := range quantities {
+		var sale models.FlashSale
+		err := tx.QueryRow(`
+			SELECT is_active, starts_at, ends_at, COALESCE(quantity_cap, 0) FROM flash_sales WHERE id = ?
+		`, saleID).Scan(&sale.IsActive, &sale.StartsAt, &sale.EndsAt, &sale.QuantityCap)
+		if err != nil {
+			return fmt.Errorf("flash sale %d not found: %w", saleID, err)
+		}
+		if !sale.IsRunning(now) {
+			return fmt.Errorf("flash sale %d is not running", saleID)
+		}
+		if sale.QuantityCap <= 0 {
+			continue
+		}
+
+		err = tx.QueryRow(`
+			SELECT COALESCE(SUM(oi.quantity), 0) FROM order_items oi
+			JOIN orders o ON oi.order_id = o.id
+			WHERE oi.flash_sale_id = ? AND o.payment_status NOT IN ('expired', 'cancelled')
+		`, saleID).Scan(&sale.SoldCount)
+		if err != nil {
+			return err
+		}
+		if sale.SoldCount+quantity > sale.QuantityCap {
+			return fmt.Errorf("flash sale %d quantity cap reached: %d/%d", saleID, sale.SoldCount, sale.QuantityCap)
+		}
+	}
+	return nil
+}
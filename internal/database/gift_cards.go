@@ -0,0 +1,319 @@
+package database
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Gift Cards
+
+// GiftCardCategory is the catalog category gift card products are listed in
+const GiftCardCategory = "giftcard"
+
+// generateGiftCardCode returns a code like "GC-7KQ2-M9XD-4HTP" using the referral code alphabet
+func generateGiftCardCode() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i := range buf {
+		buf[i] = referralCodeAlphabet[int(buf[i])%len(referralCodeAlphabet)]
+	}
+	return fmt.Sprintf("GC-%s-%s-%s", buf[0:4], buf[4:8], buf[8:12]), nil
+}
+
+// CreateGiftCardProduct inserts a gift card product in the gift card category, creating the category if needed
+func (db *DB) CreateGiftCardProduct(product *models.Product) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT OR IGNORE INTO categories (name, display_name, icon) VALUES (?, 'Gift Card', '🎁')
+	`, GiftCardCategory)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO products (name, description, price, category, stock, gift_card_value, gift_card_days)
+		VALUES (?, ?, ?, ?, 0, ?, ?)
+	`, product.Name, product.Description, product.Price, GiftCardCategory, product.GiftCardValue, product.GiftCardDays)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	product.ID = int(id)
+	product.Category = GiftCardCategory
+	product.IsActive = true
+	return nil
+}
+
+// issueGiftCardsTx issues one gift card per entry in prices for an order and records each like
+// a sold stock item, so the codes are delivered with the rest of the order.
+// The cards only become redeemable once the order is paid.
+func issueGiftCardsTx(tx *sql.Tx, order *models.Order, productID, value, days int, prices []int) ([]models.ProductAccount, error) {
+	var expiresAt *time.Time
+	if days > 0 {
+		expiry := time.Now().AddDate(0, 0, days)
+		expiresAt = &expiry
+	}
+
+	var accounts []models.ProductAccount
+	for _, price := range prices {
+		code, err := insertGiftCardTx(tx, order, productID, value, expiresAt)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := tx.Exec(`
+			INSERT INTO product_accounts (product_id, content_type, content_data, is_sold, sold_to_user_id, sold_order_id, sold_at)
+			VALUES (?, ?, ?, TRUE, ?, ?, CURRENT_TIMESTAMP)
+		`, productID, models.ContentTypeCode, code, order.UserID, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		accountID, err := result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO sold_accounts (order_id, product_id, account_id, user_id, content_type, content_data, sold_price)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, order.ID, productID, accountID, order.UserID, models.ContentTypeCode, code, price)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, models.ProductAccount{
+			ID:          int(accountID),
+			ProductID:   productID,
+			ContentType: models.ContentTypeCode,
+			ContentData: code,
+		})
+	}
+	return accounts, nil
+}
+
+// insertGiftCardTx inserts a gift card with a fresh unique code and returns the code
+func insertGiftCardTx(tx *sql.Tx, order *models.Order, productID, value int, expiresAt *time.Time) (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := generateGiftCardCode()
+		if err != nil {
+			return "", err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO gift_cards (code, product_id, order_id, buyer_id, value, balance, expires_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, code, productID, order.ID, order.UserID, value, value, expiresAt)
+		if err == nil {
+			return code, nil
+		}
+		if !strings.Contains(err.Error(), "UNIQUE") {
+			return "", err
+		}
+		// On a code collision, try another code
+	}
+	return "", fmt.Errorf("failed to generate gift card code for order %s", order.ID)
+}
+
+// voidOrderGiftCardsTx voids the gift cards of an order that expired or was cancelled and keeps
+// their codes out of stock when the order's accounts are restored
+func voidOrderGiftCardsTx(tx *sql.Tx, orderID string) error {
+	_, err := tx.Exec(`
+		UPDATE gift_cards SET voided_at = CURRENT_TIMESTAMP WHERE order_id = ? AND voided_at IS NULL
+	`, orderID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE product_accounts SET is_revoked = TRUE
+		WHERE sold_order_id = ? AND content_data IN (SELECT code FROM gift_cards WHERE order_id = ?)
+	`, orderID, orderID)
+	return err
+}
+
+// giftCardSelect is the shared column list and joins for gift card queries
+const giftCardSelect = `
+	SELECT g.id, g.code, g.product_id, g.order_id, g.buyer_id, g.value, g.balance, g.expires_at,
+		   g.voided_at, g.created_at, o.payment_status, p.name,
+		   (SELECT COUNT(*) FROM gift_card_redemptions r WHERE r.gift_card_id = g.id)
+	FROM gift_cards g
+	LEFT JOIN orders o ON o.id = g.order_id
+	LEFT JOIN products p ON p.id = g.product_id
+`
+
+// scanGiftCard scans a row selected with giftCardSelect
+func scanGiftCard(row rowScanner, card *models.GiftCard) error {
+	return row.Scan(&card.ID, &card.Code, &card.ProductID, &card.OrderID, &card.BuyerID, &card.Value,
+		&card.Balance, &card.ExpiresAt, &card.VoidedAt, &card.CreatedAt, &card.OrderStatus,
+		&card.ProductName, &card.RedemptionCount)
+}
+
+// getGiftCard returns the gift card matching the condition, or nil when none does
+func getGiftCard(q querier, where string, arg interface{}) (*models.GiftCard, error) {
+	var card models.GiftCard
+	err := scanGiftCard(q.QueryRow(giftCardSelect+"WHERE "+where, arg), &card)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// GetGiftCardByCode returns a gift card by its code, case-insensitively
+func (db *DB) GetGiftCardByCode(code string) (*models.GiftCard, error) {
+	return getGiftCard(db, "g.code = ?", strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// GetGiftCard returns a gift card by ID
+func (db *DB) GetGiftCard(id int) (*models.GiftCard, error) {
+	return getGiftCard(db, "g.id = ?", id)
+}
+
+// GetGiftCards returns the most recently issued gift cards
+func (db *DB) GetGiftCards(limit int) ([]models.GiftCard, error) {
+	return db.queryGiftCards(giftCardSelect+`
+		ORDER BY g.created_at DESC, g.id DESC
+		LIMIT ?
+	`, limit)
+}
+
+// GetGiftCardsForOrder returns the gift cards issued by an order
+func (db *DB) GetGiftCardsForOrder(orderID string) ([]models.GiftCard, error) {
+	return db.queryGiftCards(giftCardSelect+`
+		WHERE g.order_id = ?
+		ORDER BY g.id
+	`, orderID)
+}
+
+// queryGiftCards runs a giftCardSelect query and scans all rows
+func (db *DB) queryGiftCards(query string, args ...interface{}) ([]models.GiftCard, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cards []models.GiftCard
+	for rows.Next() {
+		var card models.GiftCard
+		if err := scanGiftCard(rows, &card); err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, rows.Err()
+}
+
+// GetGiftCardRedemptions returns the redemption history of a gift card, oldest first
+func (db *DB) GetGiftCardRedemptions(giftCardID int) ([]models.GiftCardRedemption, error) {
+	rows, err := db.Query(`
+		SELECT id, gift_card_id, user_id, amount, created_at
+		FROM gift_card_redemptions
+		WHERE gift_card_id = ?
+		ORDER BY created_at, id
+	`, giftCardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redemptions []models.GiftCardRedemption
+	for rows.Next() {
+		var r models.GiftCardRedemption
+		if err := rows.Scan(&r.ID, &r.GiftCardID, &r.UserID, &r.Amount, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, r)
+	}
+	return redemptions, rows.Err()
+}
+
+// RedeemGiftCard moves amount from a gift card to the user's balance; amount 0 redeems everything left.
+// The card is returned with its updated balance. When it cannot be redeemed, the error says why
+// and the card, if found, is returned so the caller can explain its state.
+func (db *DB) RedeemGiftCard(code string, userID int64, amount int) (*models.GiftCard, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	card, err := getGiftCard(tx, "g.code = ?", strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, fmt.Errorf("gift card not found")
+	}
+	if state := card.State(time.Now()); state != models.GiftCardActive {
+		return card, fmt.Errorf("gift card not redeemable: %s", state)
+	}
+
+	if amount == 0 {
+		amount = card.Balance
+	}
+	if amount < 0 || amount > card.Balance {
+		return card, fmt.Errorf("gift card amount exceeds balance: %d > %d", amount, card.Balance)
+	}
+
+	// The balance condition guards against a concurrent redemption of the same card
+	result, err := tx.Exec(`
+		UPDATE gift_cards SET balance = balance - ? WHERE id = ? AND balance >= ?
+	`, amount, card.ID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return card, fmt.Errorf("gift card amount exceeds balance: %d > %d", amount, card.Balance)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO gift_card_redemptions (gift_card_id, user_id, amount) VALUES (?, ?, ?)
+	`, card.ID, userID, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := adjustBalanceTx(tx, userID, amount, models.BalanceTxGiftCard, card.Code, "Penukaran gift card"); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	card.Balance -= amount
+	card.RedemptionCount++
+	return card, nil
+}
+
+// VoidGiftCard voids a gift card so its remaining balance can no longer be redeemed.
+// Returns false if the card does not exist or is already voided.
+func (db *DB) VoidGiftCard(id int) (bool, error) {
+	result, err := db.Exec(`
+		UPDATE gift_cards SET voided_at = CURRENT_TIMESTAMP WHERE id = ? AND voided_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
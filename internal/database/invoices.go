@@ -0,0 +1,64 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Invoices
+
+// GetInvoiceByOrder returns the invoice of an order, or nil if none was issued yet
+func (db *DB) GetInvoiceByOrder(orderID string) (*models.Invoice, error) {
+	invoice := &models.Invoice{}
+	err := db.QueryRow(`
+		SELECT id, order_id, number, created_at FROM invoices WHERE order_id = ? AND number IS NOT NULL
+	`, orderID).Scan(&invoice.ID, &invoice.OrderID, &invoice.Number, &invoice.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// IssueInvoice returns the invoice of an order, numbering a new one the first time.
+// An order keeps the same number however often its invoice is downloaded.
+func (db *DB) IssueInvoice(orderID string) (*models.Invoice, error) {
+	if invoice, err := db.GetInvoiceByOrder(orderID); err != nil || invoice != nil {
+		return invoice, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	invoice := &models.Invoice{OrderID: orderID, CreatedAt: time.Now()}
+	err = tx.QueryRow(`
+		INSERT INTO invoices (order_id, created_at) VALUES (?, ?)
+		ON CONFLICT(order_id) DO NOTHING
+		RETURNING id
+	`, orderID, invoice.CreatedAt).Scan(&invoice.ID)
+	if err == sql.ErrNoRows {
+		// Issued concurrently by another update
+		tx.Rollback()
+		return db.GetInvoiceByOrder(orderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	invoice.Number = fmt.Sprintf("INV-%d-%06d", invoice.CreatedAt.Year(), invoice.ID)
+	if _, err := tx.Exec(`UPDATE invoices SET number = ? WHERE id = ?`, invoice.Number, invoice.ID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Purchase Limits
+
+// GetUserPurchasedQuantity returns units of a product the user has ordered in the last `hours` hours.
+// Unpaid, paid and pre-ordered orders count; expired and cancelled ones do not.
+func (db *DB) GetUserPurchasedQuantity(userID int64, productID int, hours int) (int, error) {
+	var quantity int
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(oi.quantity), 0)
+		FROM order_items oi
+		JOIN orders o ON oi.order_id = o.id
+		WHERE o.user_id = ? AND oi.product_id = ?
+		AND o.payment_status IN ('pending', 'paid', 'awaiting_stock')
+		AND o.created_at >= datetime('now', '-' || ? || ' hours')
+	`, userID, productID, hours).Scan(&quantity)
+	return quantity, err
+}
+
+// CountPendingOrders returns the number of unpaid orders of a user
+func (db *DB) CountPendingOrders(userID int64) (int, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*) FROM orders WHERE user_id = ? AND payment_status = 'pending'
+	`, userID).Scan(&count)
+	return count, err
+}
+
+// GetCartQuantity returns the quantity of a product currently in the user's cart
+func (db *DB) GetCartQuantity(userID int64, productID int) (int, error) {
+	var quantity int
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(quantity), 0) FROM cart WHERE user_id = ? AND product_id = ?
+	`, userID, productID).Scan(&quantity)
+	return quantity, err
+}
+
+// checkOrderLimitsTx re-checks the pending-order cap and the per-product purchase limits of a new order
+// inside its transaction, so two checkouts at once cannot both pass the check made before it.
+// Must run before the order is inserted.
+func checkOrderLimitsTx(tx *sql.Tx, order *models.Order) error {
+	limits := order.Limits
+	if limits.MaxPendingOrders > 0 {
+		var pending int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM orders WHERE user_id = ? AND payment_status = 'pending'
+		`, order.UserID).Scan(&pending)
+		if err != nil {
+			return err
+		}
+		if pending >= limits.MaxPendingOrders {
+			return fmt.Errorf("pending order limit reached for user %d: %d pending, limit %d",
+				order.UserID, pending, limits.MaxPendingOrders)
+		}
+	}
+
+	if limits.MaxQtyPerProduct <= 0 || limits.PeriodHours <= 0 {
+		return nil
+	}
+	for _, item := range order.Items {
+		var purchased int
+		err := tx.QueryRow(`
+			SELECT COALESCE(SUM(oi.quantity), 0)
+			FROM order_items oi
+			JOIN orders o ON oi.order_id = o.id
+			WHERE o.user_id = ? AND oi.product_id = ?
+			AND o.payment_status IN ('pending', 'paid', 'awaiting_stock')
+			AND o.created_at >= datetime('now', '-' || ? || ' hours')
+		`, order.UserID, item.ProductID, limits.PeriodHours).Scan(&purchased)
+		if err != nil {
+			return err
+		}
+		if purchased+item.Quantity > limits.MaxQtyPerProduct {
+			return fmt.Errorf("purchase limit reached for product ID %d: purchased %d, requested %d, limit %d",
+				item.ProductID, purchased, item.Quantity, limits.MaxQtyPerProduct)
+		}
+	}
+	return nil
+}
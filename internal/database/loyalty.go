@@ -0,0 +1,332 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Loyalty Points
+//
+// Earned points are stored as lots, each with its own expiry. Points are spent from the lot
+// expiring first, and every change is recorded in loyalty_transactions.
+
+// unexpiredLot is the SQL condition for lots that can still be spent
+const unexpiredLot = `remaining > 0 AND (expires_at IS NULL OR expires_at > datetime('now'))`
+
+// GetLoyaltySummary returns the user's usable points and the batch expiring first
+func (db *DB) GetLoyaltySummary(userID int64) (*models.LoyaltySummary, error) {
+	summary := &models.LoyaltySummary{}
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(remaining), 0) FROM loyalty_points WHERE user_id = ? AND `+unexpiredLot,
+		userID).Scan(&summary.Points)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt time.Time
+	err = db.QueryRow(`
+		SELECT expires_at, SUM(remaining) FROM loyalty_points
+		WHERE user_id = ? AND expires_at IS NOT NULL AND `+unexpiredLot+`
+		GROUP BY expires_at
+		ORDER BY expires_at
+		LIMIT 1
+	`, userID).Scan(&expiresAt, &summary.ExpiringPoints)
+	if err == sql.ErrNoRows {
+		return summary, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	summary.NextExpiry = &expiresAt
+	return summary, nil
+}
+
+// GetLoyaltyPoints returns the user's usable points
+func (db *DB) GetLoyaltyPoints(userID int64) (int, error) {
+	summary, err := db.GetLoyaltySummary(userID)
+	if err != nil {
+		return 0, err
+	}
+	return summary.Points, nil
+}
+
+// GetLoyaltyHistory returns the user's most recent points changes
+func (db *DB) GetLoyaltyHistory(userID int64, limit int) ([]models.LoyaltyTransaction, error) {
+	rows, err := db.Query(`
+		SELECT id, user_id, points, type, reference, description, created_at
+		FROM loyalty_transactions
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []models.LoyaltyTransaction
+	for rows.Next() {
+		var t models.LoyaltyTransaction
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Points, &t.Type, &t.Reference, &t.Description, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, t)
+	}
+	return history, rows.Err()
+}
+
+// recordLoyaltyTx adds an entry to the points ledger
+func recordLoyaltyTx(tx *sql.Tx, userID int64, points int, txType models.LoyaltyTransactionType, reference, description string, expiresAt interface{}) error {
+	_, err := tx.Exec(`
+		INSERT INTO loyalty_transactions (user_id, points, type, reference, description, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, userID, points, txType, nullableString(reference), nullableString(description), expiresAt)
+	return err
+}
+
+// hasLoyaltyTx reports whether the ledger already has an entry of the type for the reference
+func hasLoyaltyTx(tx *sql.Tx, txType models.LoyaltyTransactionType, reference string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM loyalty_transactions WHERE type = ? AND reference = ?
+	`, txType, reference).Scan(&count)
+	return count > 0, err
+}
+
+// addPointsLotTx stores a lot of points. expiresAt is an SQL datetime string or nil for no expiry.
+func addPointsLotTx(tx *sql.Tx, userID int64, points int, expiresAt interface{}) error {
+	_, err := tx.Exec(`
+		INSERT INTO loyalty_points (user_id, points, remaining, expires_at) VALUES (?, ?, ?, ?)
+	`, userID, points, points, expiresAt)
+	return err
+}
+
+// expiryAfterDays returns the SQL datetime string days from now, or nil when days is 0
+func expiryAfterDays(tx *sql.Tx, days int) (interface{}, error) {
+	if days <= 0 {
+		return nil, nil
+	}
+	var expiresAt string
+	err := tx.QueryRow(`SELECT datetime('now', '+' || ? || ' days')`, days).Scan(&expiresAt)
+	return expiresAt, err
+}
+
+// consumePointsTx spends up to points from the user's lots, soonest expiry first.
+// Returns the points taken and the earliest expiry among the lots used (nil when none expire).
+func consumePointsTx(tx *sql.Tx, userID int64, points int) (int, interface{}, error) {
+	rows, err := tx.Query(`
+		SELECT id, remaining, expires_at FROM loyalty_points
+		WHERE user_id = ? AND `+unexpiredLot+`
+		ORDER BY expires_at IS NULL, expires_at, id
+	`, userID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	type lot struct {
+		id, remaining int
+		expiresAt     sql.NullString
+	}
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		if err := rows.Scan(&l.id, &l.remaining, &l.expiresAt); err != nil {
+			rows.Close()
+			return 0, nil, err
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+
+	taken := 0
+	var earliest interface{}
+	for _, l := range lots {
+		if taken == points {
+			break
+		}
+		take := l.remaining
+		if take > points-taken {
+			take = points - taken
+		}
+		if _, err := tx.Exec(`UPDATE loyalty_points SET remaining = remaining - ? WHERE id = ?`, take, l.id); err != nil {
+			return 0, nil, err
+		}
+		if earliest == nil && l.expiresAt.Valid {
+			earliest = l.expiresAt.String
+		}
+		taken += take
+	}
+	return taken, earliest, nil
+}
+
+// AwardOrderPoints credits the points earned by a paid order. points maps sold account IDs to the
+// points each item earned, so a refunded item takes back exactly its share.
+// Returns the points awarded; an order is only awarded once.
+func (db *DB) AwardOrderPoints(orderID string, userID int64, points map[int]int, expiryDays int) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	awarded, err := hasLoyaltyTx(tx, models.LoyaltyTxEarn, orderID)
+	if err != nil || awarded {
+		return 0, err
+	}
+
+	total := 0
+	for soldAccountID, p := range points {
+		if p <= 0 {
+			continue
+		}
+		_, err := tx.Exec(`
+			UPDATE sold_accounts SET loyalty_points = ? WHERE id = ? AND order_id = ?
+		`, p, soldAccountID, orderID)
+		if err != nil {
+			return 0, err
+		}
+		total += p
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	expiresAt, err := expiryAfterDays(tx, expiryDays)
+	if err != nil {
+		return 0, err
+	}
+	if err := addPointsLotTx(tx, userID, total, expiresAt); err != nil {
+		return 0, err
+	}
+	if err := recordLoyaltyTx(tx, userID, total, models.LoyaltyTxEarn, orderID, "Poin pembelian", expiresAt); err != nil {
+		return 0, err
+	}
+
+	return total, tx.Commit()
+}
+
+// spendOrderPointsTx redeems the points used by a new order
+func spendOrderPointsTx(tx *sql.Tx, order *models.Order) error {
+	if order.PointsUsed <= 0 {
+		return nil
+	}
+
+	taken, earliest, err := consumePointsTx(tx, order.UserID, order.PointsUsed)
+	if err != nil {
+		return err
+	}
+	if taken < order.PointsUsed {
+		return fmt.Errorf("insufficient points for user %d: have %d, need %d", order.UserID, taken, order.PointsUsed)
+	}
+	return recordLoyaltyTx(tx, order.UserID, -taken, models.LoyaltyTxRedeem, order.ID, "Tukar poin di checkout", earliest)
+}
+
+// restoreOrderPointsTx returns the points spent on an order that expired or was cancelled.
+// They keep the earliest expiry of the points originally spent. Safe to call more than once.
+func restoreOrderPointsTx(tx *sql.Tx, orderID string) error {
+	var userID int64
+	var points int
+	var expiresAt sql.NullString
+	err := tx.QueryRow(`
+		SELECT user_id, -points, expires_at FROM loyalty_transactions WHERE type = ? AND reference = ?
+	`, models.LoyaltyTxRedeem, orderID).Scan(&userID, &points, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	restored, err := hasLoyaltyTx(tx, models.LoyaltyTxRestore, orderID)
+	if err != nil || restored {
+		return err
+	}
+
+	var expiry interface{}
+	if expiresAt.Valid {
+		expiry = expiresAt.String
+	}
+	if err := addPointsLotTx(tx, userID, points, expiry); err != nil {
+		return err
+	}
+	return recordLoyaltyTx(tx, userID, points, models.LoyaltyTxRestore, orderID, "Pengembalian poin pesanan batal/kedaluwarsa", expiry)
+}
+
+// reverseSoldAccountPointsTx takes back the points a refunded item earned, as far as the buyer still has them
+func reverseSoldAccountPointsTx(tx *sql.Tx, soldAccountID int) error {
+	var userID int64
+	var points int
+	err := tx.QueryRow(`
+		SELECT user_id, COALESCE(loyalty_points, 0) FROM sold_accounts WHERE id = ?
+	`, soldAccountID).Scan(&userID, &points)
+	if err != nil || points <= 0 {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE sold_accounts SET loyalty_points = 0 WHERE id = ?`, soldAccountID); err != nil {
+		return err
+	}
+
+	taken, _, err := consumePointsTx(tx, userID, points)
+	if err != nil || taken == 0 {
+		return err
+	}
+	return recordLoyaltyTx(tx, userID, -taken, models.LoyaltyTxReversal, strconv.Itoa(soldAccountID), "Poin dibatalkan karena refund", nil)
+}
+
+// ExpireLoyaltyPoints zeroes every lot past its expiry and records it in the ledger.
+// Returns the total points expired.
+func (db *DB) ExpireLoyaltyPoints() (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT id, user_id, remaining FROM loyalty_points
+		WHERE remaining > 0 AND expires_at IS NOT NULL AND expires_at <= datetime('now')
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	type lot struct {
+		id        int
+		userID    int64
+		remaining int
+	}
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		if err := rows.Scan(&l.id, &l.userID, &l.remaining); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, l := range lots {
+		if _, err := tx.Exec(`UPDATE loyalty_points SET remaining = 0 WHERE id = ?`, l.id); err != nil {
+			return 0, err
+		}
+		if err := recordLoyaltyTx(tx, l.userID, -l.remaining, models.LoyaltyTxExpire, "", "Poin hangus", nil); err != nil {
+			return 0, err
+		}
+		total += l.remaining
+	}
+
+	return total, tx.Commit()
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"telegram-premium-store/internal/models"
+)
+
+// User Moderation
+//
+// A banned user has is_active = FALSE with the reason and end of the ban on their row, so broadcasts and
+// reminders that already filter on is_active skip them. Every ban and unban is also kept in moderation_actions.
+
+const banColumns = `user_id, COALESCE(ban_reason, ''), banned_until, COALESCE(banned_by, 0),
+			   banned_at, username, first_name`
+
+// scanBan scans a row selected with banColumns
+func scanBan(row rowScanner, ban *models.UserBan) error {
+	var bannedAt *time.Time
+	if err := row.Scan(&ban.UserID, &ban.Reason, &ban.Until, &ban.BannedBy, &bannedAt, &ban.Username, &ban.FirstName); err != nil {
+		return err
+	}
+	if bannedAt != nil {
+		ban.BannedAt = *bannedAt
+	}
+	return nil
+}
+
+// BanUser bans a user until the given time, or permanently when until is nil
+func (db *DB) BanUser(userID, adminID int64, reason string, until *time.Time) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var untilValue *string
+	if until != nil {
+		formatted := until.UTC().Format("2006-01-02 15:04:05")
+		untilValue = &formatted
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE users SET is_active = FALSE, ban_reason = ?, banned_until = ?, banned_by = ?, banned_at = CURRENT_TIMESTAMP
+		WHERE user_id = ?
+	`, reason, untilValue, adminID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO moderation_actions (user_id, admin_id, action, reason, until) VALUES (?, ?, ?, ?, ?)
+	`, userID, adminID, models.ModerationBan, reason, untilValue); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UnbanUser lifts a user's ban. Returns false if they were not banned.
+func (db *DB) UnbanUser(userID, adminID int64, reason string) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE users SET is_active = TRUE, ban_reason = NULL, banned_until = NULL, banned_by = NULL, banned_at = NULL
+		WHERE user_id = ? AND is_active = FALSE
+	`, userID)
+	if err != nil {
+		return false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO moderation_actions (user_id, admin_id, action, reason) VALUES (?, ?, ?, ?)
+	`, userID, adminID, models.ModerationUnban, nullableString(reason)); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// GetUserBan returns the user's ban, or nil if they are not banned. A temporary ban that has run out
+// no longer counts, even before LiftExpiredBans has cleared it.
+func (db *DB) GetUserBan(userID int64) (*models.UserBan, error) {
+	ban := &models.UserBan{}
+	err := scanBan(db.QueryRow(`
+		SELECT `+banColumns+` FROM users
+		WHERE user_id = ? AND is_active = FALSE AND (banned_until IS NULL OR banned_until > datetime('now'))
+	`, userID), ban)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ban, nil
+}
+
+// GetBannedUsers returns banned users, most recently banned first
+func (db *DB) GetBannedUsers(limit int) ([]models.UserBan, error) {
+	rows, err := db.Query(`
+		SELECT `+banColumns+` FROM users
+		WHERE is_active = FALSE
+		ORDER BY banned_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bans []models.UserBan
+	for rows.Next() {
+		var ban models.UserBan
+		if err := scanBan(rows, &ban); err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+	return bans, rows.Err()
+}
+
+// CountBannedUsers returns how many users are banned
+func (db *DB) CountBannedUsers() (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM users WHERE is_active = FALSE`).Scan(&count)
+	return count, err
+}
+
+// LiftExpiredBans unbans users whose temporary ban has run out and returns their IDs
+func (db *DB) LiftExpiredBans() ([]int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT user_id FROM users
+		WHERE is_active = FALSE AND banned_until IS NOT NULL AND banned_until <= datetime('now')
+	`)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, userID := range userIDs {
+		if _, err := tx.Exec(`
+			UPDATE users SET is_active = TRUE, ban_reason = NULL, banned_until = NULL, banned_by = NULL, banned_at = NULL
+			WHERE user_id = ?
+		`, userID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO moderation_actions (user_id, action) VALUES (?, ?)
+		`, userID, models.ModerationExpired); err != nil {
+			return nil, err
+		}
+	}
+	return userIDs, tx.Commit()
+}
+
+// GetModerationHistory returns the latest moderation actions of a user, newest first
+func (db *DB) GetModerationHistory(userID int64, limit int) ([]models.ModerationAction, error) {
+	rows, err := db.Query(`
+		SELECT id, user_id, admin_id, action, reason, until, created_at
+		FROM moderation_actions
+		WHERE user_id = ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []models.ModerationAction
+	for rows.Next() {
+		var action models.ModerationAction
+		if err := rows.Scan(&action.ID, &action.UserID, &action.AdminID, &action.Action, &action.Reason,
+			&action.Until, &action.CreatedAt); err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, rows.Err()
+}
+
+// GetPendingOrderIDs returns the IDs of the user's unpaid orders
+func (db *DB) GetPendingOrderIDs(userID int64) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT id FROM orders WHERE user_id = ? AND payment_status = ?
+	`, userID, models.PaymentStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orderIDs []string
+	for rows.Next() {
+		var orderID string
+		if err := rows.Scan(&orderID); err != nil {
+			return nil, err
+		}
+		orderIDs = append(orderIDs, orderID)
+	}
+	return orderIDs, rows.Err()
+}
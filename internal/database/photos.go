@@ -0,0 +1,88 @@
+package database
+
+import (
+	"database/sql"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Product Photos
+
+const productPhotoColumns = `id, product_id, file_id, local_path, position, created_at`
+
+// scanProductPhoto scans a row selected with productPhotoColumns
+func scanProductPhoto(row rowScanner, photo *models.ProductPhoto) error {
+	return row.Scan(&photo.ID, &photo.ProductID, &photo.FileID, &photo.LocalPath, &photo.Position, &photo.CreatedAt)
+}
+
+// AddProductPhoto appends a photo to the end of a product's gallery and sets its ID and position
+func (db *DB) AddProductPhoto(photo *models.ProductPhoto) error {
+	return db.QueryRow(`
+		INSERT INTO product_photos (product_id, file_id, local_path, position)
+		SELECT ?, ?, ?, COALESCE(MAX(position), -1) + 1 FROM product_photos WHERE product_id = ?
+		RETURNING id, position
+	`, photo.ProductID, photo.FileID, photo.LocalPath, photo.ProductID).Scan(&photo.ID, &photo.Position)
+}
+
+// GetProductPhotos returns a product's photos, primary photo first
+func (db *DB) GetProductPhotos(productID int) ([]models.ProductPhoto, error) {
+	rows, err := db.Query(`
+		SELECT `+productPhotoColumns+`
+		FROM product_photos
+		WHERE product_id = ?
+		ORDER BY position, id
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var photos []models.ProductPhoto
+	for rows.Next() {
+		var photo models.ProductPhoto
+		if err := scanProductPhoto(rows, &photo); err != nil {
+			return nil, err
+		}
+		photos = append(photos, photo)
+	}
+	return photos, rows.Err()
+}
+
+// GetProductPhoto returns a photo by ID, or nil if it does not exist
+func (db *DB) GetProductPhoto(id int) (*models.ProductPhoto, error) {
+	photo := &models.ProductPhoto{}
+	err := scanProductPhoto(db.QueryRow(`SELECT `+productPhotoColumns+` FROM product_photos WHERE id = ?`, id), photo)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return photo, nil
+}
+
+// SetPrimaryProductPhoto moves a photo to the front of its product's gallery
+func (db *DB) SetPrimaryProductPhoto(id int) error {
+	_, err := db.Exec(`
+		UPDATE product_photos
+		SET position = (SELECT MIN(p.position) FROM product_photos p WHERE p.product_id = product_photos.product_id) - 1
+		WHERE id = ?
+	`, id)
+	return err
+}
+
+// UpdateProductPhotoFileID replaces a photo's Telegram file ID after it was re-uploaded from the local copy
+func (db *DB) UpdateProductPhotoFileID(id int, fileID string) error {
+	_, err := db.Exec(`UPDATE product_photos SET file_id = ? WHERE id = ?`, fileID, id)
+	return err
+}
+
+// DeleteProductPhoto removes a photo. Returns false if it does not exist.
+func (db *DB) DeleteProductPhoto(id int) (bool, error) {
+	result, err := db.Exec(`DELETE FROM product_photos WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Pre-order Management
+
+// SetProductPreorder enables or disables pre-orders for a product.
+// limit caps the units reserved by open pre-orders; eta is the expected restock date.
+func (db *DB) SetProductPreorder(productID int, enabled bool, limit int, eta *time.Time) error {
+	result, err := db.Exec(`
+		UPDATE products
+		SET preorder_enabled = ?, preorder_limit = ?, preorder_eta = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND is_active = TRUE
+	`, enabled, limit, eta, productID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("product %d not found", productID)
+	}
+	return nil
+}
+
+// GetPreorderReservedCount returns units of a product held by pre-orders that are unpaid or awaiting stock
+func (db *DB) GetPreorderReservedCount(productID int) (int, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(oi.quantity), 0)
+		FROM order_items oi
+		JOIN orders o ON oi.order_id = o.id
+		WHERE oi.product_id = ? AND o.is_preorder = TRUE
+		AND o.payment_status IN ('pending', 'awaiting_stock')
+	`, productID).Scan(&count)
+	return count, err
+}
+
+// GetPreorderProducts returns products with pre-orders enabled
+func (db *DB) GetPreorderProducts() ([]models.Product, error) {
+	rows, err := db.Query(`
+		SELECT ` + productColumns + `
+		FROM products
+		WHERE is_active = TRUE AND preorder_enabled = TRUE
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var product models.Product
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
+// CreatePreorder creates an order for out-of-stock products without assigning accounts.
+// The pre-order cap of every product is checked inside the same transaction.
+func (db *DB) CreatePreorder(order *models.Order) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, item := range order.Items {
+		var enabled bool
+		var limit, reserved int
+		err = tx.QueryRow(`
+			SELECT preorder_enabled, preorder_limit FROM products WHERE id = ? AND is_active = TRUE
+		`, item.ProductID).Scan(&enabled, &limit)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return fmt.Errorf("pre-order is not enabled for product ID %d", item.ProductID)
+		}
+
+		err = tx.QueryRow(`
+			SELECT COALESCE(SUM(oi.quantity), 0)
+			FROM order_items oi
+			JOIN orders o ON oi.order_id = o.id
+			WHERE oi.product_id = ? AND o.is_preorder = TRUE
+			AND o.payment_status IN ('pending', 'awaiting_stock')
+		`, item.ProductID).Scan(&reserved)
+		if err != nil {
+			return err
+		}
+		if reserved+item.Quantity > limit {
+			return fmt.Errorf("pre-order limit reached for product ID %d: reserved %d, limit %d",
+				item.ProductID, reserved, limit)
+		}
+	}
+
+	if err := checkOrderLimitsTx(tx, order); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO orders (id, user_id, total_amount, payment_method, payment_status, qris_code, qris_expiry, is_preorder,
+			discount_amount, coupon_id, coupon_code, balance_used, points_used, points_discount, customer_group)
+		VALUES (?, ?, ?, ?, ?, ?, ?, TRUE, ?, ?, ?, ?, ?, ?, (SELECT customer_group FROM users WHERE user_id = ?))
+	`, order.ID, order.UserID, order.TotalAmount, order.PaymentMethod,
+		order.PaymentStatus, order.QRISCode, order.QRISExpiry,
+		order.DiscountAmount, order.CouponID, order.CouponCode, order.BalanceUsed, order.PointsUsed, order.PointsDiscount, order.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := recordCouponRedemptionTx(tx, order); err != nil {
+		return err
+	}
+
+	if err := debitOrderBalanceTx(tx, order); err != nil {
+		return err
+	}
+
+	if err := spendOrderPointsTx(tx, order); err != nil {
+		return err
+	}
+
+	if err := checkFlashSaleCapsTx(tx, order); err != nil {
+		return err
+	}
+
+	for _, item := range order.Items {
+		_, err = tx.Exec(`
+			INSERT INTO order_items (order_id, product_id, quantity, price, flash_sale_id)
+			VALUES (?, ?, ?, ?, ?)
+		`, order.ID, item.ProductID, item.Quantity, item.Price, item.FlashSaleID)
+		if err != nil {
+			return err
+		}
+	}
+
+	order.IsPreorder = true
+	return tx.Commit()
+}
+
+// GetAwaitingPreorderIDs returns IDs of paid pre-orders containing a product, oldest first
+func (db *DB) GetAwaitingPreorderIDs(productID int) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT o.id, o.created_at
+		FROM orders o
+		JOIN order_items oi ON oi.order_id = o.id
+		WHERE oi.product_id = ? AND o.is_preorder = TRUE AND o.payment_status = 'awaiting_stock'
+		ORDER BY o.created_at ASC, o.id ASC
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orderIDs []string
+	for rows.Next() {
+		var orderID string
+		var createdAt time.Time
+		if err := rows.Scan(&orderID, &createdAt); err != nil {
+			return nil, err
+		}
+		orderIDs = append(orderIDs, orderID)
+	}
+
+	return orderIDs, rows.Err()
+}
+
+// FulfillPreorder assigns stock to a paid pre-order and marks it paid.
+// Returns false without changes when there is not enough stock for every item.
+func (db *DB) FulfillPreorder(orderID string) (bool, error) {
+	order, err := db.GetOrder(orderID)
+	if err != nil {
+		return false, err
+	}
+	if order == nil || order.PaymentStatus != models.PaymentStatusAwaitingStock {
+		return false, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Claim the order first so concurrent stock additions cannot fulfill it twice
+	result, err := tx.Exec(`
+		UPDATE orders SET payment_status = ?, updated_at = CURRENT_TIMESTAMP, completed_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND payment_status = ?
+	`, models.PaymentStatusPaid, orderID, models.PaymentStatusAwaitingStock)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	for _, item := range order.Items {
+		available, err := availableAccountCount(tx, item.ProductID)
+		if err != nil {
+			return false, err
+		}
+		if available < item.Quantity {
+			return false, nil
+		}
+
+		if _, err := assignAccountsTx(tx, order, item); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"database/sql"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Customer Groups & Price Tiers
+
+// GetCustomerGroup returns the customer group of a user, retail for unknown users
+func (db *DB) GetCustomerGroup(userID int64) (models.CustomerGroup, error) {
+	var group string
+	err := db.QueryRow(`SELECT COALESCE(customer_group, 'retail') FROM users WHERE user_id = ?`, userID).Scan(&group)
+	if err == sql.ErrNoRows {
+		return models.CustomerRetail, nil
+	}
+	if err != nil {
+		return models.CustomerRetail, err
+	}
+	return models.CustomerGroup(group), nil
+}
+
+// SetCustomerGroup assigns a user to a customer group. Returns false if the user does not exist.
+func (db *DB) SetCustomerGroup(userID int64, group models.CustomerGroup) (bool, error) {
+	result, err := db.Exec(`UPDATE users SET customer_group = ? WHERE user_id = ?`, group, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CountUsersByGroup returns the number of users in each customer group
+func (db *DB) CountUsersByGroup() (map[models.CustomerGroup]int, error) {
+	rows, err := db.Query(`
+		SELECT COALESCE(customer_group, 'retail'), COUNT(*) FROM users GROUP BY COALESCE(customer_group, 'retail')
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[models.CustomerGroup]int)
+	for rows.Next() {
+		var group string
+		var count int
+		if err := rows.Scan(&group, &count); err != nil {
+			return nil, err
+		}
+		counts[models.CustomerGroup(group)] = count
+	}
+	return counts, rows.Err()
+}
+
+// CreatePriceTier inserts a new price tier
+func (db *DB) CreatePriceTier(tier *models.PriceTier) error {
+	result, err := db.Exec(`
+		INSERT INTO price_tiers (customer_group, product_id, min_quantity, price, discount_percent)
+		VALUES (?, ?, ?, ?, ?)
+	`, tier.CustomerGroup, tier.ProductID, tier.MinQuantity, tier.Price, tier.DiscountPercent)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	tier.ID = int(id)
+	return nil
+}
+
+// GetPriceTiers returns the price tiers of a customer group, or of every group when group is empty
+func (db *DB) GetPriceTiers(group models.CustomerGroup) ([]models.PriceTier, error) {
+	rows, err := db.Query(`
+		SELECT t.id, t.customer_group, t.product_id, t.min_quantity, COALESCE(t.price, 0),
+			   COALESCE(t.discount_percent, 0), t.created_at, p.name
+		FROM price_tiers t
+		LEFT JOIN products p ON p.id = t.product_id
+		WHERE ? = '' OR t.customer_group = ?
+		ORDER BY t.customer_group, t.product_id IS NOT NULL, p.name, t.min_quantity
+	`, group, group)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tiers []models.PriceTier
+	for rows.Next() {
+		var t models.PriceTier
+		if err := rows.Scan(&t.ID, &t.CustomerGroup, &t.ProductID, &t.MinQuantity, &t.Price,
+			&t.DiscountPercent, &t.CreatedAt, &t.ProductName); err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, t)
+	}
+	return tiers, rows.Err()
+}
+
+// DeletePriceTier removes a price tier. Returns false if it does not exist.
+func (db *DB) DeletePriceTier(id int) (bool, error) {
+	result, err := db.Exec(`DELETE FROM price_tiers WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Purchased Items
+
+// GetUserPurchasedOrders returns the user's paid orders with delivered items, newest first. Items are assigned
+// when an order is created, so unpaid and cancelled orders have sold_accounts rows too and must be left out.
+func (db *DB) GetUserPurchasedOrders(userID int64, limit, offset int) ([]models.PurchasedOrder, error) {
+	rows, err := db.Query(`
+		SELECT sa.order_id, o.created_at, COUNT(*), GROUP_CONCAT(p.name, char(31))
+		FROM sold_accounts sa
+		JOIN orders o ON o.id = sa.order_id
+		JOIN products p ON p.id = sa.product_id
+		WHERE sa.user_id = ? AND o.payment_status = ?
+		GROUP BY sa.order_id
+		ORDER BY o.created_at DESC
+		LIMIT ? OFFSET ?
+	`, userID, models.PaymentStatusPaid, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []models.PurchasedOrder
+	for rows.Next() {
+		var order models.PurchasedOrder
+		var names string
+		if err := rows.Scan(&order.OrderID, &order.PurchasedAt, &order.ItemCount, &names); err != nil {
+			return nil, err
+		}
+		// Names are joined with the unit separator, since product names may contain commas
+		seen := make(map[string]bool)
+		for _, name := range strings.Split(names, "\x1f") {
+			if !seen[name] {
+				seen[name] = true
+				order.ProductNames = append(order.ProductNames, name)
+			}
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+// Scheduled Message Deletions
+
+// ScheduleMessageDeletion remembers a bot message to delete from the chat at the given time
+func (db *DB) ScheduleMessageDeletion(chatID int64, messageID int, at time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO scheduled_deletions (chat_id, message_id, delete_at) VALUES (?, ?, ?)
+	`, chatID, messageID, at.UTC().Format("2006-01-02 15:04:05"))
+	return err
+}
+
+// GetDueMessageDeletions returns messages whose deletion time has passed
+func (db *DB) GetDueMessageDeletions(limit int) ([]models.ScheduledDeletion, error) {
+	rows, err := db.Query(`
+		SELECT id, chat_id, message_id FROM scheduled_deletions
+		WHERE delete_at <= datetime('now')
+		ORDER BY delete_at
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deletions []models.ScheduledDeletion
+	for rows.Next() {
+		var deletion models.ScheduledDeletion
+		if err := rows.Scan(&deletion.ID, &deletion.ChatID, &deletion.MessageID); err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, deletion)
+	}
+	return deletions, rows.Err()
+}
+
+// RemoveMessageDeletion forgets a scheduled deletion once it has been carried out
+func (db *DB) RemoveMessageDeletion(id int) error {
+	_, err := db.Exec(`DELETE FROM scheduled_deletions WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Referral Program
+
+// referralCodeAlphabet avoids characters that are easy to confuse (0/O, 1/I)
+const referralCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+func generateReferralCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i := range buf {
+		buf[i] = referralCodeAlphabet[int(buf[i])%len(referralCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// GetOrCreateReferralCode returns the user's referral code, generating one on first use
+func (db *DB) GetOrCreateReferralCode(userID int64) (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		var code sql.NullString
+		err := db.QueryRow(`SELECT referral_code FROM users WHERE user_id = ?`, userID).Scan(&code)
+		if err != nil {
+			return "", err
+		}
+		if code.Valid && code.String != "" {
+			return code.String, nil
+		}
+
+		newCode, err := generateReferralCode()
+		if err != nil {
+			return "", err
+		}
+		_, err = db.Exec(`
+			UPDATE users SET referral_code = ? WHERE user_id = ? AND referral_code IS NULL
+		`, newCode, userID)
+		if err != nil && !strings.Contains(err.Error(), "UNIQUE") {
+			return "", err
+		}
+		// On a code collision or a concurrent update, loop and read again
+	}
+	return "", fmt.Errorf("failed to generate referral code for user %d", userID)
+}
+
+// AttributeReferral links a new user to the owner of a referral code.
+// Only users who joined in the last 10 minutes, have no orders and were not referred before
+// can be attributed, so existing users cannot be claimed by sharing links.
+// Returns the referrer ID, or 0 when the user was not attributed.
+func (db *DB) AttributeReferral(userID int64, code string) (int64, error) {
+	var referrerID int64
+	err := db.QueryRow(`SELECT user_id FROM users WHERE referral_code = ?`, strings.ToUpper(code)).Scan(&referrerID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if referrerID == userID {
+		return 0, nil
+	}
+
+	result, err := db.Exec(`
+		UPDATE users SET referred_by = ?, referred_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND referred_by IS NULL
+		AND join_date >= datetime('now', '-10 minutes')
+		AND NOT EXISTS (SELECT 1 FROM orders WHERE user_id = ?)
+	`, referrerID, userID, userID)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, err
+	}
+	return referrerID, nil
+}
+
+// GetReferralStats returns invite and reward totals for a referrer
+func (db *DB) GetReferralStats(userID int64) (*models.ReferralStats, error) {
+	stats := &models.ReferralStats{}
+	err := db.QueryRow(`SELECT COUNT(*) FROM users WHERE referred_by = ?`, userID).Scan(&stats.Invited)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.QueryRow(`
+		SELECT COUNT(*),
+			   COALESCE(SUM(CASE WHEN reward_type != 'coupon' THEN amount ELSE 0 END), 0),
+			   COALESCE(SUM(CASE WHEN reward_type = 'coupon' THEN 1 ELSE 0 END), 0)
+		FROM referral_rewards WHERE referrer_id = ?
+	`, userID).Scan(&stats.Converted, &stats.BalanceEarned, &stats.CouponsEarned)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetReferredUsers returns the most recent users invited by a referrer
+func (db *DB) GetReferredUsers(referrerID int64, limit int) ([]models.ReferredUser, error) {
+	rows, err := db.Query(`
+		SELECT u.user_id, u.username, u.first_name, u.referred_at,
+			   rr.id IS NOT NULL, rr.reward_type, COALESCE(rr.amount, 0)
+		FROM users u
+		LEFT JOIN referral_rewards rr ON rr.referred_id = u.user_id
+		WHERE u.referred_by = ?
+		ORDER BY u.referred_at DESC
+		LIMIT ?
+	`, referrerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.ReferredUser
+	for rows.Next() {
+		var u models.ReferredUser
+		if err := rows.Scan(&u.UserID, &u.Username, &u.FirstName, &u.ReferredAt,
+			&u.Rewarded, &u.RewardType, &u.Amount); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// GrantReferralReward rewards the referrer of a user for that user's first paid order.
+// Balance rewards are credited to the referrer; coupon rewards insert the given coupon owned by the referrer.
+// Returns the referrer ID, or 0 when the user has no referrer or the reward was already granted.
+func (db *DB) GrantReferralReward(referredID int64, orderID string, rewardType models.ReferralRewardType, amount int, coupon *models.Coupon) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var referrerID sql.NullInt64
+	err = tx.QueryRow(`SELECT referred_by FROM users WHERE user_id = ?`, referredID).Scan(&referrerID)
+	if err == sql.ErrNoRows || (err == nil && !referrerID.Valid) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO referral_rewards (referrer_id, referred_id, order_id, reward_type, amount)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(referred_id) DO NOTHING
+	`, referrerID.Int64, referredID, orderID, rewardType, amount)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, err
+	}
+
+	if coupon != nil {
+		coupon.UserID = &referrerID.Int64
+		if err := insertCoupon(tx, coupon); err != nil {
+			return 0, err
+		}
+		_, err = tx.Exec(`UPDATE referral_rewards SET coupon_code = ? WHERE referred_id = ?`, coupon.Code, referredID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if rewardType != models.ReferralRewardCoupon && amount > 0 {
+		err = adjustBalanceTx(tx, referrerID.Int64, amount, models.BalanceTxReferral, orderID,
+			fmt.Sprintf("Bonus referral dari user %d", referredID))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return referrerID.Int64, nil
+}
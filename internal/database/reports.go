@@ -0,0 +1,91 @@
+package database
+
+import (
+	"fmt"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Profit Reporting
+
+// profitGroupColumns maps a report dimension to its key and label expressions
+var profitGroupColumns = map[models.ProfitGroupBy][2]string{
+	models.ProfitByProduct:  {"CAST(sa.product_id AS TEXT)", "p.name"},
+	models.ProfitByCategory: {"p.category", "COALESCE(c.display_name, p.category)"},
+	models.ProfitBySupplier: {"COALESCE(NULLIF(sa.supplier, ''), '-')", "COALESCE(NULLIF(sa.supplier, ''), 'Tanpa Supplier')"},
+	models.ProfitByDay:      {"date(sa.sold_at)", "date(sa.sold_at)"},
+	models.ProfitByGroup: {"COALESCE(o.customer_group, 'retail')",
+		"CASE COALESCE(o.customer_group, 'retail') WHEN 'reseller' THEN 'Reseller' WHEN 'vip' THEN 'VIP' ELSE 'Retail' END"},
+}
+
+// paidPriceColumn is what the buyer actually paid for a sold item: its price less its share of the order's coupon
+// and points discount, spread over the order's items in proportion to their price. Store balance counts as paid.
+// It needs the orders table as o and orderSubtotalJoin.
+const paidPriceColumn = `(sa.sold_price - COALESCE(sa.sold_price * (COALESCE(o.discount_amount, 0) + COALESCE(o.points_discount, 0))
+			   / NULLIF(os.subtotal, 0), 0))`
+
+// orderSubtotalJoin joins the undiscounted total of each order's items as os. Replacement items are sold
+// for 0, so replacing an item does not change the subtotal.
+const orderSubtotalJoin = `JOIN (SELECT order_id, SUM(sold_price) AS subtotal FROM sold_accounts GROUP BY order_id) os ON os.order_id = sa.order_id`
+
+// GetProfitReport returns revenue, cost and profit of paid sales grouped by the given dimension.
+// Revenue is what buyers paid after coupons and points. Refunded items keep their cost but contribute no revenue. Only sales from the last `days` days are included; use 0 for all time.
+func (db *DB) GetProfitReport(groupBy models.ProfitGroupBy, days int) ([]models.ProfitReportRow, error) {
+	columns, ok := profitGroupColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown profit report grouping: %s", groupBy)
+	}
+
+	orderBy := "revenue - cost DESC"
+	if groupBy == models.ProfitByDay {
+		orderBy = "key DESC"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s AS key, %s AS label, COUNT(*),
+			   COALESCE(SUM(CASE WHEN sa.status = 'refunded' THEN 0 ELSE `+paidPriceColumn+` END), 0) AS revenue, COALESCE(SUM(sa.cost_price), 0) AS cost
+		FROM sold_accounts sa
+		JOIN orders o ON sa.order_id = o.id
+		`+orderSubtotalJoin+`
+		JOIN products p ON sa.product_id = p.id
+		LEFT JOIN categories c ON c.name = p.category
+		WHERE o.payment_status IN ('paid', 'refunded')
+		AND (? = 0 OR sa.sold_at >= datetime('now', '-' || ? || ' days'))
+		GROUP BY key, label
+		ORDER BY %s
+	`, columns[0], columns[1], orderBy)
+
+	rows, err := db.Query(query, days, days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var report []models.ProfitReportRow
+	for rows.Next() {
+		var row models.ProfitReportRow
+		if err := rows.Scan(&row.Key, &row.Label, &row.ItemsSold, &row.Revenue, &row.Cost); err != nil {
+			return nil, err
+		}
+		report = append(report, row)
+	}
+
+	return report, rows.Err()
+}
+
+// GetProfitSummary returns total revenue (after coupons and points), cost and profit of paid sales in the last `days` days (0 for all time)
+func (db *DB) GetProfitSummary(days int) (*models.ProfitReportRow, error) {
+	summary := &models.ProfitReportRow{Key: "total", Label: "Total"}
+	err := db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN sa.status = 'refunded' THEN 0 ELSE `+paidPriceColumn+` END), 0), COALESCE(SUM(sa.cost_price), 0)
+		FROM sold_accounts sa
+		JOIN orders o ON sa.order_id = o.id
+		`+orderSubtotalJoin+`
+		WHERE o.payment_status IN ('paid', 'refunded')
+		AND (? = 0 OR sa.sold_at >= datetime('now', '-' || ? || ' days'))
+	`, days, days).Scan(&summary.ItemsSold, &summary.Revenue, &summary.Cost)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Product Reviews
+//
+// A buyer can rate each product of a paid order once; rating it again replaces the earlier rating.
+// Reviews hidden by an admin are kept but neither shown nor counted in the average.
+
+const reviewColumns = `r.id, r.order_id, r.product_id, r.user_id, r.rating, r.comment, r.is_hidden,
+			   r.created_at, r.updated_at, COALESCE(p.name, ''), u.first_name`
+
+const reviewJoins = `
+		FROM reviews r
+		LEFT JOIN products p ON p.id = r.product_id
+		LEFT JOIN users u ON u.user_id = r.user_id`
+
+// scanReview scans a row selected with reviewColumns
+func scanReview(row rowScanner, review *models.Review) error {
+	return row.Scan(&review.ID, &review.OrderID, &review.ProductID, &review.UserID, &review.Rating,
+		&review.Comment, &review.IsHidden, &review.CreatedAt, &review.UpdatedAt,
+		&review.ProductName, &review.UserFirstName)
+}
+
+// queryReviews runs a query selecting reviewColumns
+func (db *DB) queryReviews(query string, args ...interface{}) ([]models.Review, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []models.Review
+	for rows.Next() {
+		var review models.Review
+		if err := scanReview(rows, &review); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// SaveReview stores the user's rating of a product from one of their orders and returns the review ID.
+// The order must be paid, belong to the user and contain the product.
+func (db *DB) SaveReview(orderID string, productID int, userID int64, rating int) (int, error) {
+	if rating < 1 || rating > 5 {
+		return 0, fmt.Errorf("invalid rating: %d", rating)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var purchased int
+	err = tx.QueryRow(`
+		SELECT COUNT(*)
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE oi.order_id = ? AND oi.product_id = ? AND o.user_id = ? AND o.payment_status = ?
+	`, orderID, productID, userID, models.PaymentStatusPaid).Scan(&purchased)
+	if err != nil {
+		return 0, err
+	}
+	if purchased == 0 {
+		return 0, fmt.Errorf("review not allowed: product %d is not in paid order %s of user %d", productID, orderID, userID)
+	}
+
+	var id int
+	err = tx.QueryRow(`
+		INSERT INTO reviews (order_id, product_id, user_id, rating) VALUES (?, ?, ?, ?)
+		ON CONFLICT(order_id, product_id) DO UPDATE SET rating = excluded.rating, updated_at = CURRENT_TIMESTAMP
+		RETURNING id
+	`, orderID, productID, userID, rating).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// SetReviewComment sets the comment of the user's own review. Returns false if the review is not theirs.
+func (db *DB) SetReviewComment(reviewID int, userID int64, comment string) (bool, error) {
+	result, err := db.Exec(`
+		UPDATE reviews SET comment = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND user_id = ?
+	`, nullableString(strings.TrimSpace(comment)), reviewID, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetReview returns a review by ID, or nil if it does not exist
+func (db *DB) GetReview(id int) (*models.Review, error) {
+	review := &models.Review{}
+	err := scanReview(db.QueryRow(`SELECT `+reviewColumns+reviewJoins+` WHERE r.id = ?`, id), review)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// GetProductRating returns the average rating and number of visible reviews of a product
+func (db *DB) GetProductRating(productID int) (*models.ProductRating, error) {
+	rating := &models.ProductRating{}
+	err := db.QueryRow(`
+		SELECT COALESCE(AVG(rating), 0), COUNT(*) FROM reviews WHERE product_id = ? AND is_hidden = FALSE
+	`, productID).Scan(&rating.Average, &rating.Count)
+	if err != nil {
+		return nil, err
+	}
+	return rating, nil
+}
+
+// GetProductReviews returns the most recent visible reviews of a product
+func (db *DB) GetProductReviews(productID, limit int) ([]models.Review, error) {
+	return db.queryReviews(`
+		SELECT `+reviewColumns+reviewJoins+`
+		WHERE r.product_id = ? AND r.is_hidden = FALSE
+		ORDER BY r.updated_at DESC, r.id DESC
+		LIMIT ?
+	`, productID, limit)
+}
+
+// GetReviews returns the most recent reviews including hidden ones, of one product or of all when productID is 0
+func (db *DB) GetReviews(productID, limit int) ([]models.Review, error) {
+	return db.queryReviews(`
+		SELECT `+reviewColumns+reviewJoins+`
+		WHERE ? = 0 OR r.product_id = ?
+		ORDER BY r.updated_at DESC, r.id DESC
+		LIMIT ?
+	`, productID, productID, limit)
+}
+
+// SetReviewHidden hides or shows a review. Returns false if the review does not exist.
+func (db *DB) SetReviewHidden(id int, hidden bool) (bool, error) {
+	result, err := db.Exec(`UPDATE reviews SET is_hidden = ? WHERE id = ?`, hidden, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// DeleteReview removes a review. Returns false if the review does not exist.
+func (db *DB) DeleteReview(id int) (bool, error) {
+	result, err := db.Exec(`DELETE FROM reviews WHERE id = ?`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetReviewPrompts returns orders paid at least delayHours ago whose buyer has not been asked for a review,
+// with the products not reviewed yet. Orders paid more than a week before that are skipped.
+func (db *DB) GetReviewPrompts(delayHours, limit int) ([]models.ReviewPrompt, error) {
+	rows, err := db.Query(`
+		SELECT id, user_id FROM orders
+		WHERE payment_status = ? AND review_prompted_at IS NULL AND completed_at IS NOT NULL
+		AND datetime(completed_at) <= datetime('now', ?)
+		AND datetime(completed_at) > datetime('now', ?)
+		ORDER BY completed_at
+		LIMIT ?
+	`, models.PaymentStatusPaid, fmt.Sprintf("-%d hours", delayHours), fmt.Sprintf("-%d hours", delayHours+7*24), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompts []models.ReviewPrompt
+	for rows.Next() {
+		var prompt models.ReviewPrompt
+		if err := rows.Scan(&prompt.OrderID, &prompt.UserID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		prompts = append(prompts, prompt)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range prompts {
+		products, err := db.getUnreviewedOrderProducts(prompts[i].OrderID)
+		if err != nil {
+			return nil, err
+		}
+		prompts[i].Products = products
+	}
+	return prompts, nil
+}
+
+// getUnreviewedOrderProducts returns the distinct products of an order that have no review yet
+func (db *DB) getUnreviewedOrderProducts(orderID string) ([]models.Product, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT p.id, p.name
+		FROM order_items oi
+		JOIN products p ON p.id = oi.product_id
+		LEFT JOIN reviews r ON r.order_id = oi.order_id AND r.product_id = oi.product_id
+		WHERE oi.order_id = ? AND r.id IS NULL
+		ORDER BY p.name
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var product models.Product
+		if err := rows.Scan(&product.ID, &product.Name); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
+
+// MarkReviewPrompted records that the buyer of an order was asked for a review
+func (db *DB) MarkReviewPrompted(orderID string) error {
+	_, err := db.Exec(`UPDATE orders SET review_prompted_at = CURRENT_TIMESTAMP WHERE id = ?`, orderID)
+	return err
+}
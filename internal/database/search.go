@@ -0,0 +1,314 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"telegram-premium-store/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Product Search
+//
+// Products are indexed in the product_search FTS5 table, kept in sync with the products table by
+// triggers and rebuilt at startup. SQLite builds without FTS5 (go-sqlite3 needs the sqlite_fts5
+// build tag) fall back to LIKE matching. Search terms no product word starts with are corrected
+// to the closest word in the catalog, so small typos still find the product.
+
+// maxSearchTerms caps the number of words of a query that are searched
+const maxSearchTerms = 5
+
+// setupSearchIndex creates the FTS5 index and its triggers, then rebuilds it from the products table
+func (db *DB) setupSearchIndex() error {
+	_, err := db.Exec(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS product_search USING fts5(
+			name, description, category,
+			tokenize = 'unicode61 remove_diacritics 2'
+		)
+	`)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			logrus.Warn("SQLite was built without FTS5, product search falls back to LIKE matching (build with -tags sqlite_fts5)")
+			return nil
+		}
+		return err
+	}
+
+	// category holds both the category name and its display name so either can be searched
+	const indexRow = `(new.id, new.name, new.description,
+		new.category || ' ' || COALESCE((SELECT display_name FROM categories WHERE name = new.category), ''))`
+	statements := []string{
+		`CREATE TRIGGER IF NOT EXISTS product_search_insert AFTER INSERT ON products BEGIN
+			INSERT INTO product_search (rowid, name, description, category) VALUES ` + indexRow + `;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS product_search_update AFTER UPDATE ON products BEGIN
+			DELETE FROM product_search WHERE rowid = old.id;
+			INSERT INTO product_search (rowid, name, description, category) VALUES ` + indexRow + `;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS product_search_delete AFTER DELETE ON products BEGIN
+			DELETE FROM product_search WHERE rowid = old.id;
+		END`,
+		`DELETE FROM product_search`,
+		`INSERT INTO product_search (rowid, name, description, category)
+			SELECT p.id, p.name, p.description, p.category || ' ' || COALESCE(c.display_name, '')
+			FROM products p
+			LEFT JOIN categories c ON c.name = p.category`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	db.fullTextSearch = true
+	return nil
+}
+
+// searchWords lowercases text and splits it into words of letters and digits
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NormalizeSearchQuery lowercases a query and splits it into at most maxSearchTerms words
+func NormalizeSearchQuery(query string) []string {
+	terms := searchWords(query)
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	return terms
+}
+
+// SearchProducts finds active products matching every word of the query, best match first.
+// When nothing matches, misspelled words are corrected and the search is run again.
+func (db *DB) SearchProducts(query string, limit, offset int) (*models.SearchResult, error) {
+	terms := NormalizeSearchQuery(query)
+	result := &models.SearchResult{Query: strings.Join(terms, " ")}
+	if len(terms) == 0 {
+		return result, nil
+	}
+
+	// Check the whole result set rather than this page, so later pages of a corrected search still work
+	exact, err := db.searchProducts(terms, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(exact) == 0 {
+		corrected, err := db.correctSearchTerms(terms)
+		if err != nil {
+			return nil, err
+		}
+		if joined := strings.Join(corrected, " "); joined != result.Query {
+			result.Corrected = joined
+			terms = corrected
+		}
+	}
+
+	result.Products, err = db.searchProducts(terms, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// searchProducts runs one search page for terms, matched as word prefixes
+func (db *DB) searchProducts(terms []string, limit, offset int) ([]models.Product, error) {
+	var query string
+	var args []interface{}
+
+	if db.fullTextSearch {
+		// Every term must match as a prefix; name matches outrank category, then description matches
+		match := make([]string, len(terms))
+		for i, term := range terms {
+			match[i] = `"` + term + `"*`
+		}
+		query = `
+			SELECT ` + productColumns + `
+			FROM products
+			JOIN (
+				SELECT rowid AS match_id, bm25(product_search, 10.0, 1.0, 4.0) AS score
+				FROM product_search WHERE product_search MATCH ?
+			) ON match_id = products.id
+			WHERE is_active = TRUE
+			ORDER BY score, name
+			LIMIT ? OFFSET ?
+		`
+		args = []interface{}{strings.Join(match, " ")}
+	} else {
+		var conditions []string
+		for _, term := range terms {
+			conditions = append(conditions, `(LOWER(name) LIKE ? OR LOWER(description) LIKE ? OR LOWER(category) LIKE ?
+				OR LOWER(COALESCE((SELECT display_name FROM categories WHERE categories.name = products.category), '')) LIKE ?)`)
+			pattern := "%" + term + "%"
+			args = append(args, pattern, pattern, pattern, pattern)
+		}
+		query = `
+			SELECT ` + productColumns + `
+			FROM products
+			WHERE is_active = TRUE AND ` + strings.Join(conditions, " AND ") + `
+			ORDER BY LOWER(name) LIKE ? DESC, name
+			LIMIT ? OFFSET ?
+		`
+		args = append(args, "%"+terms[0]+"%")
+	}
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var product models.Product
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
+
+// correctSearchTerms replaces each term that no catalog word starts with by the closest catalog word
+func (db *DB) correctSearchTerms(terms []string) ([]string, error) {
+	vocabulary, err := db.searchVocabulary()
+	if err != nil {
+		return nil, err
+	}
+
+	corrected := make([]string, len(terms))
+	for i, term := range terms {
+		corrected[i] = closestWord(term, vocabulary)
+	}
+	return corrected, nil
+}
+
+// searchVocabulary returns the distinct words of active product names, descriptions and categories
+func (db *DB) searchVocabulary() ([]string, error) {
+	rows, err := db.Query(`
+		SELECT name, description, category,
+			COALESCE((SELECT display_name FROM categories WHERE categories.name = products.category), '')
+		FROM products
+		WHERE is_active = TRUE
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var name, description, category, displayName string
+		if err := rows.Scan(&name, &description, &category, &displayName); err != nil {
+			return nil, err
+		}
+		for _, text := range []string{name, description, category, displayName} {
+			for _, word := range searchWords(text) {
+				seen[word] = true
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	vocabulary := make([]string, 0, len(seen))
+	for word := range seen {
+		vocabulary = append(vocabulary, word)
+	}
+	sort.Strings(vocabulary)
+	return vocabulary, nil
+}
+
+// closestWord returns the vocabulary word closest to term, or term itself when a word already
+// starts with it or nothing is within the allowed number of typos
+func closestWord(term string, vocabulary []string) string {
+	maxTypos := 0
+	switch n := len([]rune(term)); {
+	case n >= 8:
+		maxTypos = 2
+	case n >= 4:
+		maxTypos = 1
+	}
+
+	best, bestDistance := term, maxTypos+1
+	for _, word := range vocabulary {
+		if strings.HasPrefix(word, term) {
+			return term
+		}
+		distance := editDistance(term, word)
+		// Also compare with the start of longer words, so "netfli" style partial typos match
+		if runes := []rune(word); len(runes) > len([]rune(term)) {
+			if d := editDistance(term, string(runes[:len([]rune(term))])); d < distance {
+				distance = d
+			}
+		}
+		if distance < bestDistance {
+			best, bestDistance = word, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// LogSearchMiss records a search that found no products
+func (db *DB) LogSearchMiss(userID int64, query string) error {
+	_, err := db.Exec(`INSERT INTO search_misses (user_id, query) VALUES (?, ?)`, userID, query)
+	return err
+}
+
+// GetSearchMisses returns the most frequent searches without results over the last days
+func (db *DB) GetSearchMisses(days, limit int) ([]models.SearchMiss, error) {
+	rows, err := db.Query(`
+		SELECT query, COUNT(*), COUNT(DISTINCT user_id), MAX(created_at)
+		FROM search_misses
+		WHERE created_at > datetime('now', ?)
+		GROUP BY query
+		ORDER BY COUNT(*) DESC, MAX(created_at) DESC
+		LIMIT ?
+	`, fmt.Sprintf("-%d days", days), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var misses []models.SearchMiss
+	for rows.Next() {
+		var miss models.SearchMiss
+		var lastSearched string
+		if err := rows.Scan(&miss.Query, &miss.Count, &miss.Users, &lastSearched); err != nil {
+			return nil, err
+		}
+		// MAX() loses the column type, so the driver returns SQLite's datetime text
+		miss.LastSearched, _ = time.Parse("2006-01-02 15:04:05", lastSearched)
+		misses = append(misses, miss)
+	}
+	return misses, rows.Err()
+}
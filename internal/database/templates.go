@@ -0,0 +1,46 @@
+package database
+
+import (
+	"database/sql"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Message Templates
+//
+// Admins change the text of a template per language; a language without a saved text uses the default text.
+
+// GetMessageTemplate returns the admin's text for a template in a language, or nil if it uses the default text
+func (db *DB) GetMessageTemplate(name, lang string) (*models.MessageTemplate, error) {
+	tmpl := &models.MessageTemplate{}
+	err := db.QueryRow(`
+		SELECT name, lang, body, updated_by, updated_at FROM message_templates WHERE name = ? AND lang = ?
+	`, name, lang).Scan(&tmpl.Name, &tmpl.Language, &tmpl.Body, &tmpl.UpdatedBy, &tmpl.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// SaveMessageTemplate replaces the text of a template in a language
+func (db *DB) SaveMessageTemplate(name, lang, body string, adminID int64) error {
+	_, err := db.Exec(`
+		INSERT INTO message_templates (name, lang, body, updated_by) VALUES (?, ?, ?, ?)
+		ON CONFLICT(name, lang) DO UPDATE SET body = excluded.body, updated_by = excluded.updated_by,
+			updated_at = CURRENT_TIMESTAMP
+	`, name, lang, body, adminID)
+	return err
+}
+
+// DeleteMessageTemplate goes back to the default text of a template in a language. Returns false if it was not changed.
+func (db *DB) DeleteMessageTemplate(name, lang string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM message_templates WHERE name = ? AND lang = ?`, name, lang)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
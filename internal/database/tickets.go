@@ -0,0 +1,229 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Support Tickets
+//
+// A ticket is open while it waits for an admin and answered while it waits for the buyer.
+// Buyers have at most one ticket that is not closed; writing to support continues it.
+
+const ticketColumns = `t.id, t.user_id, t.order_id, t.status, t.assigned_to, t.created_at, t.updated_at,
+			   t.last_customer_message_at, t.first_response_at, t.closed_at, u.username, u.first_name,
+			   (SELECT COUNT(*) FROM ticket_messages m WHERE m.ticket_id = t.id)`
+
+const ticketJoins = `
+		FROM support_tickets t
+		LEFT JOIN users u ON u.user_id = t.user_id`
+
+// scanTicket scans a row selected with ticketColumns
+func scanTicket(row rowScanner, ticket *models.SupportTicket) error {
+	return row.Scan(&ticket.ID, &ticket.UserID, &ticket.OrderID, &ticket.Status, &ticket.AssignedTo,
+		&ticket.CreatedAt, &ticket.UpdatedAt, &ticket.LastCustomerMessageAt, &ticket.FirstResponseAt,
+		&ticket.ClosedAt, &ticket.Username, &ticket.UserFirstName, &ticket.MessageCount)
+}
+
+// queryTicket runs a query selecting ticketColumns of at most one ticket, returning nil if there is none
+func (db *DB) queryTicket(query string, args ...interface{}) (*models.SupportTicket, error) {
+	ticket := &models.SupportTicket{}
+	err := scanTicket(db.QueryRow(query, args...), ticket)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// CreateTicket opens a ticket for the user, optionally about one of their orders
+func (db *DB) CreateTicket(userID int64, orderID *string) (int, error) {
+	var id int
+	err := db.QueryRow(`
+		INSERT INTO support_tickets (user_id, order_id) VALUES (?, ?) RETURNING id
+	`, userID, orderID).Scan(&id)
+	return id, err
+}
+
+// GetTicket returns a ticket by ID, or nil if it does not exist
+func (db *DB) GetTicket(id int) (*models.SupportTicket, error) {
+	return db.queryTicket(`SELECT `+ticketColumns+ticketJoins+` WHERE t.id = ?`, id)
+}
+
+// GetActiveTicket returns the user's ticket that is not closed yet, or nil
+func (db *DB) GetActiveTicket(userID int64) (*models.SupportTicket, error) {
+	return db.queryTicket(`
+		SELECT `+ticketColumns+ticketJoins+`
+		WHERE t.user_id = ? AND t.status != ?
+		ORDER BY t.id DESC LIMIT 1
+	`, userID, models.TicketStatusClosed)
+}
+
+// GetTicketQueue returns tickets that are not closed: those waiting for an admin first, longest waiting first
+func (db *DB) GetTicketQueue(limit int) ([]models.SupportTicket, error) {
+	rows, err := db.Query(`
+		SELECT `+ticketColumns+ticketJoins+`
+		WHERE t.status != ?
+		ORDER BY t.status = ? DESC, t.last_customer_message_at
+		LIMIT ?
+	`, models.TicketStatusClosed, models.TicketStatusOpen, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickets []models.SupportTicket
+	for rows.Next() {
+		var ticket models.SupportTicket
+		if err := scanTicket(rows, &ticket); err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, rows.Err()
+}
+
+// AddTicketMessage records a message and moves the ticket to whoever has to answer next.
+// The first admin answer sets the first response time and assigns the ticket if nobody has it yet.
+func (db *DB) AddTicketMessage(ticketID int, senderID int64, fromAdmin bool, text string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO ticket_messages (ticket_id, sender_id, from_admin, text) VALUES (?, ?, ?, ?)
+	`, ticketID, senderID, fromAdmin, text); err != nil {
+		return err
+	}
+
+	if fromAdmin {
+		_, err = tx.Exec(`
+			UPDATE support_tickets
+			SET status = ?, first_response_at = COALESCE(first_response_at, CURRENT_TIMESTAMP),
+				assigned_to = COALESCE(assigned_to, ?), updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, models.TicketStatusAnswered, senderID, ticketID)
+	} else {
+		_, err = tx.Exec(`
+			UPDATE support_tickets
+			SET status = ?, last_customer_message_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, models.TicketStatusOpen, ticketID)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetTicketMessages returns the latest messages of a ticket, oldest first
+func (db *DB) GetTicketMessages(ticketID, limit int) ([]models.TicketMessage, error) {
+	rows, err := db.Query(`
+		SELECT id, ticket_id, sender_id, from_admin, text, created_at FROM (
+			SELECT * FROM ticket_messages WHERE ticket_id = ? ORDER BY id DESC LIMIT ?
+		) ORDER BY id
+	`, ticketID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []models.TicketMessage
+	for rows.Next() {
+		var message models.TicketMessage
+		if err := rows.Scan(&message.ID, &message.TicketID, &message.SenderID, &message.FromAdmin,
+			&message.Text, &message.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// AssignTicket makes an admin responsible for a ticket
+func (db *DB) AssignTicket(ticketID int, adminID int64) error {
+	_, err := db.Exec(`
+		UPDATE support_tickets SET assigned_to = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, adminID, ticketID)
+	return err
+}
+
+// CloseTicket closes a ticket. Returns false if it was already closed or does not exist.
+func (db *DB) CloseTicket(ticketID int) (bool, error) {
+	result, err := db.Exec(`
+		UPDATE support_tickets SET status = ?, closed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status != ?
+	`, models.TicketStatusClosed, ticketID, models.TicketStatusClosed)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ReopenTicket puts a closed ticket back in the queue. Returns false if it was not closed.
+func (db *DB) ReopenTicket(ticketID int) (bool, error) {
+	result, err := db.Exec(`
+		UPDATE support_tickets SET status = ?, closed_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = ?
+	`, models.TicketStatusOpen, ticketID, models.TicketStatusClosed)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// SaveTicketRelay remembers that a chat message is a relayed copy of a ticket message
+func (db *DB) SaveTicketRelay(chatID int64, messageID, ticketID int) error {
+	_, err := db.Exec(`
+		INSERT OR REPLACE INTO ticket_relays (chat_id, message_id, ticket_id) VALUES (?, ?, ?)
+	`, chatID, messageID, ticketID)
+	return err
+}
+
+// GetTicketIDByRelay returns the ticket a relayed message belongs to, or 0 if it is not a relayed message
+func (db *DB) GetTicketIDByRelay(chatID int64, messageID int) (int, error) {
+	var ticketID int
+	err := db.QueryRow(`
+		SELECT ticket_id FROM ticket_relays WHERE chat_id = ? AND message_id = ?
+	`, chatID, messageID).Scan(&ticketID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return ticketID, err
+}
+
+// GetTicketStats counts tickets in the queue and averages response times of tickets opened since the given time
+func (db *DB) GetTicketStats(since time.Time) (*models.TicketStats, error) {
+	stats := &models.TicketStats{}
+	err := db.QueryRow(`
+		SELECT COALESCE(SUM(status = ?), 0), COALESCE(SUM(status = ?), 0) FROM support_tickets
+	`, models.TicketStatusOpen, models.TicketStatusAnswered).Scan(&stats.Open, &stats.Answered)
+	if err != nil {
+		return nil, err
+	}
+
+	var firstResponse, resolution sql.NullFloat64
+	err = db.QueryRow(`
+		SELECT
+			COUNT(first_response_at),
+			AVG((julianday(first_response_at) - julianday(created_at)) * 86400),
+			COALESCE(SUM(closed_at IS NOT NULL), 0),
+			AVG((julianday(closed_at) - julianday(created_at)) * 86400)
+		FROM support_tickets
+		WHERE created_at >= ?
+	`, since.UTC().Format("2006-01-02 15:04:05")).Scan(&stats.Responded, &firstResponse, &stats.ClosedRecently, &resolution)
+	if err != nil {
+		return nil, err
+	}
+	stats.AvgFirstResponse = time.Duration(firstResponse.Float64 * float64(time.Second))
+	stats.AvgResolution = time.Duration(resolution.Float64 * float64(time.Second))
+	return stats, nil
+}
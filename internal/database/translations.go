@@ -0,0 +1,112 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+
+	"telegram-premium-store/internal/models"
+)
+
+// Languages & Translations
+
+// GetUserLanguage returns the language a user picked, or "" when they have not picked one
+func (db *DB) GetUserLanguage(userID int64) (string, error) {
+	var language sql.NullString
+	err := db.QueryRow(`SELECT language FROM users WHERE user_id = ?`, userID).Scan(&language)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return language.String, err
+}
+
+// SetUserLanguage stores the language a user picked. Returns false if the user does not exist.
+func (db *DB) SetUserLanguage(userID int64, language string) (bool, error) {
+	result, err := db.Exec(`UPDATE users SET language = ? WHERE user_id = ?`, language, userID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// SetProductTranslation creates or replaces a product's translation in one language
+func (db *DB) SetProductTranslation(t *models.ProductTranslation) error {
+	_, err := db.Exec(`
+		INSERT INTO product_translations (product_id, language, name, description)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(product_id, language) DO UPDATE SET
+			name = excluded.name,
+			description = excluded.description,
+			updated_at = CURRENT_TIMESTAMP
+	`, t.ProductID, t.Language, t.Name, t.Description)
+	return err
+}
+
+// DeleteProductTranslation removes a product's translation. Returns false if there was none.
+func (db *DB) DeleteProductTranslation(productID int, language string) (bool, error) {
+	result, err := db.Exec(`
+		DELETE FROM product_translations WHERE product_id = ? AND language = ?
+	`, productID, language)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// GetProductTranslations returns every translation of a product
+func (db *DB) GetProductTranslations(productID int) ([]models.ProductTranslation, error) {
+	rows, err := db.Query(`
+		SELECT product_id, language, name, description
+		FROM product_translations
+		WHERE product_id = ?
+		ORDER BY language
+	`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var translations []models.ProductTranslation
+	for rows.Next() {
+		var t models.ProductTranslation
+		if err := rows.Scan(&t.ProductID, &t.Language, &t.Name, &t.Description); err != nil {
+			return nil, err
+		}
+		translations = append(translations, t)
+	}
+	return translations, rows.Err()
+}
+
+// GetProductTranslationMap returns the translations of the given products in one language, by product ID
+func (db *DB) GetProductTranslationMap(language string, productIDs []int) (map[int]models.ProductTranslation, error) {
+	translations := make(map[int]models.ProductTranslation)
+	if len(productIDs) == 0 {
+		return translations, nil
+	}
+
+	args := []interface{}{language}
+	for _, id := range productIDs {
+		args = append(args, id)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(productIDs)), ",")
+
+	rows, err := db.Query(`
+		SELECT product_id, language, name, description
+		FROM product_translations
+		WHERE language = ? AND product_id IN (`+placeholders+`)
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.ProductTranslation
+		if err := rows.Scan(&t.ProductID, &t.Language, &t.Name, &t.Description); err != nil {
+			return nil, err
+		}
+		translations[t.ProductID] = t
+	}
+	return translations, rows.Err()
+}
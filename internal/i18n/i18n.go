@@ -0,0 +1,179 @@
+// Package i18n provides the bot's message catalog, loaded from one JSON file per language,
+// and locale-aware number and date formatting.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed locales/*.json
+var embeddedLocales embed.FS
+
+// Catalog holds the translated messages of every loaded language
+type Catalog struct {
+	defaultLang string
+	messages    map[string]map[string]string // language -> key -> message
+}
+
+// Load reads the bundled locale files, then the JSON files in dir (if set), whose keys
+// override or extend the bundled ones. Each file is named after its language, e.g. "en.json".
+func Load(dir, defaultLang string) (*Catalog, error) {
+	c := &Catalog{
+		defaultLang: defaultLang,
+		messages:    make(map[string]map[string]string),
+	}
+
+	if err := c.loadFS(embeddedLocales, "locales"); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := c.loadFS(os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := c.messages[defaultLang]; !ok {
+		return nil, fmt.Errorf("no locale file for default language %q", defaultLang)
+	}
+	return c, nil
+}
+
+// loadFS merges every *.json file in dir of fsys into the catalog
+func (c *Catalog) loadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("failed to read locale %s: %w", file, err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("failed to parse locale %s: %w", file, err)
+		}
+
+		lang := strings.TrimSuffix(path.Base(file), ".json")
+		if c.messages[lang] == nil {
+			c.messages[lang] = make(map[string]string)
+		}
+		for key, message := range messages {
+			c.messages[lang][key] = message
+		}
+	}
+	return nil
+}
+
+// Default returns the default language
+func (c *Catalog) Default() string {
+	return c.defaultLang
+}
+
+// Languages returns the loaded languages, the default one first
+func (c *Catalog) Languages() []string {
+	langs := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		if lang != c.defaultLang {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	return append([]string{c.defaultLang}, langs...)
+}
+
+// Match returns the loaded language for a language code like "en-US", or "" when there is none
+func (c *Catalog) Match(code string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	if _, ok := c.messages[lang]; ok {
+		return lang
+	}
+	return ""
+}
+
+// Has reports whether the language itself defines the key, without falling back
+func (c *Catalog) Has(lang, key string) bool {
+	_, ok := c.messages[lang][key]
+	return ok
+}
+
+// T returns the message for key in lang, formatted with args. Missing messages fall back to
+// the default language, then to the key itself so gaps are visible instead of blank.
+func (c *Catalog) T(lang, key string, args ...interface{}) string {
+	message, ok := c.messages[lang][key]
+	if !ok {
+		message, ok = c.messages[c.defaultLang][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// TN is T for a message about n things. When n is 1 and the language defines key + ".one",
+// that singular form is used instead, e.g. "product.rating.one" for "(1 review)".
+func (c *Catalog) TN(lang, key string, n int, args ...interface{}) string {
+	if n == 1 && c.Has(lang, key+".one") {
+		key += ".one"
+	}
+	return c.T(lang, key, args...)
+}
+
+// Name returns the display name of a language, e.g. "🇬🇧 English"
+func (c *Catalog) Name(lang string) string {
+	return c.T(lang, "language.name")
+}
+
+// FormatNumber formats n with the language's thousands separator
+func (c *Catalog) FormatNumber(lang string, n int) string {
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	digits := fmt.Sprintf("%d", n)
+	separator := c.T(lang, "format.thousands_separator")
+
+	var result strings.Builder
+	result.WriteString(sign)
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			result.WriteString(separator)
+		}
+		result.WriteRune(d)
+	}
+	return result.String()
+}
+
+// FormatDecimal formats f with one decimal in the language's decimal separator, e.g. "4,5" or "4.5"
+func (c *Catalog) FormatDecimal(lang string, f float64) string {
+	return strings.Replace(fmt.Sprintf("%.1f", f), ".", c.T(lang, "format.decimal_separator"), 1)
+}
+
+// FormatPrice formats an amount with the currency symbol, e.g. "Rp 25.000" or "Rp 25,000"
+func (c *Catalog) FormatPrice(lang string, amount int, symbol string) string {
+	return fmt.Sprintf("%s %s", symbol, c.FormatNumber(lang, amount))
+}
+
+// FormatDate formats a date in the language's date layout
+func (c *Catalog) FormatDate(lang string, t time.Time) string {
+	return t.Format(c.T(lang, "format.date"))
+}
+
+// FormatDateTime formats a date and time in the language's layout
+func (c *Catalog) FormatDateTime(lang string, t time.Time) string {
+	return t.Format(c.T(lang, "format.datetime"))
+}
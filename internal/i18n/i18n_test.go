@@ -0,0 +1,178 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// loadTestCatalog loads the bundled locales plus the given locale files, keyed by file name
+func loadTestCatalog(t *testing.T, defaultLang string, files map[string]string) *Catalog {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := Load(dir, defaultLang)
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	return c
+}
+
+func TestT(t *testing.T) {
+	c := loadTestCatalog(t, "id", map[string]string{
+		"id.json": `{"test.only_default": "Hanya %s", "test.both": "Halo %s"}`,
+		"en.json": `{"test.both": "Hello %s"}`,
+	})
+
+	tests := []struct {
+		name string
+		lang string
+		key  string
+		args []interface{}
+		want string
+	}{
+		{"own language", "en", "test.both", []interface{}{"Budi"}, "Hello Budi"},
+		{"default language", "id", "test.both", []interface{}{"Budi"}, "Halo Budi"},
+		{"falls back to the default language", "en", "test.only_default", []interface{}{"ini"}, "Hanya ini"},
+		{"unknown language falls back to the default", "fr", "test.both", []interface{}{"Budi"}, "Halo Budi"},
+		{"missing everywhere returns the key", "en", "test.missing", nil, "test.missing"},
+		{"no args leaves verbs alone", "en", "test.both", nil, "Hello %s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.T(tt.lang, tt.key, tt.args...); got != tt.want {
+				t.Errorf("T(%q, %q) = %q, want %q", tt.lang, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTN(t *testing.T) {
+	c := loadTestCatalog(t, "id", map[string]string{
+		"id.json": `{"test.items": "%d item"}`,
+		"en.json": `{"test.items": "%d items", "test.items.one": "%d item"}`,
+	})
+
+	tests := []struct {
+		name string
+		lang string
+		n    int
+		want string
+	}{
+		{"singular", "en", 1, "1 item"},
+		{"plural", "en", 2, "2 items"},
+		{"zero is plural", "en", 0, "0 items"},
+		{"language without a singular form", "id", 1, "1 item"},
+		{"singular of the default language is not borrowed", "id", 5, "5 item"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.TN(tt.lang, "test.items", tt.n, tt.n); got != tt.want {
+				t.Errorf("TN(%q, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("directory overrides and extends the bundled locales", func(t *testing.T) {
+		c := loadTestCatalog(t, "id", map[string]string{
+			"en.json": `{"cart.checkout": "💳 Pay now"}`,
+			"fr.json": `{"language.name": "🇫🇷 Français"}`,
+		})
+		if got := c.T("en", "cart.checkout"); got != "💳 Pay now" {
+			t.Errorf("overridden message = %q", got)
+		}
+		if got := c.T("en", "cart.clear"); got != "🗑️ Empty Cart" {
+			t.Errorf("bundled message next to an override = %q", got)
+		}
+		if got := c.Match("fr-FR"); got != "fr" {
+			t.Errorf("Match(fr-FR) = %q, want fr", got)
+		}
+	})
+
+	t.Run("missing default language", func(t *testing.T) {
+		if _, err := Load("", "xx"); err == nil {
+			t.Error("Load() without a locale for the default language succeeded")
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "en.json"), []byte(`{"a": `), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(dir, "id"); err == nil {
+			t.Error("Load() with a broken locale file succeeded")
+		}
+	})
+}
+
+func TestBundledLocalesHaveTheSameKeys(t *testing.T) {
+	c, err := Load("", "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lang := range c.Languages() {
+		for key := range c.messages[c.defaultLang] {
+			if !c.Has(lang, key) {
+				t.Errorf("%s.json is missing %q", lang, key)
+			}
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	c := loadTestCatalog(t, "id", nil)
+
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"en", "en"},
+		{"en-US", "en"},
+		{" EN-gb ", "en"},
+		{"id", "id"},
+		{"fr", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := c.Match(tt.code); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	c := loadTestCatalog(t, "id", nil)
+	date := time.Date(2026, 3, 7, 9, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"number id", c.FormatNumber("id", 1234567), "1.234.567"},
+		{"number en", c.FormatNumber("en", 1234567), "1,234,567"},
+		{"small number", c.FormatNumber("id", 999), "999"},
+		{"zero", c.FormatNumber("id", 0), "0"},
+		{"negative number", c.FormatNumber("id", -25000), "-25.000"},
+		{"decimal id", c.FormatDecimal("id", 4.55), "4,5"},
+		{"decimal en", c.FormatDecimal("en", 4.25), "4.2"},
+		{"price", c.FormatPrice("id", 25000, "Rp"), "Rp 25.000"},
+		{"date id", c.FormatDate("id", date), "07/03/2026"},
+		{"datetime id", c.FormatDateTime("id", date), "07/03/2026 09:05"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+// Package invoice renders proof-of-purchase receipts for paid orders as PDF documents
+package invoice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/i18n"
+)
+
+// Store is the seller shown in the invoice header
+type Store struct {
+	Name        string
+	Description string
+	Email       string
+	Phone       string
+	Telegram    string // Admin username, without @
+}
+
+// Item is a purchased product line
+type Item struct {
+	Name      string
+	Quantity  int
+	UnitPrice int
+}
+
+// Adjustment is a discount (negative amount) or fee (positive amount) applied to the item subtotal
+type Adjustment struct {
+	Label  string
+	Amount int
+}
+
+// Payment is part of the total paid with one method
+type Payment struct {
+	Method string
+	Amount int
+}
+
+// Invoice holds everything printed on a receipt
+type Invoice struct {
+	Language      string // Language the labels, prices and dates are printed in
+	Number        string
+	IssuedAt      time.Time
+	OrderID       string
+	PaidAt        time.Time
+	PaymentMethod string
+	Customer      string
+	CustomerID    int64
+	Store         Store
+	Items         []Item
+	Adjustments   []Adjustment
+	Payments      []Payment
+	Currency      string // Currency symbol, e.g. "Rp"
+}
+
+// Subtotal returns the sum of the item lines
+func (inv *Invoice) Subtotal() int {
+	subtotal := 0
+	for _, item := range inv.Items {
+		subtotal += item.UnitPrice * item.Quantity
+	}
+	return subtotal
+}
+
+// Total returns the subtotal after discounts and fees
+func (inv *Invoice) Total() int {
+	total := inv.Subtotal()
+	for _, adjustment := range inv.Adjustments {
+		total += adjustment.Amount
+	}
+	return total
+}
+
+// FileName returns the file name the invoice is sent as
+func (inv *Invoice) FileName() string {
+	return inv.Number + ".pdf"
+}
+
+// Page layout in points
+const (
+	marginLeft   = 50.0
+	marginRight  = pageWidth - 50.0
+	marginBottom = 70.0
+	rowHeight    = 18.0
+
+	columnQuantity = 350.0 // right edges of the item table columns
+	columnPrice    = 450.0
+	columnSubtotal = marginRight
+	totalsLabel    = 330.0
+)
+
+// RenderPDF renders the invoice on A4 pages, continuing on a new page when the items do not fit.
+// Labels come from the catalog in the invoice's language.
+func RenderPDF(inv *Invoice, catalog *i18n.Catalog) []byte {
+	r := &renderer{inv: inv, catalog: catalog}
+	r.addPage()
+	r.writeHeader()
+	r.writeParties()
+	r.writeItems()
+	r.writeTotals()
+	r.writeFooter()
+	return r.doc.bytes(r.t("invoice.title", inv.Number))
+}
+
+// renderer lays out an invoice top to bottom, keeping the current baseline in y
+type renderer struct {
+	doc     pdfDocument
+	inv     *Invoice
+	catalog *i18n.Catalog
+	y       float64
+}
+
+// t returns a catalog message in the invoice's language
+func (r *renderer) t(key string, args ...interface{}) string {
+	return r.catalog.T(r.inv.Language, key, args...)
+}
+
+func (r *renderer) addPage() {
+	r.doc.addPage()
+	r.y = pageHeight - 60
+	if len(r.doc.pages) > 1 {
+		r.doc.setGray(0.4)
+		r.doc.text(fontRegular, 9, marginLeft, r.y, r.t("invoice.continued", r.inv.Number, len(r.doc.pages)))
+		r.y -= 30
+	}
+}
+
+// ensureSpace moves to a new page when less than height is left above the bottom margin
+func (r *renderer) ensureSpace(height float64) bool {
+	if r.y-height >= marginBottom {
+		return false
+	}
+	r.addPage()
+	return true
+}
+
+func (r *renderer) price(amount int) string {
+	return r.catalog.FormatPrice(r.inv.Language, amount, r.inv.Currency)
+}
+
+func (r *renderer) dateTime(t time.Time) string {
+	return r.catalog.FormatDateTime(r.inv.Language, t)
+}
+
+func (r *renderer) writeHeader() {
+	inv := r.inv
+	top := r.y
+
+	r.doc.setGray(0)
+	r.doc.text(fontBold, 18, marginLeft, r.y, truncateText(fontBold, 18, inv.Store.Name, 280))
+	r.y -= 16
+	r.doc.setGray(0.35)
+	if inv.Store.Description != "" {
+		r.doc.text(fontRegular, 9, marginLeft, r.y, truncateText(fontRegular, 9, inv.Store.Description, 280))
+		r.y -= 12
+	}
+	var contacts []string
+	if inv.Store.Email != "" {
+		contacts = append(contacts, inv.Store.Email)
+	}
+	if inv.Store.Phone != "" {
+		contacts = append(contacts, inv.Store.Phone)
+	}
+	if inv.Store.Telegram != "" {
+		contacts = append(contacts, "Telegram @"+inv.Store.Telegram)
+	}
+	if len(contacts) > 0 {
+		r.doc.text(fontRegular, 9, marginLeft, r.y, truncateText(fontRegular, 9, strings.Join(contacts, " | "), 300))
+		r.y -= 12
+	}
+
+	r.doc.setGray(0)
+	r.doc.textRight(fontBold, 20, marginRight, top, r.t("invoice.heading"))
+	r.doc.textRight(fontRegular, 10, marginRight, top-18, r.t("invoice.number", inv.Number))
+	r.doc.textRight(fontRegular, 10, marginRight, top-31, r.t("invoice.date", r.dateTime(inv.IssuedAt)))
+
+	r.y = min(r.y, top-31) - 16
+	r.doc.setGray(0.75)
+	r.doc.line(marginLeft, r.y, marginRight, r.y, 1)
+	r.y -= 24
+}
+
+func (r *renderer) writeParties() {
+	inv := r.inv
+	top := r.y
+
+	r.doc.setGray(0.35)
+	r.doc.text(fontBold, 9, marginLeft, r.y, r.t("invoice.bill_to"))
+	r.doc.setGray(0)
+	r.doc.text(fontBold, 11, marginLeft, r.y-15, truncateText(fontBold, 11, inv.Customer, 240))
+	r.doc.text(fontRegular, 10, marginLeft, r.y-29, r.t("invoice.telegram_id", inv.CustomerID))
+
+	details := [][2]string{
+		{r.t("invoice.order_id"), inv.OrderID},
+		{r.t("invoice.paid_at"), r.dateTime(inv.PaidAt)},
+		{r.t("invoice.method"), inv.PaymentMethod},
+		{r.t("invoice.status"), r.t("invoice.status_paid")},
+	}
+	y := top
+	for _, detail := range details {
+		r.doc.setGray(0.35)
+		r.doc.text(fontRegular, 9, 310, y, detail[0])
+		r.doc.setGray(0)
+		r.doc.textRight(fontRegular, 9, marginRight, y, detail[1])
+		y -= 14
+	}
+
+	r.y = min(top-29, y) - 26
+}
+
+func (r *renderer) writeItemsHeader() {
+	r.doc.setGray(0.92)
+	r.doc.fillRect(marginLeft, r.y-6, marginRight-marginLeft, rowHeight+2)
+	r.doc.setGray(0)
+	r.doc.text(fontBold, 9, marginLeft+6, r.y, r.t("invoice.column_product"))
+	r.doc.textRight(fontBold, 9, columnQuantity, r.y, r.t("invoice.column_quantity"))
+	r.doc.textRight(fontBold, 9, columnPrice, r.y, r.t("invoice.column_price"))
+	r.doc.textRight(fontBold, 9, columnSubtotal-6, r.y, r.t("invoice.column_subtotal"))
+	r.y -= rowHeight + 4
+}
+
+func (r *renderer) writeItems() {
+	r.writeItemsHeader()
+	for _, item := range r.inv.Items {
+		if r.ensureSpace(rowHeight) {
+			r.writeItemsHeader()
+		}
+		r.doc.setGray(0)
+		r.doc.text(fontRegular, 10, marginLeft+6, r.y, truncateText(fontRegular, 10, item.Name, columnQuantity-marginLeft-50))
+		r.doc.textRight(fontRegular, 10, columnQuantity, r.y, fmt.Sprintf("%d", item.Quantity))
+		r.doc.textRight(fontRegular, 10, columnPrice, r.y, r.price(item.UnitPrice))
+		r.doc.textRight(fontRegular, 10, columnSubtotal-6, r.y, r.price(item.UnitPrice*item.Quantity))
+		r.doc.setGray(0.85)
+		r.doc.line(marginLeft, r.y-6, marginRight, r.y-6, 0.5)
+		r.y -= rowHeight
+	}
+	r.y -= 10
+}
+
+func (r *renderer) writeTotals() {
+	inv := r.inv
+	lines := 2 + len(inv.Adjustments) + len(inv.Payments)
+	r.ensureSpace(float64(lines)*16 + 30)
+
+	r.totalLine(fontRegular, r.t("invoice.subtotal"), r.price(inv.Subtotal()))
+	for _, adjustment := range inv.Adjustments {
+		amount := r.price(adjustment.Amount)
+		if adjustment.Amount < 0 {
+			amount = "-" + r.price(-adjustment.Amount)
+		}
+		r.totalLine(fontRegular, adjustment.Label, amount)
+	}
+
+	r.doc.setGray(0.75)
+	r.doc.line(totalsLabel, r.y+10, marginRight, r.y+10, 0.75)
+	r.totalLine(fontBold, r.t("invoice.total"), r.price(inv.Total()))
+	r.y -= 6
+
+	for _, payment := range inv.Payments {
+		r.totalLine(fontRegular, r.t("invoice.paid_via", payment.Method), r.price(payment.Amount))
+	}
+}
+
+func (r *renderer) totalLine(font, label, amount string) {
+	r.doc.setGray(0)
+	r.doc.text(font, 10, totalsLabel, r.y, truncateText(font, 10, label, columnPrice-totalsLabel+20))
+	r.doc.textRight(font, 10, columnSubtotal-6, r.y, amount)
+	r.y -= 16
+}
+
+func (r *renderer) writeFooter() {
+	r.ensureSpace(50)
+	r.y -= 24
+	r.doc.setGray(0.75)
+	r.doc.line(marginLeft, r.y+14, marginRight, r.y+14, 0.5)
+	r.doc.setGray(0.35)
+	r.doc.text(fontRegular, 9, marginLeft, r.y, r.t("invoice.thanks", r.inv.Store.Name))
+	r.doc.text(fontRegular, 9, marginLeft, r.y-12, r.t("invoice.generated"))
+}
@@ -0,0 +1,176 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-premium-store/internal/i18n"
+)
+
+func TestTotals(t *testing.T) {
+	items := []Item{
+		{Name: "Netflix 1 Bulan", Quantity: 2, UnitPrice: 25000},
+		{Name: "Spotify 1 Bulan", Quantity: 1, UnitPrice: 15000},
+	}
+
+	tests := []struct {
+		name         string
+		items        []Item
+		adjustments  []Adjustment
+		payments     []Payment
+		wantSubtotal int
+		wantTotal    int
+	}{
+		{"no items", nil, nil, nil, 0, 0},
+		{"items only", items, nil, nil, 65000, 65000},
+		{
+			name:         "coupon discount",
+			items:        items,
+			adjustments:  []Adjustment{{Label: "Diskon kupon", Amount: -6500}},
+			wantSubtotal: 65000,
+			wantTotal:    58500,
+		},
+		{
+			name:  "coupon and points discounts with a fee",
+			items: items,
+			adjustments: []Adjustment{
+				{Label: "Diskon kupon", Amount: -6500},
+				{Label: "Tukar 50 poin", Amount: -5000},
+				{Label: "Biaya transaksi", Amount: 350},
+			},
+			wantSubtotal: 65000,
+			wantTotal:    53850,
+		},
+		{
+			name:         "balance is a payment, not a discount",
+			items:        items,
+			adjustments:  []Adjustment{{Label: "Tukar 50 poin", Amount: -5000}},
+			payments:     []Payment{{Method: "Saldo", Amount: 20000}, {Method: "QRIS", Amount: 40000}},
+			wantSubtotal: 65000,
+			wantTotal:    60000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := &Invoice{Items: tt.items, Adjustments: tt.adjustments, Payments: tt.payments}
+			if got := inv.Subtotal(); got != tt.wantSubtotal {
+				t.Errorf("Subtotal() = %d, want %d", got, tt.wantSubtotal)
+			}
+			if got := inv.Total(); got != tt.wantTotal {
+				t.Errorf("Total() = %d, want %d", got, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestEscapePDFString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Netflix Premium", "Netflix Premium"},
+		{"Paket (1 Bulan)", `Paket \(1 Bulan\)`},
+		{`C:\akun`, `C:\\akun`},
+		{`\(x)`, `\\\(x\)`},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := escapePDFString(tt.input); got != tt.want {
+			t.Errorf("escapePDFString(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// testInvoice returns an invoice with the given number of item lines
+func testInvoice(lang string, items int) *Invoice {
+	paid := time.Date(2026, 3, 7, 9, 5, 0, 0, time.UTC)
+	inv := &Invoice{
+		Language:      lang,
+		Number:        "INV-202603-0001",
+		IssuedAt:      paid,
+		OrderID:       "3f2a9c1e-0000-4000-8000-000000000000",
+		PaidAt:        paid,
+		PaymentMethod: "QRIS",
+		Customer:      "Budi (@budi)",
+		CustomerID:    123456789,
+		Currency:      "Rp",
+		Store:         Store{Name: "Premium Store (Official)", Telegram: "admin"},
+		Adjustments:   []Adjustment{{Label: "Diskon kupon", Amount: -5000}},
+	}
+	for i := 0; i < items; i++ {
+		inv.Items = append(inv.Items, Item{Name: fmt.Sprintf("Produk %d", i+1), Quantity: 1, UnitPrice: 10000})
+	}
+	inv.Payments = []Payment{{Method: "QRIS", Amount: inv.Total()}}
+	return inv
+}
+
+func TestRenderPDF(t *testing.T) {
+	catalog, err := i18n.Load("", "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	startxrefPattern := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`)
+
+	tests := []struct {
+		name      string
+		lang      string
+		items     int
+		wantPages int
+		wantText  []string
+	}{
+		{"single page", "id", 3, 1, []string{"Dibayar via QRIS", "Rp 25.000", `Premium Store \(Official\)`}},
+		{"english", "en", 3, 1, []string{"Paid via QRIS", "Rp 25,000", "BILLED TO"}},
+		{"continues on a new page", "id", 40, 2, []string{`lanjutan \(halaman 2\)`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf := RenderPDF(testInvoice(tt.lang, tt.items), catalog)
+
+			if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+				t.Fatalf("document starts with %q", pdf[:min(len(pdf), 8)])
+			}
+			for _, text := range tt.wantText {
+				if !bytes.Contains(pdf, []byte(text)) {
+					t.Errorf("document does not contain %q", text)
+				}
+			}
+			if got := bytes.Count(pdf, []byte("/Type /Page ")); got != tt.wantPages {
+				t.Errorf("document has %d pages, want %d", got, tt.wantPages)
+			}
+
+			match := startxrefPattern.FindSubmatch(pdf)
+			if match == nil {
+				t.Fatal("document does not end with the startxref offset and EOF marker")
+			}
+			xref, _ := strconv.Atoi(string(match[1]))
+			if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+				t.Fatalf("startxref %d does not point at the xref table", xref)
+			}
+
+			lines := strings.Split(string(pdf[xref:]), "\n")
+			var count int
+			if _, err := fmt.Sscanf(lines[1], "0 %d", &count); err != nil {
+				t.Fatalf("xref subsection header %q: %v", lines[1], err)
+			}
+			// Object 0 is the free list head; every other entry must point at its "n 0 obj" marker
+			for n := 1; n < count; n++ {
+				entry := lines[2+n]
+				offset, err := strconv.Atoi(entry[:10])
+				if err != nil || !strings.HasSuffix(entry, " n ") {
+					t.Fatalf("xref entry %d is %q", n, entry)
+				}
+				if marker := fmt.Sprintf("%d 0 obj\n", n); !bytes.HasPrefix(pdf[offset:], []byte(marker)) {
+					t.Errorf("xref entry %d points at %q, want %q", n, pdf[offset:offset+len(marker)], marker)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size in points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+)
+
+// Fonts available on every page. Both are PDF standard fonts, so readers
+// provide them and nothing has to be embedded in the document.
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+// pdfDocument is a minimal PDF writer for text, lines and filled boxes
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+}
+
+// addPage starts a new page; later drawing goes to it
+func (d *pdfDocument) addPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+}
+
+// setGray sets the fill (text) and stroke color, 0 is black and 1 is white
+func (d *pdfDocument) setGray(level float64) {
+	fmt.Fprintf(d.page, "%.2f g %.2f G\n", level, level)
+}
+
+// text draws s with its baseline starting at x, y
+func (d *pdfDocument) text(font string, size, x, y float64, s string) {
+	fmt.Fprintf(d.page, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escapePDFString(encodeWinAnsi(s)))
+}
+
+// textRight draws s so that it ends at x
+func (d *pdfDocument) textRight(font string, size, x, y float64, s string) {
+	d.text(font, size, x-textWidth(font, size, s), y, s)
+}
+
+// line draws a straight line
+func (d *pdfDocument) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+// fillRect draws a filled rectangle with its lower left corner at x, y
+func (d *pdfDocument) fillRect(x, y, w, h float64) {
+	fmt.Fprintf(d.page, "%.2f %.2f %.2f %.2f re f\n", x, y, w, h)
+}
+
+// bytes assembles the document: catalog, page tree, fonts, info, then a page and content stream per page
+func (d *pdfDocument) bytes(title string) []byte {
+	var out bytes.Buffer
+	var offsets []int
+
+	startObject := func() int {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n", len(offsets))
+		return len(offsets)
+	}
+	endObject := func() {
+		out.WriteString("endobj\n")
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	const firstPageObject = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObject+i*2)
+	}
+
+	startObject()
+	out.WriteString("<< /Type /Catalog /Pages 2 0 R >>\n")
+	endObject()
+
+	startObject()
+	fmt.Fprintf(&out, "<< /Type /Pages /Kids [%s] /Count %d >>\n", strings.Join(kids, " "), len(d.pages))
+	endObject()
+
+	startObject()
+	out.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>\n")
+	endObject()
+
+	startObject()
+	out.WriteString("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>\n")
+	endObject()
+
+	startObject()
+	fmt.Fprintf(&out, "<< /Title (%s) /Producer (telegram-premium-store) >>\n", escapePDFString(encodeWinAnsi(title)))
+	endObject()
+
+	for _, page := range d.pages {
+		pageObject := startObject()
+		fmt.Fprintf(&out, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>\n",
+			pageWidth, pageHeight, fontRegular, fontBold, pageObject+1)
+		endObject()
+
+		startObject()
+		fmt.Fprintf(&out, "<< /Length %d >>\nstream\n", page.Len())
+		out.Write(page.Bytes())
+		out.WriteString("endstream\n")
+		endObject()
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// winAnsiReplacements maps common typographic characters outside Latin-1 to their WinAnsi bytes
+var winAnsiReplacements = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// encodeWinAnsi converts s to the encoding of the standard fonts. Characters
+// the fonts cannot draw, such as emoji, are left out.
+func encodeWinAnsi(s string) string {
+	var out strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			out.WriteByte(byte(r))
+		case r == '\t' || r == '\n':
+			out.WriteByte(' ')
+		default:
+			if b, ok := winAnsiReplacements[r]; ok {
+				out.WriteByte(b)
+			}
+		}
+	}
+	return strings.Join(strings.Fields(out.String()), " ")
+}
+
+// escapePDFString escapes the characters that delimit a PDF literal string
+func escapePDFString(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
+
+// textWidth returns the width of s in points when drawn in font at size
+func textWidth(font string, size float64, s string) float64 {
+	widths := &helveticaWidths
+	if font == fontBold {
+		widths = &helveticaBoldWidths
+	}
+
+	total := 0
+	for _, c := range []byte(encodeWinAnsi(s)) {
+		if c >= 0x20 && c < 0x7f {
+			total += widths[c-0x20]
+		} else {
+			total += 556 // close enough for accented letters
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// truncateText shortens s with an ellipsis so that it fits in maxWidth
+func truncateText(font string, size float64, s string, maxWidth float64) string {
+	if textWidth(font, size, s) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimSpace(string(runes)) + "..."
+		if textWidth(font, size, candidate) <= maxWidth {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Glyph widths of ASCII 0x20-0x7e in thousandths of the font size, from the Adobe font metrics
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,43 @@ type User struct {
 	JoinDate  time.Time `json:"join_date" db:"join_date"`
 	IsAdmin   bool      `json:"is_admin" db:"is_admin"`
 	IsActive  bool      `json:"is_active" db:"is_active"`
+
+	CustomerGroup CustomerGroup `json:"customer_group" db:"customer_group"`
+	Language      string        `json:"language,omitempty" db:"language"` // Chosen UI language, empty for the default
+}
+
+// CustomerGroup decides which price tiers a user gets
+type CustomerGroup string
+
+const (
+	CustomerRetail   CustomerGroup = "retail"
+	CustomerReseller CustomerGroup = "reseller"
+	CustomerVIP      CustomerGroup = "vip"
+)
+
+// CustomerGroups lists all customer groups, retail first
+var CustomerGroups = []CustomerGroup{CustomerRetail, CustomerReseller, CustomerVIP}
+
+// ParseCustomerGroup returns the customer group with the given name
+func ParseCustomerGroup(name string) (CustomerGroup, bool) {
+	for _, group := range CustomerGroups {
+		if strings.EqualFold(name, string(group)) {
+			return group, true
+		}
+	}
+	return "", false
+}
+
+// Label returns the display name of a customer group
+func (g CustomerGroup) Label() string {
+	switch g {
+	case CustomerReseller:
+		return "Reseller"
+	case CustomerVIP:
+		return "VIP"
+	default:
+		return "Retail"
+	}
 }
 
 // Product represents a premium application for sale
@@ -31,20 +69,47 @@ type Product struct {
 	Stock       int       `json:"stock" db:"stock"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	// Pre-order settings used when the product is out of stock
+	PreorderEnabled bool       `json:"preorder_enabled" db:"preorder_enabled"`
+	PreorderLimit   int        `json:"preorder_limit" db:"preorder_limit"` // Max units reserved by open pre-orders
+	PreorderETA     *time.Time `json:"preorder_eta,omitempty" db:"preorder_eta"`
+
+	// Bundles are sold as one product and deliver stock from each component product
+	IsBundle bool `json:"is_bundle" db:"is_bundle"`
+
+	// Gift cards issue a fresh code worth GiftCardValue per unit sold instead of using stock
+	GiftCardValue int `json:"gift_card_value" db:"gift_card_value"`
+	GiftCardDays  int `json:"gift_card_days" db:"gift_card_days"` // Validity after purchase, 0 = never expires
+
+	// Sent to the buyer together with the delivered items
+	DeliveryInstructions *string `json:"delivery_instructions,omitempty" db:"delivery_instructions"`
+
+	// Set by ApplyFlashSale and ApplyPriceTier when a sale or the buyer's group lowers the price
+	OriginalPrice int        `json:"original_price,omitempty"`
+	FlashSale     *FlashSale `json:"-"`
+	PriceTier     *PriceTier `json:"-"`
 }
 
 // CartItem represents an item in user's shopping cart
 type CartItem struct {
-	ID        int       `json:"id" db:"id"`
-	UserID    int64     `json:"user_id" db:"user_id"`
-	ProductID int       `json:"product_id" db:"product_id"`
-	Quantity  int       `json:"quantity" db:"quantity"`
-	AddedAt   time.Time `json:"added_at" db:"added_at"`
+	ID         int       `json:"id" db:"id"`
+	UserID     int64     `json:"user_id" db:"user_id"`
+	ProductID  int       `json:"product_id" db:"product_id"`
+	Quantity   int       `json:"quantity" db:"quantity"`
+	AddedAt    time.Time `json:"added_at" db:"added_at"`
+	PriceAtAdd *int      `json:"price_at_add,omitempty" db:"price_at_add"` // Product price when the item was added
 	
 	// Joined fields from Product
-	ProductName  string  `json:"product_name,omitempty" db:"product_name"`
-	ProductPrice int     `json:"product_price,omitempty" db:"product_price"`
-	ProductImage *string `json:"product_image,omitempty" db:"product_image"`
+	ProductName     string  `json:"product_name,omitempty" db:"product_name"`
+	ProductPrice    int     `json:"product_price,omitempty" db:"product_price"`
+	ProductImage    *string `json:"product_image,omitempty" db:"product_image"`
+	ProductCategory string  `json:"product_category,omitempty" db:"product_category"`
+
+	// Set by ApplyFlashSale and ApplyPriceTier when a sale or the buyer's group lowers ProductPrice
+	OriginalPrice int        `json:"original_price,omitempty"`
+	FlashSale     *FlashSale `json:"-"`
+	PriceTier     *PriceTier `json:"-"`
 }
 
 // Order represents a purchase order
@@ -59,18 +124,38 @@ type Order struct {
 	CreatedAt     time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time    `json:"updated_at" db:"updated_at"`
 	CompletedAt   *time.Time   `json:"completed_at" db:"completed_at"`
+	IsPreorder    bool         `json:"is_preorder" db:"is_preorder"`
+
+	// Coupon applied at checkout; TotalAmount is already net of DiscountAmount
+	DiscountAmount int     `json:"discount_amount" db:"discount_amount"`
+	CouponID       *int    `json:"coupon_id,omitempty" db:"coupon_id"`
+	CouponCode     *string `json:"coupon_code,omitempty" db:"coupon_code"`
+	BalanceUsed    int     `json:"balance_used" db:"balance_used"`       // Store balance spent; TotalAmount is what is left to pay
+	PointsUsed     int     `json:"points_used" db:"points_used"`         // Loyalty points redeemed on the order
+	PointsDiscount int     `json:"points_discount" db:"points_discount"` // Discount bought with PointsUsed; TotalAmount is already net of it
 	
 	// Joined fields
 	Items []OrderItem `json:"items,omitempty"`
+
+	// Limits checked again when the order is stored; not persisted
+	Limits OrderLimits `json:"-"`
+}
+
+// OrderLimits are the per-user order limits a new order must stay within. Zero disables a limit.
+type OrderLimits struct {
+	MaxPendingOrders int // Unpaid orders a user may have open
+	MaxQtyPerProduct int // Units of one product per PeriodHours
+	PeriodHours      int
 }
 
 // OrderItem represents individual items in an order
 type OrderItem struct {
-	ID        int    `json:"id" db:"id"`
-	OrderID   string `json:"order_id" db:"order_id"`
-	ProductID int    `json:"product_id" db:"product_id"`
-	Quantity  int    `json:"quantity" db:"quantity"`
-	Price     int    `json:"price" db:"price"` // Price at time of purchase
+	ID          int    `json:"id" db:"id"`
+	OrderID     string `json:"order_id" db:"order_id"`
+	ProductID   int    `json:"product_id" db:"product_id"`
+	Quantity    int    `json:"quantity" db:"quantity"`
+	Price       int    `json:"price" db:"price"`                           // Price at time of purchase
+	FlashSaleID *int   `json:"flash_sale_id,omitempty" db:"flash_sale_id"` // Flash sale that set the price, if any
 	
 	// Joined fields from Product
 	ProductName        string  `json:"product_name,omitempty" db:"product_name"`
@@ -87,6 +172,8 @@ const (
 	PaymentStatusExpired   PaymentStatus = "expired"
 	PaymentStatusCancelled PaymentStatus = "cancelled"
 	PaymentStatusRefunded  PaymentStatus = "refunded"
+	// PaymentStatusAwaitingStock marks a paid pre-order waiting for stock to be delivered
+	PaymentStatusAwaitingStock PaymentStatus = "awaiting_stock"
 )
 
 // Value implements the driver.Valuer interface for PaymentStatus
@@ -142,6 +229,150 @@ func GetDefaultCategories() []ProductCategory {
 	}
 }
 
+// ProductPhoto is a photo of a product uploaded by an admin from Telegram
+type ProductPhoto struct {
+	ID        int       `json:"id" db:"id"`
+	ProductID int       `json:"product_id" db:"product_id"`
+	FileID    string    `json:"file_id" db:"file_id"`       // Telegram file ID used to resend the photo
+	LocalPath *string   `json:"local_path" db:"local_path"` // Local copy, used when the file ID no longer works
+	Position  int       `json:"position" db:"position"`     // Lowest position is the primary photo
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Invoice is the numbered receipt of a paid order
+type Invoice struct {
+	ID        int       `json:"id" db:"id"`
+	OrderID   string    `json:"order_id" db:"order_id"`
+	Number    string    `json:"number" db:"number"` // e.g. INV-2026-000042, sequential across all orders
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ProductTranslation is a product's name and description in another language
+type ProductTranslation struct {
+	ProductID   int    `json:"product_id" db:"product_id"`
+	Language    string `json:"language" db:"language"`
+	Name        string `json:"name" db:"name"`
+	Description string `json:"description" db:"description"`
+}
+
+// Review is a buyer's rating of a product from one of their paid orders
+type Review struct {
+	ID        int       `json:"id" db:"id"`
+	OrderID   string    `json:"order_id" db:"order_id"`
+	ProductID int       `json:"product_id" db:"product_id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	Rating    int       `json:"rating" db:"rating"` // 1 to 5 stars
+	Comment   *string   `json:"comment" db:"comment"`
+	IsHidden  bool      `json:"is_hidden" db:"is_hidden"` // Hidden by an admin, not shown or counted
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Joined fields
+	ProductName   string  `json:"product_name,omitempty"`
+	UserFirstName *string `json:"user_first_name,omitempty"`
+}
+
+// Stars returns the rating as a row of star emoji, e.g. "⭐⭐⭐⭐"
+func (r *Review) Stars() string {
+	return strings.Repeat("⭐", r.Rating)
+}
+
+// ReviewerName returns the reviewer's first name, or "Pembeli" when unknown
+func (r *Review) ReviewerName() string {
+	if r.UserFirstName != nil && *r.UserFirstName != "" {
+		return *r.UserFirstName
+	}
+	return "Pembeli"
+}
+
+// ProductRating is the average of a product's visible reviews
+type ProductRating struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+// ReviewPrompt is a paid order whose buyer is asked to review the products
+type ReviewPrompt struct {
+	OrderID  string    `json:"order_id"`
+	UserID   int64     `json:"user_id"`
+	Products []Product `json:"products"` // Products of the order not reviewed yet
+}
+
+// TicketStatus is where a support ticket is in its conversation
+type TicketStatus string
+
+const (
+	TicketStatusOpen     TicketStatus = "open"     // Waiting for an admin
+	TicketStatusAnswered TicketStatus = "answered" // Waiting for the buyer
+	TicketStatusClosed   TicketStatus = "closed"
+)
+
+// SupportTicket is a buyer's help request relayed to the admins
+type SupportTicket struct {
+	ID                    int          `json:"id" db:"id"`
+	UserID                int64        `json:"user_id" db:"user_id"`
+	OrderID               *string      `json:"order_id" db:"order_id"` // Order the ticket is about, if any
+	Status                TicketStatus `json:"status" db:"status"`
+	AssignedTo            *int64       `json:"assigned_to" db:"assigned_to"` // Admin handling the ticket
+	CreatedAt             time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time    `json:"updated_at" db:"updated_at"`
+	LastCustomerMessageAt time.Time    `json:"last_customer_message_at" db:"last_customer_message_at"`
+	FirstResponseAt       *time.Time   `json:"first_response_at" db:"first_response_at"`
+	ClosedAt              *time.Time   `json:"closed_at" db:"closed_at"`
+
+	// Joined fields
+	Username      *string `json:"username,omitempty"`
+	UserFirstName *string `json:"user_first_name,omitempty"`
+	MessageCount  int     `json:"message_count"`
+}
+
+// CustomerName returns the buyer's first name and username for admin views
+func (t *SupportTicket) CustomerName() string {
+	name := "Pembeli"
+	if t.UserFirstName != nil && *t.UserFirstName != "" {
+		name = *t.UserFirstName
+	}
+	if t.Username != nil && *t.Username != "" {
+		name += " (@" + *t.Username + ")"
+	}
+	return name
+}
+
+// TicketMessage is one message in a support ticket
+type TicketMessage struct {
+	ID        int       `json:"id" db:"id"`
+	TicketID  int       `json:"ticket_id" db:"ticket_id"`
+	SenderID  int64     `json:"sender_id" db:"sender_id"`
+	FromAdmin bool      `json:"from_admin" db:"from_admin"`
+	Text      string    `json:"text" db:"text"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TicketStats summarizes the support queue and response times
+type TicketStats struct {
+	Open             int           `json:"open"`
+	Answered         int           `json:"answered"`
+	ClosedRecently   int           `json:"closed_recently"`    // Opened in the period and closed since
+	AvgFirstResponse time.Duration `json:"avg_first_response"` // From opening to the first admin answer, in the period
+	AvgResolution    time.Duration `json:"avg_resolution"`     // From opening to closing, in the period
+	Responded        int           `json:"responded"`          // Tickets counted in AvgFirstResponse
+}
+
+// SearchResult is one page of a product search
+type SearchResult struct {
+	Products  []Product `json:"products"`
+	Query     string    `json:"query"`     // Normalized search terms, separated by spaces
+	Corrected string    `json:"corrected"` // Terms after typo correction, empty when none was needed
+}
+
+// SearchMiss is a search query that found no products, with how often it was searched
+type SearchMiss struct {
+	Query        string    `json:"query" db:"query"`
+	Count        int       `json:"count" db:"count"`
+	Users        int       `json:"users" db:"users"`
+	LastSearched time.Time `json:"last_searched" db:"last_searched"`
+}
+
 // CartSummary represents a summary of cart contents
 type CartSummary struct {
 	TotalItems int `json:"total_items"`
@@ -180,6 +411,17 @@ func FormatPrice(price int, symbol string) string {
 	return fmt.Sprintf("%s %s", symbol, formatNumber(price))
 }
 
+// Strikethrough renders text struck through with combining characters,
+// since legacy Markdown messages have no strike-through entity
+func Strikethrough(text string) string {
+	var result strings.Builder
+	for _, r := range text {
+		result.WriteRune(r)
+		result.WriteRune('\u0336')
+	}
+	return result.String()
+}
+
 // formatNumber formats number with thousand separators
 func formatNumber(n int) string {
 	str := fmt.Sprintf("%d", n)
@@ -216,6 +458,10 @@ type ProductAccount struct {
 	// Legacy fields for backward compatibility (deprecated, use ContentData instead)
 	Email        *string    `json:"email,omitempty" db:"email"`
 	Password     *string    `json:"password,omitempty" db:"password"`
+	CostPrice    int        `json:"cost_price" db:"cost_price"` // Purchase price paid to the supplier
+	Supplier     *string    `json:"supplier,omitempty" db:"supplier"`
+	BatchID      *int       `json:"batch_id,omitempty" db:"batch_id"`
+	IsRevoked    bool       `json:"is_revoked" db:"is_revoked"`
 	IsSold       bool       `json:"is_sold" db:"is_sold"`
 	SoldToUserID *int64     `json:"sold_to_user_id" db:"sold_to_user_id"`
 	SoldOrderID  *string    `json:"sold_order_id" db:"sold_order_id"`
@@ -233,10 +479,14 @@ type SoldAccount struct {
 	ContentType ProductContentType `json:"content_type" db:"content_type"`
 	ContentData string             `json:"content_data" db:"content_data"`
 	// Legacy fields for backward compatibility
-	Email       *string    `json:"email,omitempty" db:"email"`
-	Password    *string    `json:"password,omitempty" db:"password"`
-	SoldPrice   int        `json:"sold_price" db:"sold_price"`
-	SoldAt      time.Time  `json:"sold_at" db:"sold_at"`
+	Email       *string           `json:"email,omitempty" db:"email"`
+	Password    *string           `json:"password,omitempty" db:"password"`
+	SoldPrice   int               `json:"sold_price" db:"sold_price"`
+	CostPrice   int               `json:"cost_price" db:"cost_price"` // Snapshot of stock cost at time of sale
+	Supplier    *string           `json:"supplier,omitempty" db:"supplier"`
+	BatchID     *int              `json:"batch_id,omitempty" db:"batch_id"`
+	Status      SoldAccountStatus `json:"status" db:"status"`
+	SoldAt      time.Time         `json:"sold_at" db:"sold_at"`
 	
 	// Joined fields
 	ProductName    string  `json:"product_name,omitempty" db:"product_name"`
@@ -245,6 +495,688 @@ type SoldAccount struct {
 	BuyerUsername  *string `json:"buyer_username,omitempty" db:"username"`
 }
 
+// PurchasedOrder summarizes the items a user received in one order, for /produkku
+type PurchasedOrder struct {
+	OrderID      string    `json:"order_id"`
+	PurchasedAt  time.Time `json:"purchased_at"`
+	ItemCount    int       `json:"item_count"`
+	ProductNames []string  `json:"product_names"`
+}
+
+// ScheduledDeletion is a bot message to delete from a chat once it is due
+type ScheduledDeletion struct {
+	ID        int   `json:"id" db:"id"`
+	ChatID    int64 `json:"chat_id" db:"chat_id"`
+	MessageID int   `json:"message_id" db:"message_id"`
+}
+
+// ModerationActionType is what happened to a user's account
+type ModerationActionType string
+
+const (
+	ModerationBan     ModerationActionType = "ban"     // Blocked by an admin
+	ModerationUnban   ModerationActionType = "unban"   // Unblocked by an admin
+	ModerationExpired ModerationActionType = "expired" // Temporary ban ran out
+)
+
+// UserBan is a user who is currently banned
+type UserBan struct {
+	UserID    int64      `json:"user_id" db:"user_id"`
+	Reason    string     `json:"reason" db:"ban_reason"`
+	Until     *time.Time `json:"until,omitempty" db:"banned_until"` // Nil for a permanent ban
+	BannedBy  int64      `json:"banned_by" db:"banned_by"`
+	BannedAt  time.Time  `json:"banned_at" db:"banned_at"`
+	Username  *string    `json:"username,omitempty" db:"username"`
+	FirstName *string    `json:"first_name,omitempty" db:"first_name"`
+}
+
+// ModerationAction is an entry in a user's moderation history
+type ModerationAction struct {
+	ID        int                  `json:"id" db:"id"`
+	UserID    int64                `json:"user_id" db:"user_id"`
+	AdminID   *int64               `json:"admin_id,omitempty" db:"admin_id"` // Nil for automatic actions
+	Action    ModerationActionType `json:"action" db:"action"`
+	Reason    *string              `json:"reason,omitempty" db:"reason"`
+	Until     *time.Time           `json:"until,omitempty" db:"until"` // End of a temporary ban
+	CreatedAt time.Time            `json:"created_at" db:"created_at"`
+}
+
+// MessageTemplate is an admin's replacement for the default text of a message template in one language
+type MessageTemplate struct {
+	Name      string    `json:"name" db:"name"`
+	Language  string    `json:"lang" db:"lang"`
+	Body      string    `json:"body" db:"body"`
+	UpdatedBy int64     `json:"updated_by" db:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SoldAccountStatus represents the after-sale state of a delivered item
+type SoldAccountStatus string
+
+const (
+	SoldAccountActive   SoldAccountStatus = "active"
+	SoldAccountReplaced SoldAccountStatus = "replaced" // Swapped for another item after a batch revoke
+	SoldAccountRefunded SoldAccountStatus = "refunded" // Buyer refunded after a batch revoke
+)
+
+// Scan implements the sql.Scanner interface for SoldAccountStatus
+func (s *SoldAccountStatus) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*s = SoldAccountActive
+	case string:
+		*s = SoldAccountStatus(v)
+	case []byte:
+		*s = SoldAccountStatus(v)
+	}
+	return nil
+}
+
+// StockBatch represents a named group of stock items imported together from a supplier
+type StockBatch struct {
+	ID           int        `json:"id" db:"id"`
+	Name         string     `json:"name" db:"name"`
+	ProductID    int        `json:"product_id" db:"product_id"`
+	ContentType  string     `json:"content_type" db:"content_type"`
+	CostPrice    int        `json:"cost_price" db:"cost_price"`
+	Supplier     *string    `json:"supplier,omitempty" db:"supplier"`
+	ItemCount    int        `json:"item_count" db:"item_count"`
+	CreatedBy    int64      `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	RevokeReason *string    `json:"revoke_reason,omitempty" db:"revoke_reason"`
+
+	// Joined fields
+	ProductName    string `json:"product_name,omitempty"`
+	SoldCount      int    `json:"sold_count"`
+	AvailableCount int    `json:"available_count"`
+}
+
+// IsRevoked returns true if the batch has been revoked
+func (b *StockBatch) IsRevoked() bool {
+	return b.RevokedAt != nil
+}
+
+// SupplierName returns the supplier name or a dash when unknown
+func (b *StockBatch) SupplierName() string {
+	if b.Supplier == nil || *b.Supplier == "" {
+		return "-"
+	}
+	return *b.Supplier
+}
+
+// BatchRevokeResult summarizes the effect of revoking a stock batch
+type BatchRevokeResult struct {
+	PulledCount      int           `json:"pulled_count"`      // Unsold items removed from sale
+	SwappedPending   int           `json:"swapped_pending"`   // Items reserved by unpaid orders swapped for fresh stock
+	UnswappedPending int           `json:"unswapped_pending"` // Items reserved by unpaid orders with no replacement available
+	Affected         []SoldAccount `json:"affected"`          // Items already delivered in paid orders
+}
+
+// BundleItem is a component product of a bundle
+type BundleItem struct {
+	BundleID  int `json:"bundle_id" db:"bundle_id"`
+	ProductID int `json:"product_id" db:"product_id"`
+	Quantity  int `json:"quantity" db:"quantity"` // Units of the component in one bundle
+
+	// Joined fields from Product
+	ProductName  string `json:"product_name,omitempty" db:"product_name"`
+	ProductPrice int    `json:"product_price,omitempty" db:"product_price"`
+}
+
+// BundleComponentsValue returns what the components of one bundle cost when bought separately
+func BundleComponentsValue(items []BundleItem) int {
+	total := 0
+	for _, item := range items {
+		total += item.ProductPrice * item.Quantity
+	}
+	return total
+}
+
+// SplitBundlePrice divides the price of one bundle among its component units, in proportion to
+// each component's own price, so revenue can be attributed to the components.
+// Returns the price of every unit per component product ID; the shares always add up to price.
+func SplitBundlePrice(price int, items []BundleItem) map[int][]int {
+	shares := make(map[int][]int)
+	if len(items) == 0 {
+		return shares
+	}
+
+	total := BundleComponentsValue(items)
+	units := 0
+	for _, item := range items {
+		units += item.Quantity
+	}
+
+	allocated := 0
+	for i, item := range items {
+		// Weight by component value, or evenly per unit when every component is free
+		share := 0
+		switch {
+		case i == len(items)-1:
+			share = price - allocated
+		case total > 0:
+			share = price * item.ProductPrice * item.Quantity / total
+		case units > 0:
+			share = price * item.Quantity / units
+		}
+		allocated += share
+
+		unitPrices := make([]int, item.Quantity)
+		for u := range unitPrices {
+			unitPrices[u] = share / item.Quantity
+		}
+		if item.Quantity > 0 {
+			unitPrices[item.Quantity-1] += share % item.Quantity
+		}
+		shares[item.ProductID] = append(shares[item.ProductID], unitPrices...)
+	}
+	return shares
+}
+
+// CouponDiscountType represents how a coupon discount is calculated
+type CouponDiscountType string
+
+const (
+	CouponDiscountPercent CouponDiscountType = "percent"
+	CouponDiscountFixed   CouponDiscountType = "fixed"
+)
+
+// Coupon represents a discount code buyers can apply at checkout
+type Coupon struct {
+	ID                int                `json:"id" db:"id"`
+	Code              string             `json:"code" db:"code"`
+	DiscountType      CouponDiscountType `json:"discount_type" db:"discount_type"`
+	DiscountValue     int                `json:"discount_value" db:"discount_value"` // Percent (1-100) or fixed amount
+	MaxDiscount       int                `json:"max_discount" db:"max_discount"`     // Cap for percent coupons, 0 = no cap
+	MinSpend          int                `json:"min_spend" db:"min_spend"`           // Minimum eligible subtotal
+	ProductID         *int               `json:"product_id,omitempty" db:"product_id"`
+	Category          *string            `json:"category,omitempty" db:"category"`
+	StartsAt          *time.Time         `json:"starts_at,omitempty" db:"starts_at"`
+	EndsAt            *time.Time         `json:"ends_at,omitempty" db:"ends_at"`
+	UsageLimit        int                `json:"usage_limit" db:"usage_limit"`       // Total redemptions, 0 = unlimited
+	PerUserLimit      int                `json:"per_user_limit" db:"per_user_limit"` // Redemptions per user, 0 = unlimited
+	FirstPurchaseOnly bool               `json:"first_purchase_only" db:"first_purchase_only"`
+	UserID            *int64             `json:"user_id,omitempty" db:"user_id"` // Personal coupon owner, nil = anyone
+	IsActive          bool               `json:"is_active" db:"is_active"`
+	CreatedBy         int64              `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time          `json:"created_at" db:"created_at"`
+
+	// Joined fields
+	UsedCount     int `json:"used_count"`     // Redemptions on orders that were not expired or cancelled
+	TotalDiscount int `json:"total_discount"` // Discount given on paid orders
+}
+
+// AppliesTo returns true if the coupon covers a product
+func (c *Coupon) AppliesTo(productID int, category string) bool {
+	if c.ProductID != nil && *c.ProductID != productID {
+		return false
+	}
+	if c.Category != nil && *c.Category != category {
+		return false
+	}
+	return true
+}
+
+// IsWithinPeriod returns true if t falls between the coupon start and end dates
+func (c *Coupon) IsWithinPeriod(t time.Time) bool {
+	if c.StartsAt != nil && t.Before(*c.StartsAt) {
+		return false
+	}
+	if c.EndsAt != nil && t.After(*c.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// CalculateDiscount returns the discount for an eligible subtotal, never more than the subtotal itself
+func (c *Coupon) CalculateDiscount(eligible int) int {
+	discount := c.DiscountValue
+	if c.DiscountType == CouponDiscountPercent {
+		discount = eligible * c.DiscountValue / 100
+		if c.MaxDiscount > 0 && discount > c.MaxDiscount {
+			discount = c.MaxDiscount
+		}
+	}
+	if discount > eligible {
+		discount = eligible
+	}
+	if discount < 0 {
+		discount = 0
+	}
+	return discount
+}
+
+// FormatValue returns a readable coupon value like "10% (maks Rp 20.000)" or "Rp 5.000"
+func (c *Coupon) FormatValue(currency string) string {
+	if c.DiscountType == CouponDiscountPercent {
+		if c.MaxDiscount > 0 {
+			return fmt.Sprintf("%d%% (maks %s)", c.DiscountValue, FormatPrice(c.MaxDiscount, currency))
+		}
+		return fmt.Sprintf("%d%%", c.DiscountValue)
+	}
+	return FormatPrice(c.DiscountValue, currency)
+}
+
+// FlashSale is a scheduled price rule for a product or a whole category
+type FlashSale struct {
+	ID              int        `json:"id" db:"id"`
+	Name            string     `json:"name" db:"name"`
+	ProductID       *int       `json:"product_id,omitempty" db:"product_id"`
+	Category        *string    `json:"category,omitempty" db:"category"`
+	SalePrice       int        `json:"sale_price" db:"sale_price"`             // Fixed sale price, 0 when DiscountPercent is used
+	DiscountPercent int        `json:"discount_percent" db:"discount_percent"` // Percent off the normal price
+	StartsAt        time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt          time.Time  `json:"ends_at" db:"ends_at"`
+	QuantityCap     int        `json:"quantity_cap" db:"quantity_cap"` // Units sold at the sale price, 0 = unlimited
+	Announce        bool       `json:"announce" db:"announce"`         // Broadcast to users when the sale starts
+	AnnouncedAt     *time.Time `json:"announced_at,omitempty" db:"announced_at"`
+	IsActive        bool       `json:"is_active" db:"is_active"`
+	CreatedBy       int64      `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+
+	// Joined fields
+	SoldCount int `json:"sold_count"` // Units on orders that were not expired or cancelled
+}
+
+// AppliesTo returns true if the sale covers a product
+func (f *FlashSale) AppliesTo(productID int, category string) bool {
+	if f.ProductID != nil && *f.ProductID != productID {
+		return false
+	}
+	if f.Category != nil && *f.Category != category {
+		return false
+	}
+	return true
+}
+
+// IsRunning returns true if the sale is enabled and t falls between its start and end time
+func (f *FlashSale) IsRunning(t time.Time) bool {
+	return f.IsActive && !t.Before(f.StartsAt) && t.Before(f.EndsAt)
+}
+
+// PriceFor returns the sale price for a normal price, never more than the normal price
+func (f *FlashSale) PriceFor(price int) int {
+	salePrice := f.SalePrice
+	if f.DiscountPercent > 0 {
+		salePrice = price * (100 - f.DiscountPercent) / 100
+	}
+	if salePrice > price || salePrice < 0 {
+		return price
+	}
+	return salePrice
+}
+
+// Remaining returns the units still available at the sale price, or -1 when the sale has no cap
+func (f *FlashSale) Remaining() int {
+	if f.QuantityCap <= 0 {
+		return -1
+	}
+	if f.SoldCount >= f.QuantityCap {
+		return 0
+	}
+	return f.QuantityCap - f.SoldCount
+}
+
+// FormatValue returns a readable sale value like "30%" or "Rp 25.000"
+func (f *FlashSale) FormatValue(currency string) string {
+	if f.DiscountPercent > 0 {
+		return fmt.Sprintf("%d%%", f.DiscountPercent)
+	}
+	return FormatPrice(f.SalePrice, currency)
+}
+
+// BestFlashSale returns the sale giving the lowest price for a quantity of a product,
+// or nil when no sale applies or the sales covering it have too few units left
+func BestFlashSale(sales []FlashSale, productID int, category string, price, quantity int) *FlashSale {
+	var best *FlashSale
+	bestPrice := price
+	for i := range sales {
+		sale := &sales[i]
+		if !sale.AppliesTo(productID, category) {
+			continue
+		}
+		if remaining := sale.Remaining(); remaining >= 0 && remaining < quantity {
+			continue
+		}
+		if salePrice := sale.PriceFor(price); salePrice < bestPrice {
+			best = sale
+			bestPrice = salePrice
+		}
+	}
+	return best
+}
+
+// ApplyFlashSale lowers the product price to the best running sale from sales
+func (p *Product) ApplyFlashSale(sales []FlashSale) {
+	sale := BestFlashSale(sales, p.ID, p.Category, p.Price, 1)
+	if sale == nil {
+		return
+	}
+	p.OriginalPrice = p.Price
+	p.Price = sale.PriceFor(p.Price)
+	p.FlashSale = sale
+}
+
+// ApplyFlashSale lowers the cart item price to the best running sale from sales
+func (c *CartItem) ApplyFlashSale(sales []FlashSale) {
+	sale := BestFlashSale(sales, c.ProductID, c.ProductCategory, c.ProductPrice, c.Quantity)
+	if sale == nil {
+		return
+	}
+	c.OriginalPrice = c.ProductPrice
+	c.ProductPrice = sale.PriceFor(c.ProductPrice)
+	c.FlashSale = sale
+}
+
+// PriceTier is a special price for a customer group, optionally from a minimum quantity
+type PriceTier struct {
+	ID              int           `json:"id" db:"id"`
+	CustomerGroup   CustomerGroup `json:"customer_group" db:"customer_group"`
+	ProductID       *int          `json:"product_id,omitempty" db:"product_id"` // nil = all products
+	MinQuantity     int           `json:"min_quantity" db:"min_quantity"`
+	Price           int           `json:"price" db:"price"`                       // Fixed unit price, 0 when DiscountPercent is used
+	DiscountPercent int           `json:"discount_percent" db:"discount_percent"` // Percent off the normal price
+	CreatedAt       time.Time     `json:"created_at" db:"created_at"`
+
+	// Joined fields
+	ProductName *string `json:"product_name,omitempty"`
+}
+
+// AppliesTo returns true if the tier covers a product bought by a group in the given quantity
+func (t *PriceTier) AppliesTo(productID int, group CustomerGroup, quantity int) bool {
+	if t.CustomerGroup != group || quantity < t.MinQuantity {
+		return false
+	}
+	return t.ProductID == nil || *t.ProductID == productID
+}
+
+// PriceFor returns the tier unit price for a normal price, never more than the normal price
+func (t *PriceTier) PriceFor(price int) int {
+	tierPrice := t.Price
+	if t.DiscountPercent > 0 {
+		tierPrice = price * (100 - t.DiscountPercent) / 100
+	}
+	if tierPrice > price || tierPrice < 0 {
+		return price
+	}
+	return tierPrice
+}
+
+// FormatValue returns a readable tier value like "15%" or "Rp 40.000"
+func (t *PriceTier) FormatValue(currency string) string {
+	if t.DiscountPercent > 0 {
+		return fmt.Sprintf("%d%%", t.DiscountPercent)
+	}
+	return FormatPrice(t.Price, currency)
+}
+
+// BestPriceTier returns the tier giving the lowest unit price, or nil when none applies
+func BestPriceTier(tiers []PriceTier, productID int, group CustomerGroup, price, quantity int) *PriceTier {
+	var best *PriceTier
+	bestPrice := price
+	for i := range tiers {
+		tier := &tiers[i]
+		if !tier.AppliesTo(productID, group, quantity) {
+			continue
+		}
+		if tierPrice := tier.PriceFor(price); tierPrice < bestPrice {
+			best = tier
+			bestPrice = tierPrice
+		}
+	}
+	return best
+}
+
+// ApplyPriceTier lowers the product price to the group's best tier when it beats the current price.
+// Tiers are computed from the normal price, so they do not stack with flash sales.
+func (p *Product) ApplyPriceTier(tiers []PriceTier, group CustomerGroup, quantity int) {
+	base := p.Price
+	if p.OriginalPrice > 0 {
+		base = p.OriginalPrice
+	}
+	tier := BestPriceTier(tiers, p.ID, group, base, quantity)
+	if tier == nil || tier.PriceFor(base) >= p.Price {
+		return
+	}
+	p.OriginalPrice = base
+	p.Price = tier.PriceFor(base)
+	p.FlashSale = nil
+	p.PriceTier = tier
+}
+
+// ApplyPriceTier lowers the cart item price to the group's best tier when it beats the current price
+func (c *CartItem) ApplyPriceTier(tiers []PriceTier, group CustomerGroup) {
+	base := c.ProductPrice
+	if c.OriginalPrice > 0 {
+		base = c.OriginalPrice
+	}
+	tier := BestPriceTier(tiers, c.ProductID, group, base, c.Quantity)
+	if tier == nil || tier.PriceFor(base) >= c.ProductPrice {
+		return
+	}
+	c.OriginalPrice = base
+	c.ProductPrice = tier.PriceFor(base)
+	c.FlashSale = nil
+	c.PriceTier = tier
+}
+
+// GiftCardStock is the availability reported for gift card products, which have no stock limit
+const GiftCardStock = 9999
+
+// IsGiftCard returns true if the product issues gift card codes
+func (p *Product) IsGiftCard() bool {
+	return p.GiftCardValue > 0
+}
+
+// GiftCardState is the lifecycle state of an issued gift card
+type GiftCardState string
+
+const (
+	GiftCardPending GiftCardState = "pending" // Order not paid yet
+	GiftCardActive  GiftCardState = "active"  // Redeemable with balance left
+	GiftCardUsed    GiftCardState = "used"    // Fully redeemed
+	GiftCardExpired GiftCardState = "expired" // Past its expiry with balance left
+	GiftCardVoided  GiftCardState = "voided"  // Voided by an admin, or the order was never paid or was refunded
+)
+
+// GiftCard is a code worth store balance, issued when a gift card product is bought
+type GiftCard struct {
+	ID        int        `json:"id" db:"id"`
+	Code      string     `json:"code" db:"code"`
+	ProductID *int       `json:"product_id,omitempty" db:"product_id"`
+	OrderID   *string    `json:"order_id,omitempty" db:"order_id"`
+	BuyerID   *int64     `json:"buyer_id,omitempty" db:"buyer_id"`
+	Value     int        `json:"value" db:"value"`
+	Balance   int        `json:"balance" db:"balance"` // Value not redeemed yet
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	VoidedAt  *time.Time `json:"voided_at,omitempty" db:"voided_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+
+	// Joined fields
+	OrderStatus     *PaymentStatus `json:"order_status,omitempty"`
+	ProductName     *string        `json:"product_name,omitempty"`
+	RedemptionCount int            `json:"redemption_count"`
+}
+
+// State returns the gift card state at time t
+func (g *GiftCard) State(t time.Time) GiftCardState {
+	switch {
+	case g.OrderStatus != nil && *g.OrderStatus == PaymentStatusPending:
+		return GiftCardPending
+	case g.VoidedAt != nil, g.OrderStatus != nil && *g.OrderStatus != PaymentStatusPaid:
+		return GiftCardVoided
+	case g.Balance <= 0:
+		return GiftCardUsed
+	case g.ExpiresAt != nil && !t.Before(*g.ExpiresAt):
+		return GiftCardExpired
+	default:
+		return GiftCardActive
+	}
+}
+
+// GiftCardRedemption records value moved from a gift card to a user's balance
+type GiftCardRedemption struct {
+	ID         int       `json:"id" db:"id"`
+	GiftCardID int       `json:"gift_card_id" db:"gift_card_id"`
+	UserID     int64     `json:"user_id" db:"user_id"`
+	Amount     int       `json:"amount" db:"amount"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// CartOptions holds the checkout choices a user made on the cart screen
+type CartOptions struct {
+	CouponCode string `json:"coupon_code,omitempty"`
+	UseBalance bool   `json:"use_balance"`
+	UsePoints  bool   `json:"use_points"`
+}
+
+// BalanceTransactionType labels a store balance change in the ledger
+type BalanceTransactionType string
+
+const (
+	BalanceTxReferral    BalanceTransactionType = "referral"     // Referral reward credit
+	BalanceTxPurchase    BalanceTransactionType = "purchase"     // Balance spent on an order
+	BalanceTxOrderRefund BalanceTransactionType = "order_refund" // Balance returned when an order expires or is cancelled
+	BalanceTxAdjustment  BalanceTransactionType = "adjustment"   // Manual admin correction
+	BalanceTxGiftCard    BalanceTransactionType = "gift_card"    // Gift card value redeemed to balance
+	BalanceTxItemRefund  BalanceTransactionType = "item_refund"  // Amount paid for a delivered item that could not be replaced
+)
+
+// LoyaltyTransactionType labels a loyalty points change in the ledger
+type LoyaltyTransactionType string
+
+const (
+	LoyaltyTxEarn     LoyaltyTransactionType = "earn"     // Points earned on a paid order
+	LoyaltyTxRedeem   LoyaltyTransactionType = "redeem"   // Points spent as a checkout discount
+	LoyaltyTxRestore  LoyaltyTransactionType = "restore"  // Points returned when an order expires or is cancelled
+	LoyaltyTxReversal LoyaltyTransactionType = "reversal" // Points taken back when a purchased item is refunded
+	LoyaltyTxExpire   LoyaltyTransactionType = "expire"   // Points that passed their expiry date
+)
+
+// LoyaltyTransaction is one entry in a user's points history
+type LoyaltyTransaction struct {
+	ID          int                    `json:"id" db:"id"`
+	UserID      int64                  `json:"user_id" db:"user_id"`
+	Points      int                    `json:"points" db:"points"` // Positive for credits, negative for debits
+	Type        LoyaltyTransactionType `json:"type" db:"type"`
+	Reference   *string                `json:"reference,omitempty" db:"reference"`
+	Description *string                `json:"description,omitempty" db:"description"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+}
+
+// LoyaltySummary is a user's usable points and the next batch about to expire
+type LoyaltySummary struct {
+	Points         int        `json:"points"`
+	ExpiringPoints int        `json:"expiring_points"` // Points in the batch expiring first
+	NextExpiry     *time.Time `json:"next_expiry,omitempty"`
+}
+
+// ConversationState is the multi-step flow a user is in, waiting for their next message
+type ConversationState string
+
+const (
+	StateQRISUpload    ConversationState = "qris_upload"    // Admin uploading a static QRIS image
+	StateCouponCode    ConversationState = "coupon_code"    // Buyer typing a coupon code from the cart
+	StateBroadcast     ConversationState = "broadcast"      // Admin typing a broadcast message; payload is the target
+	StateReviewComment ConversationState = "review_comment" // Buyer typing a review comment; payload is the review ID
+	StateProductWizard ConversationState = "product_wizard" // Admin creating or editing a product; payload is a JSON ProductDraft
+	StateProductPhotos ConversationState = "product_photos" // Admin uploading product photos; payload is the product ID
+	StateSupportTicket ConversationState = "support_ticket" // Buyer writing to support; payload is the ticket ID, or "new:<order ID>" before the first message
+	StateTicketReply   ConversationState = "ticket_reply"   // Admin typing an answer from the ticket queue; payload is the ticket ID
+	StateTemplateEdit  ConversationState = "template_edit"  // Admin typing new text for a message template; payload is the template name
+	StateBanReason     ConversationState = "ban_reason"     // Admin typing the reason for a ban; payload is "<user ID>:<duration>"
+)
+
+// TTL returns how long a user may stay in the state before it expires
+func (s ConversationState) TTL() time.Duration {
+	switch s {
+	case StateCouponCode:
+		return 10 * time.Minute
+	case StateSupportTicket:
+		// Buyers may take a while to answer the admin's questions
+		return 24 * time.Hour
+	default:
+		return 30 * time.Minute
+	}
+}
+
+// ProductDraft is a product being created or edited in the admin product wizard
+type ProductDraft struct {
+	ProductID            int      `json:"product_id,omitempty"` // 0 while creating a new product
+	Step                 string   `json:"step"`                 // Field the wizard is waiting for, or "preview"
+	Editing              bool     `json:"editing,omitempty"`    // Return to the preview after the current step
+	Name                 string   `json:"name"`
+	Description          string   `json:"description"`
+	Price                int      `json:"price"`
+	Category             string   `json:"category"`
+	ImageURL             string   `json:"image_url,omitempty"` // Image URL typed instead of uploading photos
+	Photos               []string `json:"photos,omitempty"`    // Telegram file IDs uploaded in the wizard, added to the gallery on save
+	IsActive             bool     `json:"is_active"`
+	DeliveryInstructions string   `json:"delivery_instructions,omitempty"`
+}
+
+// Conversation is a user's current conversation state
+type Conversation struct {
+	UserID    int64             `json:"user_id" db:"user_id"`
+	State     ConversationState `json:"state" db:"state"`
+	Payload   string            `json:"payload" db:"payload"` // Flow data, e.g. the broadcast target
+	ExpiresAt time.Time         `json:"expires_at" db:"expires_at"`
+	UpdatedAt time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// ReferralRewardType represents how a referrer is rewarded
+type ReferralRewardType string
+
+const (
+	ReferralRewardBalance ReferralRewardType = "balance" // Fixed balance credit
+	ReferralRewardCoupon  ReferralRewardType = "coupon"  // Personal fixed-amount coupon
+	ReferralRewardPercent ReferralRewardType = "percent" // Balance credit worth a percentage of the first purchase
+)
+
+// ReferralStats summarizes a user's referral results
+type ReferralStats struct {
+	Invited       int `json:"invited"`        // Users who joined through the referral link
+	Converted     int `json:"converted"`      // Invited users who completed a paid order
+	BalanceEarned int `json:"balance_earned"` // Balance credited from referral rewards
+	CouponsEarned int `json:"coupons_earned"` // Reward coupons received
+}
+
+// ReferredUser is a user who joined through someone's referral link
+type ReferredUser struct {
+	UserID     int64     `json:"user_id"`
+	Username   *string   `json:"username,omitempty"`
+	FirstName  *string   `json:"first_name,omitempty"`
+	ReferredAt time.Time `json:"referred_at"`
+	Rewarded   bool      `json:"rewarded"`
+	RewardType *string   `json:"reward_type,omitempty"`
+	Amount     int       `json:"amount"`
+}
+
+// DisplayName returns the first name or username, or the user ID when neither is set
+func (u *ReferredUser) DisplayName() string {
+	if u.FirstName != nil && *u.FirstName != "" {
+		return *u.FirstName
+	}
+	if u.Username != nil && *u.Username != "" {
+		return "@" + *u.Username
+	}
+	return fmt.Sprintf("User %d", u.UserID)
+}
+
+// CouponRedemption records a coupon used on an order
+type CouponRedemption struct {
+	ID             int       `json:"id" db:"id"`
+	CouponID       int       `json:"coupon_id" db:"coupon_id"`
+	OrderID        string    `json:"order_id" db:"order_id"`
+	UserID         int64     `json:"user_id" db:"user_id"`
+	DiscountAmount int       `json:"discount_amount" db:"discount_amount"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+
+	// Joined fields
+	PaymentStatus PaymentStatus `json:"payment_status,omitempty"`
+}
+
 // PaymentVerification represents payment verification for anti-manipulation
 type PaymentVerification struct {
 	ID               int       `json:"id" db:"id"`
@@ -264,6 +1196,39 @@ type StockSummary struct {
 	TotalStock     int `json:"total_stock"`
 }
 
+// ProfitGroupBy represents the dimension used to group profit reports
+type ProfitGroupBy string
+
+const (
+	ProfitByProduct  ProfitGroupBy = "product"
+	ProfitByCategory ProfitGroupBy = "category"
+	ProfitBySupplier ProfitGroupBy = "supplier"
+	ProfitByDay      ProfitGroupBy = "day"
+	ProfitByGroup    ProfitGroupBy = "group" // Customer group of the buyer
+)
+
+// ProfitReportRow represents revenue, cost and profit for one report group
+type ProfitReportRow struct {
+	Key       string `json:"key"`
+	Label     string `json:"label"`
+	ItemsSold int    `json:"items_sold"`
+	Revenue   int    `json:"revenue"`
+	Cost      int    `json:"cost"`
+}
+
+// Profit returns revenue minus cost
+func (r ProfitReportRow) Profit() int {
+	return r.Revenue - r.Cost
+}
+
+// Margin returns profit as a percentage of revenue
+func (r ProfitReportRow) Margin() float64 {
+	if r.Revenue == 0 {
+		return 0
+	}
+	return float64(r.Profit()) / float64(r.Revenue) * 100
+}
+
 // AccountCredentials represents formatted account credentials
 type AccountCredentials struct {
 	Email    string `json:"email"`
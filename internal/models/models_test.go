@@ -0,0 +1,128 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitBundlePrice(t *testing.T) {
+	tests := []struct {
+		name  string
+		price int
+		items []BundleItem
+		want  map[int][]int
+	}{
+		{
+			name:  "no components",
+			price: 10000,
+			items: nil,
+			want:  map[int][]int{},
+		},
+		{
+			name:  "single component",
+			price: 45000,
+			items: []BundleItem{{ProductID: 1, Quantity: 1, ProductPrice: 50000}},
+			want:  map[int][]int{1: {45000}},
+		},
+		{
+			name:  "proportional to component value",
+			price: 60000,
+			items: []BundleItem{
+				{ProductID: 1, Quantity: 1, ProductPrice: 50000},
+				{ProductID: 2, Quantity: 1, ProductPrice: 25000},
+			},
+			want: map[int][]int{1: {40000}, 2: {20000}},
+		},
+		{
+			name:  "several units of a component",
+			price: 30000,
+			items: []BundleItem{
+				{ProductID: 1, Quantity: 2, ProductPrice: 10000},
+				{ProductID: 2, Quantity: 1, ProductPrice: 20000},
+			},
+			want: map[int][]int{1: {7500, 7500}, 2: {15000}},
+		},
+		{
+			name:  "remainder goes to the last unit",
+			price: 10000,
+			items: []BundleItem{
+				{ProductID: 1, Quantity: 3, ProductPrice: 1000},
+			},
+			want: map[int][]int{1: {3333, 3333, 3334}},
+		},
+		{
+			name:  "rounding leftover goes to the last component",
+			price: 10000,
+			items: []BundleItem{
+				{ProductID: 1, Quantity: 1, ProductPrice: 1000},
+				{ProductID: 2, Quantity: 1, ProductPrice: 1000},
+				{ProductID: 3, Quantity: 1, ProductPrice: 1000},
+			},
+			want: map[int][]int{1: {3333}, 2: {3333}, 3: {3334}},
+		},
+		{
+			name:  "free components are split evenly per unit",
+			price: 9000,
+			items: []BundleItem{
+				{ProductID: 1, Quantity: 2, ProductPrice: 0},
+				{ProductID: 2, Quantity: 1, ProductPrice: 0},
+			},
+			want: map[int][]int{1: {3000, 3000}, 2: {3000}},
+		},
+		{
+			name:  "free bundle",
+			price: 0,
+			items: []BundleItem{
+				{ProductID: 1, Quantity: 1, ProductPrice: 10000},
+				{ProductID: 2, Quantity: 1, ProductPrice: 5000},
+			},
+			want: map[int][]int{1: {0}, 2: {0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitBundlePrice(tt.price, tt.items)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("SplitBundlePrice(%d) = %v, want %v", tt.price, got, tt.want)
+			}
+
+			sum := 0
+			for _, units := range got {
+				for _, p := range units {
+					sum += p
+				}
+			}
+			if len(tt.items) > 0 && sum != tt.price {
+				t.Errorf("shares add up to %d, want %d", sum, tt.price)
+			}
+		})
+	}
+}
+
+func TestCouponCalculateDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		coupon   Coupon
+		eligible int
+		want     int
+	}{
+		{"percent", Coupon{DiscountType: CouponDiscountPercent, DiscountValue: 10}, 50000, 5000},
+		{"percent rounds down", Coupon{DiscountType: CouponDiscountPercent, DiscountValue: 15}, 9999, 1499},
+		{"percent under the cap", Coupon{DiscountType: CouponDiscountPercent, DiscountValue: 10, MaxDiscount: 20000}, 100000, 10000},
+		{"percent capped", Coupon{DiscountType: CouponDiscountPercent, DiscountValue: 50, MaxDiscount: 20000}, 100000, 20000},
+		{"full percent", Coupon{DiscountType: CouponDiscountPercent, DiscountValue: 100}, 25000, 25000},
+		{"fixed", Coupon{DiscountType: CouponDiscountFixed, DiscountValue: 5000}, 50000, 5000},
+		{"fixed above the subtotal", Coupon{DiscountType: CouponDiscountFixed, DiscountValue: 50000}, 20000, 20000},
+		{"nothing eligible", Coupon{DiscountType: CouponDiscountFixed, DiscountValue: 5000}, 0, 0},
+		{"negative value", Coupon{DiscountType: CouponDiscountFixed, DiscountValue: -5000}, 20000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.coupon.CalculateDiscount(tt.eligible); got != tt.want {
+				t.Errorf("CalculateDiscount(%d) = %d, want %d", tt.eligible, got, tt.want)
+			}
+		})
+	}
+}
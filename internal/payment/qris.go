@@ -268,10 +268,19 @@ func (q *QRISService) IsExpired(expiryTime *time.Time) bool {
 
 // GetPaymentInstructions returns localized payment instructions
 func (q *QRISService) GetPaymentInstructions(orderID string, amount int) string {
-	messages := config.GetMessages()
-	return fmt.Sprintf(messages.PaymentInstructions,
+	return fmt.Sprintf(`💳 *INSTRUKSI PEMBAYARAN QRIS*
+
+1️⃣ Scan QR Code di bawah ini dengan aplikasi e-wallet atau mobile banking Anda
+2️⃣ Pastikan nominal pembayaran sesuai: *%s*
+3️⃣ Konfirmasi pembayaran di aplikasi Anda
+4️⃣ Pembayaran akan otomatis terverifikasi dalam 1-2 menit
+5️⃣ Anda akan menerima notifikasi setelah pembayaran berhasil
+
+⚠️ *PENTING:*
+• QR Code ini hanya berlaku untuk 15 menit
+• Jangan ubah nominal pembayaran
+• Simpan Order ID untuk referensi: *#%s*`,
 		models.FormatPrice(amount, q.config.CurrencySymbol),
-		q.config.CurrencySymbol,
 		orderID)
 }
 
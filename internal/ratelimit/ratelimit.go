@@ -0,0 +1,272 @@
+package ratelimit
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Per-user rate limiting
+//
+// Every user has a token bucket per action. A request takes one token from each bucket of its actions and
+// is throttled when one of them is empty. Buckets refill evenly over their period, so a user can burst up
+// to the bucket size and then continues at the average rate. Users who keep hitting the limit collect
+// strikes and are muted for a while.
+
+// Action is a kind of request with its own limit
+type Action string
+
+const (
+	ActionGeneral  Action = "general"  // Every update a user sends
+	ActionCheckout Action = "checkout" // Creating an order from the cart or a pre-order
+	ActionQR       Action = "qr"       // Generating a QRIS payment image
+	ActionSearch   Action = "search"   // Product search, including inline queries
+)
+
+// Actions lists every action in display order
+var Actions = []Action{ActionGeneral, ActionCheckout, ActionQR, ActionSearch}
+
+// Rate allows Burst requests at once, refilled evenly over Per
+type Rate struct {
+	Burst int
+	Per   time.Duration
+}
+
+// Config configures a Limiter
+type Config struct {
+	Rates        map[Action]Rate // Actions without a rate, or with a zero burst, are not limited
+	MuteStrikes  int             // Throttled requests within StrikeWindow that mute the user (0 disables mutes)
+	StrikeWindow time.Duration
+	MuteDuration time.Duration
+}
+
+// sweepInterval is how often idle buckets are dropped to keep memory bounded
+const sweepInterval = 10 * time.Minute
+
+type bucketKey struct {
+	userID int64
+	action Action
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type userState struct {
+	strikes     int
+	firstStrike time.Time
+	mutedUntil  time.Time
+	notified    bool // A throttling reply was sent since the user's last allowed request
+	throttled   int  // Throttled requests since the limiter started
+}
+
+// Decision is the outcome of a request
+type Decision struct {
+	Allowed    bool
+	Action     Action        // The action whose limit was hit
+	RetryAfter time.Duration // When the request would be allowed again
+	Muted      bool          // The user is muted; MutedUntil says until when
+	MutedUntil time.Time
+	JustMuted  bool // This request caused the mute
+	Notify     bool // First refusal since the user's last allowed request; reply once instead of to every request
+}
+
+// Stats are counters since the limiter started
+type Stats struct {
+	Since      time.Time
+	Allowed    int
+	Throttled  map[Action]int
+	Mutes      int
+	MutedUsers []MutedUser
+	TopUsers   []ThrottledUser // Users with the most throttled requests, most first
+}
+
+// MutedUser is a user who is currently muted
+type MutedUser struct {
+	UserID int64
+	Until  time.Time
+}
+
+// ThrottledUser is a user and their number of throttled requests
+type ThrottledUser struct {
+	UserID    int64
+	Throttled int
+}
+
+// Limiter tracks request rates per user. It is safe for concurrent use.
+type Limiter struct {
+	mu        sync.Mutex
+	config    Config
+	buckets   map[bucketKey]*bucket
+	users     map[int64]*userState
+	allowed   int
+	throttled map[Action]int
+	mutes     int
+	since     time.Time
+	lastSweep time.Time
+}
+
+// New creates a limiter
+func New(config Config) *Limiter {
+	now := time.Now()
+	return &Limiter{
+		config:    config,
+		buckets:   make(map[bucketKey]*bucket),
+		users:     make(map[int64]*userState),
+		throttled: make(map[Action]int),
+		since:     now,
+		lastSweep: now,
+	}
+}
+
+// Allow decides whether the user may make a request counting against the given actions.
+// Tokens are only taken when every action has one left.
+func (l *Limiter) Allow(userID int64, actions ...Action) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	user := l.users[userID]
+	if user != nil && now.Before(user.mutedUntil) {
+		return Decision{Muted: true, MutedUntil: user.mutedUntil, RetryAfter: user.mutedUntil.Sub(now)}
+	}
+
+	var blocked Action
+	var retryAfter time.Duration
+	for _, action := range actions {
+		rate, ok := l.config.Rates[action]
+		if !ok || rate.Burst <= 0 || rate.Per <= 0 {
+			continue
+		}
+		b := l.refill(bucketKey{userID, action}, rate, now)
+		if b.tokens < 1 {
+			wait := time.Duration((1 - b.tokens) * float64(rate.Per) / float64(rate.Burst))
+			if wait > retryAfter {
+				blocked, retryAfter = action, wait
+			}
+		}
+	}
+
+	if retryAfter == 0 {
+		for _, action := range actions {
+			if b := l.buckets[bucketKey{userID, action}]; b != nil {
+				b.tokens--
+			}
+		}
+		if user != nil {
+			user.notified = false
+		}
+		l.allowed++
+		return Decision{Allowed: true}
+	}
+
+	if user == nil {
+		user = &userState{}
+		l.users[userID] = user
+	}
+	l.throttled[blocked]++
+	user.throttled++
+
+	decision := Decision{Action: blocked, RetryAfter: retryAfter, Notify: !user.notified}
+	user.notified = true
+
+	if l.config.MuteStrikes > 0 {
+		if user.strikes == 0 || now.Sub(user.firstStrike) > l.config.StrikeWindow {
+			user.strikes, user.firstStrike = 0, now
+		}
+		user.strikes++
+		if user.strikes >= l.config.MuteStrikes {
+			user.strikes = 0
+			user.mutedUntil = now.Add(l.config.MuteDuration)
+			l.mutes++
+			decision.Muted, decision.JustMuted = true, true
+			decision.MutedUntil, decision.RetryAfter = user.mutedUntil, l.config.MuteDuration
+		}
+	}
+	return decision
+}
+
+// refill returns the user's bucket for an action with the tokens earned since it was last used
+func (l *Limiter) refill(key bucketKey, rate Rate, now time.Time) *bucket {
+	b := l.buckets[key]
+	if b == nil {
+		b = &bucket{tokens: float64(rate.Burst), last: now}
+		l.buckets[key] = b
+		return b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * float64(rate.Burst) / rate.Per.Seconds()
+	if b.tokens > float64(rate.Burst) {
+		b.tokens = float64(rate.Burst)
+	}
+	b.last = now
+	return b
+}
+
+// sweep drops buckets that have refilled completely. Users are only tracked once they have been throttled
+// and are kept for the stats.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if rate := l.config.Rates[key.action]; now.Sub(b.last) >= rate.Per {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Unmute lifts a user's mute. Returns false if they were not muted.
+func (l *Limiter) Unmute(userID int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	user := l.users[userID]
+	if user == nil || !time.Now().Before(user.mutedUntil) {
+		return false
+	}
+	user.mutedUntil = time.Time{}
+	user.strikes = 0
+	return true
+}
+
+// Stats returns the counters, currently muted users and the most throttled users
+func (l *Limiter) Stats(topUsers int) Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	stats := Stats{
+		Since:     l.since,
+		Allowed:   l.allowed,
+		Throttled: make(map[Action]int, len(l.throttled)),
+		Mutes:     l.mutes,
+	}
+	for action, count := range l.throttled {
+		stats.Throttled[action] = count
+	}
+	for userID, user := range l.users {
+		if now.Before(user.mutedUntil) {
+			stats.MutedUsers = append(stats.MutedUsers, MutedUser{UserID: userID, Until: user.mutedUntil})
+		}
+		if user.throttled > 0 {
+			stats.TopUsers = append(stats.TopUsers, ThrottledUser{UserID: userID, Throttled: user.throttled})
+		}
+	}
+
+	sort.Slice(stats.MutedUsers, func(i, j int) bool { return stats.MutedUsers[i].Until.Before(stats.MutedUsers[j].Until) })
+	sort.Slice(stats.TopUsers, func(i, j int) bool {
+		if stats.TopUsers[i].Throttled != stats.TopUsers[j].Throttled {
+			return stats.TopUsers[i].Throttled > stats.TopUsers[j].Throttled
+		}
+		return stats.TopUsers[i].UserID < stats.TopUsers[j].UserID
+	})
+	if len(stats.TopUsers) > topUsers {
+		stats.TopUsers = stats.TopUsers[:topUsers]
+	}
+	return stats
+}
@@ -0,0 +1,201 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+type step struct {
+	actions     []Action
+	wantAllowed bool
+	wantAction  Action
+}
+
+func TestAllow(t *testing.T) {
+	perHour := func(n int) Rate { return Rate{Burst: n, Per: time.Hour} }
+
+	tests := []struct {
+		name  string
+		rates map[Action]Rate
+		steps []step
+	}{
+		{
+			name:  "burst then throttled",
+			rates: map[Action]Rate{ActionGeneral: perHour(3)},
+			steps: []step{
+				{[]Action{ActionGeneral}, true, ""},
+				{[]Action{ActionGeneral}, true, ""},
+				{[]Action{ActionGeneral}, true, ""},
+				{[]Action{ActionGeneral}, false, ActionGeneral},
+			},
+		},
+		{
+			name:  "action without a rate is not limited",
+			rates: map[Action]Rate{ActionGeneral: perHour(1)},
+			steps: []step{
+				{[]Action{ActionSearch}, true, ""},
+				{[]Action{ActionSearch}, true, ""},
+				{[]Action{ActionSearch}, true, ""},
+			},
+		},
+		{
+			name:  "zero burst is not limited",
+			rates: map[Action]Rate{ActionGeneral: perHour(0)},
+			steps: []step{
+				{[]Action{ActionGeneral}, true, ""},
+				{[]Action{ActionGeneral}, true, ""},
+			},
+		},
+		{
+			name:  "strictest action decides and blocked requests take no tokens",
+			rates: map[Action]Rate{ActionGeneral: perHour(3), ActionCheckout: perHour(1)},
+			steps: []step{
+				{[]Action{ActionGeneral, ActionCheckout}, true, ""},
+				{[]Action{ActionGeneral, ActionCheckout}, false, ActionCheckout},
+				{[]Action{ActionGeneral}, true, ""},
+				{[]Action{ActionGeneral}, true, ""},
+				{[]Action{ActionGeneral}, false, ActionGeneral},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(Config{Rates: tt.rates})
+			for i, s := range tt.steps {
+				d := l.Allow(1, s.actions...)
+				if d.Allowed != s.wantAllowed {
+					t.Fatalf("step %d: Allowed = %v, want %v", i, d.Allowed, s.wantAllowed)
+				}
+				if d.Action != s.wantAction {
+					t.Fatalf("step %d: Action = %q, want %q", i, d.Action, s.wantAction)
+				}
+			}
+		})
+	}
+}
+
+func TestAllowPerUser(t *testing.T) {
+	l := New(Config{Rates: map[Action]Rate{ActionGeneral: {Burst: 1, Per: time.Hour}}})
+	if !l.Allow(1, ActionGeneral).Allowed {
+		t.Fatal("first request of user 1 throttled")
+	}
+	if l.Allow(1, ActionGeneral).Allowed {
+		t.Fatal("second request of user 1 allowed")
+	}
+	if !l.Allow(2, ActionGeneral).Allowed {
+		t.Fatal("user 2 throttled by the bucket of user 1")
+	}
+}
+
+func TestRefill(t *testing.T) {
+	rate := Rate{Burst: 4, Per: time.Hour}
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    int // Requests allowed after the bucket was emptied and elapsed passed
+	}{
+		{"nothing refilled", 0, 0},
+		{"less than one token", 10 * time.Minute, 0},
+		{"one token", 15 * time.Minute, 1},
+		{"two tokens", 30 * time.Minute, 2},
+		{"capped at burst", 5 * time.Hour, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(Config{Rates: map[Action]Rate{ActionGeneral: rate}})
+			for i := 0; i < rate.Burst; i++ {
+				l.Allow(1, ActionGeneral)
+			}
+
+			// Pretend the bucket was last used elapsed ago
+			l.buckets[bucketKey{1, ActionGeneral}].last = time.Now().Add(-tt.elapsed)
+
+			allowed := 0
+			for l.Allow(1, ActionGeneral).Allowed {
+				allowed++
+			}
+			if allowed != tt.want {
+				t.Errorf("allowed %d requests, want %d", allowed, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	l := New(Config{Rates: map[Action]Rate{ActionQR: {Burst: 2, Per: time.Minute}}})
+	l.Allow(1, ActionQR)
+	l.Allow(1, ActionQR)
+
+	d := l.Allow(1, ActionQR)
+	if d.Allowed {
+		t.Fatal("third request allowed")
+	}
+	// One token takes Per/Burst to refill
+	if d.RetryAfter <= 29*time.Second || d.RetryAfter > 30*time.Second {
+		t.Errorf("RetryAfter = %s, want about 30s", d.RetryAfter)
+	}
+}
+
+func TestNotify(t *testing.T) {
+	l := New(Config{Rates: map[Action]Rate{ActionGeneral: {Burst: 1, Per: time.Hour}}})
+	l.Allow(1, ActionGeneral)
+
+	if d := l.Allow(1, ActionGeneral); !d.Notify {
+		t.Error("first refusal does not notify")
+	}
+	if d := l.Allow(1, ActionGeneral); d.Notify {
+		t.Error("second refusal notifies again")
+	}
+
+	l.buckets[bucketKey{1, ActionGeneral}].last = time.Now().Add(-time.Hour)
+	if d := l.Allow(1, ActionGeneral); !d.Allowed {
+		t.Fatal("request after refill throttled")
+	}
+	if d := l.Allow(1, ActionGeneral); !d.Notify {
+		t.Error("refusal after an allowed request does not notify")
+	}
+}
+
+func TestMute(t *testing.T) {
+	l := New(Config{
+		Rates:        map[Action]Rate{ActionGeneral: {Burst: 1, Per: time.Hour}},
+		MuteStrikes:  3,
+		StrikeWindow: time.Minute,
+		MuteDuration: 10 * time.Minute,
+	})
+	l.Allow(1, ActionGeneral)
+
+	tests := []struct {
+		name          string
+		wantMuted     bool
+		wantJustMuted bool
+	}{
+		{"first strike", false, false},
+		{"second strike", false, false},
+		{"third strike mutes", true, true},
+		{"while muted", true, false},
+	}
+	for _, tt := range tests {
+		d := l.Allow(1, ActionGeneral)
+		if d.Allowed || d.Muted != tt.wantMuted || d.JustMuted != tt.wantJustMuted {
+			t.Fatalf("%s: got Allowed=%v Muted=%v JustMuted=%v, want Muted=%v JustMuted=%v",
+				tt.name, d.Allowed, d.Muted, d.JustMuted, tt.wantMuted, tt.wantJustMuted)
+		}
+	}
+
+	if stats := l.Stats(5); stats.Mutes != 1 || len(stats.MutedUsers) != 1 {
+		t.Errorf("stats: Mutes = %d, MutedUsers = %d, want 1 and 1", stats.Mutes, len(stats.MutedUsers))
+	}
+	if !l.Unmute(1) {
+		t.Fatal("Unmute of a muted user returned false")
+	}
+	if l.Unmute(1) {
+		t.Error("Unmute of a user who is no longer muted returned true")
+	}
+	if d := l.Allow(1, ActionGeneral); d.Muted {
+		t.Error("user still muted after Unmute")
+	}
+}
@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+	"telegram-premium-store/internal/templates"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// cartMaintenance expires stale carts and sends abandoned-cart reminders every 15 minutes
+func (s *Scheduler) cartMaintenance() {
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.expireStaleCarts()
+			s.sendCartReminders()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// expireStaleCarts removes cart items that have not been touched for CartExpiryHours
+func (s *Scheduler) expireStaleCarts() {
+	if s.config.CartExpiryHours <= 0 {
+		return
+	}
+
+	removed, err := s.db.DeleteStaleCartItems(s.config.CartExpiryHours)
+	if err != nil {
+		logrus.Errorf("Failed to expire stale carts: %v", err)
+		return
+	}
+
+	if removed > 0 {
+		logrus.Infof("Removed %d stale cart items", removed)
+	}
+}
+
+// sendCartReminders sends one reminder to each user whose cart has been idle for CartReminderHours
+func (s *Scheduler) sendCartReminders() {
+	if s.config.CartReminderHours <= 0 {
+		return
+	}
+
+	userIDs, err := s.db.GetUsersForCartReminder(s.config.CartReminderHours)
+	if err != nil {
+		logrus.Errorf("Failed to query carts for reminder: %v", err)
+		return
+	}
+
+	sales, err := s.db.GetFlashSales(true)
+	if err != nil {
+		logrus.Errorf("Failed to get running flash sales: %v", err)
+	}
+	tiers, err := s.db.GetPriceTiers("")
+	if err != nil {
+		logrus.Errorf("Failed to get price tiers: %v", err)
+	}
+
+	for _, userID := range userIDs {
+		cartItems, err := s.db.GetCart(userID)
+		if err != nil {
+			logrus.Errorf("Failed to get cart for user %d: %v", userID, err)
+			continue
+		}
+		group, err := s.db.GetCustomerGroup(userID)
+		if err != nil {
+			logrus.Errorf("Failed to get customer group of user %d: %v", userID, err)
+		}
+		for i := range cartItems {
+			cartItems[i].ApplyFlashSale(sales)
+			cartItems[i].ApplyPriceTier(tiers, group)
+		}
+
+		// Mark first so a failed send is not retried every tick
+		if err := s.db.MarkCartReminderSent(userID); err != nil {
+			logrus.Errorf("Failed to mark cart reminder for user %d: %v", userID, err)
+			continue
+		}
+
+		if len(cartItems) == 0 {
+			continue
+		}
+
+		lang := s.userLanguage(userID)
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(s.i18n.T(lang, "button.view_cart"), "cart"),
+			),
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(s.i18n.T(lang, "cart_reminder.turn_off"), "cart_reminder:off"),
+			),
+		)
+
+		if _, err := s.templates.Send(s.api, userID, templates.CartReminder, lang, s.cartReminderVars(lang, cartItems), keyboard); err != nil {
+			logrus.Errorf("Failed to send cart reminder to user %d: %v", userID, err)
+			continue
+		}
+
+		logrus.Infof("Cart reminder sent to user %d (%d items)", userID, len(cartItems))
+	}
+}
+
+// cartReminderVars lists the cart with price and stock changes since each item was added
+func (s *Scheduler) cartReminderVars(lang string, cartItems []models.CartItem) templates.Vars {
+	var items strings.Builder
+	total := 0
+	for _, item := range cartItems {
+		total += item.ProductPrice * item.Quantity
+
+		items.WriteString(fmt.Sprintf("🔸 <b>%s</b> x%d - %s\n", templates.Escape(templates.FormatHTML, item.ProductName),
+			item.Quantity, s.i18n.FormatPrice(lang, item.ProductPrice, s.config.CurrencySymbol)))
+
+		if item.PriceAtAdd != nil && *item.PriceAtAdd != item.ProductPrice {
+			icon := "📈"
+			if item.ProductPrice < *item.PriceAtAdd {
+				icon = "📉"
+			}
+			items.WriteString(s.i18n.T(lang, "cart_reminder.price_changed", icon,
+				s.i18n.FormatPrice(lang, *item.PriceAtAdd, s.config.CurrencySymbol)) + "\n")
+		}
+		if item.FlashSale != nil {
+			items.WriteString(s.i18n.T(lang, "cart_reminder.flash_sale", s.i18n.FormatDateTime(lang, item.FlashSale.EndsAt)) + "\n")
+		}
+
+		available, err := s.db.GetAvailableAccountCount(item.ProductID)
+		if err != nil {
+			continue
+		}
+		if available == 0 {
+			items.WriteString(s.i18n.T(lang, "cart_reminder.out_of_stock") + "\n")
+		} else if available < item.Quantity {
+			items.WriteString(s.i18n.T(lang, "cart_reminder.low_stock", available) + "\n")
+		}
+	}
+
+	expiryNote := ""
+	if s.config.CartExpiryHours > 0 {
+		expiryNote = s.i18n.T(lang, "cart_reminder.expiry_note", s.config.CartExpiryHours) + "\n"
+	}
+
+	return templates.Vars{
+		"items":       templates.Raw(items.String()),
+		"total":       s.i18n.FormatPrice(lang, total, s.config.CurrencySymbol),
+		"expiry_note": expiryNote,
+	}
+}
@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+	"telegram-premium-store/internal/templates"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// flashSaleAnnouncer announces flash sales to all users as soon as they start
+func (s *Scheduler) flashSaleAnnouncer() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	s.announceFlashSales()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.announceFlashSales()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// announceFlashSales broadcasts every running sale that has announcements on and was not announced yet
+func (s *Scheduler) announceFlashSales() {
+	sales, err := s.db.GetFlashSales(true)
+	if err != nil {
+		logrus.Errorf("Failed to get running flash sales: %v", err)
+		return
+	}
+
+	for i := range sales {
+		sale := &sales[i]
+		if !sale.Announce || sale.AnnouncedAt != nil {
+			continue
+		}
+
+		// Claim first so the sale is announced only once, even if sending fails halfway
+		claimed, err := s.db.MarkFlashSaleAnnounced(sale.ID)
+		if err != nil {
+			logrus.Errorf("Failed to mark flash sale %d announced: %v", sale.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		s.sendFlashSaleAnnouncement(sale)
+	}
+}
+
+// sendFlashSaleAnnouncement sends the start announcement of a sale to every user
+func (s *Scheduler) sendFlashSaleAnnouncement(sale *models.FlashSale) {
+	text, button := s.buildFlashSaleAnnouncement(sale)
+
+	userIDs, err := s.db.GetAllUsers()
+	if err != nil {
+		logrus.Errorf("Failed to get users for flash sale %d announcement: %v", sale.ID, err)
+		return
+	}
+
+	broadcastID, err := s.db.CreateBroadcast(text, "flash_sale", sale.CreatedBy)
+	if err != nil {
+		logrus.Errorf("Failed to record flash sale %d broadcast: %v", sale.ID, err)
+	}
+
+	sent := 0
+	for _, userID := range userIDs {
+		msg := tgbotapi.NewMessage(userID, text)
+		msg.ParseMode = tgbotapi.ModeMarkdown
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(button))
+
+		if _, err := templates.SendFormatted(s.api, msg); err != nil {
+			logrus.Debugf("Failed to send flash sale announcement to user %d: %v", userID, err)
+		} else {
+			sent++
+		}
+
+		// Stay well below Telegram's limit of 30 messages per second
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if broadcastID > 0 {
+		s.db.UpdateBroadcastSent(broadcastID, sent)
+	}
+	logrus.Infof("Flash sale %d announced to %d/%d users", sale.ID, sent, len(userIDs))
+}
+
+// buildFlashSaleAnnouncement builds the announcement text and the button leading to the sale products
+func (s *Scheduler) buildFlashSaleAnnouncement(sale *models.FlashSale) (string, tgbotapi.InlineKeyboardButton) {
+	var text strings.Builder
+	text.WriteString("⚡ *FLASH SALE DIMULAI!*\n\n")
+	text.WriteString(fmt.Sprintf("🔥 *%s*\n\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, sale.Name)))
+
+	button := tgbotapi.NewInlineKeyboardButtonData("🛍️ Belanja Sekarang", "catalog:0")
+
+	var products []models.Product
+	switch {
+	case sale.ProductID != nil:
+		product, err := s.db.GetProduct(*sale.ProductID)
+		if err == nil && product != nil {
+			products = append(products, *product)
+		}
+		button = tgbotapi.NewInlineKeyboardButtonData("🛍️ Lihat Produk", fmt.Sprintf("product:%d", *sale.ProductID))
+	case sale.Category != nil:
+		products, _ = s.db.GetProducts(*sale.Category, 5, 0)
+		button = tgbotapi.NewInlineKeyboardButtonData("🛍️ Lihat Produk", fmt.Sprintf("category:%s:0", *sale.Category))
+	default:
+		text.WriteString(fmt.Sprintf("💸 Diskon %s untuk semua produk!\n", sale.FormatValue(s.config.CurrencySymbol)))
+	}
+
+	for _, product := range products {
+		salePrice := sale.PriceFor(product.Price)
+		text.WriteString(fmt.Sprintf("🔸 *%s*\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name)))
+		text.WriteString(fmt.Sprintf("   %s ➜ *%s*\n",
+			models.Strikethrough(models.FormatPrice(product.Price, s.config.CurrencySymbol)),
+			models.FormatPrice(salePrice, s.config.CurrencySymbol)))
+	}
+
+	text.WriteString(fmt.Sprintf("\n⏰ Berakhir: %s\n", sale.EndsAt.Format("02/01/2006 15:04")))
+	if sale.QuantityCap > 0 {
+		text.WriteString(fmt.Sprintf("🎟️ Terbatas hanya %d unit!\n", sale.QuantityCap))
+	}
+	text.WriteString("\nBuruan sebelum kehabisan! 🏃")
+
+	return text.String(), button
+}
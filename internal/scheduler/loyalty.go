@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loyaltyPointsExpirer expires loyalty points past their expiry date every hour
+func (s *Scheduler) loyaltyPointsExpirer() {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	s.expireLoyaltyPoints()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.expireLoyaltyPoints()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// expireLoyaltyPoints zeroes expired points so the ledger shows when they were lost
+func (s *Scheduler) expireLoyaltyPoints() {
+	expired, err := s.db.ExpireLoyaltyPoints()
+	if err != nil {
+		logrus.Errorf("Failed to expire loyalty points: %v", err)
+		return
+	}
+	if expired > 0 {
+		logrus.Infof("Expired %d loyalty points", expired)
+	}
+}
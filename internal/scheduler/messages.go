@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// messageDeleter deletes scheduled bot messages, such as revealed purchased items, every 30 seconds
+func (s *Scheduler) messageDeleter() {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.deleteDueMessages()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// deleteDueMessages deletes the messages whose time is up
+func (s *Scheduler) deleteDueMessages() {
+	deletions, err := s.db.GetDueMessageDeletions(100)
+	if err != nil {
+		logrus.Errorf("Failed to get scheduled message deletions: %v", err)
+		return
+	}
+
+	for _, deletion := range deletions {
+		// The user may have deleted the message already; either way it is not retried
+		if _, err := s.api.Request(tgbotapi.NewDeleteMessage(deletion.ChatID, deletion.MessageID)); err != nil {
+			logrus.Debugf("Failed to delete message %d in chat %d: %v", deletion.MessageID, deletion.ChatID, err)
+		}
+		if err := s.db.RemoveMessageDeletion(deletion.ID); err != nil {
+			logrus.Errorf("Failed to remove scheduled deletion %d: %v", deletion.ID, err)
+		}
+	}
+}
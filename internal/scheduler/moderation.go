@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"time"
+
+	"telegram-premium-store/internal/templates"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// banExpirer lifts temporary bans that have run out, every minute
+func (s *Scheduler) banExpirer() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.liftExpiredBans()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// liftExpiredBans unbans users whose ban has ended and lets them know they can use the bot again
+func (s *Scheduler) liftExpiredBans() {
+	userIDs, err := s.db.LiftExpiredBans()
+	if err != nil {
+		logrus.Errorf("Failed to lift expired bans: %v", err)
+		return
+	}
+
+	for _, userID := range userIDs {
+		logrus.Infof("Ban of user %d expired", userID)
+		msg := tgbotapi.NewMessage(userID, s.i18n.T(s.userLanguage(userID), "ban.expired"))
+		if _, err := templates.SendFormatted(s.api, msg); err != nil {
+			logrus.Debugf("Failed to notify user %d about their expired ban: %v", userID, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-premium-store/internal/models"
+	"telegram-premium-store/internal/templates"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// maxReviewPromptProducts caps the products rated from one prompt message, keeping the keyboard short
+const maxReviewPromptProducts = 5
+
+// reviewPrompter asks buyers to rate their purchases ReviewPromptDelayHours after payment, checking every 15 minutes
+func (s *Scheduler) reviewPrompter() {
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sendReviewPrompts()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// sendReviewPrompts sends one rating request per paid order that is old enough
+func (s *Scheduler) sendReviewPrompts() {
+	if s.config.ReviewPromptDelayHours <= 0 {
+		return
+	}
+
+	prompts, err := s.db.GetReviewPrompts(s.config.ReviewPromptDelayHours, 50)
+	if err != nil {
+		logrus.Errorf("Failed to get orders for review prompts: %v", err)
+		return
+	}
+
+	for _, prompt := range prompts {
+		// Mark first so a failed send is not retried every tick
+		if err := s.db.MarkReviewPrompted(prompt.OrderID); err != nil {
+			logrus.Errorf("Failed to mark review prompt for order %s: %v", prompt.OrderID, err)
+			continue
+		}
+		if len(prompt.Products) == 0 {
+			continue
+		}
+
+		if _, err := s.templates.Send(s.api, prompt.UserID, templates.ReviewPrompt, s.userLanguage(prompt.UserID), reviewPromptVars(prompt),
+			reviewPromptKeyboard(prompt)); err != nil {
+			logrus.Errorf("Failed to send review prompt for order %s to user %d: %v", prompt.OrderID, prompt.UserID, err)
+			continue
+		}
+
+		logrus.Infof("Review prompt sent to user %d for order %s", prompt.UserID, prompt.OrderID)
+	}
+}
+
+// reviewPromptVars lists the products of an order the buyer is asked to rate
+func reviewPromptVars(prompt models.ReviewPrompt) templates.Vars {
+	var products strings.Builder
+	for i, product := range prompt.Products {
+		if i == maxReviewPromptProducts {
+			break
+		}
+		products.WriteString(fmt.Sprintf("🔸 %s\n", product.Name))
+	}
+	return templates.Vars{
+		"products": products.String(),
+		"order_id": prompt.OrderID[:8],
+	}
+}
+
+// reviewPromptKeyboard returns a product name row followed by a row of 1-5 star buttons for each product
+func reviewPromptKeyboard(prompt models.ReviewPrompt) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, product := range prompt.Products {
+		if i == maxReviewPromptProducts {
+			break
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📱 "+product.Name, "product_header"),
+		))
+
+		var stars []tgbotapi.InlineKeyboardButton
+		for rating := 1; rating <= 5; rating++ {
+			stars = append(stars, tgbotapi.NewInlineKeyboardButtonData(
+				fmt.Sprintf("%d⭐", rating),
+				fmt.Sprintf("rate:%s:%d:%d", prompt.OrderID, product.ID, rating),
+			))
+		}
+		rows = append(rows, stars)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
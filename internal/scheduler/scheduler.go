@@ -6,7 +6,9 @@ import (
 
 	"telegram-premium-store/internal/config"
 	"telegram-premium-store/internal/database"
+	"telegram-premium-store/internal/i18n"
 	"telegram-premium-store/internal/models"
+	"telegram-premium-store/internal/templates"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sirupsen/logrus"
@@ -14,22 +16,38 @@ import (
 
 // Scheduler handles background tasks
 type Scheduler struct {
-	db     *database.DB
-	api    *tgbotapi.BotAPI
-	config *config.Config
-	stopCh chan bool
+	db        *database.DB
+	api       *tgbotapi.BotAPI
+	config    *config.Config
+	i18n      *i18n.Catalog
+	templates *templates.Store
+	stopCh    chan bool
 }
 
 // NewScheduler creates a new scheduler
-func NewScheduler(db *database.DB, api *tgbotapi.BotAPI, cfg *config.Config) *Scheduler {
+func NewScheduler(db *database.DB, api *tgbotapi.BotAPI, cfg *config.Config, catalog *i18n.Catalog) *Scheduler {
 	return &Scheduler{
-		db:     db,
-		api:    api,
-		config: cfg,
-		stopCh: make(chan bool),
+		db:        db,
+		api:       api,
+		config:    cfg,
+		i18n:      catalog,
+		templates: templates.NewStore(db),
+		stopCh:    make(chan bool),
 	}
 }
 
+// userLanguage returns the language the user chose, or the default one
+func (s *Scheduler) userLanguage(userID int64) string {
+	lang, err := s.db.GetUserLanguage(userID)
+	if err != nil {
+		logrus.Errorf("Failed to get language of user %d: %v", userID, err)
+	}
+	if lang = s.i18n.Match(lang); lang == "" {
+		return s.i18n.Default()
+	}
+	return lang
+}
+
 // Start starts the background scheduler
 func (s *Scheduler) Start() {
 	logrus.Info("🕐 Starting background scheduler...")
@@ -43,6 +61,27 @@ func (s *Scheduler) Start() {
 	// Start admin notification checker (every 30 seconds)
 	go s.adminNotificationChecker()
 
+	// Start cart expiry and abandoned-cart reminders (every 15 minutes)
+	go s.cartMaintenance()
+
+	// Start flash sale start announcements (every minute)
+	go s.flashSaleAnnouncer()
+
+	// Start loyalty points expiry (every hour)
+	go s.loyaltyPointsExpirer()
+
+	// Start expired conversation state cleanup (every hour)
+	go s.conversationCleanup()
+
+	// Start review prompts for paid orders (every 15 minutes)
+	go s.reviewPrompter()
+
+	// Start deleting revealed purchased items whose time is up (every 30 seconds)
+	go s.messageDeleter()
+
+	// Start lifting temporary user bans that have ended (every minute)
+	go s.banExpirer()
+
 	logrus.Info("✅ Background scheduler started")
 }
 
@@ -123,34 +162,21 @@ func (s *Scheduler) handleExpiredOrder(orderID string, userID int64, totalAmount
 	}
 
 	// Send expiry notification to user
-	expiredText := fmt.Sprintf(`⏰ *WAKTU PEMBAYARAN HABIS*
-
-Waktu pembayaran untuk pesanan #%s telah habis.
-
-💰 Nominal: %s
-📅 Expired: %s
-
-💡 Silakan dapat melakukan pemesanan kembali jika masih membutuhkan produk tersebut.
-
-Terima kasih atas pengertiannya.`, 
-		orderID[:8],
-		models.FormatPrice(totalAmount, s.config.CurrencySymbol),
-		time.Now().Format("02/01/2006 15:04"))
-
+	lang := s.userLanguage(userID)
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📱 Pesan Lagi", "catalog:0"),
+			tgbotapi.NewInlineKeyboardButtonData(s.i18n.T(lang, "button.order_again"), "catalog:0"),
 		),
 		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("🏠 Menu Utama", "start"),
+			tgbotapi.NewInlineKeyboardButtonData(s.i18n.T(lang, "button.main_menu"), "start"),
 		),
 	)
 
-	msg := tgbotapi.NewMessage(userID, expiredText)
-	msg.ParseMode = tgbotapi.ModeMarkdown
-	msg.ReplyMarkup = keyboard
-
-	_, err = s.api.Send(msg)
+	_, err = s.templates.Send(s.api, userID, templates.OrderExpired, lang, templates.Vars{
+		"order_id":   orderID[:8],
+		"total":      s.i18n.FormatPrice(lang, totalAmount, s.config.CurrencySymbol),
+		"expired_at": s.i18n.FormatDateTime(lang, time.Now()),
+	}, keyboard)
 	if err != nil {
 		logrus.Errorf("Failed to send expiry notification to user %d: %v", userID, err)
 	}
@@ -209,7 +235,7 @@ func (s *Scheduler) sendDailyStockAlert() {
 	if len(outOfStockProducts) > 0 {
 		alertText += "❌ *STOK HABIS:*\n"
 		for _, product := range outOfStockProducts {
-			alertText += fmt.Sprintf("• %s\n", product.Name)
+			alertText += fmt.Sprintf("• %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name))
 		}
 		alertText += "\n"
 	}
@@ -217,7 +243,7 @@ func (s *Scheduler) sendDailyStockAlert() {
 	if len(lowStockList) > 0 {
 		alertText += "⚠️ *STOK RENDAH:*\n"
 		for _, product := range lowStockList {
-			alertText += fmt.Sprintf("• %s (sisa: %d)\n", product.Name, product.Stock)
+			alertText += fmt.Sprintf("• %s (sisa: %d)\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, product.Name), product.Stock)
 		}
 		alertText += "\n"
 	}
@@ -230,7 +256,7 @@ func (s *Scheduler) sendDailyStockAlert() {
 		msg := tgbotapi.NewMessage(adminID, alertText)
 		msg.ParseMode = tgbotapi.ModeMarkdown
 
-		_, err := s.api.Send(msg)
+		_, err := templates.SendFormatted(s.api, msg)
 		if err != nil {
 			logrus.Errorf("Failed to send stock alert to admin %d: %v", adminID, err)
 		}
@@ -318,7 +344,7 @@ Gunakan /admin untuk mengelola pesanan.`,
 			msg := tgbotapi.NewMessage(adminID, notificationText)
 			msg.ParseMode = tgbotapi.ModeMarkdown
 
-			_, err := s.api.Send(msg)
+			_, err := templates.SendFormatted(s.api, msg)
 			if err != nil {
 				logrus.Errorf("Failed to send payment notification to admin %d: %v", adminID, err)
 			}
@@ -329,4 +355,23 @@ Gunakan /admin untuk mengelola pesanan.`,
 
 		logrus.Infof("Payment notification sent for order %s", orderID)
 	}
-}
\ No newline at end of file
+}
+// conversationCleanup purges expired conversation states every hour
+func (s *Scheduler) conversationCleanup() {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			removed, err := s.db.DeleteExpiredConversations()
+			if err != nil {
+				logrus.Errorf("Failed to delete expired conversation states: %v", err)
+			} else if removed > 0 {
+				logrus.Infof("Deleted %d expired conversation states", removed)
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
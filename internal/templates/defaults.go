@@ -0,0 +1,221 @@
+package templates
+
+// Template names used by the bot and scheduler
+const (
+	PaymentSuccess = "payment_success"
+	AdminSale      = "admin_sale"
+	OrderExpired   = "order_expired"
+	CartReminder   = "cart_reminder"
+	ReviewPrompt   = "review_prompt"
+)
+
+// defaults are the templates shipped with the bot, with English text for the templates buyers receive.
+// Admins can override the text of every language with /template.
+var defaults = []Definition{
+	{
+		Name:        PaymentSuccess,
+		Title:       "Pembayaran Berhasil",
+		Description: "Dikirim ke pembeli bersama data produk setelah pembayaran dikonfirmasi",
+		Format:      FormatHTML,
+		Body: `🎉 <b>PEMBAYARAN BERHASIL!</b>
+
+✅ Pembayaran Anda untuk Order #{order_id} telah dikonfirmasi.
+
+💰 Total Pembayaran: {total}
+📅 Tanggal: {date}
+
+━━━━━━━━━━━━━━━━━━━━━
+
+🔐 <b>AKUN PREMIUM ANDA:</b>
+
+{items}{gift_cards}━━━━━━━━━━━━━━━━━━━━━
+
+📋 <b>CARA MENGGUNAKAN:</b>
+1. Tap/klik pada data produk untuk menyalin
+2. 🔐 Akun: Login dengan email | password
+3. 🔗 Link: Klik atau salin link untuk redeem
+4. 🎫 Kode: Gunakan kode untuk aktivasi
+
+⚠️ <b>PENTING:</b>
+• Simpan data ini dengan aman
+• Jangan share ke orang lain
+• Segera gunakan sesuai petunjuk produk
+
+📂 Lihat lagi data produk kapan saja lewat /produkku
+💬 Butuh bantuan? Hubungi /contact
+⭐️ Terima kasih telah berbelanja!`,
+		Translations: map[string]string{
+			"en": `🎉 <b>PAYMENT SUCCESSFUL!</b>
+
+✅ Your payment for Order #{order_id} has been confirmed.
+
+💰 Amount Paid: {total}
+📅 Date: {date}
+
+━━━━━━━━━━━━━━━━━━━━━
+
+🔐 <b>YOUR PREMIUM ACCOUNTS:</b>
+
+{items}{gift_cards}━━━━━━━━━━━━━━━━━━━━━
+
+📋 <b>HOW TO USE:</b>
+1. Tap the product data to copy it
+2. 🔐 Account: log in with email | password
+3. 🔗 Link: open or copy the link to redeem
+4. 🎫 Code: use the code to activate
+
+⚠️ <b>IMPORTANT:</b>
+• Keep this data safe
+• Do not share it with anyone
+• Use it as described for the product
+
+📂 See your product data again any time with /produkku
+💬 Need help? Contact /contact
+⭐️ Thank you for shopping with us!`,
+		},
+		Vars: []Var{
+			{Name: "order_id", Description: "Nomor order (8 karakter)", Sample: "a1b2c3d4"},
+			{Name: "total", Description: "Total pembayaran", Sample: "Rp 45.000"},
+			{Name: "date", Description: "Waktu pembayaran", Sample: "17/08/2026 10:30"},
+			{Name: "items", Description: "Produk dan data akun per produk, termasuk petunjuk", Raw: true,
+				Sample: "📦 <b>Netflix_Premium 1 Bulan</b>\n   Jumlah: 1 item\n\n   Akun #1:\n   <code>user@mail.com | rahasia</code>\n\n"},
+			{Name: "gift_cards", Description: "Kode gift card yang dibeli, kosong jika tidak ada", Raw: true, Sample: ""},
+		},
+	},
+	{
+		Name:        AdminSale,
+		Title:       "Notifikasi Penjualan",
+		Description: "Dikirim ke semua admin setiap ada pesanan yang lunas",
+		Format:      FormatHTML,
+		Body: `💰 <b>PEMBERITAHUAN PENJUALAN BARU!</b>
+
+━━━━━━━━━━━━━━━━━━━━━
+
+📋 <b>INFORMASI PESANAN</b>
+🆔 Order ID: <code>{order_id}</code>
+📅 Waktu: {date}
+💰 Total: <b>{total}</b>
+✅ Status: LUNAS
+
+👤 <b>DATA PEMBELI</b>
+📛 Nama: {buyer_name}
+👤 Username: {buyer_username}
+🆔 User ID: <code>{buyer_id}</code>
+
+📦 <b>DETAIL PEMBELIAN</b>
+
+{items}━━━━━━━━━━━━━━━━━━━━━
+
+📦 <b>PRODUK YANG TERJUAL</b>
+
+{accounts}━━━━━━━━━━━━━━━━━━━━━
+
+📊 <b>STATUS STOK TERKINI</b>
+{stock}━━━━━━━━━━━━━━━━━━━━━
+
+✨ <b>Transaksi berhasil diproses!</b>`,
+		Vars: []Var{
+			{Name: "order_id", Description: "ID order lengkap", Sample: "a1b2c3d4-e5f6-7890-abcd-ef1234567890"},
+			{Name: "date", Description: "Waktu pembayaran", Sample: "17/08/2026 10:30:00"},
+			{Name: "total", Description: "Nominal yang dibayar", Sample: "Rp 45.000"},
+			{Name: "buyer_name", Description: "Nama pembeli", Sample: "Budi_Santoso"},
+			{Name: "buyer_username", Description: "Username pembeli, atau - jika tidak ada", Sample: "@budi_store"},
+			{Name: "buyer_id", Description: "User ID Telegram pembeli", Sample: "123456789"},
+			{Name: "items", Description: "Produk, jumlah dan subtotal", Raw: true,
+				Sample: "1. <b>Netflix_Premium 1 Bulan</b>\n   • Jumlah: 1 akun\n   • Harga satuan: Rp 45.000\n   • Subtotal: Rp 45.000\n\n"},
+			{Name: "accounts", Description: "Data akun yang terjual", Raw: true,
+				Sample: "📦 <b>Netflix_Premium 1 Bulan</b> (1 item):\n   1. Akun: <code>user@mail.com | rahasia</code>\n\n"},
+			{Name: "stock", Description: "Sisa stok produk yang terjual", Raw: true,
+				Sample: "• Netflix_Premium 1 Bulan:\n  ✅ Tersedia: 4 akun\n  💰 Terjual: 6 akun\n  📊 Total: 10 akun\n\n"},
+		},
+	},
+	{
+		Name:        OrderExpired,
+		Title:       "Pesanan Kedaluwarsa",
+		Description: "Dikirim ke pembeli saat waktu pembayaran pesanan habis",
+		Format:      FormatHTML,
+		Body: `⏰ <b>WAKTU PEMBAYARAN HABIS</b>
+
+Waktu pembayaran untuk pesanan #{order_id} telah habis.
+
+💰 Nominal: {total}
+📅 Expired: {expired_at}
+
+💡 Silakan dapat melakukan pemesanan kembali jika masih membutuhkan produk tersebut.
+
+Terima kasih atas pengertiannya.`,
+		Translations: map[string]string{
+			"en": `⏰ <b>PAYMENT TIME IS UP</b>
+
+The payment time for order #{order_id} has run out.
+
+💰 Amount: {total}
+📅 Expired: {expired_at}
+
+💡 Feel free to order again if you still need the product.
+
+Thank you for your understanding.`,
+		},
+		Vars: []Var{
+			{Name: "order_id", Description: "Nomor order (8 karakter)", Sample: "a1b2c3d4"},
+			{Name: "total", Description: "Nominal pesanan", Sample: "Rp 45.000"},
+			{Name: "expired_at", Description: "Waktu pesanan kedaluwarsa", Sample: "17/08/2026 10:45"},
+		},
+	},
+	{
+		Name:        CartReminder,
+		Title:       "Pengingat Keranjang",
+		Description: "Dikirim ke pembeli yang meninggalkan produk di keranjang",
+		Format:      FormatHTML,
+		Body: `🛒 <b>KERANJANG ANDA MENUNGGU!</b>
+
+Anda masih punya produk di keranjang yang belum di-checkout:
+
+{items}
+💰 <b>Total: {total}</b>
+
+{expiry_note}💡 Ketik /pengingat untuk mengatur pengingat keranjang.`,
+		Translations: map[string]string{
+			"en": `🛒 <b>YOUR CART IS WAITING!</b>
+
+You still have products in your cart that have not been checked out:
+
+{items}
+💰 <b>Total: {total}</b>
+
+{expiry_note}💡 Type /pengingat to change your cart reminder settings.`,
+		},
+		Vars: []Var{
+			{Name: "items", Description: "Produk di keranjang beserta perubahan harga dan stok", Raw: true,
+				Sample: "🔸 <b>Spotify_Family</b> x1 - Rp 25.000\n   📉 Harga berubah dari Rp 30.000\n"},
+			{Name: "total", Description: "Total harga keranjang", Sample: "Rp 25.000"},
+			{Name: "expiry_note", Description: "Kapan keranjang dikosongkan otomatis, kosong jika tidak pernah",
+				Sample: "⏰ Keranjang akan dikosongkan otomatis setelah 72 jam tanpa aktivitas.\n"},
+		},
+	},
+	{
+		Name:        ReviewPrompt,
+		Title:       "Permintaan Ulasan",
+		Description: "Dikirim ke pembeli beberapa jam setelah pembayaran untuk memberi rating",
+		Format:      FormatHTML,
+		Body: `⭐ <b>BAGAIMANA PEMBELIAN ANDA?</b>
+
+Terima kasih sudah berbelanja! Bantu pembeli lain dengan memberi rating produk berikut:
+
+{products}
+Pilih 1 sampai 5 bintang di bawah nama produk. Setelah itu Anda bisa menambahkan komentar.`,
+		Translations: map[string]string{
+			"en": `⭐ <b>HOW WAS YOUR PURCHASE?</b>
+
+Thank you for shopping with us! Help other buyers by rating these products:
+
+{products}
+Pick 1 to 5 stars below a product name. You can add a comment afterwards.`,
+		},
+		Vars: []Var{
+			{Name: "products", Description: "Produk yang bisa diberi rating, satu per baris",
+				Sample: "🔸 Netflix_Premium 1 Bulan\n🔸 Spotify_Family\n"},
+			{Name: "order_id", Description: "Nomor order (8 karakter)", Sample: "a1b2c3d4"},
+		},
+	},
+}
@@ -0,0 +1,120 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+
+	"telegram-premium-store/internal/database"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// Store renders templates with the text admins saved in the database, or the default text.
+// Nothing is cached, so an edit is used by the bot and the scheduler from the next message on.
+type Store struct {
+	db *database.DB
+}
+
+// NewStore creates a template store
+func NewStore(db *database.DB) *Store {
+	return &Store{db: db}
+}
+
+// Body returns the text in use for a template in a language and whether an admin has changed it
+func (s *Store) Body(def *Definition, lang string) (string, bool, error) {
+	custom, err := s.db.GetMessageTemplate(def.Name, lang)
+	if err != nil {
+		return def.DefaultBody(lang), false, err
+	}
+	if custom == nil {
+		return def.DefaultBody(lang), false, nil
+	}
+	return custom.Body, true, nil
+}
+
+// Render fills a template in a language with variables. If the saved text cannot be loaded the default text is used.
+func (s *Store) Render(name, lang string, vars Vars) *Rendered {
+	def := Lookup(name)
+	if def == nil {
+		// Only reachable through a typo in the code; make it visible instead of sending nothing
+		logrus.Errorf("Unknown message template %q", name)
+		return &Rendered{Text: fmt.Sprintf("[template %s]", name), Plain: fmt.Sprintf("[template %s]", name)}
+	}
+
+	body, _, err := s.Body(def, lang)
+	if err != nil {
+		logrus.Errorf("Failed to load message template %s (%s), using default: %v", name, lang, err)
+	}
+	return def.Render(body, vars)
+}
+
+// Send renders a template in the recipient's language and sends it to chatID with an optional reply markup
+func (s *Store) Send(api *tgbotapi.BotAPI, chatID int64, name, lang string, vars Vars, markup interface{}) (tgbotapi.Message, error) {
+	rendered := s.Render(name, lang, vars)
+	msg := rendered.Message(chatID)
+	msg.ReplyMarkup = markup
+	return SendWithFallback(api, msg, rendered.Plain)
+}
+
+// SendWithFallback sends a formatted message, and sends plain instead without formatting if Telegram rejects it
+func SendWithFallback(api *tgbotapi.BotAPI, msg tgbotapi.MessageConfig, plain string) (tgbotapi.Message, error) {
+	sent, err := api.Send(msg)
+	if err == nil || !IsFormattingError(err) {
+		return sent, err
+	}
+
+	logrus.Warnf("Telegram rejected message formatting for chat %d, sending as plain text: %v", msg.ChatID, err)
+	msg.Text = plain
+	msg.ParseMode = ""
+	msg.Entities = nil
+	return api.Send(msg)
+}
+
+// SendFormatted sends a message, photo or document caption, or edit. If Telegram rejects its formatting
+// it is sent again as the plain text the reader would have seen.
+func SendFormatted(api *tgbotapi.BotAPI, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	sent, err := api.Send(c)
+	if !IsFormattingError(err) {
+		return sent, err
+	}
+	plain, ok := withoutFormatting(c)
+	if !ok {
+		return sent, err
+	}
+
+	logrus.Warnf("Telegram rejected message formatting, sending as plain text: %v", err)
+	return api.Send(plain)
+}
+
+// withoutFormatting returns c with its formatting stripped, or false if c has no formatted text
+func withoutFormatting(c tgbotapi.Chattable) (tgbotapi.Chattable, bool) {
+	switch m := c.(type) {
+	case tgbotapi.MessageConfig:
+		m.Text = StripFormatting(Format(m.ParseMode), m.Text)
+		m.ParseMode, m.Entities = "", nil
+		return m, true
+	case tgbotapi.EditMessageTextConfig:
+		m.Text = StripFormatting(Format(m.ParseMode), m.Text)
+		m.ParseMode, m.Entities = "", nil
+		return m, true
+	case tgbotapi.PhotoConfig:
+		m.Caption = StripFormatting(Format(m.ParseMode), m.Caption)
+		m.ParseMode, m.CaptionEntities = "", nil
+		return m, true
+	case tgbotapi.DocumentConfig:
+		m.Caption = StripFormatting(Format(m.ParseMode), m.Caption)
+		m.ParseMode, m.CaptionEntities = "", nil
+		return m, true
+	case tgbotapi.EditMessageCaptionConfig:
+		m.Caption = StripFormatting(Format(m.ParseMode), m.Caption)
+		m.ParseMode, m.CaptionEntities = "", nil
+		return m, true
+	}
+	return c, false
+}
+
+// IsFormattingError reports whether Telegram refused a message because its formatting is invalid
+func IsFormattingError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "can't parse entities")
+}
@@ -0,0 +1,229 @@
+package templates
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Message templates
+//
+// A template is message text with {name} placeholders. Variables are escaped for the template's
+// format when they are filled in, so product names and usernames can never break the formatting.
+// Defaults ship in code; admins can override the text of any template, which is stored in the database.
+
+// Format is the Telegram parse mode a template is written in
+type Format string
+
+const (
+	FormatHTML       Format = tgbotapi.ModeHTML
+	FormatMarkdownV2 Format = tgbotapi.ModeMarkdownV2
+	FormatMarkdown   Format = tgbotapi.ModeMarkdown // Legacy Markdown of the bot's own messages, not offered for templates
+)
+
+// MaxLength is the longest text Telegram accepts in a single message
+const MaxLength = 4096
+
+// Var documents a variable a template can use
+type Var struct {
+	Name        string
+	Description string
+	Sample      string // Value used for previews
+	Raw         bool   // Filled with already formatted text by the bot, e.g. lists with bold names
+}
+
+// Definition is a template shipped in code
+type Definition struct {
+	Name         string
+	Title        string
+	Description  string
+	Format       Format
+	Body         string            // Default text in Indonesian, also used for languages without a translation
+	Translations map[string]string // Default text in other languages, by language code
+	Vars         []Var
+}
+
+// Raw is a variable value that is already formatted for the template's format and is inserted as is
+type Raw string
+
+// Vars are the values filled into a template's placeholders. Values other than Raw are
+// formatted with fmt.Sprint and escaped.
+type Vars map[string]interface{}
+
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+var registry = make(map[string]*Definition)
+
+func init() {
+	for i := range defaults {
+		registry[defaults[i].Name] = &defaults[i]
+	}
+}
+
+// Lookup returns the definition of a template, or nil if there is none with that name
+func Lookup(name string) *Definition {
+	return registry[name]
+}
+
+// All returns every template definition sorted by name
+func All() []*Definition {
+	all := make([]*Definition, 0, len(registry))
+	for _, def := range registry {
+		all = append(all, def)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// DefaultBody returns the default text of the template in a language
+func (d *Definition) DefaultBody(lang string) string {
+	if body, ok := d.Translations[lang]; ok {
+		return body
+	}
+	return d.Body
+}
+
+// HasVar reports whether the template documents a variable with that name
+func (d *Definition) HasVar(name string) bool {
+	for _, v := range d.Vars {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SampleVars returns the sample value of every variable, for previews
+func (d *Definition) SampleVars() Vars {
+	vars := make(Vars, len(d.Vars))
+	for _, v := range d.Vars {
+		if v.Raw {
+			vars[v.Name] = Raw(v.Sample)
+		} else {
+			vars[v.Name] = v.Sample
+		}
+	}
+	return vars
+}
+
+// Validate checks text an admin wants to use for the template before it is saved
+func (d *Definition) Validate(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("teks template tidak boleh kosong")
+	}
+
+	var unknown []string
+	for _, match := range placeholderPattern.FindAllStringSubmatch(body, -1) {
+		if !d.HasVar(match[1]) {
+			unknown = append(unknown, match[0])
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("variabel tidak dikenal: %s", strings.Join(unknown, ", "))
+	}
+
+	if rendered := d.Render(body, d.SampleVars()); len([]rune(rendered.Text)) > MaxLength {
+		return fmt.Errorf("pesan terlalu panjang (maksimal %d karakter)", MaxLength)
+	}
+	return nil
+}
+
+// Rendered is a template filled with variables, ready to send
+type Rendered struct {
+	Text      string // Formatted text
+	ParseMode string
+	Plain     string // The same message without formatting, sent if Telegram rejects Text
+}
+
+// Message returns a message config sending the formatted text
+func (r *Rendered) Message(chatID int64) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(chatID, r.Text)
+	msg.ParseMode = r.ParseMode
+	return msg
+}
+
+// Render fills the template text with variables. Unknown placeholders are left as they are.
+func (d *Definition) Render(body string, vars Vars) *Rendered {
+	plainBody := StripFormatting(d.Format, body)
+
+	text := placeholderPattern.ReplaceAllStringFunc(body, func(placeholder string) string {
+		value, ok := vars[placeholder[1:len(placeholder)-1]]
+		if !ok {
+			return placeholder
+		}
+		if raw, ok := value.(Raw); ok {
+			return string(raw)
+		}
+		return Escape(d.Format, fmt.Sprint(value))
+	})
+
+	plain := placeholderPattern.ReplaceAllStringFunc(plainBody, func(placeholder string) string {
+		value, ok := vars[placeholder[1:len(placeholder)-1]]
+		if !ok {
+			return placeholder
+		}
+		if raw, ok := value.(Raw); ok {
+			return StripFormatting(d.Format, string(raw))
+		}
+		return fmt.Sprint(value)
+	})
+
+	return &Rendered{Text: text, ParseMode: string(d.Format), Plain: plain}
+}
+
+// Escape makes text safe to insert into a message in the given format
+func Escape(format Format, text string) string {
+	switch format {
+	case FormatHTML:
+		return html.EscapeString(text)
+	case FormatMarkdownV2:
+		// tgbotapi does not escape the backslash itself, which MarkdownV2 requires
+		return tgbotapi.EscapeText(tgbotapi.ModeMarkdownV2, strings.ReplaceAll(text, `\`, `\\`))
+	case FormatMarkdown:
+		return tgbotapi.EscapeText(tgbotapi.ModeMarkdown, text)
+	default:
+		return text
+	}
+}
+
+var (
+	htmlTagPattern          = regexp.MustCompile(`<[^>]*>`)
+	markdownV2EscapePattern = regexp.MustCompile(`\\([\x21-\x7e])`)
+	markdownV2MarkerPattern = regexp.MustCompile("\\|\\||__|[*_~`]")
+	markdownEscapePattern   = regexp.MustCompile("\\\\([_*`\\[])")
+	markdownMarkerPattern   = regexp.MustCompile("[*_`]")
+)
+
+// StripFormatting turns formatted text into what the reader would see, for the plain text fallback
+func StripFormatting(format Format, text string) string {
+	switch format {
+	case FormatHTML:
+		return html.UnescapeString(htmlTagPattern.ReplaceAllString(text, ""))
+	case FormatMarkdownV2:
+		return stripMarkers(markdownV2EscapePattern, markdownV2MarkerPattern, text)
+	case FormatMarkdown:
+		return stripMarkers(markdownEscapePattern, markdownMarkerPattern, text)
+	default:
+		return text
+	}
+}
+
+// stripMarkers drops unescaped markers and unescapes the escaped characters
+func stripMarkers(escapePattern, markerPattern *regexp.Regexp, text string) string {
+	// Drop unescaped markers first, keeping escaped characters by protecting them with a placeholder byte
+	protected := escapePattern.ReplaceAllString(text, "\x00$1")
+	var out strings.Builder
+	parts := strings.Split(protected, "\x00")
+	for i, part := range parts {
+		if i > 0 && part != "" {
+			out.WriteByte(part[0])
+			part = part[1:]
+		}
+		out.WriteString(markerPattern.ReplaceAllString(part, ""))
+	}
+	return out.String()
+}
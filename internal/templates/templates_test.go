@@ -0,0 +1,205 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		text   string
+		want   string
+	}{
+		{"html", FormatHTML, `Netflix <4K> & "Family"`, "Netflix &lt;4K&gt; &amp; &#34;Family&#34;"},
+		{"markdownv2", FormatMarkdownV2, "Spotify_Premium (1 bulan) *promo*", `Spotify\_Premium \(1 bulan\) \*promo\*`},
+		{"markdownv2 dot and dash", FormatMarkdownV2, "v1.2 - new!", `v1\.2 \- new\!`},
+		{"markdown", FormatMarkdown, "Netflix_Premium *4K* [promo]", `Netflix\_Premium \*4K\* \[promo]`},
+		{"unknown format", Format("plain"), "*as is*", "*as is*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Escape(tt.format, tt.text); got != tt.want {
+				t.Errorf("Escape(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripFormatting(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		text   string
+		want   string
+	}{
+		{"html tags", FormatHTML, "<b>Total:</b> <code>Rp 10.000</code>", "Total: Rp 10.000"},
+		{"html entities", FormatHTML, "A &amp; B &lt;3", "A & B <3"},
+		{"markdownv2 markers", FormatMarkdownV2, "*Bold* _italic_ __under__ ~strike~ `code` ||spoiler||", "Bold italic under strike code spoiler"},
+		{"markdownv2 escaped characters are kept", FormatMarkdownV2, `Harga Rp 10\.000 \(promo\) \*`, "Harga Rp 10.000 (promo) *"},
+		{"markdownv2 escaped marker next to a marker", FormatMarkdownV2, `*5\* rating*`, "5* rating"},
+		{"escaped backslash", FormatMarkdownV2, `a\\b`, `a\b`},
+		{"markdown markers", FormatMarkdown, "*Total:* _Rp 10.000_ `AB12`", "Total: Rp 10.000 AB12"},
+		{"markdown escaped characters are kept", FormatMarkdown, `*Netflix\_Premium* \*promo\*`, "Netflix_Premium *promo*"},
+		{"unknown format", Format("plain"), "*as is*", "*as is*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripFormatting(tt.format, tt.text); got != tt.want {
+				t.Errorf("StripFormatting(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripFormattingOfEscape(t *testing.T) {
+	// Whatever Escape produces must read as the original text in the plain fallback
+	texts := []string{"Netflix <4K> & Co", "Spotify_Premium (1 bulan) *promo* v1.2!", `back\slash`}
+	for _, format := range []Format{FormatHTML, FormatMarkdownV2, FormatMarkdown} {
+		for _, text := range texts {
+			if got := StripFormatting(format, Escape(format, text)); got != text {
+				t.Errorf("%s: StripFormatting(Escape(%q)) = %q", format, text, got)
+			}
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    Format
+		body      string
+		vars      Vars
+		wantText  string
+		wantPlain string
+	}{
+		{
+			name:      "html escapes values",
+			format:    FormatHTML,
+			body:      "<b>{product}</b> x{quantity}",
+			vars:      Vars{"product": "Netflix <4K>", "quantity": 2},
+			wantText:  "<b>Netflix &lt;4K&gt;</b> x2",
+			wantPlain: "Netflix <4K> x2",
+		},
+		{
+			name:      "markdownv2 escapes values",
+			format:    FormatMarkdownV2,
+			body:      "*{product}* \\- {price}",
+			vars:      Vars{"product": "Spotify_Premium", "price": "Rp 10.000"},
+			wantText:  `*Spotify\_Premium* \- Rp 10\.000`,
+			wantPlain: "Spotify_Premium - Rp 10.000",
+		},
+		{
+			name:      "raw values are inserted as is",
+			format:    FormatHTML,
+			body:      "Items:\n{items}",
+			vars:      Vars{"items": Raw("<b>Netflix</b> &amp; Spotify")},
+			wantText:  "Items:\n<b>Netflix</b> &amp; Spotify",
+			wantPlain: "Items:\nNetflix & Spotify",
+		},
+		{
+			name:      "unknown placeholders are left alone",
+			format:    FormatHTML,
+			body:      "Hi {name}, order {order_id}",
+			vars:      Vars{"name": "Budi"},
+			wantText:  "Hi Budi, order {order_id}",
+			wantPlain: "Hi Budi, order {order_id}",
+		},
+		{
+			name:      "formatting inside values is not interpreted",
+			format:    FormatMarkdownV2,
+			body:      "{comment}",
+			vars:      Vars{"comment": "*not bold*"},
+			wantText:  `\*not bold\*`,
+			wantPlain: "*not bold*",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := &Definition{Name: "test", Format: tt.format, Body: tt.body}
+			rendered := def.Render(tt.body, tt.vars)
+			if rendered.Text != tt.wantText {
+				t.Errorf("Text = %q, want %q", rendered.Text, tt.wantText)
+			}
+			if rendered.Plain != tt.wantPlain {
+				t.Errorf("Plain = %q, want %q", rendered.Plain, tt.wantPlain)
+			}
+			if rendered.ParseMode != string(tt.format) {
+				t.Errorf("ParseMode = %q, want %q", rendered.ParseMode, tt.format)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	def := &Definition{
+		Name:   "test",
+		Format: FormatHTML,
+		Vars: []Var{
+			{Name: "order_id", Sample: "AB12CD34"},
+			{Name: "items", Sample: "<b>Netflix</b>", Raw: true},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"known variables", "Order {order_id}\n{items}", ""},
+		{"no variables", "Terima kasih!", ""},
+		{"empty", "  \n ", "tidak boleh kosong"},
+		{"unknown variable", "Order {order_id} {total}", "{total}"},
+		{"too long", strings.Repeat("a", MaxLength+1), "terlalu panjang"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := def.Validate(tt.body)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultsValidate(t *testing.T) {
+	for _, def := range All() {
+		if err := def.Validate(def.Body); err != nil {
+			t.Errorf("default template %s does not validate: %v", def.Name, err)
+		}
+		for lang, body := range def.Translations {
+			if err := def.Validate(body); err != nil {
+				t.Errorf("default template %s (%s) does not validate: %v", def.Name, lang, err)
+			}
+		}
+	}
+}
+
+func TestDefaultBody(t *testing.T) {
+	def := &Definition{Name: "test", Body: "Halo", Translations: map[string]string{"en": "Hello"}}
+
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{"id", "Halo"},
+		{"en", "Hello"},
+		{"fr", "Halo"},
+	}
+	for _, tt := range tests {
+		if got := def.DefaultBody(tt.lang); got != tt.want {
+			t.Errorf("DefaultBody(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}